@@ -0,0 +1,72 @@
+package lmsensors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// AdapterDevice is the kernel device behind an i2c bus, so that chips on identically-named adapters, eg one per socket, can be told apart.
+type AdapterDevice struct {
+	Path            string      // The adapter's device, eg /sys/devices/pci0000:00/0000:00:14.0/i2c-1
+	Name            string      // Its description, eg "SMBus PIIX4 adapter port 0 at 0b00"
+	Parent          string      // The device providing it, eg 0000:00:14.0
+	ParentSubsystem string      // eg "pci"
+	PCI             *PCIAddress `json:",omitempty"` // The parent's address, if it's a PCI device
+}
+
+// PCIAddress is a PCI device's address, eg 0000:00:14.0.
+type PCIAddress struct {
+	Domain, Bus, Slot, Function int
+}
+
+func (a PCIAddress) String() string {
+	return fmt.Sprintf("%04x:%02x:%02x.%x", a.Domain, a.Bus, a.Slot, a.Function)
+}
+
+// ResolveAdapter finds the device behind an i2c bus number, under [SysfsRoot].
+func ResolveAdapter(nr int) (*AdapterDevice, error) {
+	path, err := filepath.EvalSymlinks(filepath.Join(SysfsRoot, "class", "i2c-adapter", "i2c-"+strconv.Itoa(nr)))
+	if err != nil {
+		return nil, err
+	}
+	parent := filepath.Dir(path)
+	ad := &AdapterDevice{
+		Path:   path,
+		Name:   readSysfsString(filepath.Join(path, "name")),
+		Parent: filepath.Base(parent),
+	}
+	if subsys, err := os.Readlink(filepath.Join(parent, "subsystem")); err == nil {
+		ad.ParentSubsystem = filepath.Base(subsys)
+	}
+	if ad.ParentSubsystem == "pci" {
+		var a PCIAddress
+		if _, err := fmt.Sscanf(ad.Parent, "%x:%x:%x.%x", &a.Domain, &a.Bus, &a.Slot, &a.Function); err == nil {
+			ad.PCI = &a
+		}
+	}
+	return ad, nil
+}
+
+// adapterDevice is [ResolveAdapter], resolved once per bus per Init rather than for every chip on every Get, or nil if it can't be.
+// Each call gets its own copy.
+func adapterDevice(nr int) *AdapterDevice {
+	machineMu.Lock()
+	defer machineMu.Unlock()
+	m := machineLocked()
+	ad, ok := m.adapters[nr]
+	if !ok {
+		ad, _ = ResolveAdapter(nr)
+		m.adapters[nr] = ad
+	}
+	if ad == nil {
+		return nil
+	}
+	c := *ad
+	if ad.PCI != nil {
+		pci := *ad.PCI
+		c.PCI = &pci
+	}
+	return &c
+}
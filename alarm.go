@@ -0,0 +1,43 @@
+package lmsensors
+
+// AlarmEvent reports a sensor going into, or out of, alarm.
+type AlarmEvent struct {
+	Chip   string
+	Sensor string
+	Rule   string // Name of the [Threshold] that raised it, or empty for the hardware's own alarm flag
+	Value  float64
+	Raised bool // false when the alarm clears
+}
+
+type alarmDetector struct {
+	fn     func(AlarmEvent)
+	raised map[string]bool
+}
+
+// WithAlarmDetection reports sensors whose hardware alarm flag (see [Sensor.Alarm]) is raised, or cleared.
+func WithAlarmDetection(fn func(AlarmEvent)) WatcherOption {
+	return func(w *Watcher) {
+		w.alarms = &alarmDetector{fn: fn, raised: map[string]bool{}}
+	}
+}
+
+func (d *alarmDetector) observe(sys *System) {
+	for _, chip := range sys.Chips {
+		for name, sensor := range chip.Sensors {
+			if !Valid(sensor) {
+				continue // Neither raised nor cleared by a failed read
+			}
+			key := sensorKey(chip.ID, name)
+			alarm := sensor.Alarm()
+			if alarm == d.raised[key] {
+				continue
+			}
+			if alarm {
+				d.raised[key] = true
+			} else {
+				delete(d.raised, key)
+			}
+			d.fn(AlarmEvent{Chip: chip.ID, Sensor: name, Value: sensor.Reading(), Raised: alarm})
+		}
+	}
+}
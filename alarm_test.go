@@ -0,0 +1,60 @@
+package lmsensors
+
+import "testing"
+
+func TestAlarmDetection(t *testing.T) {
+	var events []AlarmEvent
+	w := NewWatcher(0, WithAlarmDetection(func(e AlarmEvent) {
+		events = append(events, e)
+	}))
+
+	for _, alarm := range []bool{false, true, true, false} {
+		w.observe(&System{Chips: map[string]*Chip{
+			"it87-isa-0290": {ID: "it87-isa-0290", Sensors: map[string]Sensor{
+				"intrusion0": &IntrusionSensor{Name: "intrusion0", alarm: alarm},
+			}},
+		}})
+	}
+	if len(events) != 2 || !events[0].Raised || events[1].Raised || events[0].Sensor != "intrusion0" || events[0].Value != 1 {
+		t.Errorf("wrong events: %v", events)
+	}
+}
+
+func TestAlarmDetectionTemp(t *testing.T) {
+	var events []AlarmEvent
+	w := NewWatcher(0, WithAlarmDetection(func(e AlarmEvent) {
+		events = append(events, e)
+	}))
+
+	for _, alarm := range []bool{false, true, false} {
+		w.observe(&System{Chips: map[string]*Chip{
+			"nct6798-isa-0290": {ID: "nct6798-isa-0290", Sensors: map[string]Sensor{
+				"SYSTIN": &TempSensor{baseSensor: baseSensor{Name: "SYSTIN", Value: 85, alarm: alarm}},
+			}},
+		}})
+	}
+	if len(events) != 2 || !events[0].Raised || events[1].Raised || events[0].Sensor != "SYSTIN" || events[0].Value != 85 {
+		t.Errorf("wrong events: %v", events)
+	}
+}
+
+func TestAlarmDetectionFailedRead(t *testing.T) {
+	var events []AlarmEvent
+	w := NewWatcher(0, WithAlarmDetection(func(e AlarmEvent) {
+		events = append(events, e)
+	}))
+
+	for _, s := range []Sensor{
+		&TempSensor{baseSensor: baseSensor{Name: "SYSTIN", Value: 85, alarm: true}},
+		&TempSensor{baseSensor: baseSensor{Name: "SYSTIN", Value: NoValue}},
+		nil,
+		&TempSensor{baseSensor: baseSensor{Name: "SYSTIN", Value: 85, alarm: true}},
+	} {
+		w.observe(&System{Chips: map[string]*Chip{
+			"nct6798-isa-0290": {ID: "nct6798-isa-0290", Sensors: map[string]Sensor{"SYSTIN": s}},
+		}})
+	}
+	if len(events) != 1 || !events[0].Raised {
+		t.Errorf("a failed read raised or cleared the alarm: %v", events)
+	}
+}
@@ -0,0 +1,244 @@
+package lmsensors
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// AlarmNotifier delivers sensors' hardware alarms as they're raised and cleared, without reading every sensor over and over as a [Watcher] with [WithAlarmDetection] does.
+// Drivers that support it, eg lm90 with its ALERT line wired up, notify changes to their alarm attributes, which it waits on with epoll; the rest it reads on a timer.
+// Whether an attribute notifies is only known once it has, so until then it's read on the timer too, unless its driver's [Quirk.AlarmNotify] says it will.
+// Sensors are named as [GetSysfs] names them. Chips that appear after it starts aren't watched.
+type AlarmNotifier struct {
+	Sensors  Selector      // Defaults to all of them
+	Interval time.Duration // Between reads of the alarms that don't notify; defaults to a second
+	OnAlarm  func(AlarmEvent)
+}
+
+type notifySensor struct {
+	chip, name string
+	input      sampledSensor
+	alarms     []*notifyAttr
+	raised     bool
+}
+
+type notifyAttr struct {
+	sensor   *notifySensor
+	file     attrFile
+	polled   *os.File // The file registered with epoll, if any
+	raised   bool
+	notifies bool
+}
+
+// timed is whether the attribute needs reading on the timer.
+func (a *notifyAttr) timed() bool {
+	return !a.notifies || a.polled == nil
+}
+
+// Run reports the sensors in alarm when it starts, and then every change, until ctx is done.
+func (n *AlarmNotifier) Run(ctx context.Context) error {
+	sensors, err := n.sensors()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, s := range sensors {
+			s.input.file.close()
+			for _, a := range s.alarms {
+				a.file.close()
+			}
+		}
+	}()
+
+	epfd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(epfd)
+	// ctx being done is signalled through a pipe, so the wait doesn't have to time out to notice.
+	var wake [2]int
+	if err := syscall.Pipe2(wake[:], syscall.O_CLOEXEC|syscall.O_NONBLOCK); err != nil {
+		return err
+	}
+	defer syscall.Close(wake[0])
+	defer syscall.Close(wake[1])
+	if err := syscall.EpollCtl(epfd, syscall.EPOLL_CTL_ADD, wake[0], &syscall.EpollEvent{Events: syscall.EPOLLIN, Fd: int32(wake[0])}); err != nil {
+		return err
+	}
+	done, exited := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			_, _ = syscall.Write(wake[1], []byte{0})
+		case <-done:
+		}
+	}()
+	// Deferred last, so run first: the pipe's only closed once nothing can write to it.
+	defer func() {
+		close(done)
+		<-exited
+	}()
+
+	p := poller{epfd: epfd, byFD: map[int]*notifyAttr{}}
+	var buf [32]byte
+	read := func(a *notifyAttr) {
+		// sysfs only notifies an attribute's waiters of changes after it's been read.
+		b, err := a.file.read(buf[:])
+		v, ok := parseAttr(b)
+		a.raised = err == nil && ok && v != 0
+		p.register(a)
+	}
+	for _, s := range sensors {
+		for _, a := range s.alarms {
+			read(a)
+		}
+		n.update(s)
+	}
+
+	interval := n.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	next := time.Now().Add(interval)
+	events := make([]syscall.EpollEvent, 16)
+	for {
+		timeout := -1
+		if timed(sensors) {
+			timeout = int(max(time.Until(next)+time.Millisecond-1, 0) / time.Millisecond) // Rounded up, so as not to wake just before
+		}
+		nev, err := syscall.EpollWait(epfd, events, timeout)
+		if err != nil && !errors.Is(err, syscall.EINTR) {
+			return err
+		}
+		for _, ev := range events[:max(nev, 0)] {
+			if int(ev.Fd) == wake[0] {
+				return ctx.Err()
+			}
+			if a, ok := p.byFD[int(ev.Fd)]; ok {
+				a.notifies = true
+				read(a)
+				n.update(a.sensor)
+			}
+		}
+		if now := time.Now(); !now.Before(next) {
+			for _, s := range sensors {
+				for _, a := range s.alarms {
+					if a.timed() {
+						read(a)
+					}
+				}
+				n.update(s)
+			}
+			next = next.Add(interval)
+			if next.Before(now) {
+				next = now.Add(interval)
+			}
+		}
+	}
+}
+
+// poller is the epoll set of the alarm attributes that can be waited on.
+type poller struct {
+	epfd int
+	byFD map[int]*notifyAttr
+}
+
+// register adds an attribute's file to the set, if it can be waited on and isn't already.
+func (p poller) register(a *notifyAttr) {
+	if a.file.f == a.polled {
+		return
+	}
+	// A file that's been reopened was closed, which took it out of the epoll set.
+	for fd, b := range p.byFD {
+		if b == a {
+			delete(p.byFD, fd)
+		}
+	}
+	a.polled = nil
+	if a.file.f == nil {
+		return
+	}
+	fd := int(a.file.f.Fd())
+	// Regular files, eg in fixture trees, can't be waited on, and are left to the timer.
+	if syscall.EpollCtl(p.epfd, syscall.EPOLL_CTL_ADD, fd, &syscall.EpollEvent{Events: syscall.EPOLLPRI | syscall.EPOLLERR, Fd: int32(fd)}) == nil {
+		a.polled = a.file.f
+		p.byFD[fd] = a
+	}
+}
+
+// timed is whether any of the alarms need reading on the timer.
+func timed(sensors []*notifySensor) bool {
+	for _, s := range sensors {
+		for _, a := range s.alarms {
+			if a.timed() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sensors finds the alarm attributes of the selected sensors.
+func (n *AlarmNotifier) sensors() ([]*notifySensor, error) {
+	sys, err := GetSysfs()
+	if err != nil {
+		return nil, err
+	}
+	sel := n.Sensors
+	if sel == "" {
+		sel = "*/*"
+	}
+	var sensors []*notifySensor
+	for _, chip := range sys.Chips {
+		notifies := quirkOf(chip.Type).AlarmNotify
+		for name, sensor := range chip.Sensors {
+			b, ok := sensor.(interface{ base() *baseSensor })
+			if !ok || b.base().feature.dir == "" || !sel.Match(chip.ID, name) {
+				continue
+			}
+			f := b.base().feature
+			// eg temp1_alarm, temp1_max_alarm and temp1_crit_alarm
+			paths, _ := filepath.Glob(filepath.Join(f.dir, f.name+"_*alarm"))
+			if len(paths) == 0 {
+				continue
+			}
+			s := &notifySensor{chip: chip.ID, name: name}
+			s.input, _ = openSampled(sensorKey(chip.ID, name), f) // Without an input, the events' values are NoValue
+			for _, path := range paths {
+				s.alarms = append(s.alarms, &notifyAttr{sensor: s, file: attrFile{path: path}, notifies: notifies})
+			}
+			sensors = append(sensors, s)
+		}
+	}
+	// So that the events at the start come in a stable order.
+	sort.Slice(sensors, func(i, j int) bool {
+		return sensorKey(sensors[i].chip, sensors[i].name) < sensorKey(sensors[j].chip, sensors[j].name)
+	})
+	return sensors, nil
+}
+
+// update reports the sensor going into or out of alarm, if it has, reading its value to go with it.
+func (n *AlarmNotifier) update(s *notifySensor) {
+	raised := false
+	for _, a := range s.alarms {
+		raised = raised || a.raised
+	}
+	if raised == s.raised {
+		return
+	}
+	s.raised = raised
+	val := NoValue
+	if s.input.file.path != "" {
+		var buf [32]byte
+		val, _ = s.input.read(buf[:])
+	}
+	if n.OnAlarm != nil {
+		n.OnAlarm(AlarmEvent{Chip: s.chip, Sensor: s.name, Value: val, Raised: raised})
+	}
+}
@@ -0,0 +1,161 @@
+package lmsensors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestAlarmNotifier(t *testing.T) {
+	root := t.TempDir()
+	defer func() { SysfsRoot = "/sys" }()
+	SysfsRoot = root
+	dir := filepath.Join(root, "class", "hwmon", "hwmon0")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(attr, v string) {
+		if err := os.WriteFile(filepath.Join(dir, attr), []byte(v+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("name", "lm90")
+	write("temp1_input", "45000")
+	write("temp1_max_alarm", "0")
+	write("temp1_crit_alarm", "0")
+	write("temp2_input", "90000")
+	write("temp2_alarm", "1")
+	write("temp3_input", "30000") // No alarms, so not watched
+
+	events := make(chan AlarmEvent, 10)
+	n := &AlarmNotifier{Interval: 5 * time.Millisecond, OnAlarm: func(e AlarmEvent) { events <- e }}
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() { errs <- n.Run(ctx) }()
+	next := func() AlarmEvent {
+		t.Helper()
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(time.Second):
+			t.Fatal("no event")
+			return AlarmEvent{}
+		}
+	}
+
+	// Fixture files can't be waited on, so this is the fallback of reading them on the timer.
+	if e := next(); e.Chip != "lm90-virtual-0" || e.Sensor != "temp2" || !e.Raised || e.Value != 90 {
+		t.Errorf("at the start: %+v", e)
+	}
+	write("temp1_input", "101000")
+	write("temp1_crit_alarm", "1")
+	if e := next(); e.Sensor != "temp1" || !e.Raised || e.Value != 101 {
+		t.Errorf("raised: %+v", e)
+	}
+	write("temp1_max_alarm", "1")
+	write("temp1_crit_alarm", "0")
+	write("temp2_alarm", "0")
+	if e := next(); e.Sensor != "temp2" || e.Raised {
+		t.Errorf("cleared: %+v", e)
+	}
+	write("temp1_max_alarm", "0")
+	if e := next(); e.Sensor != "temp1" || e.Raised {
+		t.Errorf("cleared: %+v", e)
+	}
+
+	cancel()
+	select {
+	case err := <-errs:
+		if err != context.Canceled {
+			t.Errorf("run: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("didn't stop")
+	}
+	select {
+	case e := <-events:
+		t.Errorf("spurious %+v", e)
+	default:
+	}
+}
+
+// pollable is a real sysfs attribute, which unlike the fixtures can be waited on; it's never notified, but any numeric one will do.
+const pollable = "/sys/kernel/profiling"
+
+func TestAlarmNotifierEpoll(t *testing.T) {
+	if _, err := os.ReadFile(pollable); err != nil {
+		t.Skip(err)
+	}
+	epfd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Close(epfd)
+	p := poller{epfd: epfd, byFD: map[int]*notifyAttr{}}
+	var buf [32]byte
+
+	a := &notifyAttr{file: attrFile{path: pollable}, notifies: true}
+	defer a.file.close()
+	if _, err := a.file.read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	p.register(a)
+	if a.polled == nil || a.timed() {
+		t.Fatal("not waited on")
+	}
+	// Reopened, eg after its device came back, it's registered again under its new descriptor.
+	a.file.close()
+	if _, err := a.file.read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	p.register(a)
+	if a.polled != a.file.f || len(p.byFD) != 1 {
+		t.Errorf("not reregistered: %v", p.byFD)
+	}
+	if n, err := syscall.EpollWait(epfd, make([]syscall.EpollEvent, 1), 10); n != 0 || err != nil {
+		t.Errorf("woken without a notification: %d %v", n, err)
+	}
+
+	fixture := &notifyAttr{file: attrFile{path: filepath.Join(t.TempDir(), "temp1_alarm")}, notifies: true}
+	if err := os.WriteFile(fixture.file.path, []byte("0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defer fixture.file.close()
+	fixture.file.read(buf[:])
+	p.register(fixture)
+	if !fixture.timed() {
+		t.Error("regular file not left to the timer")
+	}
+
+	// With every alarm waited on, there's no timer, and the run only ends by being cancelled.
+	root := t.TempDir()
+	defer func() { SysfsRoot = "/sys" }()
+	SysfsRoot = root
+	dir := filepath.Join(root, "class", "hwmon", "hwmon0")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "name"), []byte("notifytest\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "temp1_input"), []byte("45000\n"), 0o644)
+	if err := os.Symlink(pollable, filepath.Join(dir, "temp1_alarm")); err != nil {
+		t.Fatal(err)
+	}
+	RegisterQuirk("notifytest", Quirk{AlarmNotify: true})
+	n := &AlarmNotifier{Interval: time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() { errs <- n.Run(ctx) }()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-errs:
+		if err != context.Canceled {
+			t.Errorf("run: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("didn't stop")
+	}
+}
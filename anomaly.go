@@ -0,0 +1,129 @@
+package lmsensors
+
+import (
+	"math"
+	"time"
+)
+
+// Anomaly is how unusual a reading is, as judged by an [AnomalyDetector].
+type Anomaly struct {
+	Expected float64 // What it was expected to be, eg a moving average
+	Score    float64 // How far from that it is, in the detector's units, eg standard deviations for [EWMA]
+}
+
+// AnomalyEvent reports a sensor whose readings have become unusual for it, or have gone back to normal, see [WithAnomalyDetection].
+type AnomalyEvent struct {
+	Chip      string
+	Sensor    string
+	Value     float64
+	Time      time.Time
+	Anomaly        // As of this reading
+	Anomalous bool // false when it's back to normal
+}
+
+// AnomalyDetector judges whether readings are unusual, relative to each sensor's history rather than to fixed thresholds, which miss failures like fans slowly wearing out.
+// It's given each new, valid reading of every sensor, in order, from the polling goroutine.
+type AnomalyDetector interface {
+	// Observe takes a reading of a sensor, by chip/sensor, and says whether it's anomalous.
+	Observe(key string, at time.Time, value float64) (Anomaly, bool)
+}
+
+type anomalyDetector struct {
+	d         AnomalyDetector
+	fn        func(AnomalyEvent)
+	last      map[string]time.Time
+	anomalous map[string]bool
+}
+
+// WithAnomalyDetection feeds every sensor's readings to the detector, eg an [EWMA], calling fn when a sensor's readings become anomalous, and again when they're back to normal.
+// Readings carried over by [WithChipInterval] aren't given to it again.
+func WithAnomalyDetection(d AnomalyDetector, fn func(AnomalyEvent)) WatcherOption {
+	return func(w *Watcher) {
+		w.anomalies = &anomalyDetector{d: d, fn: fn, last: map[string]time.Time{}, anomalous: map[string]bool{}}
+	}
+}
+
+func (d *anomalyDetector) observe(sys *System) {
+	for _, chip := range sys.Chips {
+		for name, sensor := range chip.Sensors {
+			val, ok := valueOf(sensor)
+			if !ok {
+				continue
+			}
+			key := sensorKey(chip.ID, name)
+			at := TimeOf(sensor)
+			if at.IsZero() {
+				at = sys.Time
+			}
+			if last, ok := d.last[key]; ok && !at.IsZero() && !at.After(last) {
+				continue
+			}
+			d.last[key] = at
+			a, anomalous := d.d.Observe(key, at, val)
+			if anomalous == d.anomalous[key] {
+				continue
+			}
+			d.anomalous[key] = anomalous
+			d.fn(AnomalyEvent{Chip: chip.ID, Sensor: name, Value: val, Time: at, Anomaly: a, Anomalous: anomalous})
+		}
+	}
+}
+
+// EWMA is an [AnomalyDetector] that keeps an exponentially weighted moving average and variance of each sensor, and flags readings too many standard deviations from the average, ie with too high a z-score.
+// Its zero value is ready to use, with the defaults.
+// Because the average follows the readings, slow changes, like a fan's bearing wearing over months, aren't flagged; ones over minutes or hours, like it seizing, or dust building up, are.
+type EWMA struct {
+	Alpha     float64 // The weight of each new reading, from 0 to 1; defaults to 0.05, so the average is over about the last 40 readings
+	Threshold float64 // How many standard deviations is anomalous; defaults to 4
+	Warmup    int     // How many readings of a sensor to take before judging any; defaults to 30
+
+	// MinStddev is the least the standard deviation's taken to be, so that a sensor that has read the same for a while isn't flagged for its next change; defaults to 1% of the average.
+	MinStddev float64
+
+	state map[string]*ewmaState
+}
+
+type ewmaState struct {
+	mean, variance float64
+	n              int
+}
+
+func (e *EWMA) Observe(key string, _ time.Time, value float64) (Anomaly, bool) {
+	alpha, threshold, warmup := e.Alpha, e.Threshold, e.Warmup
+	if alpha <= 0 || alpha > 1 {
+		alpha = 0.05
+	}
+	if threshold <= 0 {
+		threshold = 4
+	}
+	if warmup <= 0 {
+		warmup = 30
+	}
+	if e.state == nil {
+		e.state = map[string]*ewmaState{}
+	}
+	st, ok := e.state[key]
+	if !ok {
+		e.state[key] = &ewmaState{mean: value, n: 1}
+		return Anomaly{Expected: value}, false
+	}
+
+	stddev := math.Sqrt(st.variance)
+	if e.MinStddev > 0 {
+		stddev = max(stddev, e.MinStddev)
+	} else {
+		stddev = max(stddev, math.Abs(st.mean)/100)
+	}
+	a := Anomaly{Expected: st.mean}
+	if stddev > 0 {
+		a.Score = math.Abs(value-st.mean) / stddev
+	}
+	anomalous := st.n >= warmup && a.Score > threshold
+
+	// Anomalous readings are taken into the average too, so that a lasting change, like a new fan curve, stops being flagged once it's been seen for a while.
+	d := value - st.mean
+	st.mean += alpha * d
+	st.variance = (1 - alpha) * (st.variance + alpha*d*d)
+	st.n++
+	return a, anomalous
+}
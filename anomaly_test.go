@@ -0,0 +1,71 @@
+package lmsensors
+
+import (
+	"testing"
+	"time"
+)
+
+func fanSystem(at time.Time, rpm float64) *System {
+	return &System{Time: at, Chips: map[string]*Chip{
+		"nct6798-isa-0290": {ID: "nct6798-isa-0290", Sensors: map[string]Sensor{
+			"fan2": &FanSensor{baseSensor: baseSensor{Name: "fan2", Value: rpm, Time: at}},
+		}},
+	}}
+}
+
+func TestAnomalyDetection(t *testing.T) {
+	var events []AnomalyEvent
+	w := NewWatcher(0, WithAnomalyDetection(&EWMA{Warmup: 10}, func(e AnomalyEvent) {
+		events = append(events, e)
+	}))
+
+	// A fan wobbling around 1200 RPM, which then drops to 900 in a minute, and stays there.
+	at := time.Unix(1700000000, 0)
+	rpm := func(i int) float64 {
+		switch {
+		case i < 60:
+			return 1200 + float64(i%3-1)*10
+		case i < 66:
+			return 1200 - float64(i-59)*50
+		default:
+			return 900
+		}
+	}
+	for i := range 300 {
+		sys := fanSystem(at.Add(time.Duration(i)*10*time.Second), rpm(i))
+		w.observe(sys)
+		w.observe(sys) // Carried over, so not seen again
+	}
+	if len(events) != 2 {
+		t.Fatalf("wrong events: %+v", events)
+	}
+	if e := events[0]; !e.Anomalous || e.Sensor != "fan2" || e.Value >= 1200 || e.Expected < 1150 || e.Score <= 4 {
+		t.Errorf("wrong anomaly: %+v", e)
+	}
+	if e := events[1]; e.Anomalous || e.Value != 900 {
+		t.Errorf("wrong return to normal: %+v", e)
+	}
+}
+
+func TestEWMA(t *testing.T) {
+	e := &EWMA{Warmup: 5, Threshold: 3}
+	at := time.Unix(1700000000, 0)
+	// A temperature that's read 40 for ages isn't anomalous at 41, as the standard deviation's floored.
+	for range 100 {
+		if _, ok := e.Observe("k10temp-pci-00c3/Tctl", at, 40); ok {
+			t.Fatal("flat readings anomalous")
+		}
+	}
+	if a, ok := e.Observe("k10temp-pci-00c3/Tctl", at, 41); ok || a.Expected != 40 {
+		t.Errorf("step of 1 anomalous: %+v", a)
+	}
+	if a, ok := e.Observe("k10temp-pci-00c3/Tctl", at, 60); !ok || a.Score < 3 {
+		t.Errorf("jump not anomalous: %+v", a)
+	}
+	// Nor during the warmup.
+	for _, v := range []float64{40, 80, 40, 80} {
+		if _, ok := e.Observe("k10temp-pci-00c3/Tccd1", at, v); ok {
+			t.Error("anomalous during warmup")
+		}
+	}
+}
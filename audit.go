@@ -0,0 +1,83 @@
+package lmsensors
+
+import (
+	"errors"
+	"sync/atomic"
+
+	sf "github.com/mt-inside/go-lmsensors/subfeature"
+)
+
+// WriteEvent describes a write to the hardware, made or, in dry-run mode, not.
+type WriteEvent struct {
+	Chip       string
+	Prefix     string        // The chip's prefix, eg nct6798
+	Attr       string        // The sysfs attribute written, eg pwm1 or temp1_max
+	Path       string        // The full path of Attr
+	SubFeature sf.SubFeature // If written through libsensors
+	Old        float64       // The value before the write, unless OldErr
+	OldErr     error
+	New        float64
+	DryRun     bool  // Whether the write was skipped because of dry-run mode
+	Err        error // The result of the write
+}
+
+// AuditFunc is told about every write, after it's made, or skipped in dry-run mode.
+type AuditFunc func(WriteEvent)
+
+var (
+	dryRun    atomic.Bool
+	readOnly  atomic.Bool
+	auditFunc atomic.Pointer[AuditFunc]
+)
+
+// ErrReadOnlyMode is returned by every write path in read-only mode, see [SetReadOnly].
+var ErrReadOnlyMode = errors.New("read-only mode")
+
+// SetReadOnly turns read-only mode on or off. In read-only mode every write path fails with [ErrReadOnlyMode], without touching the hardware, so monitoring-only deployments can be sure nothing's changed.
+// Unlike dry-run mode, the writes fail; they're still reported to the [AuditFunc].
+// It applies to the whole process, and to writes that start after it returns.
+func SetReadOnly(on bool) {
+	readOnly.Store(on)
+}
+
+// SetDryRun turns dry-run mode on or off. In dry-run mode every write path reports what it would do to the [AuditFunc], but doesn't touch the hardware.
+// It can be turned on just around a preview, eg of a fan curve, and off again.
+func SetDryRun(on bool) {
+	dryRun.Store(on)
+}
+
+// SetAuditFunc sets the [AuditFunc] that records writes; nil turns auditing off, which is the default.
+// Writes under way when it's changed are reported to whichever was set when they finished.
+func SetAuditFunc(fn AuditFunc) {
+	if fn == nil {
+		auditFunc.Store(nil)
+		return
+	}
+	auditFunc.Store(&fn)
+}
+
+// WriteAudited makes a write this package doesn't cover, eg to a /proc file of a vendor driver, like its own: through the [AuditFunc], and not in dry-run mode.
+// It's for packages built on this one.
+func WriteAudited(ev WriteEvent, write func() error) error {
+	return audit(ev, write)
+}
+
+// audit makes a write, unless in dry-run or read-only mode, and records it. All write paths go through it.
+// Writes to files the user can't write fail with a [PrivilegeError], in dry-run mode too, so that a preview shows them.
+func audit(ev WriteEvent, write func() error) error {
+	ev.DryRun = dryRun.Load()
+	switch {
+	case readOnly.Load():
+		ev.Err = ErrReadOnlyMode
+	case ev.Path != "":
+		ev.Err = checkWritable(ev.Prefix, ev.Path)
+	}
+	if !ev.DryRun && ev.Err == nil {
+		ev.Err = write()
+	}
+	logger.Info("write", "chip", ev.Chip, "attr", ev.Attr, "old", ev.Old, "new", ev.New, "dry_run", ev.DryRun, "error", ev.Err)
+	if fn := auditFunc.Load(); fn != nil {
+		(*fn)(ev)
+	}
+	return ev.Err
+}
@@ -0,0 +1,73 @@
+package lmsensors
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	var evs []WriteEvent
+	SetAuditFunc(func(ev WriteEvent) { evs = append(evs, ev) })
+	defer SetAuditFunc(nil)
+
+	writes := 0
+	write := func() error {
+		writes++
+		return errors.New("nope")
+	}
+
+	SetDryRun(true)
+	err := audit(WriteEvent{Chip: "nct6798-isa-0290", Attr: "pwm1", Old: 100, New: 255}, write)
+	SetDryRun(false)
+	if err != nil || writes != 0 || len(evs) != 1 || !evs[0].DryRun || evs[0].Old != 100 || evs[0].New != 255 {
+		t.Errorf("dry run wrote: err=%v writes=%d events=%+v", err, writes, evs)
+	}
+
+	err = audit(WriteEvent{Chip: "nct6798-isa-0290", Attr: "pwm1", Old: 100, New: 255}, write)
+	if err == nil || writes != 1 || len(evs) != 2 || evs[1].DryRun || evs[1].Err != err {
+		t.Errorf("write not audited: err=%v writes=%d events=%+v", err, writes, evs)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	var evs []WriteEvent
+	SetAuditFunc(func(ev WriteEvent) { evs = append(evs, ev) })
+	defer SetAuditFunc(nil)
+	SetReadOnly(true)
+	defer SetReadOnly(false)
+
+	writes := 0
+	write := func() error {
+		writes++
+		return nil
+	}
+	for _, dry := range []bool{false, true} {
+		SetDryRun(dry)
+		err := audit(WriteEvent{Chip: "nct6798-isa-0290", Attr: "pwm1", New: 255}, write)
+		if !errors.Is(err, ErrReadOnlyMode) || writes != 0 {
+			t.Errorf("dry run %t: wrote in read-only mode: err=%v writes=%d", dry, err, writes)
+		}
+	}
+	SetDryRun(false)
+	if len(evs) != 2 || !errors.Is(evs[0].Err, ErrReadOnlyMode) {
+		t.Errorf("refused writes not audited: %+v", evs)
+	}
+
+	// Through a real write path too
+	dev := HwmonDevice{Name: "nct6798", Path: t.TempDir()}
+	dev.attrDir = dev.Path
+	if err := dev.SetPWMDuty(1, 128); !errors.Is(err, ErrReadOnlyMode) {
+		t.Errorf("SetPWMDuty in read-only mode: %v", err)
+	}
+}
+
+func TestPrivilegeError(t *testing.T) {
+	var err error = &PrivilegeError{Path: "/sys/class/hwmon/hwmon1/pwm1", Prefix: "it87", UdevRule: udevRule("sensors", "it87", "pwm1")}
+	if !errors.Is(err, ErrNeedsPrivilege) {
+		t.Error("PrivilegeError isn't ErrNeedsPrivilege")
+	}
+	want := `ACTION=="add", SUBSYSTEM=="hwmon", ATTR{name}=="it87", RUN+="/bin/sh -c 'cd /sys%p && chgrp sensors pwm1 && chmod g+w pwm1'"`
+	if rule := err.(*PrivilegeError).UdevRule; rule != want {
+		t.Errorf("wrong udev rule:\n got %s\nwant %s", rule, want)
+	}
+}
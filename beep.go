@@ -0,0 +1,64 @@
+package lmsensors
+
+import (
+	sf "github.com/mt-inside/go-lmsensors/subfeature"
+)
+
+// featureRef is where a feature's sysfs attributes are, for writing them later.
+type featureRef struct {
+	chip, prefix, dir, name string
+}
+
+// write writes one of the feature's attributes, eg "beep" for temp1_beep, directly to sysfs, through [audit].
+// sub is the subfeature it is, which is the error for features that weren't read by this package.
+func (f featureRef) write(sub sf.SubFeature, suffix string, val float64) error {
+	if f.dir == "" {
+		return sub
+	}
+	return writeAttribute(f.chip, f.prefix, f.dir, f.name+"_"+suffix, val)
+}
+
+// beepSubFeatures are the beep subfeatures of each type of feature that can have one; intrusion's is handled by [IntrusionSensor].
+var beepSubFeatures = map[LmSensorType]sf.SubFeature{
+	Voltage:     sf.IN_BEEP,
+	Fan:         sf.FAN_BEEP,
+	Temperature: sf.TEMP_BEEP,
+	Current:     sf.CURR_BEEP,
+}
+
+// Beep is whether the chip beeps when the sensor's alarm goes off. It's false for those that can't, see [BeepOf].
+func (s *baseSensor) Beep() bool {
+	return s.beep
+}
+
+// SetBeep turns beeping on the sensor's alarm on or off, by writing its beep attribute; the reading isn't changed until the sensor is read again.
+// The write goes through the [AuditFunc], and isn't made in dry-run mode, see [SetDryRun]. Only sensors read by this package, with a beep attribute, can be set.
+func (s *baseSensor) SetBeep(on bool) error {
+	if !s.hasBeep {
+		return s.beepSub
+	}
+	return s.feature.write(s.beepSub, "beep", boolValue(on))
+}
+
+// SetBeep turns beeping on intrusion on or off, like the other sensors' SetBeep; the current setting is the Beep field.
+func (s *IntrusionSensor) SetBeep(on bool) error {
+	return s.feature.write(sf.INTRUSION_BEEP, "beep", boolValue(on))
+}
+
+// BeepOf is whether a sensor beeps on its alarm, and whether it has a beep setting at all.
+func BeepOf(s Sensor) (on, ok bool) {
+	switch s := s.(type) {
+	case interface{ base() *baseSensor }:
+		return s.base().beep, s.base().hasBeep
+	case *IntrusionSensor:
+		return s.Beep, true
+	}
+	return false, false
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
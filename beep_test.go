@@ -0,0 +1,46 @@
+package lmsensors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	sf "github.com/mt-inside/go-lmsensors/subfeature"
+)
+
+func TestBeep(t *testing.T) {
+	get := func(sub sf.SubFeature) (float64, error) {
+		if sub == sf.TEMP_BEEP {
+			return 1, nil
+		}
+		return 0, sub
+	}
+	s := newSensor("nct6798", Temperature, sf.TEMP_INPUT, baseSensor{Name: "SYSTIN", Value: 40}, get).(*TempSensor)
+	if on, ok := BeepOf(s); !on || !ok || !s.Beep() {
+		t.Errorf("wrong beep: %t %t", on, ok)
+	}
+	if err := s.SetBeep(false); err == nil {
+		t.Error("set beep on sensor with nowhere to write")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "temp1_beep")
+	if err := os.WriteFile(path, []byte("1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s.feature = featureRef{"nct6798-isa-0290", "nct6798", dir, "temp1"}
+	if err := s.SetBeep(false); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := os.ReadFile(path); string(p) != "0" {
+		t.Errorf("beep not written: %q", p)
+	}
+
+	v := newSensor("nct6798", Voltage, sf.IN_INPUT, baseSensor{Name: "in0", Value: 1}, get).(*VoltageSensor)
+	if _, ok := BeepOf(v); ok {
+		t.Error("beep on sensor without one")
+	}
+	if err := v.SetBeep(true); err != sf.IN_BEEP {
+		t.Errorf("wrong error: %v", err)
+	}
+}
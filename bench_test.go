@@ -0,0 +1,103 @@
+package lmsensors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	sf "github.com/mt-inside/go-lmsensors/subfeature"
+)
+
+// denseSystem is the size of a busy server board: a few SuperIO-ish chips, lots of DIMM and drive sensors.
+func denseSystem() *System {
+	sys := &System{Chips: map[string]*Chip{}}
+	for c := range 16 {
+		id := fmt.Sprintf("chip%d-isa-%04x", c, c)
+		chip := &Chip{ID: id, Type: "chip", Bus: "isa", Address: fmt.Sprintf("%04x", c), Sensors: map[string]Sensor{}}
+		for s := range 40 {
+			name := fmt.Sprintf("temp%d", s)
+			chip.Sensors[name] = &TempSensor{baseSensor: baseSensor{Name: name, Value: 40 + float64(s)/10}, TempType: Thermistor, TempTypeRaw: 4}
+		}
+		sys.Chips[id] = chip
+	}
+	return sys
+}
+
+func initOrSkip(b *testing.B) {
+	if err := Init(); err != nil {
+		b.Skip(err)
+	}
+	b.Cleanup(Cleanup)
+	for range Chips {
+		return
+	}
+	b.Skip("no chips")
+}
+
+func BenchmarkGet(b *testing.B) {
+	initOrSkip(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := Get(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkInputValue(b *testing.B) {
+	initOrSkip(b)
+	var feat Feature
+	for _, chip := range Chips {
+		for _, f := range chip.Features {
+			feat = f
+			break
+		}
+		break
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, _, err := feat.InputValue(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewSensor(b *testing.B) {
+	get := func(sub sf.SubFeature) (float64, error) { return 0, sub }
+	b.ReportAllocs()
+	for b.Loop() {
+		newSensor("k10temp", Temperature, sf.TEMP_INPUT, baseSensor{Name: "Tctl", Value: 42}, get)
+	}
+}
+
+func BenchmarkJSON(b *testing.B) {
+	sys := denseSystem()
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := json.NewEncoder(io.Discard).Encode(sys); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSnapshot(b *testing.B) {
+	sys := denseSystem()
+	b.ReportAllocs()
+	for b.Loop() {
+		NewSnapshot(sys, time.Time{})
+	}
+}
+
+// BenchmarkGetSysfs is the direct sysfs path, which bypasses libsensors.
+func BenchmarkGetSysfs(b *testing.B) {
+	SysfsRoot = "testdata/machines/intel-desktop"
+	b.Cleanup(func() { SysfsRoot = "/sys" })
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := GetSysfs(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package lmsensors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mt-inside/go-lmsensors/bus"
+	"github.com/mt-inside/go-lmsensors/chipname"
+	"github.com/mt-inside/go-lmsensors/sensorsconf"
+)
+
+// AdapterName is the description libsensors gives a bus, eg "SMBus PIIX4 adapter at 0b00" for an i2c bus, or "" for an i2c bus that doesn't exist.
+// It reads the i2c adapters' descriptions from [SysfsRoot], like libsensors does, so doesn't need [Init].
+func AdapterName(id chipname.BusID) string {
+	if id.Type == bus.I2C && id.Nr == chipname.Any {
+		return ""
+	}
+	return sysfsAdapter(id.Type, id.Nr)
+}
+
+// ResolveBus turns a bus name, eg "i2c-1", into the bus it means on this machine, for tools that take bus names in their configuration.
+// i2c bus numbers aren't stable across boots, so, like libsensors, when conf has a bus statement for the name, the bus is the one whose adapter has that statement's description, whatever its number now.
+// conf can be nil. It reads the adapters' descriptions from [SysfsRoot], so doesn't need [Init].
+func ResolveBus(name string, conf *sensorsconf.Config) (chipname.BusID, error) {
+	id, err := chipname.ParseBus(name)
+	if err != nil || id.Type != bus.I2C || conf == nil {
+		return id, err
+	}
+	for _, b := range conf.Buses {
+		if b.Name != name {
+			continue
+		}
+		nr, ok := i2cAdapterByName(b.Adapter)
+		if !ok {
+			return chipname.BusID{}, fmt.Errorf("%s (line %d): no i2c adapter %q", name, b.Line, b.Adapter)
+		}
+		id.Nr = nr
+	}
+	return id, nil
+}
+
+// i2cAdapterByName finds the number of the i2c bus with the given description.
+func i2cAdapterByName(adapter string) (int, bool) {
+	dir := filepath.Join(SysfsRoot, "class", "i2c-adapter")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, false
+	}
+	for _, e := range entries {
+		nr, ok := strings.CutPrefix(e.Name(), "i2c-")
+		if !ok {
+			continue
+		}
+		if readSysfsString(filepath.Join(dir, e.Name(), "name")) != adapter {
+			continue
+		}
+		n, err := strconv.Atoi(nr)
+		if err == nil {
+			return n, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,118 @@
+package lmsensors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mt-inside/go-lmsensors/bus"
+	"github.com/mt-inside/go-lmsensors/chipname"
+	"github.com/mt-inside/go-lmsensors/sensorsconf"
+)
+
+func TestResolveBus(t *testing.T) {
+	root := t.TempDir()
+	defer func() { SysfsRoot = "/sys" }()
+	SysfsRoot = root
+	for nr, name := range map[string]string{"0": "SMBus PIIX4 adapter port 0 at 0b00", "3": "SMBus PIIX4 adapter port 2 at 0b00"} {
+		dir := filepath.Join(root, "class", "i2c-adapter", "i2c-"+nr)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "name"), []byte(name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	conf, err := sensorsconf.Parse(`bus "i2c-1" "SMBus PIIX4 adapter port 2 at 0b00"
+bus "i2c-2" "missing"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]chipname.BusID{
+		"i2c-1": {Type: bus.I2C, Nr: 3}, // Translated
+		"i2c-0": {Type: bus.I2C, Nr: 0}, // No statement
+		"isa":   {Type: bus.ISA, Nr: chipname.Any},
+	} {
+		if id, err := ResolveBus(name, conf); err != nil || id != want {
+			t.Errorf("%s: got %v %v, want %v", name, id, err, want)
+		}
+	}
+	if id, err := ResolveBus("i2c-1", nil); err != nil || id.Nr != 1 {
+		t.Errorf("without config: %v %v", id, err)
+	}
+	if _, err := ResolveBus("i2c-2", conf); err == nil {
+		t.Error("no error for missing adapter")
+	}
+
+	for id, want := range map[chipname.BusID]string{
+		{Type: bus.I2C, Nr: 3}:            "SMBus PIIX4 adapter port 2 at 0b00",
+		{Type: bus.I2C, Nr: 1}:            "",
+		{Type: bus.ISA, Nr: chipname.Any}: "ISA adapter",
+	} {
+		if got := AdapterName(id); got != want {
+			t.Errorf("adapter of %s: got %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestResolveAdapter(t *testing.T) {
+	defer func() { SysfsRoot = "/sys" }()
+	SysfsRoot = filepath.Join("testdata", "machines", "amd-server")
+	ad, err := ResolveAdapter(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ad.Parent != "0000:00:14.0" || ad.ParentSubsystem != "pci" || ad.PCI == nil || ad.PCI.String() != "0000:00:14.0" {
+		t.Errorf("got %+v", ad)
+	}
+	if _, err := ResolveAdapter(7); err == nil {
+		t.Error("expected an error for a missing bus")
+	}
+	if cached := adapterDevice(0); cached == nil || *cached.PCI != *ad.PCI {
+		t.Errorf("cached adapter: %+v", cached)
+	}
+	if adapterDevice(7) != nil {
+		t.Error("cached adapter for a missing bus")
+	}
+
+	// Adapters that aren't on PCI, eg on a platform device, have no address
+	root := t.TempDir()
+	SysfsRoot = root
+	dev := filepath.Join(root, "devices", "platform", "i2c-gpio.1")
+	if err := os.MkdirAll(filepath.Join(dev, "i2c-4"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("../../bus/platform", filepath.Join(dev, "subsystem")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "class", "i2c-adapter"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dev, "i2c-4"), filepath.Join(root, "class", "i2c-adapter", "i2c-4")); err != nil {
+		t.Fatal(err)
+	}
+	ad, err = ResolveAdapter(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ad.Parent != "i2c-gpio.1" || ad.ParentSubsystem != "platform" || ad.PCI != nil {
+		t.Errorf("got %+v", ad)
+	}
+
+	// Chips get it from a cache, which only Init empties
+	if adapterDevice(4) == nil {
+		t.Fatal("no cached adapter")
+	}
+	if err := os.Remove(filepath.Join(root, "class", "i2c-adapter", "i2c-4")); err != nil {
+		t.Fatal(err)
+	}
+	if adapterDevice(4) == nil {
+		t.Error("adapter resolved again")
+	}
+	forgetMachine()
+	if adapterDevice(4) != nil {
+		t.Error("adapter still cached")
+	}
+}
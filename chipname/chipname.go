@@ -0,0 +1,202 @@
+// Package chipname parses and formats libsensors chip names, like "it87-isa-0290" or "coretemp-*", in pure Go.
+// It follows sensors_parse_chip_name(), see https://github.com/lm-sensors/lm-sensors/blob/42f240d2a457834bcbdf4dc8b57237f97b5f5854/lib/data.c#L62
+package chipname
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mt-inside/go-lmsensors/bus"
+)
+
+// Any is the wildcard value of [Name.BusNr] and [Name.Addr].
+const Any = -1
+
+// Name is a parsed chip name, which may have wildcards.
+type Name struct {
+	Prefix string   // eg "it87", or "" for any
+	Bus    bus.Type // bus.ANY for any
+	BusNr  int      // For buses that are numbered (i2c, spi, hid, scsi), or [Any]
+	Addr   int      // Or [Any]
+}
+
+// ErrSyntax is returned for names that don't parse.
+var ErrSyntax = errors.New("bad chip name")
+
+var busNames = map[string]bus.Type{
+	"i2c":     bus.I2C,
+	"isa":     bus.ISA,
+	"pci":     bus.PCI,
+	"spi":     bus.SPI,
+	"virtual": bus.VIRTUAL,
+	"acpi":    bus.ACPI,
+	"hid":     bus.HID,
+	"mdio":    bus.MDIO,
+	"scsi":    bus.SCSI,
+}
+
+func numbered(t bus.Type) bool {
+	switch t {
+	case bus.I2C, bus.SPI, bus.HID, bus.SCSI:
+		return true
+	}
+	return false
+}
+
+func syntaxError(name, why string) error {
+	return fmt.Errorf("%w %q: %s", ErrSyntax, name, why)
+}
+
+// Parse parses a chip name, as used in sensors.conf chip statements and on the sensors command line.
+func Parse(name string) (Name, error) {
+	n := Name{Bus: bus.ANY, BusNr: Any, Addr: Any}
+	rest := name
+	if rest == "*" {
+		return n, nil
+	}
+	if r, ok := strings.CutPrefix(rest, "*-"); ok {
+		rest = r
+	} else {
+		prefix, r, found := strings.Cut(rest, "-")
+		if prefix == "" {
+			return Name{}, syntaxError(name, "no prefix")
+		}
+		n.Prefix = prefix
+		if !found {
+			return n, nil
+		}
+		rest = r
+	}
+	if rest == "*" {
+		return n, nil
+	}
+
+	busName, rest, found := strings.Cut(rest, "-")
+	if !found {
+		return Name{}, syntaxError(name, "no address")
+	}
+	t, ok := busNames[busName]
+	if !ok {
+		return Name{}, syntaxError(name, "unknown bus type "+strconv.Quote(busName))
+	}
+	n.Bus = t
+
+	if numbered(t) {
+		nr, r, found := strings.Cut(rest, "-")
+		if !found {
+			return Name{}, syntaxError(name, "no bus number")
+		}
+		if nr != "*" {
+			v, err := strconv.ParseUint(nr, 10, 15)
+			if err != nil {
+				return Name{}, syntaxError(name, "bad bus number")
+			}
+			n.BusNr = int(v)
+		}
+		rest = r
+	}
+
+	if rest != "*" {
+		v, err := strconv.ParseUint(rest, 16, 31)
+		if err != nil {
+			return Name{}, syntaxError(name, "bad address")
+		}
+		n.Addr = int(v)
+	}
+	return n, nil
+}
+
+// Wildcards is whether any part of the name is a wildcard.
+func (n Name) Wildcards() bool {
+	return n.Prefix == "" || n.Bus == bus.ANY || (numbered(n.Bus) && n.BusNr == Any) || n.Addr == Any
+}
+
+// String formats the name like libsensors does, eg "lm75-i2c-0-48".
+func (n Name) String() string {
+	prefix := n.Prefix
+	if prefix == "" {
+		prefix = "*"
+	}
+	if n.Bus == bus.ANY {
+		if n.Prefix == "" {
+			return "*"
+		}
+		return prefix + "-*"
+	}
+
+	var ret strings.Builder
+	ret.WriteString(prefix + "-" + strings.ToLower(n.Bus.String()) + "-")
+	if numbered(n.Bus) {
+		if n.BusNr == Any {
+			ret.WriteString("*-")
+		} else {
+			ret.WriteString(strconv.Itoa(n.BusNr) + "-")
+		}
+	}
+	switch {
+	case n.Addr == Any:
+		ret.WriteString("*")
+	case n.Bus == bus.ISA || n.Bus == bus.PCI:
+		fmt.Fprintf(&ret, "%04x", n.Addr)
+	case n.Bus == bus.I2C:
+		fmt.Fprintf(&ret, "%02x", n.Addr)
+	default:
+		fmt.Fprintf(&ret, "%x", n.Addr)
+	}
+	return ret.String()
+}
+
+// Match is whether a chip, which usually won't have wildcards, is matched by the name.
+func (n Name) Match(chip Name) bool {
+	return (n.Prefix == "" || n.Prefix == chip.Prefix) &&
+		(n.Bus == bus.ANY || n.Bus == chip.Bus) &&
+		(n.BusNr == Any || n.BusNr == chip.BusNr) &&
+		(n.Addr == Any || n.Addr == chip.Addr)
+}
+
+// BusID is a bus, like libsensors' sensors_bus_id.
+type BusID struct {
+	Type bus.Type
+	Nr   int // For numbered buses, or [Any]
+}
+
+// ParseBus parses a bus name, as used in sensors.conf bus statements, eg "i2c-1" or "isa".
+// It follows libsensors' sensors_parse_bus_id(), which isn't exported.
+func ParseBus(name string) (BusID, error) {
+	busName, nr, found := strings.Cut(name, "-")
+	t, ok := busNames[busName]
+	if !ok {
+		return BusID{}, syntaxError(name, "unknown bus type "+strconv.Quote(busName))
+	}
+	id := BusID{Type: t, Nr: Any}
+	switch {
+	case !numbered(t) && found:
+		return BusID{}, syntaxError(name, busName+" buses aren't numbered")
+	case !numbered(t):
+		return id, nil
+	case !found:
+		return BusID{}, syntaxError(name, "no bus number")
+	case nr == "*":
+		return id, nil
+	}
+	v, err := strconv.ParseUint(nr, 10, 15)
+	if err != nil {
+		return BusID{}, syntaxError(name, "bad bus number")
+	}
+	id.Nr = int(v)
+	return id, nil
+}
+
+// String formats the bus like libsensors does, eg "i2c-1".
+func (id BusID) String() string {
+	name := strings.ToLower(id.Type.String())
+	if !numbered(id.Type) {
+		return name
+	}
+	if id.Nr == Any {
+		return name + "-*"
+	}
+	return name + "-" + strconv.Itoa(id.Nr)
+}
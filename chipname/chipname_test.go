@@ -0,0 +1,87 @@
+package chipname
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mt-inside/go-lmsensors/bus"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]Name{
+		"it87-isa-0290":    {"it87", bus.ISA, Any, 0x290},
+		"k10temp-pci-00c3": {"k10temp", bus.PCI, Any, 0xc3},
+		"lm75-i2c-0-48":    {"lm75", bus.I2C, 0, 0x48},
+		"lm75-i2c-*-48":    {"lm75", bus.I2C, Any, 0x48},
+		"acpitz-acpi-0":    {"acpitz", bus.ACPI, Any, 0},
+		"coretemp-*":       {"coretemp", bus.ANY, Any, Any},
+		"coretemp":         {"coretemp", bus.ANY, Any, Any},
+		"*-isa-*":          {"", bus.ISA, Any, Any},
+		"*":                {"", bus.ANY, Any, Any},
+	}
+	for s, want := range cases {
+		n, err := Parse(s)
+		if err != nil || n != want {
+			t.Errorf("%s: got %+v %v, want %+v", s, n, err, want)
+		}
+	}
+	for _, s := range []string{"", "-isa-0290", "it87-isa", "it87-foo-1", "lm75-i2c-48", "lm75-i2c-x-48", "it87-isa-zz"} {
+		if _, err := Parse(s); !errors.Is(err, ErrSyntax) {
+			t.Errorf("%q: no syntax error: %v", s, err)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	chip, _ := Parse("lm75-i2c-1-48")
+	if chip.Wildcards() {
+		t.Error("concrete name has wildcards")
+	}
+	for pattern, want := range map[string]bool{"lm75-*": true, "*-i2c-*-48": true, "lm75-i2c-0-48": false, "it87-*": false, "*": true} {
+		n, _ := Parse(pattern)
+		if n.Match(chip) != want {
+			t.Errorf("%s matching %s: got %t", pattern, chip, !want)
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, s := range []string{"it87-isa-0290", "lm75-i2c-0-48", "coretemp-*", "*-pci-*", "*"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		n, err := Parse(s)
+		if err != nil {
+			return
+		}
+		// Names print back to something that parses to the same thing.
+		again, err := Parse(n.String())
+		if err != nil || again != n {
+			t.Fatalf("%q -> %+v -> %q -> %+v %v", s, n, n.String(), again, err)
+		}
+	})
+}
+
+func TestParseBus(t *testing.T) {
+	cases := map[string]BusID{
+		"i2c-1":   {bus.I2C, 1},
+		"i2c-*":   {bus.I2C, Any},
+		"isa":     {bus.ISA, Any},
+		"virtual": {bus.VIRTUAL, Any},
+		"hid-3":   {bus.HID, 3},
+	}
+	for s, want := range cases {
+		id, err := ParseBus(s)
+		if err != nil || id != want {
+			t.Errorf("%s: got %+v %v, want %+v", s, id, err, want)
+		}
+		if id.String() != s {
+			t.Errorf("%s: formatted as %s", s, id)
+		}
+	}
+	for _, s := range []string{"", "i2c", "i2c-x", "isa-0", "foo-1"} {
+		if _, err := ParseBus(s); !errors.Is(err, ErrSyntax) {
+			t.Errorf("%q: no syntax error: %v", s, err)
+		}
+	}
+}
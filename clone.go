@@ -0,0 +1,69 @@
+package lmsensors
+
+import "maps"
+
+// Clone deep-copies the system, so it can be kept, eg as history, without aliasing anything a later [Get] or the caller might change.
+func (sys *System) Clone() *System {
+	c := &System{Chips: make(map[string]*Chip, len(sys.Chips)), Time: sys.Time, Stats: sys.Stats.clone()}
+	if t := sys.Throttling; t != nil {
+		tt := *t
+		tt.CPUs = append([]string(nil), t.CPUs...)
+		c.Throttling = &tt
+	}
+	for id, chip := range sys.Chips {
+		c.Chips[id] = chip.Clone()
+	}
+	return c
+}
+
+// Clone deep-copies the chip and its sensors.
+func (c *Chip) Clone() *Chip {
+	cc := *c
+	cc.Stats = c.Stats.clone()
+	cc.Sensors = make(map[string]Sensor, len(c.Sensors))
+	for name, s := range c.Sensors {
+		cs := cloneSensor(s)
+		if subs := subfeaturesOf(cs); subs != nil {
+			*subs = maps.Clone(*subs)
+		}
+		if b, ok := cs.(interface{ base() *baseSensor }); ok {
+			b.base().Limits = b.base().Limits.clone()
+		}
+		cc.Sensors[name] = cs
+	}
+	return &cc
+}
+
+func (s CollectionStats) clone() CollectionStats {
+	s.SubFeatures = maps.Clone(s.SubFeatures)
+	return s
+}
+
+// cloneSensor copies the sensor types in this package; others, which the application must have made, are shared.
+func cloneSensor(s Sensor) Sensor {
+	switch s := s.(type) {
+	case *TempSensor:
+		c := *s
+		return &c
+	case *VoltageSensor:
+		c := *s
+		return &c
+	case *FanSensor:
+		c := *s
+		return &c
+	case *CurrentSensor:
+		c := *s
+		return &c
+	case *IntrusionSensor:
+		c := *s
+		return &c
+	case *GenericSensor:
+		c := *s
+		return &c
+	case *UnimplementedSensor:
+		c := *s
+		return &c
+	default:
+		return s
+	}
+}
@@ -0,0 +1,29 @@
+package lmsensors
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	sys := tempSystem(40)
+	sys.Time = time.Now()
+	sys.Chips["k10temp-pci-00c3"].Stats.SubFeatures = map[string]time.Duration{}
+	sys.Chips["k10temp-pci-00c3"].Sensors["Tctl"].(*TempSensor).Subfeatures = map[string]float64{"temp1_input": 40}
+	c := sys.Clone()
+
+	chip := sys.Chips["k10temp-pci-00c3"]
+	chip.Sensors["Tctl"].(*TempSensor).Value = 90
+	chip.Sensors["Tctl"].(*TempSensor).Subfeatures["temp1_input"] = 90
+	chip.Sensors["new"] = &FanSensor{}
+	chip.Stats.SubFeatures["temp1_input"] = 1
+	chip.ID = "changed"
+
+	cc := c.Chips["k10temp-pci-00c3"]
+	if cc.Sensors["Tctl"].Reading() != 40 || SubfeaturesOf(cc.Sensors["Tctl"])["temp1_input"] != 40 || len(cc.Sensors) != 2 || len(cc.Stats.SubFeatures) != 0 || cc.ID != "k10temp-pci-00c3" {
+		t.Errorf("clone aliases the original: %+v", cc)
+	}
+	if !c.Time.Equal(sys.Time) {
+		t.Errorf("clone's time is %v, want %v", c.Time, sys.Time)
+	}
+}
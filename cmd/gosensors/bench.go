@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/testbench"
+)
+
+// bench runs a stress test with the command after the flags as the load, eg gosensors bench -duration 5m stress-ng --cpu 0.
+// The report goes to stdout, and the command's output to stderr.
+func bench(ctx context.Context, log *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	before := fs.Duration("before", time.Minute, "how long to record for idle, before the load")
+	after := fs.Duration("after", 5*time.Minute, "how long to record for cooling down, after the load")
+	duration := fs.Duration("duration", 0, "how long to run the load for, if it doesn't stop by itself")
+	interval := fs.Duration("interval", time.Second, "how often to read the sensors")
+	sensors := fs.String("sensors", "", "selector of the sensors to report, eg k10temp-*/*; defaults to the temperatures and fans")
+	asJSON := fs.Bool("json", false, "write the report as JSON")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		return errors.New("no load command given")
+	}
+
+	if err := lmsensors.Init(); err != nil {
+		return err
+	}
+	defer lmsensors.Cleanup()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	b := &testbench.Bench{
+		Command:  fs.Args(),
+		Duration: *duration,
+		Before:   *before,
+		After:    *after,
+		Interval: *interval,
+		Sensors:  lmsensors.Selector(*sensors),
+		Stdout:   os.Stderr,
+		Stderr:   os.Stderr,
+	}
+	var last testbench.Phase = -1
+	b.OnPoll = func(p testbench.Phase, _ *lmsensors.System) {
+		if p != last {
+			log.Info("stress test", "phase", p)
+			last = p
+		}
+	}
+	rep, err := b.Run(ctx)
+	if rep == nil {
+		return err
+	}
+	if err != nil {
+		log.Warn("stress test stopped early", "error", err)
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rep); err != nil {
+			return err
+		}
+	} else if err := rep.Write(os.Stdout); err != nil {
+		return err
+	}
+	if rep.CommandError != "" {
+		return fmt.Errorf("load command failed: %s", rep.CommandError)
+	}
+	return nil
+}
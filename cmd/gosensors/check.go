@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/nagios"
+)
+
+// selectors is a repeatable flag of sensor selectors.
+type selectors []lmsensors.Selector
+
+func (s *selectors) String() string {
+	strs := make([]string, len(*s))
+	for i, sel := range *s {
+		strs[i] = string(sel)
+	}
+	return strings.Join(strs, ",")
+}
+
+func (s *selectors) Set(v string) error {
+	sel := lmsensors.Selector(v)
+	if !sel.Valid() {
+		return fmt.Errorf("bad selector: %q", v)
+	}
+	*s = append(*s, sel)
+	return nil
+}
+
+// rangeFlag is an optional flag in the Nagios range syntax.
+type rangeFlag struct{ r *nagios.Range }
+
+func (f *rangeFlag) String() string {
+	if f.r == nil {
+		return ""
+	}
+	return f.r.String()
+}
+
+func (f *rangeFlag) Set(v string) error {
+	r, err := nagios.ParseRange(v)
+	f.r = &r
+	return err
+}
+
+// check is a Nagios plugin, eg for NRPE:
+//
+//	gosensors check -s 'k10temp-*/Tctl' -w 75 -c 90
+func check(ctx context.Context, _ *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	var sels selectors
+	var warn, crit rangeFlag
+	fs.Var(&sels, "s", "selector of the sensors to check, eg 'k10temp-*/Tctl'; repeatable, and by default all of them")
+	fs.Var(&warn, "w", "warning threshold, in the Nagios range syntax, eg 75 or 10:20; without -w or -c, the hardware's limits are used")
+	fs.Var(&crit, "c", "critical threshold, in the Nagios range syntax")
+	name := fs.String("name", "SENSORS", "prefix of the output")
+	ignoreAlarms := fs.Bool("ignore-alarms", false, "don't count the hardware's alarm flags as critical")
+	_ = fs.Parse(args)
+
+	// Nagios needs something on stdout, even on failure.
+	unknown := func(err error) error {
+		fmt.Printf("%s UNKNOWN - %v\n", *name, err)
+		return exitCode(nagios.Unknown)
+	}
+	lmsensors.SetLogger(nil) // Keep the output to the plugin's line
+	if err := lmsensors.Init(); err != nil {
+		return unknown(err)
+	}
+	defer lmsensors.Cleanup()
+	sys, err := lmsensors.GetContext(ctx, lmsensors.WithLimitCheck())
+	if err != nil && len(sys.Chips) == 0 {
+		return unknown(err)
+	}
+
+	res := nagios.Check{Name: *name, Sensors: sels, Warning: warn.r, Critical: crit.r, IgnoreAlarms: *ignoreAlarms}.Run(lmsensors.NewSnapshot(sys, time.Now()))
+	fmt.Println(res)
+	return exitCode(res.Status)
+}
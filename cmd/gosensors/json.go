@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// jsonMode writes one reading as JSON, in the versioned format of [lmsensors.JSONVersion], for scripts in other languages.
+func jsonMode(ctx context.Context, _ *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("json", flag.ExitOnError)
+	compat := fs.Bool("compat", false, "write version 1 of the format, without the version number or the sensors' types, units and alarms")
+	schema := fs.Bool("schema", false, "write the format's JSON Schema, rather than a reading")
+	limits := fs.Bool("limits", false, "read the sensors' limits too")
+	_ = fs.Parse(args)
+
+	if *schema {
+		_, err := os.Stdout.Write(lmsensors.JSONSchema)
+		return err
+	}
+	if err := lmsensors.Init(); err != nil {
+		return err
+	}
+	defer lmsensors.Cleanup()
+	var opts []lmsensors.Option
+	if *limits {
+		opts = append(opts, lmsensors.WithLimitCheck())
+	}
+	sys, err := lmsensors.GetContext(ctx, opts...)
+	if err != nil && len(sys.Chips) == 0 {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if *compat {
+		return enc.Encode(lmsensors.JSONCompat(sys))
+	}
+	return enc.Encode(sys)
+}
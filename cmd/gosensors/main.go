@@ -0,0 +1,120 @@
+// Command gosensors reads the sensors for other monitoring systems, speaking their protocols.
+//
+// Usage:
+//
+//	gosensors telegraf [flags]                Telegraf's execd input protocol
+//	gosensors check [flags]                   A Nagios plugin
+//	gosensors snmp [flags]                    An SNMP AgentX subagent
+//	gosensors netdata [flags] [update_every]  A netdata external plugin
+//	gosensors json [flags]                    One reading as JSON
+//	gosensors bench [flags] command...        A stress test, with command as the load
+//	gosensors sample [flags] selector         The selected sensors, many times a second, as CSV
+//
+// Run a mode with -h for its flags.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/config"
+	"github.com/mt-inside/go-lmsensors/daemon"
+)
+
+type mode struct {
+	run   func(ctx context.Context, log *slog.Logger, args []string) error
+	usage string
+}
+
+var modes = map[string]mode{
+	"telegraf": {telegraf, "Telegraf's execd input protocol"},
+	"check":    {check, "A Nagios plugin"},
+	"snmp":     {snmpAgent, "An SNMP AgentX subagent"},
+	"netdata":  {netdata, "A netdata external plugin"},
+	"json":     {jsonMode, "One reading as JSON"},
+	"bench":    {bench, "A stress test, with the command given as the load"},
+	"sample":   {sample, "The selected sensors, many times a second, as CSV"},
+}
+
+// exitCode is returned by modes to exit with a particular code, having already said why.
+type exitCode int
+
+func (e exitCode) Error() string {
+	return "exit " + strconv.Itoa(int(e))
+}
+
+func main() {
+	m, ok := mode{}, len(os.Args) > 1
+	if ok {
+		m, ok = modes[os.Args[1]]
+	}
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	log := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	lmsensors.SetLogger(log)
+	err := m.run(context.Background(), log, os.Args[2:])
+	if code, ok := err.(exitCode); ok {
+		os.Exit(int(code))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gosensors:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gosensors <mode> [flags]")
+	var names []string
+	for name := range modes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, modes[name].usage)
+	}
+}
+
+func init() {
+	config.RegisterFormat(".yaml", yaml.Unmarshal)
+	config.RegisterFormat(".yml", yaml.Unmarshal)
+	config.RegisterFormat(".toml", toml.Unmarshal)
+}
+
+// configFlag adds the -config flag, for a config file that selects and relabels sensors, see [config.Load].
+// It can be JSON, YAML or TOML, going by its extension.
+func configFlag(fs *flag.FlagSet) *string {
+	return fs.String("config", "", "config file, selecting and relabelling sensors; .json, .yaml or .toml")
+}
+
+// daemonOptions makes the options for a mode that runs as a daemon, from the config file if there is one.
+// On SIGHUP, the config file is re-read too.
+func daemonOptions(path string) (daemon.Options, error) {
+	if path == "" {
+		return daemon.Options{}, nil
+	}
+	c, err := config.Load(path)
+	if err != nil {
+		return daemon.Options{}, err
+	}
+	opts := daemon.OptionsFromConfig(c)
+	opts.OnReload = func() error {
+		nc, err := config.Load(path)
+		if err != nil {
+			return err
+		}
+		*c = *nc
+		return nil
+	}
+	return opts, nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/daemon"
+	"github.com/mt-inside/go-lmsensors/format"
+)
+
+// netdata is an external plugin for netdata: link it into plugins.d as eg gosensors.plugin, running "gosensors netdata".
+// netdata passes how often to update, in seconds, as the first argument.
+func netdata(ctx context.Context, log *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("netdata", flag.ExitOnError)
+	cfg := configFlag(fs)
+	_ = fs.Parse(args)
+	every := 1
+	if fs.NArg() > 0 {
+		n, err := strconv.Atoi(fs.Arg(0))
+		if err != nil || n < 1 {
+			return fmt.Errorf("bad update_every: %q", fs.Arg(0))
+		}
+		every = n
+	}
+
+	opts, err := daemonOptions(*cfg)
+	if err != nil {
+		return err
+	}
+	opts.Logger = log
+	opts.Interval = time.Duration(every) * time.Second
+	opts.Watcher = append(opts.Watcher, lmsensors.WithAlignment())
+	nd := &format.Netdata{W: os.Stdout, UpdateEvery: every}
+	opts.Sinks = append(opts.Sinks, daemon.SinkFunc(func(_ context.Context, sys *lmsensors.System) error {
+		return nd.Write(lmsensors.NewSnapshot(sys, time.Now()))
+	}))
+	return daemon.Run(ctx, opts)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// sample reads the sensors the selector after the flags matches at a high rate, eg gosensors sample -interval 10ms 'k10temp-*/Tctl', writing CSV to stdout until interrupted.
+// What it cost is logged at the end, see [lmsensors.SamplerStats].
+func sample(ctx context.Context, log *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("sample", flag.ExitOnError)
+	interval := fs.Duration("interval", 10*time.Millisecond, "how often to read the sensors")
+	duration := fs.Duration("duration", 0, "how long to sample for; defaults to until interrupted")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("give one selector of the sensors to sample, eg k10temp-*/*")
+	}
+	sel := lmsensors.Selector(fs.Arg(0))
+	if !sel.Valid() {
+		return fmt.Errorf("bad selector %q", sel)
+	}
+
+	s, err := lmsensors.NewSampler(sel)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	if *duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	fmt.Fprintln(w, "seconds,"+strings.Join(s.Sensors(), ","))
+	var start time.Time
+	line := make([]byte, 0, 256)
+	st, err := s.Run(ctx, *interval, func(at time.Time, vals []float64) {
+		if start.IsZero() {
+			start = at
+		}
+		line = strconv.AppendFloat(line[:0], at.Sub(start).Seconds(), 'f', 6, 64)
+		for _, v := range vals {
+			line = append(line, ',')
+			if !math.IsNaN(v) {
+				line = strconv.AppendFloat(line, v, 'g', -1, 64)
+			}
+		}
+		line = append(line, '\n')
+		_, _ = w.Write(line)
+	})
+	if err != nil {
+		return err
+	}
+	log.Info("sampled", "samples", st.Samples, "missed", st.Missed, "elapsed", st.Elapsed, "mean_read", st.MeanRead(), "max_read", st.MaxRead, "max_late", st.MaxLate, "cpu", fmt.Sprintf("%.1f%%", 100*st.Overhead()))
+	return w.Flush()
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/snmp"
+)
+
+// snmpAgent is an AgentX subagent, serving LM-SENSORS-MIB and ENTITY-SENSOR-MIB through the host's snmpd, which needs "master agentx" in its config.
+// It reconnects when snmpd restarts.
+func snmpAgent(ctx context.Context, log *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("snmp", flag.ExitOnError)
+	addr := fs.String("agentx", "/var/agentx/master", "the master agent's AgentX socket: a path, or tcp:host:port")
+	interval := fs.Duration("interval", 10*time.Second, "how often to read the sensors")
+	_ = fs.Parse(args)
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := lmsensors.Init(); err != nil {
+		return err
+	}
+	defer lmsensors.Cleanup()
+	w := lmsensors.NewWatcher(*interval)
+	go func() { _ = w.Run(ctx) }()
+
+	agent := snmp.Subagent{Addr: *addr, Snapshot: w.Snapshot}
+	for {
+		err := agent.Run(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Warn("agentx session ended, reconnecting", "error", err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
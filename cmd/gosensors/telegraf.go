@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/daemon"
+	"github.com/mt-inside/go-lmsensors/format"
+)
+
+// telegraf speaks the protocol of Telegraf's execd input: readings in line protocol on stdout, and a reload on SIGHUP.
+// Configure Telegraf with eg
+//
+//	[[inputs.execd]]
+//	  command = ["gosensors", "telegraf"]
+//	  signal = "STDIN"
+//	  data_format = "influx"
+func telegraf(ctx context.Context, log *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("telegraf", flag.ExitOnError)
+	cfg := configFlag(fs)
+	interval := fs.Duration("interval", 0, `how often to write readings; by default, they're written whenever Telegraf sends a line on stdin, ie with signal = "STDIN"`)
+	_ = fs.Parse(args)
+
+	opts, err := daemonOptions(*cfg)
+	if err != nil {
+		return err
+	}
+	opts.Logger = log
+	opts.Watcher = append(opts.Watcher, lmsensors.WithGetOptions(lmsensors.WithLimitCheck()))
+	opts.Sinks = append(opts.Sinks, lineProtocolSink(os.Stdout))
+	if *interval > 0 {
+		opts.Interval = *interval
+	} else {
+		opts.Trigger = lines(os.Stdin)
+	}
+	return daemon.Run(ctx, opts)
+}
+
+func lineProtocolSink(w io.Writer) daemon.Sink {
+	return daemon.SinkFunc(func(_ context.Context, sys *lmsensors.System) error {
+		return format.LineProtocol(w, lmsensors.NewSnapshot(sys, time.Now()))
+	})
+}
+
+// lines sends to the channel for each line read from r, and closes it at the end.
+func lines(r io.Reader) <-chan struct{} {
+	ch := make(chan struct{})
+	go func() {
+		defer close(ch)
+		s := bufio.NewScanner(r)
+		for s.Scan() {
+			ch <- struct{}{}
+		}
+	}()
+	return ch
+}
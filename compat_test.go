@@ -0,0 +1,108 @@
+package lmsensors
+
+import (
+	"encoding/json"
+	"math"
+	"os/exec"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// compatTolerance is how far a reading may move between sensors(1) reading it and us reading it.
+func compatTolerance(typ LmSensorType, v float64) float64 {
+	switch typ {
+	case Temperature:
+		return 3
+	case Fan:
+		return math.Max(100, v*0.1)
+	case Voltage, Current:
+		return math.Max(0.05, v*0.05)
+	default:
+		return math.Max(1, math.Abs(v)*0.1)
+	}
+}
+
+// sensorsJSONInput finds a feature's reading in `sensors -j` output, where it's keyed by subfeature name, eg temp1_input.
+func sensorsJSONInput(subs map[string]any) (float64, bool) {
+	var keys []string
+	for k := range subs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, suffix := range []string{"_input", "_average", "_alarm"} {
+		for _, k := range keys {
+			if v, ok := subs[k].(float64); ok && strings.HasSuffix(k, suffix) {
+				return v, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// TestSensorsCompat compares our reading with the real sensors(1)'s, to catch us drifting from upstream's semantics.
+// It's skipped where lm-sensors isn't installed or there are no chips.
+func TestSensorsCompat(t *testing.T) {
+	path, err := exec.LookPath("sensors")
+	if err != nil {
+		t.Skip("sensors(1) isn't installed")
+	}
+	if err := Init(); err != nil {
+		t.Skip(err)
+	}
+	defer Cleanup()
+
+	out, err := exec.Command(path, "-j").Output()
+	if err != nil {
+		t.Skipf("sensors -j: %v", err)
+	}
+	theirs := map[string]map[string]any{}
+	if err := json.Unmarshal(out, &theirs); err != nil {
+		t.Fatalf("can't parse sensors -j output: %v", err)
+	}
+	ours, _ := Get() // Chips with unreadable features are compared as far as they go
+	if len(ours.Chips) == 0 {
+		t.Skip("no chips")
+	}
+
+	for id := range theirs {
+		if _, ok := ours.Chips[id]; !ok {
+			t.Errorf("sensors has chip %s, we don't", id)
+		}
+	}
+	for id, chip := range ours.Chips {
+		tc, ok := theirs[id]
+		if !ok {
+			t.Errorf("we have chip %s, sensors doesn't", id)
+			continue
+		}
+		if adapter, _ := tc["Adapter"].(string); adapter != chip.Adapter {
+			t.Errorf("%s: adapter %q, sensors says %q", id, chip.Adapter, adapter)
+		}
+		for label, s := range chip.Sensors {
+			tf, ok := tc[label].(map[string]any)
+			if !ok {
+				t.Errorf("%s: we have sensor %q, sensors doesn't", id, label)
+				continue
+			}
+			if s == nil {
+				continue
+			}
+			v, ok := sensorsJSONInput(tf)
+			if !ok {
+				continue
+			}
+			if d := math.Abs(v - s.Reading()); d > compatTolerance(s.Type(), v) {
+				t.Errorf("%s/%s: we read %v, sensors read %v", id, label, s.Reading(), v)
+			}
+		}
+		for label, v := range tc {
+			if _, isFeature := v.(map[string]any); !isFeature {
+				continue // eg Adapter
+			}
+			if _, ok := chip.Sensors[label]; !ok {
+				t.Errorf("%s: sensors has sensor %q, we don't", id, label)
+			}
+		}
+	}
+}
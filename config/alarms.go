@@ -0,0 +1,27 @@
+package config
+
+import (
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// AlarmDetection raises alarms according to the config's alarm rules, calling fn when one is raised or cleared.
+// A rule with a For duration only raises once its sensor has been out of range for that long.
+// The rules become [lmsensors.Threshold]s, so they're reflected in [lmsensors.Watcher.AlarmStates] too.
+// The config is applied to the readings first, with [lmsensors.WithFilter], so sensors it leaves out never raise alarms, and the rest raise them under their labels.
+func (c *Config) AlarmDetection(fn func(lmsensors.AlarmEvent)) lmsensors.WatcherOption {
+	return func(w *lmsensors.Watcher) {
+		lmsensors.WithFilter(c)(w)
+		lmsensors.WithThresholds(fn, c.Thresholds()...)(w)
+	}
+}
+
+// Thresholds are the config's alarm rules, as [lmsensors.Threshold]s.
+func (c *Config) Thresholds() []lmsensors.Threshold {
+	ths := make([]lmsensors.Threshold, 0, len(c.Alarms))
+	for _, r := range c.Alarms {
+		ths = append(ths, lmsensors.Threshold{Name: r.Name, Sensor: r.Sensor, Above: r.Above, Below: r.Below, For: time.Duration(r.For)})
+	}
+	return ths
+}
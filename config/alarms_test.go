@@ -0,0 +1,58 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+func TestAlarmRules(t *testing.T) {
+	above := 90.0
+	c := &Config{Alarms: []AlarmRule{{Name: "cpu hot", Sensor: "k10temp-*/Tctl", Above: &above, For: Duration(10 * time.Second)}}}
+	want := []lmsensors.Threshold{{Name: "cpu hot", Sensor: "k10temp-*/Tctl", Above: &above, For: 10 * time.Second}}
+	if got := c.Thresholds(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestAlarmDetection(t *testing.T) {
+	above := 90.0
+	c := &Config{
+		Exclude: []lmsensors.Selector{"*/Tccd1"},
+		Labels:  map[string]string{"k10temp-pci-00c3/Tctl": "CPU"},
+		Alarms: []AlarmRule{
+			{Name: "cpu hot", Sensor: "k10temp-*/CPU", Above: &above, For: Duration(10 * time.Second)},
+			{Name: "anything hot", Sensor: "*/T*", Above: &above},
+		},
+	}
+	var events []lmsensors.AlarmEvent
+	fn := func(ev lmsensors.AlarmEvent) { events = append(events, ev) }
+	// Giving the config's filter again, as daemon.OptionsFromConfig does, doesn't apply it twice.
+	w := lmsensors.NewWatcher(time.Second, lmsensors.WithFilter(c), c.AlarmDetection(fn))
+
+	start := time.Now()
+	for i, v := range []float64{80, 95, 95, 85, 95, 95, 95, 80} {
+		at := start.Add(time.Duration(i) * 5 * time.Second)
+		tctl, tccd := &lmsensors.TempSensor{}, &lmsensors.TempSensor{}
+		tctl.Name, tctl.Value, tctl.Time = "Tctl", v, at
+		tccd.Name, tccd.Value, tccd.Time = "Tccd1", 100, at // Excluded, so never in alarm
+		w.Observe(&lmsensors.System{Time: at, Chips: map[string]*lmsensors.Chip{
+			"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]lmsensors.Sensor{"Tctl": tctl, "Tccd1": tccd, "broken": nil}},
+		}})
+	}
+	// The first excursion is too short; the second lasts 10s.
+	if len(events) != 2 {
+		t.Fatalf("wrong number of events: %v", events)
+	}
+	if !events[0].Raised || events[0].Rule != "cpu hot" || events[0].Sensor != "CPU" || events[0].Value != 95 {
+		t.Errorf("wrong raise: %+v", events[0])
+	}
+	if events[1].Raised || events[1].Value != 80 {
+		t.Errorf("wrong clear: %+v", events[1])
+	}
+	if st := w.AlarmStates(); len(st) != 0 {
+		t.Errorf("still in alarm: %+v", st)
+	}
+}
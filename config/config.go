@@ -0,0 +1,307 @@
+// Package config is the configuration file shared by the daemon and command-line tools: poll interval, filters, label overrides, alarm rules and exporter settings.
+//
+// JSON is understood out of the box. To keep this package's dependencies down, YAML and TOML decoders are registered by the application, as gosensors does, eg:
+//
+//	config.RegisterFormat(".yaml", yaml.Unmarshal)
+//	config.RegisterFormat(".toml", toml.Unmarshal)
+//
+// The struct tags are such that gopkg.in/yaml.v3 and github.com/BurntSushi/toml both work.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/fancontrol"
+)
+
+// Duration is a [time.Duration] that's written like "5s" in config files.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Config is the whole configuration file.
+type Config struct {
+	Interval      Duration `json:"interval" yaml:"interval" toml:"interval"`                   // How often to poll; defaults to 1s
+	SensorsConfig string   `json:"sensors_config" yaml:"sensors_config" toml:"sensors_config"` // libsensors config file, if not the default
+
+	ChipIntervals []ChipInterval `json:"chip_intervals" yaml:"chip_intervals" toml:"chip_intervals"` // Overrides of Interval for some chips; the first match wins
+
+	Include []lmsensors.Selector `json:"include" yaml:"include" toml:"include"` // Only these sensors, if any are given
+	Exclude []lmsensors.Selector `json:"exclude" yaml:"exclude" toml:"exclude"` // Not these sensors, even if included
+
+	Labels map[string]string `json:"labels" yaml:"labels" toml:"labels"` // Overrides, from "chip/sensor" to the new label
+
+	Alarms []AlarmRule `json:"alarms" yaml:"alarms" toml:"alarms"`
+	Notify []Action    `json:"notify" yaml:"notify" toml:"notify"` // What to do when an alarm is raised or cleared
+
+	Fans []Fan `json:"fans" yaml:"fans" toml:"fans"` // Fan curves, eg as suggested by [fancontrol.AutoTune]
+
+	Exporters Exporters `json:"exporters" yaml:"exporters" toml:"exporters"`
+}
+
+// Fan drives a PWM output from one or more temperatures, see [fancontrol.Controller].
+// A single source can be given by Sensor and Curve, rather than in Sources.
+type Fan struct {
+	PWM    string             `json:"pwm" yaml:"pwm" toml:"pwm"`          // eg "nct6798/pwm1", see [fancontrol.FindPWM]
+	Sensor lmsensors.Selector `json:"sensor" yaml:"sensor" toml:"sensor"` // The hottest match drives it
+	Curve  fancontrol.Curve   `json:"curve" yaml:"curve" toml:"curve"`
+
+	Sources []fancontrol.Source `json:"sources" yaml:"sources" toml:"sources"`
+	Policy  fancontrol.Policy   `json:"policy" yaml:"policy" toml:"policy"` // How the sources combine: "max", the default, or "average"
+}
+
+// sources are all the fan's sources, including the one given by Sensor and Curve.
+func (f Fan) sources() []fancontrol.Source {
+	if f.Sensor == "" && f.Curve == nil {
+		return f.Sources
+	}
+	return append([]fancontrol.Source{{Sensor: f.Sensor, Curve: f.Curve}}, f.Sources...)
+}
+
+// Controller finds the fan's PWM output, and makes its [fancontrol.Controller].
+func (f Fan) Controller() (*fancontrol.Controller, error) {
+	pwm, err := fancontrol.FindPWM(f.PWM)
+	if err != nil {
+		return nil, err
+	}
+	return fancontrol.NewMultiController(pwm, f.Policy, f.sources()...), nil
+}
+
+// ChipInterval polls some chips more, or less, often than the rest, see [lmsensors.WithChipInterval].
+type ChipInterval struct {
+	Chips    lmsensors.Selector `json:"chips" yaml:"chips" toml:"chips"` // Only the chip part is used, eg "k10temp-*/*"
+	Interval Duration           `json:"interval" yaml:"interval" toml:"interval"`
+}
+
+// AlarmRule raises an alarm when a sensor is outside a range for long enough.
+type AlarmRule struct {
+	Name   string             `json:"name" yaml:"name" toml:"name"`
+	Sensor lmsensors.Selector `json:"sensor" yaml:"sensor" toml:"sensor"`
+	Above  *float64           `json:"above" yaml:"above" toml:"above"`
+	Below  *float64           `json:"below" yaml:"below" toml:"below"`
+	For    Duration           `json:"for" yaml:"for" toml:"for"`
+}
+
+// Action is one thing to do on an alarm event; exactly one of its fields should be set.
+// See package notify for the details of each.
+type Action struct {
+	Exec    []string       `json:"exec" yaml:"exec" toml:"exec"` // Command and its arguments
+	Webhook *WebhookAction `json:"webhook" yaml:"webhook" toml:"webhook"`
+	Email   *EmailAction   `json:"email" yaml:"email" toml:"email"`
+	Desktop *DesktopAction `json:"desktop" yaml:"desktop" toml:"desktop"`
+}
+
+// WebhookAction POSTs the alarm event to a URL.
+type WebhookAction struct {
+	URL      string            `json:"url" yaml:"url" toml:"url"`
+	Template string            `json:"template" yaml:"template" toml:"template"` // text/template for the body; defaults to the event as JSON
+	Headers  map[string]string `json:"headers" yaml:"headers" toml:"headers"`
+}
+
+// EmailAction sends the alarm event by SMTP.
+type EmailAction struct {
+	Server   string   `json:"server" yaml:"server" toml:"server"` // host:port
+	Username string   `json:"username" yaml:"username" toml:"username"`
+	Password string   `json:"password" yaml:"password" toml:"password"`
+	From     string   `json:"from" yaml:"from" toml:"from"`
+	To       []string `json:"to" yaml:"to" toml:"to"`
+}
+
+// DesktopAction pops up a desktop notification, through the session bus.
+type DesktopAction struct {
+	Rules   []string `json:"rules" yaml:"rules" toml:"rules"`       // Only alarms from these thresholds, with "" for the hardware's own; all if empty
+	Cleared bool     `json:"cleared" yaml:"cleared" toml:"cleared"` // Also when alarms clear
+	Expire  Duration `json:"expire" yaml:"expire" toml:"expire"`    // How long the popup stays up; defaults to the desktop's choice
+}
+
+// Exporters are the settings of the ways readings get out of the process.
+type Exporters struct {
+	HTTP HTTPExporter `json:"http" yaml:"http" toml:"http"`
+}
+
+// HTTPExporter serves the latest reading over HTTP, as OpenMetrics for Prometheus to scrape; it's off unless Listen is set.
+type HTTPExporter struct {
+	Listen string `json:"listen" yaml:"listen" toml:"listen"` // eg ":9255"
+	Path   string `json:"path" yaml:"path" toml:"path"`       // Defaults to "/metrics"
+}
+
+var formats = map[string]func([]byte, any) error{
+	".json": json.Unmarshal,
+}
+
+// RegisterFormat adds a decoder for config files with the given extension, eg ".yaml".
+func RegisterFormat(ext string, unmarshal func([]byte, any) error) {
+	formats[strings.ToLower(ext)] = unmarshal
+}
+
+// Load reads a config file, choosing the decoder by its extension, then applies defaults and validates it.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	unmarshal, ok := formats[ext]
+	if !ok {
+		return nil, fmt.Errorf("don't know how to read %s config files; see config.RegisterFormat", ext)
+	}
+	return Parse(data, unmarshal)
+}
+
+// Parse decodes a config with the given decoder, then applies defaults and validates it.
+func Parse(data []byte, unmarshal func([]byte, any) error) (*Config, error) {
+	c := &Config{}
+	if err := unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	c.setDefaults()
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Config) setDefaults() {
+	if c.Interval == 0 {
+		c.Interval = Duration(time.Second)
+	}
+	if c.Exporters.HTTP.Path == "" {
+		c.Exporters.HTTP.Path = "/metrics"
+	}
+}
+
+// Validate checks the config makes sense, returning all the problems found.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Interval < 0 {
+		errs = append(errs, fmt.Errorf("interval must be positive: %s", time.Duration(c.Interval)))
+	}
+	for i, ci := range c.ChipIntervals {
+		if !ci.Chips.Valid() || ci.Chips == "" {
+			errs = append(errs, fmt.Errorf("chip interval %d: bad selector: %q", i, ci.Chips))
+		}
+		if ci.Interval <= 0 {
+			errs = append(errs, fmt.Errorf("chip interval %d: interval must be positive: %s", i, time.Duration(ci.Interval)))
+		}
+	}
+	for _, sel := range append(append([]lmsensors.Selector{}, c.Include...), c.Exclude...) {
+		if !sel.Valid() {
+			errs = append(errs, fmt.Errorf("bad selector: %q", sel))
+		}
+	}
+	for key := range c.Labels {
+		if !strings.Contains(key, "/") {
+			errs = append(errs, fmt.Errorf("label override %q isn't of the form chip/sensor", key))
+		}
+	}
+	for i, a := range c.Alarms {
+		if !a.Sensor.Valid() || a.Sensor == "" {
+			errs = append(errs, fmt.Errorf("alarm %d (%s): bad selector: %q", i, a.Name, a.Sensor))
+		}
+		if a.Above == nil && a.Below == nil {
+			errs = append(errs, fmt.Errorf("alarm %d (%s): needs above or below", i, a.Name))
+		}
+		if a.For < 0 {
+			errs = append(errs, fmt.Errorf("alarm %d (%s): for can't be negative", i, a.Name))
+		}
+	}
+	for i, f := range c.Fans {
+		if !strings.Contains(f.PWM, "/pwm") {
+			errs = append(errs, fmt.Errorf("fan %d: bad pwm: %q", i, f.PWM))
+		}
+		srcs := f.sources()
+		if len(srcs) == 0 {
+			errs = append(errs, fmt.Errorf("fan %d (%s): needs a sensor and curve, or sources", i, f.PWM))
+		}
+		for _, src := range srcs {
+			if !src.Sensor.Valid() || src.Sensor == "" {
+				errs = append(errs, fmt.Errorf("fan %d (%s): bad selector: %q", i, f.PWM, src.Sensor))
+			}
+			if !src.Curve.Valid() {
+				errs = append(errs, fmt.Errorf("fan %d (%s): curve needs points, in order of temperature", i, f.PWM))
+			}
+			if src.Weight < 0 {
+				errs = append(errs, fmt.Errorf("fan %d (%s): weights can't be negative", i, f.PWM))
+			}
+		}
+	}
+	for i, a := range c.Notify {
+		n := 0
+		if len(a.Exec) != 0 {
+			n++
+		}
+		if a.Webhook != nil {
+			n++
+			if a.Webhook.URL == "" {
+				errs = append(errs, fmt.Errorf("notify %d: webhook needs a url", i))
+			}
+		}
+		if a.Email != nil {
+			n++
+			if a.Email.Server == "" || a.Email.From == "" || len(a.Email.To) == 0 {
+				errs = append(errs, fmt.Errorf("notify %d: email needs a server, from and to", i))
+			}
+		}
+		if a.Desktop != nil {
+			n++
+		}
+		if n != 1 {
+			errs = append(errs, fmt.Errorf("notify %d: needs exactly one of exec, webhook, email or desktop", i))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Selected is whether the config's filters keep a sensor.
+func (c *Config) Selected(chip, sensor string) bool {
+	for _, sel := range c.Exclude {
+		if sel.Match(chip, sensor) {
+			return false
+		}
+	}
+	if len(c.Include) == 0 {
+		return true
+	}
+	for _, sel := range c.Include {
+		if sel.Match(chip, sensor) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply filters a reading in place, and applies the label overrides.
+// Relabelled sensors are re-keyed under their new label; their own name is left as libsensors has it.
+func (c *Config) Apply(sys *lmsensors.System) {
+	for _, chip := range sys.Chips {
+		sensors := make(map[string]lmsensors.Sensor, len(chip.Sensors))
+		for name, s := range chip.Sensors {
+			if !c.Selected(chip.ID, name) {
+				continue
+			}
+			if label, ok := c.Labels[chip.ID+"/"+name]; ok {
+				name = label
+			}
+			sensors[name] = s
+		}
+		chip.Sensors = sensors
+	}
+}
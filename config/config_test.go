@@ -0,0 +1,88 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/fancontrol"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gosensors.json")
+	err := os.WriteFile(path, []byte(`{
+		"interval": "5s",
+		"chip_intervals": [{"chips": "BAT*/*", "interval": "1m"}],
+		"include": ["k10temp-*/*", "fan*"],
+		"exclude": ["*/Tccd*"],
+		"labels": {"k10temp-pci-00c3/Tctl": "CPU"},
+		"alarms": [{"name": "cpu hot", "sensor": "k10temp-*/Tctl", "above": 90, "for": "30s"}],
+		"fans": [
+			{"pwm": "nct6798/pwm1", "sensor": "k10temp-*/Tctl", "curve": [{"temp": 40, "duty": 64}, {"temp": 70, "duty": 255}]},
+			{"pwm": "nct6798/pwm2", "policy": "average", "sources": [
+				{"sensor": "k10temp-*/Tctl", "curve": [{"temp": 40, "duty": 64}, {"temp": 70, "duty": 255}], "weight": 2},
+				{"sensor": "amdgpu-*/edge", "curve": [{"temp": 50, "duty": 64}, {"temp": 80, "duty": 255}]}
+			]}
+		]
+	}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Duration(c.Interval) != 5*time.Second || c.Exporters.HTTP.Path != "/metrics" {
+		t.Errorf("wrong interval or defaults: %+v", c)
+	}
+	if len(c.ChipIntervals) != 1 || c.ChipIntervals[0].Chips != "BAT*/*" || time.Duration(c.ChipIntervals[0].Interval) != time.Minute {
+		t.Errorf("wrong chip intervals: %+v", c.ChipIntervals)
+	}
+	if len(c.Alarms) != 1 || *c.Alarms[0].Above != 90 || time.Duration(c.Alarms[0].For) != 30*time.Second {
+		t.Errorf("wrong alarms: %+v", c.Alarms)
+	}
+	if len(c.Fans) != 2 || c.Fans[0].Curve.Duty(55) != 160 || c.Fans[1].Policy != fancontrol.WeightedAverage || len(c.Fans[1].sources()) != 2 {
+		t.Errorf("wrong fans: %+v", c.Fans)
+	}
+
+	temp := func(name string) lmsensors.Sensor {
+		s := &lmsensors.TempSensor{}
+		s.Name = name
+		return s
+	}
+	sys := &lmsensors.System{Chips: map[string]*lmsensors.Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]lmsensors.Sensor{
+			"Tctl": temp("Tctl"), "Tccd1": temp("Tccd1"),
+		}},
+		"nct6798-isa-0290": {ID: "nct6798-isa-0290", Sensors: map[string]lmsensors.Sensor{
+			"fan1": temp("fan1"), "SYSTIN": temp("SYSTIN"),
+		}},
+	}}
+	c.Apply(sys)
+	if s := sys.Chips["k10temp-pci-00c3"].Sensors; len(s) != 1 || s["CPU"] == nil {
+		t.Errorf("wrong k10temp sensors: %v", s)
+	}
+	if s := sys.Chips["nct6798-isa-0290"].Sensors; len(s) != 1 || s["fan1"] == nil {
+		t.Errorf("wrong nct6798 sensors: %v", s)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	_, err := Parse([]byte(`{"interval": "-1s", "include": ["["], "labels": {"Tctl": "CPU"}, "alarms": [{"sensor": "Tctl"}], "chip_intervals": [{"chips": "BAT*/*"}], "fans": [{"pwm": "nct6798/pwm1", "sensor": "k10temp-*/Tctl", "curve": [{"temp": 70, "duty": 255}, {"temp": 40, "duty": 64}]}]}`), json.Unmarshal)
+	if err == nil {
+		t.Fatal("no error for bad config")
+	}
+	for _, want := range []string{"interval", "bad selector", "label override", "needs above or below", "chip interval 0", "fan 0 (nct6798/pwm1): curve"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error doesn't mention %q: %v", want, err)
+		}
+	}
+
+	if _, err := Load("gosensors.ini"); err == nil {
+		t.Error("no error for unknown format")
+	}
+}
@@ -0,0 +1,97 @@
+package correlate
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/record"
+)
+
+// Collector samples the load alongside every poll of a [lmsensors.Watcher], keeping both, over the last Window, for [Collector.Reports].
+// It's safe to get reports from other goroutines.
+type Collector struct {
+	Load    func() (float64, error) // eg [CPUUtilization]
+	Sensors lmsensors.Selector      // Defaults to all the temperatures
+	Window  time.Duration           // Defaults to an hour
+
+	mu      sync.Mutex
+	load    Series
+	sensors map[string]Series // By chip/sensor
+	err     error
+}
+
+// Watch returns an option that samples the load every poll.
+func (c *Collector) Watch() lmsensors.WatcherOption {
+	return lmsensors.OnPoll(func(sys *lmsensors.System, _ error) {
+		c.Add(lmsensors.NewSnapshot(sys, sys.Time))
+	})
+}
+
+// Add samples the load, at the snapshot's time, and keeps its sensor readings.
+func (c *Collector) Add(snap *lmsensors.Snapshot) {
+	load, err := c.Load()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sensors == nil {
+		c.sensors = map[string]Series{}
+	}
+	window := c.Window
+	if window <= 0 {
+		window = time.Hour
+	}
+	now := snap.Time()
+	c.err = err
+	if err == nil {
+		c.load = trim(append(c.load, record.Sample{Time: now, Value: load}), now.Add(-window))
+	}
+	for r := range snap.Readings {
+		if !r.Valid || (c.Sensors == "" && r.Type != lmsensors.Temperature) || (c.Sensors != "" && !c.Sensors.Match(r.Chip, r.Sensor)) {
+			continue
+		}
+		key := r.Chip + "/" + r.Sensor
+		s := c.sensors[key]
+		at := r.Time
+		if at.IsZero() {
+			at = now
+		}
+		// Readings carried over by [lmsensors.WithChipInterval] aren't new ones.
+		if len(s) == 0 || at.After(s[len(s)-1].Time) {
+			s = append(s, record.Sample{Time: at, Value: r.Value})
+		}
+		c.sensors[key] = trim(s, now.Add(-window))
+	}
+}
+
+func trim(s Series, before time.Time) Series {
+	i := sort.Search(len(s), func(i int) bool { return !s[i].Time.Before(before) })
+	return s[i:]
+}
+
+// Err is the error from the last sampling of the load, if it failed.
+func (c *Collector) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Reports correlates each sensor with the load, as [Correlate] does, most correlated first.
+// Sensors that can't be correlated, eg because they've been constant, are left out.
+func (c *Collector) Reports(step, maxLag time.Duration) []Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var reports []Report
+	for key, s := range c.sensors {
+		if r, ok := Correlate(key, c.load, s, step, maxLag); ok {
+			reports = append(reports, r)
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Correlation != reports[j].Correlation {
+			return reports[i].Correlation > reports[j].Correlation
+		}
+		return reports[i].Sensor < reports[j].Sensor
+	})
+	return reports
+}
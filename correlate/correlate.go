@@ -0,0 +1,137 @@
+// Package correlate relates sensors to load, eg CPU utilisation, to check that cooling keeps up: how closely a temperature follows the load, how far behind it, and how much it rises for each unit of load.
+//
+// Collect both alongside a [lmsensors.Watcher] with eg
+//
+//	c := &correlate.Collector{Load: correlate.CPUUtilization(), Window: time.Hour}
+//	w := lmsensors.NewWatcher(time.Second, c.Watch())
+//	...
+//	correlate.Write(os.Stdout, c.Reports(10*time.Second, 5*time.Minute))
+//
+// or correlate a load series from elsewhere, eg a benchmark's log read with [ReadSeries], with a sensor's history.
+package correlate
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors/record"
+)
+
+// Series is a sensor's, or the load's, samples, in time order.
+type Series []record.Sample
+
+// FromPoints makes a series of the averages of the points of eg a [record.Store.Query].
+func FromPoints(ps []record.Point) Series {
+	s := make(Series, len(ps))
+	for i, p := range ps {
+		s[i] = record.Sample{Time: p.Time, Value: p.Avg}
+	}
+	return s
+}
+
+// Report is how a sensor follows the load.
+type Report struct {
+	Sensor      string        // chip/sensor
+	Correlation float64       // Pearson's, between the load and the sensor Lag later: 1 for following it exactly, 0 for not at all
+	Lag         time.Duration // How far behind the load the sensor best follows it, to the step
+	Gain        float64       // How much the sensor rises for each unit of load, at Lag, eg °C per % utilisation
+	Immediate   float64       // The correlation without any lag
+	Samples     int           // The number of steps compared, at Lag
+}
+
+// minSteps is how many steps of overlap there have to be for a correlation.
+const minSteps = 10
+
+// Correlate compares a sensor's series with the load's, averaging both into steps and trying every lag to maxLag.
+// It's false if they don't overlap for long enough, or either's constant.
+func Correlate(sensor string, load, s Series, step, maxLag time.Duration) (Report, bool) {
+	if len(load) == 0 || len(s) == 0 || step <= 0 {
+		return Report{}, false
+	}
+	from := load[0].Time
+	if s[0].Time.After(from) {
+		from = s[0].Time
+	}
+	to := load[len(load)-1].Time
+	if s[len(s)-1].Time.Before(to) {
+		to = s[len(s)-1].Time
+	}
+	to = to.Add(maxLag) // The sensor's steps go that much further than the load's
+	lx, sx := resample(load, from, to, step), resample(s, from, to, step)
+
+	best := Report{Sensor: sensor, Correlation: math.Inf(-1)}
+	for k := 0; time.Duration(k)*step <= maxLag; k++ {
+		r, gain, n := pearson(lx, sx, k)
+		if n < minSteps || math.IsNaN(r) {
+			continue
+		}
+		if k == 0 {
+			best.Immediate = r
+		}
+		if r > best.Correlation {
+			best.Correlation, best.Lag, best.Gain, best.Samples = r, time.Duration(k)*step, gain, n
+		}
+	}
+	return best, !math.IsInf(best.Correlation, -1)
+}
+
+// resample averages the samples into steps from from, with NaN for the ones without any.
+func resample(s Series, from, to time.Time, step time.Duration) []float64 {
+	n := int(to.Sub(from)/step) + 1
+	sums, counts := make([]float64, n), make([]int, n)
+	for _, smp := range s {
+		if smp.Time.Before(from) {
+			continue
+		}
+		i := int(smp.Time.Sub(from) / step)
+		if i >= n {
+			break
+		}
+		sums[i] += smp.Value
+		counts[i]++
+	}
+	for i := range sums {
+		if counts[i] == 0 {
+			sums[i] = math.NaN()
+		} else {
+			sums[i] /= float64(counts[i])
+		}
+	}
+	return sums
+}
+
+// pearson correlates x with y lag steps later, over the steps both have, also giving the slope of y on x.
+func pearson(x, y []float64, lag int) (r, slope float64, n int) {
+	var sx, sy, sxx, syy, sxy float64
+	for i := 0; i+lag < len(y) && i < len(x); i++ {
+		a, b := x[i], y[i+lag]
+		if math.IsNaN(a) || math.IsNaN(b) {
+			continue
+		}
+		n++
+		sx, sy, sxx, syy, sxy = sx+a, sy+b, sxx+a*a, syy+b*b, sxy+a*b
+	}
+	if n == 0 {
+		return math.NaN(), 0, 0
+	}
+	fn := float64(n)
+	cov := sxy - sx*sy/fn
+	vx, vy := sxx-sx*sx/fn, syy-sy*sy/fn
+	if vx <= 0 || vy <= 0 {
+		return math.NaN(), 0, n
+	}
+	return cov / math.Sqrt(vx*vy), cov / vx, n
+}
+
+// Write writes the reports as a table.
+func Write(w io.Writer, reports []Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SENSOR\tCORRELATION\tLAG\tGAIN\tIMMEDIATE\tSTEPS")
+	for _, r := range reports {
+		fmt.Fprintf(tw, "%s\t%.2f\t%s\t%.3g\t%.2f\t%d\n", r.Sensor, r.Correlation, r.Lag, r.Gain, r.Immediate, r.Samples)
+	}
+	return tw.Flush()
+}
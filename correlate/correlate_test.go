@@ -0,0 +1,131 @@
+package correlate
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/record"
+)
+
+var start = time.Unix(1700000000, 0)
+
+// loadAt is a load that's busy for two minutes in every five.
+func loadAt(t time.Time) float64 {
+	if t.Sub(start)%(5*time.Minute) < 2*time.Minute {
+		return 90
+	}
+	return 10
+}
+
+func snapshot(at time.Time, load float64) *lmsensors.Snapshot {
+	// Tctl follows the load 30s later, at 0.3 °C per %; the NVMe drive doesn't.
+	tctl := &lmsensors.TempSensor{}
+	tctl.Name, tctl.Value, tctl.Time = "Tctl", 40+0.3*loadAt(at.Add(-30*time.Second)), at
+	nvme := &lmsensors.TempSensor{}
+	nvme.Name, nvme.Value, nvme.Time = "Composite", 35, at
+	fan := &lmsensors.FanSensor{}
+	fan.Name, fan.Value, fan.Time = "fan1", 1000+load, at
+	return lmsensors.NewSnapshot(&lmsensors.System{Chips: map[string]*lmsensors.Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]lmsensors.Sensor{"Tctl": tctl}},
+		"nvme-pci-0100":    {ID: "nvme-pci-0100", Sensors: map[string]lmsensors.Sensor{"Composite": nvme}},
+		"nct6798-isa-0290": {ID: "nct6798-isa-0290", Sensors: map[string]lmsensors.Sensor{"fan1": fan}},
+	}}, at)
+}
+
+func TestCollector(t *testing.T) {
+	var at time.Time
+	c := &Collector{Load: func() (float64, error) { return loadAt(at), nil }, Window: 20 * time.Minute}
+	for i := range 3600 {
+		at = start.Add(time.Duration(i) * time.Second)
+		c.Add(snapshot(at, loadAt(at)))
+	}
+	if n := len(c.load); n != 1201 {
+		t.Errorf("wrong window: %d samples", n)
+	}
+
+	reports := c.Reports(10*time.Second, 2*time.Minute)
+	if len(reports) != 1 {
+		t.Fatalf("wrong reports: %+v", reports)
+	}
+	r := reports[0]
+	if r.Sensor != "k10temp-pci-00c3/Tctl" || r.Lag != 30*time.Second || r.Correlation < 0.99 || math.Abs(r.Gain-0.3) > 0.01 || r.Immediate > 0.9 {
+		t.Errorf("wrong report: %+v", r)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, reports); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[1], "k10temp-pci-00c3/Tctl  1.00") || !strings.Contains(lines[1], "30s") {
+		t.Errorf("wrong table:\n%s", buf.String())
+	}
+
+	// Other sensors can be picked.
+	c = &Collector{Load: func() (float64, error) { return loadAt(at), nil }, Sensors: "fan*"}
+	for i := range 600 {
+		at = start.Add(time.Duration(i) * time.Second)
+		c.Add(snapshot(at, loadAt(at)))
+	}
+	if reports := c.Reports(10*time.Second, time.Minute); len(reports) != 1 || reports[0].Lag != 0 || reports[0].Gain != 1 {
+		t.Errorf("wrong fan reports: %+v", reports)
+	}
+}
+
+func TestCorrelate(t *testing.T) {
+	var load, temp Series
+	for i := range 5 {
+		load = append(load, record.Sample{Time: start.Add(time.Duration(i) * time.Second), Value: float64(i)})
+		temp = append(temp, record.Sample{Time: start.Add(time.Duration(i) * time.Second), Value: float64(i)})
+	}
+	if _, ok := Correlate("x/y", load, temp, time.Second, 0); ok {
+		t.Error("correlated too few steps")
+	}
+	if _, ok := Correlate("x/y", load, nil, time.Second, 0); ok {
+		t.Error("correlated nothing")
+	}
+}
+
+func TestReadSeries(t *testing.T) {
+	s, err := ReadSeries(strings.NewReader("time,util\n# warmup done\n1700000000,12.5\n1700000000.5 50\n\n2023-11-14T22:13:21Z\t75\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 3 || !s[1].Time.Equal(start.Add(500*time.Millisecond)) || s[1].Value != 50 || !s[2].Time.Equal(start.Add(time.Second)) {
+		t.Errorf("wrong series: %+v", s)
+	}
+	for _, bad := range []string{"1700000000,1\nnope,2\n", "1700000001,1\n1700000000,2\n", "1700000000\n"} {
+		if _, err := ReadSeries(strings.NewReader(bad)); err == nil {
+			t.Errorf("no error for %q", bad)
+		}
+	}
+}
+
+func TestCPUUtilization(t *testing.T) {
+	ProcStat = filepath.Join(t.TempDir(), "stat")
+	defer func() { ProcStat = "/proc/stat" }()
+	write := func(s string) {
+		if err := os.WriteFile(ProcStat, []byte(s), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	util := CPUUtilization()
+	write("cpu  100 0 100 700 100 0 0 0 0 0\ncpu0 100 0 100 700 100 0 0 0 0 0\n")
+	if u, err := util(); err != nil || u != 20 {
+		t.Errorf("wrong utilization since boot: %v, %v", u, err)
+	}
+	// 150 of 200 busy since.
+	write("cpu  200 0 150 740 110 0 0 0 0 0\n")
+	if u, err := util(); err != nil || u != 75 {
+		t.Errorf("wrong utilization: %v, %v", u, err)
+	}
+	write("intr 1\n")
+	if _, err := util(); err == nil {
+		t.Error("no error for a bad stat file")
+	}
+}
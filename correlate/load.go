@@ -0,0 +1,103 @@
+package correlate
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors/record"
+)
+
+// ProcStat is where [CPUUtilization] reads the CPU times from; it can be pointed at the host's, or a fixture.
+var ProcStat = "/proc/stat"
+
+// CPUUtilization returns a load function for a [Collector] that gives the percentage of time all the CPUs were busy since it was last called; the first call's is since boot.
+func CPUUtilization() func() (float64, error) {
+	var lastBusy, lastTotal uint64
+	return func() (float64, error) {
+		busy, total, err := cpuTimes()
+		if err != nil {
+			return 0, err
+		}
+		db, dt := busy-lastBusy, total-lastTotal
+		lastBusy, lastTotal = busy, total
+		if dt == 0 {
+			return 0, errors.New("no CPU time has passed")
+		}
+		return 100 * float64(db) / float64(dt), nil
+	}
+}
+
+// cpuTimes reads the aggregate cpu line, in which idle and iowait are the idle times.
+func cpuTimes() (busy, total uint64, err error) {
+	f, err := os.Open(ProcStat)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return 0, 0, fmt.Errorf("%s doesn't start with the cpu times", ProcStat)
+	}
+	// guest and guest_nice are already counted in user and nice.
+	for i, f := range fields[1:min(len(fields), 9)] {
+		v, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("bad cpu time %q in %s", f, ProcStat)
+		}
+		total += v
+		if i != 3 && i != 4 {
+			busy += v
+		}
+	}
+	return busy, total, nil
+}
+
+// ReadSeries reads a load series, eg logged by a benchmark, as lines of a time and a value, separated by a comma or whitespace.
+// Times are Unix seconds, which can be fractional, or RFC 3339. Blank lines, ones starting with #, and a header line are skipped.
+func ReadSeries(r io.Reader) (Series, error) {
+	var s Series
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: want a time and a value", n)
+		}
+		at, err := parseTime(fields[0])
+		if err != nil {
+			if n == 1 && len(s) == 0 {
+				continue // A header
+			}
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		v, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if len(s) != 0 && at.Before(s[len(s)-1].Time) {
+			return nil, fmt.Errorf("line %d: out of time order", n)
+		}
+		s = append(s, record.Sample{Time: at, Value: v})
+	}
+	return s, sc.Err()
+}
+
+func parseTime(f string) (time.Time, error) {
+	if secs, err := strconv.ParseFloat(f, 64); err == nil {
+		return time.Unix(0, int64(secs*1e9)), nil
+	}
+	return time.Parse(time.RFC3339Nano, f)
+}
@@ -0,0 +1,220 @@
+// Package daemon is a reusable service loop for sensor agents: it wires together [lmsensors.Init], a [lmsensors.Watcher], sinks, config reloading on SIGHUP, and graceful shutdown.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/config"
+)
+
+// Sink receives every successful poll, eg to export it somewhere.
+type Sink interface {
+	Send(ctx context.Context, sys *lmsensors.System) error
+}
+
+// SinkFunc adapts a function to a [Sink].
+type SinkFunc func(ctx context.Context, sys *lmsensors.System) error
+
+func (f SinkFunc) Send(ctx context.Context, sys *lmsensors.System) error {
+	return f(ctx, sys)
+}
+
+// Options configure [Run].
+type Options struct {
+	Interval      time.Duration             // How often to poll; defaults to a second
+	SensorsConfig string                    // libsensors config file, if not the default, see [lmsensors.InitFile]
+	Trigger       <-chan struct{}           // If set, poll whenever it's sent to, rather than every Interval, until it's closed
+	Watcher       []lmsensors.WatcherOption // eg detectors
+	Sinks         []Sink
+
+	// OnReload is called on SIGHUP, after libsensors has re-read its config, eg to reload the application's own.
+	// An error from it stops the daemon.
+	OnReload func() error
+
+	// HTTP, if set, is served for as long as the daemon runs: on the sockets systemd passes, if it's socket-activated, see [Listeners], or else on HTTPAddr.
+	HTTP     http.Handler
+	HTTPAddr string
+
+	Logger lmsensors.Logger // Defaults to logging nothing
+}
+
+// OptionsFromConfig makes the [Options] described by a config file.
+// Readings are filtered and relabelled according to the config before they reach the detectors and sinks.
+// With the HTTP exporter's listen address set, the latest reading is served there as OpenMetrics, at its path.
+// The libsensors config file and the exporter are set up once: changing them in the config takes a restart, not a reload.
+func OptionsFromConfig(c *config.Config) Options {
+	opts := Options{
+		Interval:      time.Duration(c.Interval),
+		SensorsConfig: c.SensorsConfig,
+	}
+	for _, ci := range c.ChipIntervals {
+		opts.Watcher = append(opts.Watcher, lmsensors.WithChipInterval(ci.Chips, time.Duration(ci.Interval)))
+	}
+	opts.Watcher = append(opts.Watcher, lmsensors.WithFilter(c))
+	if hc := c.Exporters.HTTP; hc.Listen != "" {
+		m := &metrics{}
+		opts.Watcher = append(opts.Watcher, lmsensors.OnPoll(m.publish))
+		mux := http.NewServeMux()
+		mux.Handle(hc.Path, m)
+		opts.HTTP, opts.HTTPAddr = mux, hc.Listen
+	}
+	return opts
+}
+
+// init initialises libsensors, with the options' config file if there is one.
+func (opts Options) init() error {
+	if opts.SensorsConfig != "" {
+		return lmsensors.InitFile(opts.SensorsConfig)
+	}
+	return lmsensors.Init()
+}
+
+// Run initialises libsensors, and polls every interval, or on every trigger, sending each reading to the sinks, until ctx is done or it gets SIGINT or SIGTERM.
+// On SIGHUP, libsensors is re-initialised, re-reading its config.
+// Polling, reloading and sending all happen on the calling goroutine, so they never race with each other.
+//
+// Under systemd, it can be a Type=notify unit: it's ready after the first poll, and with WatchdogSec= it pings the watchdog after every poll that reads any chips, so a wedged driver gets it restarted.
+// The watchdog needs polling more often than WatchdogSec, so it doesn't suit a Trigger.
+func Run(ctx context.Context, opts Options) error {
+	if opts.Interval == 0 {
+		opts.Interval = time.Second
+	}
+	log := opts.Logger
+	if log == nil {
+		log = nopLogger{}
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	if err := opts.init(); err != nil {
+		return err
+	}
+	defer lmsensors.Cleanup()
+
+	if opts.HTTP != nil {
+		srv, err := serveHTTP(opts.HTTP, opts.HTTPAddr, log)
+		if err != nil {
+			return err
+		}
+		defer srv.Close()
+	}
+	sdNotify := func(state string) {
+		if err := notify(state); err != nil {
+			log.Warn("can't notify systemd", "state", state, "error", err)
+		}
+	}
+	defer sdNotify("STOPPING=1")
+	watchdog := watchdogInterval()
+	if watchdog != 0 && opts.Trigger == nil && opts.Interval > watchdog/2 {
+		log.Warn("polling too slowly for the systemd watchdog", "interval", opts.Interval, "watchdog", watchdog)
+	}
+	ready := false
+
+	w := lmsensors.NewWatcher(opts.Interval, opts.Watcher...)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	tick := timer.C
+	if opts.Trigger != nil {
+		tick = nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("shutting down")
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return nil
+			}
+			return ctx.Err()
+		case <-hup:
+			log.Info("reloading")
+			sdNotify("RELOADING=1")
+			lmsensors.Cleanup()
+			if err := opts.init(); err != nil {
+				return err
+			}
+			if opts.OnReload != nil {
+				if err := opts.OnReload(); err != nil {
+					return err
+				}
+			}
+			sdNotify("READY=1")
+			if opts.Trigger != nil {
+				continue // Wait to be asked
+			}
+		case <-tick:
+		case _, ok := <-opts.Trigger:
+			if !ok {
+				log.Info("trigger closed, shutting down")
+				return nil
+			}
+		}
+
+		now := time.Now()
+		sys, err := w.Poll(ctx)
+		if err != nil {
+			log.Warn("some sensors failed to read", "error", err)
+		}
+		for _, sink := range opts.Sinks {
+			if err := sink.Send(ctx, sys); err != nil {
+				log.Error("sink failed", "error", err)
+			}
+		}
+		if !ready {
+			sdNotify("READY=1")
+			ready = true
+		}
+		if watchdog != 0 && len(sys.Chips) != 0 {
+			sdNotify("WATCHDOG=1")
+		}
+		if opts.Trigger == nil {
+			timer.Reset(time.Until(w.Next(now)))
+		}
+	}
+}
+
+// serveHTTP serves h on the sockets systemd passed, or else on addr.
+func serveHTTP(h http.Handler, addr string, log lmsensors.Logger) (*http.Server, error) {
+	ls, err := Listeners()
+	if err != nil {
+		return nil, err
+	}
+	if len(ls) == 0 {
+		if addr == "" {
+			return nil, errors.New("no HTTP address, and not socket-activated")
+		}
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		ls = map[string]net.Listener{"http": l}
+	}
+	srv := &http.Server{Handler: h, ReadHeaderTimeout: 10 * time.Second}
+	for name, l := range ls {
+		log.Info("serving HTTP", "listener", name, "addr", l.Addr())
+		go func() {
+			if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Error("HTTP server failed", "listener", name, "error", err)
+			}
+		}()
+	}
+	return srv, nil
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
@@ -0,0 +1,100 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/config"
+	"github.com/mt-inside/go-lmsensors/format"
+)
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	polls, reloads := 0, 0
+	err := Run(ctx, Options{
+		Interval: time.Millisecond,
+		Sinks: []Sink{SinkFunc(func(ctx context.Context, sys *lmsensors.System) error {
+			polls++
+			if polls == 2 {
+				_ = syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+			}
+			if polls >= 10 && reloads > 0 {
+				cancel()
+			}
+			return nil
+		})},
+		OnReload: func() error {
+			reloads++
+			return nil
+		},
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	if polls < 10 || reloads != 1 {
+		t.Errorf("polls=%d reloads=%d", polls, reloads)
+	}
+}
+
+func TestRunTrigger(t *testing.T) {
+	trigger := make(chan struct{})
+	polls := 0
+	go func() {
+		for range 3 {
+			trigger <- struct{}{}
+		}
+		close(trigger)
+	}()
+	err := Run(context.Background(), Options{
+		Trigger: trigger,
+		Sinks: []Sink{SinkFunc(func(ctx context.Context, sys *lmsensors.System) error {
+			polls++
+			return nil
+		})},
+	})
+	if err != nil || polls != 3 {
+		t.Errorf("err=%v polls=%d", err, polls)
+	}
+}
+
+func TestOptionsFromConfig(t *testing.T) {
+	c, err := config.Parse([]byte(`{"sensors_config": "/nonexistent/sensors3.conf", "exporters": {"http": {"listen": "127.0.0.1:0"}}}`), json.Unmarshal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := OptionsFromConfig(c)
+	if opts.SensorsConfig != "/nonexistent/sensors3.conf" || opts.HTTPAddr != "127.0.0.1:0" || opts.HTTP == nil {
+		t.Fatalf("wrong options: %+v", opts)
+	}
+	if err := Run(context.Background(), opts); err == nil {
+		t.Error("ran without its libsensors config")
+	}
+
+	rec := httptest.NewRecorder()
+	opts.HTTP.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("metrics before a poll: %d", rec.Code)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	opts.SensorsConfig = ""
+	opts.Sinks = []Sink{SinkFunc(func(context.Context, *lmsensors.System) error {
+		rec = httptest.NewRecorder()
+		opts.HTTP.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		cancel()
+		return nil
+	})}
+	if err := Run(ctx, opts); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != format.OpenMetricsContentType {
+		t.Errorf("wrong metrics response: %d %v", rec.Code, rec.Header())
+	}
+}
@@ -0,0 +1,40 @@
+package daemon
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/format"
+)
+
+// metrics serves the latest poll as OpenMetrics, for a config's HTTP exporter.
+type metrics struct {
+	mu     sync.Mutex
+	latest *lmsensors.Snapshot
+}
+
+func (m *metrics) publish(sys *lmsensors.System, _ error) {
+	snap := lmsensors.NewSnapshot(sys, sys.Time)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latest = snap
+}
+
+func (m *metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	snap := m.latest
+	m.mu.Unlock()
+	if snap == nil {
+		http.Error(w, "no readings yet", http.StatusServiceUnavailable)
+		return
+	}
+	var buf bytes.Buffer
+	if err := format.OpenMetrics(&buf, snap, nil); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", format.OpenMetricsContentType)
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,89 @@
+package daemon
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// notify sends a state to systemd, eg "READY=1", if it's running us as a Type=notify unit; otherwise it does nothing.
+func notify(state string) error {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return nil
+	}
+	if strings.HasPrefix(addr, "@") {
+		addr = "\x00" + addr[1:] // Abstract namespace
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// watchdogInterval is how often systemd wants to hear from us, from WatchdogSec=, or 0 if it doesn't.
+func watchdogInterval() time.Duration {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// listenFDsStart is the first file descriptor systemd passes, SD_LISTEN_FDS_START.
+const listenFDsStart = 3
+
+// Listeners returns the sockets systemd passed us through socket activation, by the names in their units' FileDescriptorName=, which default to the socket unit's name.
+// It's empty if we weren't socket-activated.
+// The environment variables describing them are unset, so that they aren't passed on to children.
+func Listeners() (map[string]net.Listener, error) {
+	defer func() {
+		for _, v := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+			os.Unsetenv(v)
+		}
+	}()
+	return listeners(os.Getenv, listenFDsStart)
+}
+
+func listeners(getenv func(string) string, start int) (map[string]net.Listener, error) {
+	if pid := getenv("LISTEN_PID"); pid == "" || pid != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(getenv("LISTEN_FDNAMES"), ":")
+	ls := map[string]net.Listener{}
+	var errs []error
+	for i := range n {
+		fd := start + i
+		syscall.CloseOnExec(fd)
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(fd), name)
+		l, err := net.FileListener(f)
+		f.Close() // FileListener dups it
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, ok := ls[name]; ok {
+			name += "." + strconv.Itoa(i)
+		}
+		ls[name] = l
+	}
+	return ls, errors.Join(errs...)
+}
@@ -0,0 +1,97 @@
+package daemon
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+func TestNotify(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: sock, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", sock)
+	t.Setenv("WATCHDOG_USEC", "10000000")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	polls := 0
+	err = Run(ctx, Options{
+		Interval: time.Millisecond,
+		Watcher: []lmsensors.WatcherOption{lmsensors.OnPoll(func(sys *lmsensors.System, _ error) {
+			sys.Chips["fake-isa-0000"] = &lmsensors.Chip{ID: "fake-isa-0000"} // The watchdog needs something read
+		})},
+		Sinks: []Sink{SinkFunc(func(context.Context, *lmsensors.System) error {
+			if polls++; polls == 3 {
+				cancel()
+			}
+			return nil
+		})},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var states []string
+	buf := make([]byte, 64)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		n, err := conn.Read(buf)
+		if err != nil {
+			break
+		}
+		states = append(states, string(buf[:n]))
+	}
+	want := []string{"READY=1", "WATCHDOG=1", "WATCHDOG=1", "WATCHDOG=1", "STOPPING=1"}
+	if len(states) != len(want) {
+		t.Fatalf("got %v, want %v", states, want)
+	}
+	for i := range want {
+		if states[i] != want[i] {
+			t.Errorf("got %v, want %v", states, want)
+		}
+	}
+}
+
+func TestListeners(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	f, err := l.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	// Pretend it was passed as the only socket, at whatever descriptor it got.
+	fd, err := syscall.Dup(int(f.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"LISTEN_PID": strconv.Itoa(os.Getpid()), "LISTEN_FDS": "1", "LISTEN_FDNAMES": "metrics"}
+	ls, err := listeners(func(k string) string { return env[k] }, fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := ls["metrics"]
+	if !ok || got.Addr().String() != l.Addr().String() {
+		t.Fatalf("listeners: %v", ls)
+	}
+	got.Close()
+
+	env["LISTEN_PID"] = "1"
+	if ls, _ := listeners(func(k string) string { return env[k] }, fd); len(ls) != 0 {
+		t.Error("another process's sockets were used")
+	}
+}
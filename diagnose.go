@@ -0,0 +1,126 @@
+package lmsensors
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// sysfsMagic is SYSFS_MAGIC from linux/magic.h
+const sysfsMagic = 0x62656572
+
+// Diagnosis explains what the system looks like from the point of view of this package.
+// It is mostly useful when [Get] returns no chips at all, which libsensors doesn't consider an error.
+type Diagnosis struct {
+	Chips            int  // Number of chips detected by libsensors
+	SysfsMounted     bool // Whether a sysfs is mounted at [SysfsRoot]
+	HwmonPresent     bool // Whether class/hwmon exists under [SysfsRoot]
+	HwmonDevices     int  // Number of entries in class/hwmon
+	HwmonDenied      int  // Number of hwmon devices whose name couldn't be read due to permissions
+	InContainer      bool // Whether we seem to be running in a container
+	SELinuxEnforcing bool // Whether SELinux is in enforcing mode
+
+	Problems []string // Human readable descriptions of anything that looks wrong
+}
+
+// Diagnose inspects the system for common reasons of not finding any sensors.
+// It must be called after [Init].
+func Diagnose() *Diagnosis {
+	d := &Diagnosis{}
+	for range Chips {
+		d.Chips++
+	}
+
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(SysfsRoot, &st); err == nil && st.Type == sysfsMagic {
+		d.SysfsMounted = true
+	}
+	d.InContainer = InContainer()
+	if p, err := os.ReadFile(filepath.Join(SysfsRoot, "fs", "selinux", "enforce")); err == nil {
+		d.SELinuxEnforcing = strings.TrimSpace(string(p)) == "1"
+	}
+
+	dir := hwmonDir()
+	entries, err := os.ReadDir(dir)
+	switch {
+	case err == nil:
+		d.HwmonPresent = true
+	case errors.Is(err, fs.ErrPermission):
+		d.HwmonPresent = true
+		d.HwmonDenied = 1
+	}
+	d.HwmonDevices = len(entries)
+	for _, e := range entries {
+		_, err := os.ReadFile(filepath.Join(dir, e.Name(), "name"))
+		if errors.Is(err, fs.ErrPermission) {
+			d.HwmonDenied++
+		}
+	}
+
+	switch {
+	case !d.SysfsMounted && d.InContainer:
+		d.problemf("no sysfs mounted at %s, and this looks like a container; mount the host's /sys (read-only is enough)", SysfsRoot)
+	case !d.SysfsMounted:
+		d.problemf("no sysfs mounted at %s", SysfsRoot)
+	case !d.HwmonPresent:
+		d.problemf("%s doesn't exist; is the kernel built with CONFIG_HWMON?", dir)
+	case d.HwmonDevices == 0 && d.InContainer:
+		d.problemf("%s is empty, and this looks like a container; the host's sysfs may not be visible", dir)
+	case d.HwmonDevices == 0:
+		d.problemf("%s is empty; load the drivers for your hardware, eg by running sensors-detect", dir)
+	}
+	if d.HwmonDenied != 0 {
+		if d.SELinuxEnforcing {
+			d.problemf("permission denied reading %d hwmon device(s), and SELinux is enforcing; check the audit log for denials", d.HwmonDenied)
+		} else {
+			d.problemf("permission denied reading %d hwmon device(s)", d.HwmonDenied)
+		}
+	}
+	if d.Chips == 0 && d.HwmonDevices != 0 && d.HwmonDenied == 0 {
+		d.problemf("%d hwmon device(s) present but libsensors detected no chips; check your sensors3.conf for ignore statements", d.HwmonDevices)
+	}
+
+	return d
+}
+
+func (d *Diagnosis) problemf(format string, args ...any) {
+	d.Problems = append(d.Problems, fmt.Sprintf(format, args...))
+}
+
+// OK is true when there are chips detected and nothing looks wrong.
+func (d *Diagnosis) OK() bool {
+	return d.Chips != 0 && len(d.Problems) == 0
+}
+
+func (d *Diagnosis) String() string {
+	var ret strings.Builder
+	fmt.Fprintf(&ret, "chips=%d sysfs=%t hwmon=%t hwmon_devices=%d container=%t selinux_enforcing=%t",
+		d.Chips, d.SysfsMounted, d.HwmonPresent, d.HwmonDevices, d.InContainer, d.SELinuxEnforcing)
+	for _, p := range d.Problems {
+		ret.WriteString("\n  " + p)
+	}
+	return ret.String()
+}
+
+// InContainer guesses whether we're running in a container (Docker, Podman, Kubernetes, ...).
+// In a container, the host's hwmon devices are only visible if the host's sysfs is mounted, see [SysfsRoot].
+func InContainer() bool {
+	for _, f := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(f); err == nil {
+			return true
+		}
+	}
+	if _, ok := os.LookupEnv("KUBERNETES_SERVICE_HOST"); ok {
+		return true
+	}
+	p, err := os.ReadFile("/proc/1/cgroup")
+	if err != nil {
+		return false
+	}
+	s := string(p)
+	return strings.Contains(s, "docker") || strings.Contains(s, "kubepods") || strings.Contains(s, "containerd") || strings.Contains(s, "libpod")
+}
@@ -0,0 +1,96 @@
+package lmsensors
+
+import (
+	"math"
+	"time"
+)
+
+// RegisterEnergyCounterBits sets how wide a driver's energy counters are, in microjoules, for drivers that wrap before 64 bits.
+// prefix is the chip prefix, eg "ina238". It sets the EnergyCounterBits of the driver's [Quirk].
+// [WithEnergyPower] picks it up from its next poll.
+func RegisterEnergyCounterBits(prefix string, bits uint) {
+	updateQuirk(prefix, func(q *Quirk) { q.EnergyCounterBits = bits })
+}
+
+func energyWrap(prefix string) float64 {
+	bits := quirkOf(prefix).EnergyCounterBits
+	if bits == 0 {
+		bits = 64
+	}
+	return math.Ldexp(1, int(bits)) / 1e6 // J
+}
+
+// EnergyDelta is the energy used between two readings of a counter that wraps at wrap, all in joules.
+// A counter that's gone backwards by more than half its range is taken to have wrapped; by less, to have been reset, eg by a driver reload, which gives false.
+func EnergyDelta(prev, cur, wrap float64) (float64, bool) {
+	d := cur - prev
+	switch {
+	case d >= 0:
+		return d, true
+	case -d > wrap/2:
+		return d + wrap, true
+	default:
+		return 0, false
+	}
+}
+
+type energySample struct {
+	energy float64
+	time   time.Time
+	power  float64
+	ok     bool
+}
+
+type energyDetector struct {
+	last map[string]*energySample
+}
+
+// WithEnergyPower adds a virtual power sensor alongside each energy counter, named like the counter with " power" appended, with the average power since the previous poll.
+// Counters wrapping is accounted for, see [RegisterEnergyCounterBits]. The power sensors appear from the second poll.
+func WithEnergyPower() WatcherOption {
+	return func(w *Watcher) {
+		w.energy = &energyDetector{last: map[string]*energySample{}}
+	}
+}
+
+func (d *energyDetector) observe(sys *System) {
+	seen := make(map[string]*energySample, len(d.last))
+	for _, chip := range sys.Chips {
+		wrap := energyWrap(chip.Type)
+		var powers []Sensor
+		for name, sensor := range chip.Sensors {
+			if g, ok := sensor.(*GenericSensor); !ok || g.FeatureType != Energy {
+				continue
+			}
+			val, ok := valueOf(sensor)
+			if !ok {
+				continue
+			}
+			key := sensorKey(chip.ID, name)
+			cur := &energySample{energy: val, time: TimeOf(sensor)}
+			prev, ok := d.last[key]
+			switch {
+			case !ok:
+			case cur.time.Equal(prev.time):
+				// Carried over from the last poll, see [WithChipInterval]
+				cur.power, cur.ok = prev.power, prev.ok
+			default:
+				if delta, ok := EnergyDelta(prev.energy, cur.energy, wrap); ok && cur.time.After(prev.time) {
+					cur.power, cur.ok = delta/cur.time.Sub(prev.time).Seconds(), true
+				}
+			}
+			seen[key] = cur
+			if cur.ok {
+				powers = append(powers, &GenericSensor{
+					baseSensor:  baseSensor{Name: name + " power", Value: cur.power, Time: cur.time},
+					FeatureType: Power,
+					unit:        "W",
+				})
+			}
+		}
+		for _, p := range powers {
+			chip.Sensors[p.GetName()] = p
+		}
+	}
+	d.last = seen
+}
@@ -0,0 +1,62 @@
+package lmsensors
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEnergyDelta(t *testing.T) {
+	wrap := math.Ldexp(1, 32) / 1e6
+	for _, c := range []struct {
+		prev, cur, want float64
+		ok              bool
+	}{
+		{100, 150, 50, true},
+		{wrap - 10, 5, 15, true}, // Wrapped
+		{100, 90, 0, false},      // Reset
+	} {
+		got, ok := EnergyDelta(c.prev, c.cur, wrap)
+		if ok != c.ok || math.Abs(got-c.want) > 1e-6 {
+			t.Errorf("EnergyDelta(%v, %v) = %v, %t", c.prev, c.cur, got, ok)
+		}
+	}
+}
+
+func TestEnergyPower(t *testing.T) {
+	RegisterEnergyCounterBits("test_energy", 32)
+	defer delete(quirks, "test_energy")
+	wrap := math.Ldexp(1, 32) / 1e6
+
+	w := NewWatcher(time.Second, WithEnergyPower())
+	start := time.Now()
+	poll := func(energy float64, at time.Time) *System {
+		sys := &System{Chips: map[string]*Chip{
+			"test_energy-isa-0000": {ID: "test_energy-isa-0000", Type: "test_energy", Sensors: map[string]Sensor{
+				"Esocket0": &GenericSensor{baseSensor: baseSensor{Name: "Esocket0", Value: energy, Time: at}, FeatureType: Energy, unit: "J"},
+			}},
+		}}
+		w.observe(sys)
+		return sys
+	}
+	power := func(sys *System) (float64, bool) {
+		s, ok := sys.Chips["test_energy-isa-0000"].Sensors["Esocket0 power"]
+		if !ok {
+			return 0, false
+		}
+		return s.Reading(), true
+	}
+
+	if _, ok := power(poll(wrap-50, start)); ok {
+		t.Error("power on the first poll")
+	}
+	if p, ok := power(poll(30, start.Add(2*time.Second))); !ok || math.Abs(p-40) > 1e-6 {
+		t.Errorf("wrong power across wrap: %v %t", p, ok)
+	}
+	if p, ok := power(poll(30, start.Add(2*time.Second))); !ok || math.Abs(p-40) > 1e-6 {
+		t.Errorf("wrong power for carried reading: %v %t", p, ok)
+	}
+	if _, ok := power(poll(10, start.Add(3*time.Second))); ok {
+		t.Error("power across a reset")
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mt-inside/go-usvc"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/tray"
+)
+
+// A headless stand-in for a tray applet: swap the callbacks' bodies for whatever tray library's SetIcon and SetTooltip.
+func main() {
+	log := usvc.GetLogger(false)
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if err := lmsensors.Init(); err != nil {
+		panic(err)
+	}
+
+	p := &tray.Provider{
+		OnIcon: func(icon string) {
+			fmt.Println("icon:", icon)
+		},
+		OnState: func(st tray.State) {
+			fmt.Println(st.Tooltip())
+			fmt.Println()
+		},
+	}
+	w := lmsensors.NewWatcher(2*time.Second, p.Watch(), lmsensors.WithAlarmDetection(func(ev lmsensors.AlarmEvent) {
+		log.Info("Alarm", "chip", ev.Chip, "sensor", ev.Sensor, "raised", ev.Raised)
+	}))
+	_ = w.Run(ctx)
+}
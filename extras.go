@@ -0,0 +1,97 @@
+package lmsensors
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	sf "github.com/mt-inside/go-lmsensors/subfeature"
+)
+
+// GenericSensor is a reading this package doesn't know how to interpret, so passes through as a plain number.
+// Its Type() is always [Unhandled], so it can't be mistaken for a properly understood reading.
+type GenericSensor struct {
+	baseSensor
+
+	FeatureType LmSensorType // The libsensors feature type, or Unhandled if it didn't come through libsensors
+	Attr        string       // The sysfs attribute it was read from, if it didn't come through libsensors
+
+	unit string
+}
+
+func (s *GenericSensor) Rendered() string {
+	return formatValue(s.Value, -1)
+}
+
+// Unit is a guess, based on the subfeature the value was read from.
+func (s *GenericSensor) Unit() string {
+	return s.unit
+}
+
+func (s *GenericSensor) Type() LmSensorType {
+	return Unhandled
+}
+
+func (s *GenericSensor) featureType() LmSensorType {
+	return s.FeatureType
+}
+
+// FeatureTypeOf is a sensor's libsensors feature type, seeing through [GenericSensor]s, which is how eg power and energy readings arrive.
+func FeatureTypeOf(s Sensor) LmSensorType {
+	if g, ok := s.(interface{ featureType() LmSensorType }); ok {
+		return g.featureType()
+	}
+	return s.Type()
+}
+
+func (s *GenericSensor) String() string {
+	return fmt.Sprintf("%s: %s", s.Name, render(s))
+}
+
+// unitGuess is the unit of a subfeature's value, going by the type of feature it's for.
+func unitGuess(sub sf.SubFeature) string {
+	if sub&0x80 != 0 {
+		return "" // Alarms, beeps, faults, etc
+	}
+	switch LmSensorType(sub >> 8) {
+	case Voltage, VID:
+		return "V"
+	case Fan:
+		return "min⁻¹"
+	case Temperature:
+		return "°C"
+	case Power:
+		return "W"
+	case Energy:
+		return "J"
+	case Current:
+		return "A"
+	case Humidity:
+		return "%RH"
+	default:
+		return ""
+	}
+}
+
+// sysfsExtras reads the numeric attributes in a hwmon directory that aren't known to libsensors.
+// Labels are skipped, as libsensors uses them for the names of the features they label.
+func sysfsExtras(dir string, known map[string]bool) map[string]Sensor {
+	attrs, err := attributes(dir)
+	if err != nil {
+		logger.Debug("can't list sysfs attributes", "path", dir, "error", err)
+		return nil
+	}
+	extras := map[string]Sensor{}
+	for _, attr := range attrs {
+		if known[attr] || strings.HasSuffix(attr, "_label") {
+			continue
+		}
+		val, err := strconv.ParseFloat(readSysfsString(filepath.Join(dir, attr)), 64)
+		if err != nil {
+			continue
+		}
+		extras[attr] = &GenericSensor{baseSensor: baseSensor{Name: attr, Value: val}, FeatureType: Unhandled, Attr: attr}
+	}
+	return extras
+}
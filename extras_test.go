@@ -0,0 +1,21 @@
+package lmsensors
+
+import "testing"
+
+func TestSysfsExtras(t *testing.T) {
+	extras := sysfsExtras("testdata/sysfs/class/hwmon/hwmon1/device", map[string]bool{"fan1_input": true})
+	if extras["pwm1"] == nil || extras["pwm1"].Reading() != 2 || extras["fan1_input"] != nil || extras["temp1_label"] != nil {
+		t.Fatalf("wrong extras: %v", extras)
+	}
+	s, ok := extras["pwm1_enable"].(*GenericSensor)
+	if !ok || s.Value != 1 || s.Attr != "pwm1_enable" || s.Type() != Unhandled {
+		t.Errorf("wrong extra: %v", extras["pwm1_enable"])
+	}
+	if str := s.String(); str != "pwm1_enable: 1" {
+		t.Errorf("wrong rendering: %s", str)
+	}
+
+	if extras := sysfsExtras("testdata/sysfs/class/hwmon/hwmon0", nil); len(extras) != 1 || extras["temp1_input"] == nil {
+		t.Errorf("labels not skipped: %v", extras)
+	}
+}
@@ -0,0 +1,124 @@
+package fancontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// ErrTargetUnreachable is returned by [AutoTune.Run] when even the maximum duty doesn't keep the temperature under the target.
+var ErrTargetUnreachable = errors.New("target temperature unreachable at maximum duty")
+
+// AutoTune finds the lowest duty of a PWM output that keeps a temperature under a target at the current load, and suggests a curve from it.
+// Run it at the steady load the fan should cope with, eg a stress test.
+type AutoTune struct {
+	PWM    PWM
+	Sensor lmsensors.Selector // The hottest match is kept under Target
+	Target float64            // In °C
+
+	MinDuty uint8   // The duty is never set below this, as some fans stall; 0 for the default of 64
+	MaxDuty uint8   // The duty it starts from; 0 for the default of 255
+	Limit   float64 // A temperature that ends the tuning, at MaxDuty, as soon as it's reached; 0 for Target+10
+	Step    uint8   // How much to lower the duty by each step; 0 for the default of 16
+
+	Settle time.Duration                                    // How long to hold each duty before reading; 0 for the default of two minutes
+	Read   func(context.Context) (*lmsensors.System, error) // Reads the temperatures; nil for [lmsensors.GetContext]
+}
+
+// TuneResult is what [AutoTune.Run] found.
+type TuneResult struct {
+	Duty  uint8   // The lowest duty that kept under the target
+	Temp  float64 // The temperature at Duty
+	Curve Curve   // A suggested curve: MinDuty up to 10 °C under the target, Duty at the target, and MaxDuty at the limit
+}
+
+func (a AutoTune) withDefaults() AutoTune {
+	if a.MinDuty == 0 {
+		a.MinDuty = 64
+	}
+	if a.MaxDuty == 0 {
+		a.MaxDuty = 255
+	}
+	if a.Limit == 0 {
+		a.Limit = a.Target + 10
+	}
+	if a.Step == 0 {
+		a.Step = 16
+	}
+	if a.Settle == 0 {
+		a.Settle = 2 * time.Minute
+	}
+	if a.Read == nil {
+		a.Read = func(ctx context.Context) (*lmsensors.System, error) { return lmsensors.GetContext(ctx) }
+	}
+	return a
+}
+
+// Run steps the duty down from MaxDuty until the temperature settles over the target, then puts the PWM output back as it was.
+// If the temperature reaches the limit, it goes straight back to MaxDuty and stops.
+func (a AutoTune) Run(ctx context.Context) (res TuneResult, err error) {
+	a = a.withDefaults()
+	if a.MinDuty > a.MaxDuty || a.Limit <= a.Target {
+		return TuneResult{}, fmt.Errorf("bad bounds: duty %d-%d, target %v, limit %v", a.MinDuty, a.MaxDuty, a.Target, a.Limit)
+	}
+	saved, err := a.PWM.save()
+	if err != nil {
+		return TuneResult{}, fmt.Errorf("%s: %w", a.PWM, err)
+	}
+	defer func() {
+		if rerr := a.PWM.restore(saved); rerr != nil {
+			err = errors.Join(err, fmt.Errorf("restoring %s: %w", a.PWM, rerr))
+		}
+	}()
+
+	found := false
+	for duty := int(a.MaxDuty); duty >= int(a.MinDuty); duty -= int(a.Step) {
+		temp, err := a.try(ctx, uint8(duty))
+		if err != nil {
+			return TuneResult{}, err
+		}
+		if temp >= a.Limit {
+			if err := a.PWM.Device.SetPWMDuty(a.PWM.Channel, a.MaxDuty); err != nil {
+				return TuneResult{}, err
+			}
+			break
+		}
+		if temp > a.Target {
+			break
+		}
+		res.Duty, res.Temp, found = uint8(duty), temp, true
+	}
+	if !found {
+		return TuneResult{}, ErrTargetUnreachable
+	}
+	res.Curve = Curve{{a.Target - 10, a.MinDuty}, {a.Target, res.Duty}, {a.Limit, a.MaxDuty}}
+	return res, nil
+}
+
+// try sets a duty and waits for the temperature to settle, bailing out early if it reaches the limit.
+func (a AutoTune) try(ctx context.Context, duty uint8) (float64, error) {
+	if err := a.PWM.Device.SetPWM(a.PWM.Channel, duty); err != nil {
+		return 0, fmt.Errorf("%s: %w", a.PWM, err)
+	}
+	deadline := time.Now().Add(a.Settle)
+	for {
+		// Check every few seconds, so that overheating is caught without waiting out the step.
+		if err := sleep(ctx, min(time.Until(deadline), 5*time.Second)); err != nil {
+			return 0, err
+		}
+		sys, err := a.Read(ctx)
+		if sys == nil {
+			return 0, err
+		}
+		temp, ok := hottest(sys, a.Sensor)
+		if !ok {
+			return 0, fmt.Errorf("%s: %w", a.Sensor, ErrNoReading)
+		}
+		if temp >= a.Limit || !time.Now().Before(deadline) {
+			return temp, nil
+		}
+	}
+}
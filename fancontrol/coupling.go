@@ -0,0 +1,170 @@
+package fancontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// StepTest works out which temperatures each fan affects, by running each PWM output slow and then fast and seeing which temperatures drop.
+// It's the calibration step every fan control setup needs. Run it at a steady load, as changes in load look like coupling.
+type StepTest struct {
+	Low, High uint8         // The duties to step between; 0 for the defaults of 64 and 255
+	Settle    time.Duration // How long to hold each step before reading, as temperatures lag; 0 for the default of a minute
+	Samples   int           // How many readings, a second apart, to average at the end of each step; 0 for 1
+	MinDelta  float64       // How much a temperature has to drop to count as affected, in °C; 0 for the default of 1
+
+	Read func(context.Context) (*lmsensors.System, error) // Reads the temperatures; nil for [lmsensors.GetContext]
+}
+
+// TempResponse is how much a temperature dropped when a fan went from slow to fast.
+type TempResponse struct {
+	Chip     string
+	Sensor   string
+	Delta    float64 // In °C; negative if it went up, which is noise, or a change in load
+	Affected bool    // Whether Delta is at least the test's MinDelta
+}
+
+// Coupling is the temperatures a PWM output affects.
+type Coupling struct {
+	PWM   PWM
+	Temps []TempResponse // Every temperature, most affected first
+}
+
+// Affected lists the temperatures, as chip/sensor, that the PWM output affects.
+func (c Coupling) Affected() []string {
+	var ret []string
+	for _, t := range c.Temps {
+		if t.Affected {
+			ret = append(ret, t.Chip+"/"+t.Sensor)
+		}
+	}
+	return ret
+}
+
+func (t StepTest) withDefaults() StepTest {
+	if t.Low == 0 {
+		t.Low = 64
+	}
+	if t.High == 0 {
+		t.High = 255
+	}
+	if t.Settle == 0 {
+		t.Settle = time.Minute
+	}
+	if t.Samples == 0 {
+		t.Samples = 1
+	}
+	if t.MinDelta == 0 {
+		t.MinDelta = 1
+	}
+	if t.Read == nil {
+		t.Read = func(ctx context.Context) (*lmsensors.System, error) { return lmsensors.GetContext(ctx) }
+	}
+	return t
+}
+
+// Run steps each of the PWM outputs in turn, with the rest held at High so that they don't mask it, and puts them all back as they were afterwards.
+func (t StepTest) Run(ctx context.Context, pwms []PWM) (cs []Coupling, err error) {
+	t = t.withDefaults()
+	saved := make([]state, len(pwms))
+	for i, p := range pwms {
+		if saved[i], err = p.save(); err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	defer func() {
+		for i, p := range pwms {
+			if rerr := p.restore(saved[i]); rerr != nil {
+				err = errors.Join(err, fmt.Errorf("restoring %s: %w", p, rerr))
+			}
+		}
+	}()
+	for _, p := range pwms {
+		if err := p.Device.SetPWM(p.Channel, t.High); err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	for _, p := range pwms {
+		low, err := t.step(ctx, p, t.Low)
+		if err != nil {
+			return nil, err
+		}
+		high, err := t.step(ctx, p, t.High)
+		if err != nil {
+			return nil, err
+		}
+		cs = append(cs, Coupling{PWM: p, Temps: responses(low, high, t.MinDelta)})
+	}
+	return cs, nil
+}
+
+// step sets the PWM output's duty, waits for the temperatures to settle, and reads them.
+func (t StepTest) step(ctx context.Context, p PWM, duty uint8) (map[string]float64, error) {
+	if err := p.Device.SetPWMDuty(p.Channel, duty); err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	if err := sleep(ctx, t.Settle); err != nil {
+		return nil, err
+	}
+	sums := map[string]float64{}
+	counts := map[string]int{}
+	for i := range t.Samples {
+		if i > 0 {
+			if err := sleep(ctx, time.Second); err != nil {
+				return nil, err
+			}
+		}
+		sys, err := t.Read(ctx)
+		if sys == nil {
+			return nil, err
+		}
+		for key, v := range temps(sys) {
+			sums[key] += v
+			counts[key]++
+		}
+	}
+	for key := range sums {
+		sums[key] /= float64(counts[key])
+	}
+	return sums, nil
+}
+
+// temps picks the valid temperatures out of a reading, by chip/sensor.
+func temps(sys *lmsensors.System) map[string]float64 {
+	ret := map[string]float64{}
+	for _, chip := range sys.Chips {
+		for name, s := range chip.Sensors {
+			if lmsensors.Valid(s) && s.Type() == lmsensors.Temperature {
+				ret[chip.ID+"/"+name] = s.Reading()
+			}
+		}
+	}
+	return ret
+}
+
+// responses compares the temperatures with the fan slow and fast.
+func responses(low, high map[string]float64, minDelta float64) []TempResponse {
+	var rs []TempResponse
+	for key, lv := range low {
+		hv, ok := high[key]
+		if !ok {
+			continue
+		}
+		chip, sensor, _ := strings.Cut(key, "/") // Sensor labels can contain slashes, chip IDs can't
+		d := lv - hv
+		rs = append(rs, TempResponse{Chip: chip, Sensor: sensor, Delta: d, Affected: d >= minDelta})
+	}
+	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].Delta != rs[j].Delta {
+			return rs[i].Delta > rs[j].Delta
+		}
+		return rs[i].Chip+"/"+rs[i].Sensor < rs[j].Chip+"/"+rs[j].Sensor
+	})
+	return rs
+}
@@ -0,0 +1,91 @@
+package fancontrol
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// fakeChip makes a hwmon device with two PWM outputs under a temporary SysfsRoot.
+func fakeChip(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	lmsensors.SysfsRoot = root
+	t.Cleanup(func() { lmsensors.SysfsRoot = "/sys" })
+	dir := filepath.Join(root, "class", "hwmon", "hwmon0")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for attr, val := range map[string]string{"name": "nct6798", "pwm1": "100", "pwm1_enable": "5", "pwm2": "120", "pwm2_enable": "5"} {
+		if err := os.WriteFile(filepath.Join(dir, attr), []byte(val+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func readInt(t *testing.T, path string) float64 {
+	t.Helper()
+	p, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := strconv.Atoi(strings.TrimSpace(string(p)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return float64(v)
+}
+
+func temp(name string, v float64) *lmsensors.TempSensor {
+	s := &lmsensors.TempSensor{}
+	s.Name, s.Value = name, v
+	return s
+}
+
+func TestStepTest(t *testing.T) {
+	dir := fakeChip(t)
+	// pwm1 cools the CPU, pwm2 the GPU, and nothing cools the board
+	read := func(context.Context) (*lmsensors.System, error) {
+		pwm1, pwm2 := readInt(t, filepath.Join(dir, "pwm1")), readInt(t, filepath.Join(dir, "pwm2"))
+		return &lmsensors.System{Chips: map[string]*lmsensors.Chip{
+			"nct6798-isa-0290": {ID: "nct6798-isa-0290", Sensors: map[string]lmsensors.Sensor{
+				"CPU":   temp("CPU", 80-0.05*pwm1),
+				"GPU":   temp("GPU", 70-0.04*pwm2),
+				"Board": temp("Board", 40),
+				"Dead":  nil, // A sensor that's gone
+			}},
+		}}, nil
+	}
+	pwms, err := PWMs()
+	if err != nil || len(pwms) != 2 {
+		t.Fatalf("PWMs: %v %v", pwms, err)
+	}
+	cs, err := StepTest{Settle: time.Nanosecond, Read: read}.Run(context.Background(), pwms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 2 {
+		t.Fatalf("wrong couplings: %+v", cs)
+	}
+	for i, want := range [][]string{{"nct6798-isa-0290/CPU"}, {"nct6798-isa-0290/GPU"}} {
+		if got := cs[i].Affected(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", cs[i].PWM, got, want)
+		}
+	}
+	if d := cs[0].Temps[0].Delta; d < 9.5 || d > 9.6 {
+		t.Errorf("wrong CPU delta %v", d)
+	}
+	for attr, want := range map[string]float64{"pwm1": 100, "pwm1_enable": 5, "pwm2": 120, "pwm2_enable": 5} {
+		if got := readInt(t, filepath.Join(dir, attr)); got != want {
+			t.Errorf("%s not restored: %v", attr, got)
+		}
+	}
+}
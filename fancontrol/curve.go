@@ -0,0 +1,192 @@
+package fancontrol
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Point is a point on a fan [Curve]: at Temp °C, run the fan at Duty, where 255 is full speed.
+type Point struct {
+	Temp float64 `json:"temp" yaml:"temp" toml:"temp"`
+	Duty uint8   `json:"duty" yaml:"duty" toml:"duty"`
+}
+
+// Curve maps a temperature to a duty, interpolating linearly between its points, which must be in order of temperature.
+// Below the first point it's the first point's duty, and above the last, the last's.
+type Curve []Point
+
+// Valid is whether the curve has points, in order of temperature.
+func (c Curve) Valid() bool {
+	return len(c) != 0 && sort.SliceIsSorted(c, func(i, j int) bool { return c[i].Temp < c[j].Temp })
+}
+
+// Duty is the duty for a temperature.
+func (c Curve) Duty(temp float64) uint8 {
+	i := sort.Search(len(c), func(i int) bool { return c[i].Temp >= temp })
+	switch {
+	case i == 0:
+		return c[0].Duty
+	case i == len(c):
+		return c[len(c)-1].Duty
+	}
+	lo, hi := c[i-1], c[i]
+	frac := (temp - lo.Temp) / (hi.Temp - lo.Temp)
+	return uint8(float64(lo.Duty) + frac*(float64(hi.Duty)-float64(lo.Duty)) + 0.5)
+}
+
+// FindPWM finds a PWM output by name, like [PWM.String] gives, eg "nct6798/pwm1"; the device can also be given by its hwmon directory, eg "hwmon2/pwm1", for when two have the same name.
+func FindPWM(name string) (PWM, error) {
+	dev, ch, ok := strings.Cut(name, "/pwm")
+	n, err := strconv.Atoi(ch)
+	if !ok || err != nil {
+		return PWM{}, fmt.Errorf("bad PWM name %q; want eg nct6798/pwm1", name)
+	}
+	devs, err := lmsensors.HwmonDevices()
+	if err != nil {
+		return PWM{}, err
+	}
+	for _, d := range devs {
+		if d.Name == dev || filepath.Base(d.Path) == dev {
+			return PWM{d, n}, nil
+		}
+	}
+	return PWM{}, fmt.Errorf("no hwmon device %s", dev)
+}
+
+// ErrNoReading is returned when none of the selected sensors has a valid reading. [Controller.Update] puts the fan to full speed when it is.
+var ErrNoReading = errors.New("no valid temperature reading")
+
+// ErrTripped is returned by [Controller.Update] once its [Watchdog] has tripped, after which it leaves the fan as the watchdog left it.
+var ErrTripped = errors.New("fan watchdog has tripped")
+
+// Controller drives a PWM output, in manual mode, from one or more temperatures, each through its own curve, combined by a [Policy].
+// Once it's updating, change its policy and sources with [Controller.Reconfigure].
+type Controller struct {
+	PWM     PWM
+	Sources []Source
+	Policy  Policy
+	Slew    lmsensors.Slew // Limits how fast the duty changes, except when failing safe to full speed; see [lmsensors.Slew]
+
+	Watchdog *Watchdog // Fed after every successful update, if set; once it's tripped, the controller stops, see [Failsafe]
+
+	mu   sync.Mutex
+	duty int // The last duty written, or -1
+}
+
+// NewController makes a [Controller] with a single source; the curve must be [Curve.Valid].
+func NewController(pwm PWM, sensor lmsensors.Selector, curve Curve) *Controller {
+	return NewMultiController(pwm, Max, Source{Sensor: sensor, Curve: curve})
+}
+
+// NewMultiController makes a [Controller] driven by several sources, eg a case fan from both the CPU and the GPU.
+func NewMultiController(pwm PWM, policy Policy, sources ...Source) *Controller {
+	return &Controller{PWM: pwm, Sources: sources, Policy: policy, duty: -1}
+}
+
+// hottest is the hottest valid temperature the selector matches.
+func hottest(sys *lmsensors.System, sel lmsensors.Selector) (float64, bool) {
+	var max float64
+	found := false
+	for _, chip := range sys.Chips {
+		for name, s := range chip.Sensors {
+			if !lmsensors.Valid(s) || s.Type() != lmsensors.Temperature || !sel.Match(chip.ID, name) {
+				continue
+			}
+			if v := s.Reading(); !found || v > max {
+				max, found = v, true
+			}
+		}
+	}
+	return max, found
+}
+
+// Update sets the fan's duty from a reading, taken at sys.Time. Without a valid temperature for every source it fails safe, straight to full speed, as the missing one could be the hot one.
+func (c *Controller) Update(sys *lmsensors.System) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Watchdog != nil && c.Watchdog.Tripped() {
+		return ErrTripped
+	}
+	target, ok := c.Policy.duty(sys, c.Sources)
+	now := sys.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	duty := uint8(255)
+	if ok {
+		duty = c.Slew.Limit(target, now)
+	} else {
+		c.Slew.Reset(duty, now)
+	}
+	if err := c.set(duty); err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNoReading
+	}
+	if c.Watchdog != nil {
+		c.Watchdog.Feed()
+	}
+	return nil
+}
+
+// Reconfigure changes the controller's policy and sources, from its next update; every source's curve must be [Curve.Valid].
+func (c *Controller) Reconfigure(policy Policy, sources []Source) error {
+	if len(sources) == 0 {
+		return errors.New("no sources")
+	}
+	for _, src := range sources {
+		if !src.Curve.Valid() {
+			return fmt.Errorf("source %s: curve needs points, in order of temperature", src.Sensor)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Policy, c.Sources = policy, append([]Source(nil), sources...)
+	return nil
+}
+
+// ControllerState is a [Controller]'s configuration, as served by [Handler].
+type ControllerState struct {
+	Policy  Policy   `json:"policy"`
+	Sources []Source `json:"sources"`
+	Duty    int      `json:"duty"` // The last it wrote, or -1 before its first update
+}
+
+func (c *Controller) state() ControllerState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ControllerState{Policy: c.Policy, Sources: append([]Source(nil), c.Sources...), Duty: c.duty}
+}
+
+func (c *Controller) set(duty uint8) error {
+	if c.duty == -1 {
+		// Into manual mode the first time
+		if err := c.PWM.Device.SetPWM(c.PWM.Channel, duty); err != nil {
+			return err
+		}
+	} else if int(duty) != c.duty {
+		if err := c.PWM.Device.SetPWMDuty(c.PWM.Channel, duty); err != nil {
+			return err
+		}
+	}
+	c.duty = int(duty)
+	return nil
+}
+
+// Watch returns an option that updates the controller on every poll of a [lmsensors.Watcher], calling fn, if not nil, with any error.
+func (c *Controller) Watch(fn func(error)) lmsensors.WatcherOption {
+	return lmsensors.OnPoll(func(sys *lmsensors.System, _ error) {
+		if err := c.Update(sys); err != nil && fn != nil {
+			fn(err)
+		}
+	})
+}
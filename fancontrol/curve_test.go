@@ -0,0 +1,115 @@
+package fancontrol
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+func TestCurve(t *testing.T) {
+	c := Curve{{40, 64}, {70, 255}}
+	for temp, want := range map[float64]uint8{20: 64, 40: 64, 55: 160, 70: 255, 90: 255} {
+		if got := c.Duty(temp); got != want {
+			t.Errorf("%v °C: got %d, want %d", temp, got, want)
+		}
+	}
+	if (Curve{{70, 255}, {40, 64}}).Valid() || (Curve{}).Valid() || !c.Valid() {
+		t.Error("wrong validity")
+	}
+}
+
+func reading(v float64) *lmsensors.System {
+	return &lmsensors.System{Chips: map[string]*lmsensors.Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]lmsensors.Sensor{"Tctl": temp("Tctl", v)}},
+	}}
+}
+
+func TestController(t *testing.T) {
+	dir := fakeChip(t)
+	pwm, err := FindPWM("nct6798/pwm1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewController(pwm, "k10temp-*/Tctl", Curve{{40, 64}, {70, 255}})
+	if err := c.Update(reading(55)); err != nil {
+		t.Fatal(err)
+	}
+	if readInt(t, filepath.Join(dir, "pwm1")) != 160 || readInt(t, filepath.Join(dir, "pwm1_enable")) != 1 {
+		t.Error("wrong duty or mode")
+	}
+	c.Slew.Rate = 10
+	sys := reading(70)
+	sys.Time = time.Now().Add(time.Second)
+	c.Slew.Reset(160, sys.Time.Add(-time.Second))
+	if err := c.Update(sys); err != nil || readInt(t, filepath.Join(dir, "pwm1")) != 186 {
+		t.Errorf("duty not slew-limited: %v", err)
+	}
+	if err := c.Update(reading(lmsensors.NoValue)); !errors.Is(err, ErrNoReading) || readInt(t, filepath.Join(dir, "pwm1")) != 255 {
+		t.Errorf("didn't fail safe: %v", err)
+	}
+	sys = reading(40)
+	sys.Chips["k10temp-pci-00c3"].Sensors["Tccd1"] = nil // A sensor that's gone
+	if err := c.Update(sys); err != nil {
+		t.Errorf("with a nil sensor: %v", err)
+	}
+}
+
+func TestAutoTune(t *testing.T) {
+	dir := fakeChip(t)
+	pwm, err := FindPWM("hwmon0/pwm2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 90 °C with the fan stopped, cooling 0.1 °C per unit of duty
+	read := func(context.Context) (*lmsensors.System, error) {
+		return reading(90 - 0.1*readInt(t, filepath.Join(dir, "pwm2"))), nil
+	}
+	res, err := AutoTune{PWM: pwm, Sensor: "k10temp-*/Tctl", Target: 75, Settle: time.Nanosecond, Read: read}.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 255, 239, ..., 159 keep under 75 °C; 143 doesn't.
+	if res.Duty != 159 || len(res.Curve) != 3 || !res.Curve.Valid() || res.Curve.Duty(75) != 159 {
+		t.Errorf("wrong result: %+v", res)
+	}
+	if readInt(t, filepath.Join(dir, "pwm2")) != 120 || readInt(t, filepath.Join(dir, "pwm2_enable")) != 5 {
+		t.Error("not restored")
+	}
+
+	if _, err := (AutoTune{PWM: pwm, Sensor: "k10temp-*/Tctl", Target: 60, Limit: 100, Settle: time.Nanosecond, Read: read}).Run(context.Background()); !errors.Is(err, ErrTargetUnreachable) {
+		t.Errorf("expected unreachable, got %v", err)
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	sys := &lmsensors.System{Chips: map[string]*lmsensors.Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]lmsensors.Sensor{"Tctl": temp("Tctl", 55)}},
+		"amdgpu-pci-0300":  {ID: "amdgpu-pci-0300", Sensors: map[string]lmsensors.Sensor{"edge": temp("edge", 80)}},
+	}}
+	sources := []Source{
+		{Sensor: "k10temp-*/Tctl", Curve: Curve{{40, 64}, {70, 255}}, Weight: 3}, // 160
+		{Sensor: "amdgpu-*/edge", Curve: Curve{{50, 64}, {80, 240}}},             // 240
+	}
+	if d, ok := Max.duty(sys, sources); !ok || d != 240 {
+		t.Errorf("max: got %d", d)
+	}
+	if d, ok := WeightedAverage.duty(sys, sources); !ok || d != 180 {
+		t.Errorf("weighted average: got %d", d)
+	}
+	sources = append(sources, Source{Sensor: "nvme-*/Composite", Curve: Curve{{40, 64}}})
+	if _, ok := Max.duty(sys, sources); ok {
+		t.Error("a missing source should fail safe")
+	}
+
+	var p Policy
+	if err := p.UnmarshalText([]byte("average")); err != nil || p != WeightedAverage {
+		t.Errorf("unmarshal: %v %v", p, err)
+	}
+	if err := p.UnmarshalText([]byte("min")); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
@@ -0,0 +1,134 @@
+package fancontrol
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Failsafe configures a [Watchdog], which puts PWM outputs somewhere safe if the control loop driving them in manual mode stops, so that a hung or killed daemon can't cook the machine.
+type Failsafe struct {
+	Timeout   time.Duration                  // How long without a [Watchdog.Feed] before it trips
+	FullSpeed bool                           // Trip to full speed, rather than back to the modes they were in when it started, usually automatic
+	OnTrip    func(reason string, err error) // Called, if not nil, when it trips, with any error putting the outputs back
+}
+
+// Watchdog guards PWM outputs, see [Failsafe]. It trips if it isn't fed for the timeout, or the process gets SIGINT or SIGTERM.
+// On a signal, once the outputs are safe, the signal is raised again, for the application's own handling, or the default of exiting.
+// Nothing can be done about SIGKILL, or a panic that doesn't unwind through [Watchdog.Close], so pick a driver with a hardware watchdog if that matters.
+type Watchdog struct {
+	f     Failsafe
+	pwms  []PWM
+	saved []state
+
+	feed    chan struct{}
+	done    chan struct{}
+	sigs    chan os.Signal
+	once    sync.Once
+	tripped atomic.Bool
+}
+
+// Start saves the state of the PWM outputs, to put back on a trip or [Watchdog.Close], and starts the watchdog; take them into manual mode after it.
+func (f Failsafe) Start(pwms ...PWM) (*Watchdog, error) {
+	if f.Timeout <= 0 {
+		return nil, fmt.Errorf("failsafe timeout must be positive: %s", f.Timeout)
+	}
+	w := &Watchdog{
+		f:     f,
+		pwms:  pwms,
+		saved: make([]state, len(pwms)),
+		feed:  make(chan struct{}, 1),
+		done:  make(chan struct{}),
+		sigs:  make(chan os.Signal, 1),
+	}
+	for i, p := range pwms {
+		var err error
+		if w.saved[i], err = p.save(); err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	signal.Notify(w.sigs, syscall.SIGINT, syscall.SIGTERM)
+	go w.run()
+	return w, nil
+}
+
+func (w *Watchdog) run() {
+	t := time.NewTimer(w.f.Timeout)
+	defer t.Stop()
+	for {
+		select {
+		case <-w.feed:
+			t.Reset(w.f.Timeout)
+		case <-t.C:
+			w.trip(fmt.Sprintf("not fed for %s", w.f.Timeout))
+			return
+		case sig := <-w.sigs:
+			w.trip("got " + sig.String())
+			if s, ok := sig.(syscall.Signal); ok {
+				_ = syscall.Kill(os.Getpid(), s)
+			}
+			return
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// Feed tells the watchdog the control loop is alive; call it after every successful update.
+func (w *Watchdog) Feed() {
+	select {
+	case w.feed <- struct{}{}:
+	default:
+	}
+}
+
+// Tripped is whether the watchdog has tripped. It doesn't re-arm; the outputs stay as it left them, with a [Controller] it guards no longer updating them, until something takes them over again.
+func (w *Watchdog) Tripped() bool {
+	return w.tripped.Load()
+}
+
+func (w *Watchdog) trip(reason string) {
+	w.once.Do(func() {
+		signal.Stop(w.sigs)
+		w.tripped.Store(true)
+		var err error
+		if w.f.FullSpeed {
+			for _, p := range w.pwms {
+				if serr := p.Device.SetPWM(p.Channel, 255); serr != nil {
+					err = errors.Join(err, fmt.Errorf("%s: %w", p, serr))
+				}
+			}
+		} else {
+			err = w.restore()
+		}
+		if w.f.OnTrip != nil {
+			w.f.OnTrip(reason, err)
+		}
+	})
+}
+
+func (w *Watchdog) restore() error {
+	var err error
+	for i, p := range w.pwms {
+		if rerr := p.restore(w.saved[i]); rerr != nil {
+			err = errors.Join(err, fmt.Errorf("%s: %w", p, rerr))
+		}
+	}
+	return err
+}
+
+// Close stops the watchdog and puts the outputs back as they were when it started, for a clean shutdown; defer it. It does nothing once tripped.
+func (w *Watchdog) Close() error {
+	var err error
+	w.once.Do(func() {
+		signal.Stop(w.sigs)
+		close(w.done)
+		err = w.restore()
+	})
+	return err
+}
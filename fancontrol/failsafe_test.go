@@ -0,0 +1,104 @@
+package fancontrol
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+	for _, full := range []bool{false, true} {
+		dir := fakeChip(t)
+		pwm, err := FindPWM("nct6798/pwm1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tripped := make(chan error, 1)
+		w, err := Failsafe{Timeout: 50 * time.Millisecond, FullSpeed: full, OnTrip: func(_ string, err error) { tripped <- err }}.Start(pwm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := pwm.Device.SetPWM(1, 30); err != nil {
+			t.Fatal(err)
+		}
+		for range 5 {
+			time.Sleep(20 * time.Millisecond)
+			w.Feed()
+		}
+		if w.Tripped() {
+			t.Fatal("tripped while being fed")
+		}
+		select {
+		case err := <-tripped:
+			if err != nil {
+				t.Error(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("didn't trip")
+		}
+		want := map[string]float64{"pwm1": 100, "pwm1_enable": 5}
+		if full {
+			want = map[string]float64{"pwm1": 255, "pwm1_enable": 1}
+		}
+		for attr, v := range want {
+			if got := readInt(t, filepath.Join(dir, attr)); got != v {
+				t.Errorf("full=%t: %s is %v, want %v", full, attr, got, v)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestWatchdogClose(t *testing.T) {
+	dir := fakeChip(t)
+	pwm, err := FindPWM("nct6798/pwm2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := Failsafe{Timeout: time.Hour}.Start(pwm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pwm.Device.SetPWM(2, 30); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if readInt(t, filepath.Join(dir, "pwm2")) != 120 || readInt(t, filepath.Join(dir, "pwm2_enable")) != 5 || w.Tripped() {
+		t.Error("not restored on close")
+	}
+}
+
+func TestWatchdogStopsController(t *testing.T) {
+	dir := fakeChip(t)
+	pwm, err := FindPWM("nct6798/pwm1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tripped := make(chan struct{})
+	w, err := Failsafe{Timeout: 50 * time.Millisecond, OnTrip: func(string, error) { close(tripped) }}.Start(pwm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	c := NewController(pwm, "k10temp-*/Tctl", Curve{{40, 64}, {70, 255}})
+	c.Watchdog = w
+	if err := c.Update(reading(55)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-tripped:
+	case <-time.After(time.Second):
+		t.Fatal("didn't trip")
+	}
+	if err := c.Update(reading(70)); !errors.Is(err, ErrTripped) {
+		t.Errorf("updated after tripping: %v", err)
+	}
+	if readInt(t, filepath.Join(dir, "pwm1")) != 100 || readInt(t, filepath.Join(dir, "pwm1_enable")) != 5 {
+		t.Error("controller took the fan back after the watchdog restored it")
+	}
+}
@@ -0,0 +1,85 @@
+// Package fancontrol is the calibration and control of fans through their PWM outputs, on top of [lmsensors.HwmonDevice]: working out which fans cool which temperatures, and driving them.
+// Every write goes through lmsensors' audit and dry-run machinery, see [lmsensors.SetDryRun].
+package fancontrol
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// PWM is one PWM output of a hwmon device.
+type PWM struct {
+	Device  lmsensors.HwmonDevice
+	Channel int // eg 1 for pwm1
+}
+
+func (p PWM) String() string {
+	return fmt.Sprintf("%s/pwm%d", p.Device.Name, p.Channel)
+}
+
+// PWMs lists every PWM output of every hwmon device.
+func PWMs() ([]PWM, error) {
+	devs, err := lmsensors.HwmonDevices()
+	if err != nil {
+		return nil, err
+	}
+	var pwms []PWM
+	for _, dev := range devs {
+		chans, err := dev.PWMs()
+		if err != nil {
+			continue
+		}
+		for _, ch := range chans {
+			pwms = append(pwms, PWM{dev, ch})
+		}
+	}
+	return pwms, nil
+}
+
+// state is a PWM output's mode and duty, so it can be put back.
+type state struct {
+	mode, duty int
+}
+
+func (p PWM) read(suffix string) (int, error) {
+	v, err := p.Device.ReadAttribute("pwm" + strconv.Itoa(p.Channel) + suffix)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(v)
+}
+
+func (p PWM) save() (state, error) {
+	mode, err := p.read("_enable")
+	if err != nil {
+		return state{}, err
+	}
+	duty, err := p.read("")
+	if err != nil {
+		return state{}, err
+	}
+	return state{mode, duty}, nil
+}
+
+func (p PWM) restore(st state) error {
+	if err := p.Device.SetPWMDuty(p.Channel, uint8(st.duty)); err != nil {
+		return err
+	}
+	return p.Device.SetPWMMode(p.Channel, st.mode)
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,206 @@
+package fancontrol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// FanState is a PWM output's state, as served by [Handler].
+type FanState struct {
+	Name  string `json:"name"`  // eg "nct6798/pwm1", see [FindPWM]
+	Mode  int    `json:"mode"`  // The raw pwm*_enable mode, eg 1 for manual; the others are driver-specific
+	Duty  int    `json:"duty"`  // Out of 255
+	Modes []int  `json:"modes"` // The modes the driver takes, see [lmsensors.HwmonDevice.PWMModes]
+
+	Controller *ControllerState `json:"controller,omitempty"` // If it's driven by one of the [Handler]'s controllers
+}
+
+// FanUpdate is the body of a PUT to [Handler]; any field can be left out.
+// The mode is set before the duty, so that a manual duty can be given in one go; those are only for outputs not driven by a controller, and the policy and sources only for those that are.
+type FanUpdate struct {
+	Mode *int   `json:"mode,omitempty"`
+	Duty *uint8 `json:"duty,omitempty"`
+
+	Policy  *Policy  `json:"policy,omitempty"`  // Defaults to the controller's current one
+	Sources []Source `json:"sources,omitempty"` // Replaces the controller's, see [Controller.Reconfigure]
+}
+
+var (
+	errBadUpdate = errors.New("bad update") // A PUT whose values can't be applied
+	errConflict  = errors.New("conflict")   // A PUT that doesn't fit how the output is driven
+)
+
+// Handler serves the state of PWM outputs over HTTP, and lets them be changed, so that a local UI can go through the same service that reports the sensors.
+// GET / lists every output, GET /{name} gets one, eg /nct6798/pwm1, and PUT /{name} changes it, with a JSON [FanUpdate].
+// Mount it with [http.StripPrefix], eg at /fans/, or give it to the httpapi package's Server, as its Fans.
+// Outputs driven by one of Controllers have its policy and sources, its curves and the temperatures they follow, served and changed instead of their mode and duty, which it'd change back at its next update.
+type Handler struct {
+	PWMs        []PWM         // Defaults to all of them, see [PWMs]
+	Controllers []*Controller // Those driving any of them
+
+	// Authorize is called before every request is served, and an error from it refuses the request with 403 Forbidden.
+	// If it's nil, only GETs are allowed.
+	Authorize func(r *http.Request) error
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case h.Authorize != nil:
+		if err := h.Authorize(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	case r.Method != http.MethodGet && r.Method != http.MethodHead:
+		http.Error(w, "changes not authorized", http.StatusForbidden)
+		return
+	}
+
+	pwms := h.PWMs
+	if pwms == nil {
+		var err error
+		if pwms, err = PWMs(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	name := strings.Trim(r.URL.Path, "/")
+	if name == "" {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		states := make([]FanState, 0, len(pwms))
+		for _, p := range pwms {
+			st, err := h.stateOf(p)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			states = append(states, st)
+		}
+		writeJSON(w, states)
+		return
+	}
+
+	i := -1
+	for j, p := range pwms {
+		if p.String() == name {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		http.Error(w, "no such PWM output: "+name, http.StatusNotFound)
+		return
+	}
+	p := pwms[i]
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut:
+		var u FanUpdate
+		if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := u.apply(p, h.controller(p)); err != nil {
+			http.Error(w, err.Error(), writeStatus(err))
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	st, err := h.stateOf(p)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, st)
+}
+
+func (h Handler) controller(p PWM) *Controller {
+	for _, c := range h.Controllers {
+		if c.PWM.String() == p.String() {
+			return c
+		}
+	}
+	return nil
+}
+
+func (h Handler) stateOf(p PWM) (FanState, error) {
+	st, err := p.save()
+	if err != nil {
+		return FanState{}, err
+	}
+	fs := FanState{Name: p.String(), Mode: st.mode, Duty: st.duty, Modes: p.Device.PWMModes()}
+	if c := h.controller(p); c != nil {
+		cs := c.state()
+		fs.Controller = &cs
+	}
+	return fs, nil
+}
+
+func (u FanUpdate) apply(p PWM, c *Controller) error {
+	if c != nil {
+		if u.Mode != nil || u.Duty != nil {
+			return fmt.Errorf("%w: %s is driven by a controller; change its policy or sources instead", errConflict, p)
+		}
+		if u.Policy == nil && u.Sources == nil {
+			return nil
+		}
+		cur := c.state()
+		policy, sources := cur.Policy, cur.Sources
+		if u.Policy != nil {
+			policy = *u.Policy
+		}
+		if u.Sources != nil {
+			sources = u.Sources
+		}
+		if err := c.Reconfigure(policy, sources); err != nil {
+			return fmt.Errorf("%w: %w", errBadUpdate, err)
+		}
+		return nil
+	}
+	if u.Policy != nil || u.Sources != nil {
+		return fmt.Errorf("%w: %s isn't driven by a controller", errConflict, p)
+	}
+	if u.Mode != nil {
+		if !slices.Contains(p.Device.PWMModes(), *u.Mode) {
+			return fmt.Errorf("%w: %s doesn't take mode %d; it takes %v", errBadUpdate, p, *u.Mode, p.Device.PWMModes())
+		}
+		if err := p.Device.SetPWMMode(p.Channel, *u.Mode); err != nil {
+			return err
+		}
+	}
+	if u.Duty != nil {
+		return p.Device.SetPWMDuty(p.Channel, *u.Duty)
+	}
+	return nil
+}
+
+// writeStatus is the HTTP status for a failed write.
+func writeStatus(err error) int {
+	switch {
+	case errors.Is(err, errBadUpdate):
+		return http.StatusBadRequest
+	case errors.Is(err, errConflict):
+		return http.StatusConflict
+	case errors.Is(err, lmsensors.ErrReadOnlyMode), errors.Is(err, lmsensors.ErrNeedsPrivilege):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,100 @@
+package fancontrol
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+func TestHandler(t *testing.T) {
+	dir := fakeChip(t)
+	do := func(h Handler, method, path, body string) (int, string) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec.Code, rec.Body.String()
+	}
+
+	code, body := do(Handler{}, http.MethodGet, "/", "")
+	var states []FanState
+	if err := json.Unmarshal([]byte(body), &states); code != http.StatusOK || err != nil {
+		t.Fatalf("list: %d %s", code, body)
+	}
+	if len(states) != 2 || states[1].Name != "nct6798/pwm2" || states[1].Mode != 5 || states[1].Duty != 120 || len(states[1].Modes) != 6 || states[1].Controller != nil {
+		t.Errorf("list: %+v", states)
+	}
+
+	if code, _ := do(Handler{}, http.MethodPut, "/nct6798/pwm1", `{"mode": 1, "duty": 200}`); code != http.StatusForbidden {
+		t.Errorf("unauthorized put: %d", code)
+	}
+	deny := Handler{Authorize: func(*http.Request) error { return errors.New("no") }}
+	if code, _ := do(deny, http.MethodGet, "/nct6798/pwm1", ""); code != http.StatusForbidden {
+		t.Errorf("denied get: %d", code)
+	}
+
+	allow := Handler{Authorize: func(*http.Request) error { return nil }}
+	if code, body := do(allow, http.MethodPut, "/nct6798/pwm1", `{"mode": 1, "duty": 200}`); code != http.StatusOK || !strings.Contains(body, `"duty":200`) {
+		t.Errorf("put: %d %s", code, body)
+	}
+	if readInt(t, filepath.Join(dir, "pwm1")) != 200 || readInt(t, filepath.Join(dir, "pwm1_enable")) != 1 {
+		t.Error("put didn't write")
+	}
+	if code, _ := do(allow, http.MethodPut, "/nct6798/pwm1", `{"duty": 256}`); code != http.StatusBadRequest {
+		t.Errorf("bad duty: %d", code)
+	}
+	if code, _ := do(allow, http.MethodGet, "/nct6798/pwm9", ""); code != http.StatusNotFound {
+		t.Errorf("missing: %d", code)
+	}
+	if code, _ := do(allow, http.MethodPut, "/nct6798/pwm1", `{"mode": 7}`); code != http.StatusBadRequest || readInt(t, filepath.Join(dir, "pwm1_enable")) != 1 {
+		t.Errorf("unsupported mode: %d", code)
+	}
+}
+
+func TestHandlerController(t *testing.T) {
+	dir := fakeChip(t)
+	pwm, err := FindPWM("nct6798/pwm2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewController(pwm, "*/CPU", Curve{{40, 50}, {80, 255}})
+	h := Handler{Controllers: []*Controller{c}, Authorize: func(*http.Request) error { return nil }}
+	do := func(method, body string) (int, FanState) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, "/nct6798/pwm2", strings.NewReader(body)))
+		var st FanState
+		json.Unmarshal(rec.Body.Bytes(), &st)
+		return rec.Code, st
+	}
+
+	code, st := do(http.MethodGet, "")
+	if code != http.StatusOK || st.Controller == nil || st.Controller.Policy != Max || len(st.Controller.Sources) != 1 || st.Controller.Duty != -1 {
+		t.Fatalf("get: %d %+v", code, st.Controller)
+	}
+	if code, _ := do(http.MethodPut, `{"duty": 200}`); code != http.StatusConflict || readInt(t, filepath.Join(dir, "pwm2")) != 120 {
+		t.Errorf("duty of a controlled output: %d", code)
+	}
+	if code, _ := do(http.MethodPut, `{"sources": [{"sensor": "*/CPU", "curve": [{"temp": 80, "duty": 10}, {"temp": 40, "duty": 20}]}]}`); code != http.StatusBadRequest {
+		t.Errorf("unsorted curve: %d", code)
+	}
+	code, st = do(http.MethodPut, `{"policy": "average", "sources": [{"sensor": "*/CPU", "curve": [{"temp": 30, "duty": 100}]}]}`)
+	if code != http.StatusOK || st.Controller.Policy != WeightedAverage || len(st.Controller.Sources[0].Curve) != 1 {
+		t.Fatalf("put: %d %+v", code, st.Controller)
+	}
+	if err := c.Update(&lmsensors.System{Chips: map[string]*lmsensors.Chip{
+		"nct6798-isa-0290": {ID: "nct6798-isa-0290", Sensors: map[string]lmsensors.Sensor{"CPU": temp("CPU", 60)}},
+	}}); err != nil || readInt(t, filepath.Join(dir, "pwm2")) != 100 {
+		t.Errorf("update with the new curve: %v", err)
+	}
+
+	h.Controllers = nil
+	if code, _ := do(http.MethodPut, `{"policy": "max"}`); code != http.StatusConflict {
+		t.Errorf("policy of an uncontrolled output: %d", code)
+	}
+}
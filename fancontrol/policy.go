@@ -0,0 +1,73 @@
+package fancontrol
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Source is one input of a [Controller]: the hottest temperature a selector matches, through its own curve.
+type Source struct {
+	Sensor lmsensors.Selector `json:"sensor" yaml:"sensor" toml:"sensor"`
+	Curve  Curve              `json:"curve" yaml:"curve" toml:"curve"`
+	Weight float64            `json:"weight" yaml:"weight" toml:"weight"` // For [WeightedAverage]; 0 counts as 1
+}
+
+// Policy is how a [Controller] combines the duties its sources ask for.
+type Policy int
+
+const (
+	Max             Policy = iota // The highest, so every source gets at least the cooling it asks for
+	WeightedAverage               // The average, by weight, eg so a case fan mostly follows the CPU but the GPU still counts
+)
+
+var policyNames = map[Policy]string{Max: "max", WeightedAverage: "average"}
+
+func (p Policy) String() string {
+	if name, ok := policyNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("Policy(%d)", int(p))
+}
+
+func (p Policy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText reads a policy as it's written in config files: "max" or "average".
+func (p *Policy) UnmarshalText(text []byte) error {
+	for pol, name := range policyNames {
+		if string(text) == name {
+			*p = pol
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown fan policy %q; want max or average", text)
+}
+
+// duty combines the duties the sources ask for; it's false unless every source has a reading.
+func (p Policy) duty(sys *lmsensors.System, sources []Source) (uint8, bool) {
+	if len(sources) == 0 {
+		return 0, false
+	}
+	var max, sum, weights float64
+	for _, src := range sources {
+		temp, ok := hottest(sys, src.Sensor)
+		if !ok {
+			return 0, false
+		}
+		d := float64(src.Curve.Duty(temp))
+		w := src.Weight
+		if w == 0 {
+			w = 1
+		}
+		max = math.Max(max, d)
+		sum += w * d
+		weights += w
+	}
+	if p == WeightedAverage {
+		return uint8(math.Round(sum / weights)), true
+	}
+	return uint8(max), true
+}
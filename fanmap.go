@@ -0,0 +1,72 @@
+package lmsensors
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FanMapping says which temperatures drive a PWM output when it's in an automatic mode.
+// These come from sysfs attributes that libsensors doesn't cover: pwm*_temp_sel (nct6775 and friends) and pwm*_auto_channels_temp (the generic ABI, eg it87).
+type FanMapping struct {
+	PWM        int      // The PWM channel, eg 1 for pwm1, which usually drives the fan with the same number
+	Mode       int      // Raw pwm*_enable value; it's driver-specific, but 0 is usually full speed, 1 manual, and anything else automatic
+	Temps      []int    // The temperature channels driving it, eg 2 for temp2
+	TempLabels []string // The labels of those channels, or "tempN" if they don't have one
+}
+
+// FanMappings reads the fan-to-temperature mappings of the device.
+func (d HwmonDevice) FanMappings() ([]FanMapping, error) {
+	return fanMappings(d.attrDir)
+}
+
+// FanMappings reads the fan-to-temperature mappings of the chip from sysfs.
+func (chip ChipPtr) FanMappings() ([]FanMapping, error) {
+	return fanMappings(chip.Path())
+}
+
+func fanMappings(dir string) ([]FanMapping, error) {
+	attrs, err := attributes(dir)
+	if err != nil {
+		return nil, err
+	}
+	var ms []FanMapping
+	for _, attr := range attrs {
+		rest, ok := strings.CutPrefix(attr, "pwm")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			continue // Not a bare pwmN
+		}
+		m := FanMapping{PWM: n, Mode: -1}
+		read := func(name string) (int, bool) {
+			v, err := strconv.Atoi(readSysfsString(filepath.Join(dir, attr+"_"+name)))
+			return v, err == nil
+		}
+		if mode, ok := read("enable"); ok {
+			m.Mode = mode
+		}
+		if sel, ok := read("temp_sel"); ok && sel > 0 {
+			m.Temps = []int{sel}
+		} else if mask, ok := read("auto_channels_temp"); ok {
+			for bit := 0; bit < 32; bit++ {
+				if mask&(1<<bit) != 0 {
+					m.Temps = append(m.Temps, bit+1)
+				}
+			}
+		}
+		for _, t := range m.Temps {
+			label := readSysfsString(filepath.Join(dir, "temp"+strconv.Itoa(t)+"_label"))
+			if label == "" {
+				label = "temp" + strconv.Itoa(t)
+			}
+			m.TempLabels = append(m.TempLabels, label)
+		}
+		ms = append(ms, m)
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i].PWM < ms[j].PWM })
+	return ms, nil
+}
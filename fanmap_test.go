@@ -0,0 +1,20 @@
+package lmsensors
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFanMappings(t *testing.T) {
+	ms, err := fanMappings("testdata/sysfs/class/hwmon/hwmon1/device")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FanMapping{
+		{PWM: 1, Mode: 1, Temps: []int{2}, TempLabels: []string{"CPUTIN"}},
+		{PWM: 2, Mode: 2, Temps: []int{1, 3}, TempLabels: []string{"SYSTIN", "temp3"}},
+	}
+	if !reflect.DeepEqual(ms, want) {
+		t.Errorf("wrong mappings:\n got %+v\nwant %+v", ms, want)
+	}
+}
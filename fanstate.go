@@ -0,0 +1,59 @@
+package lmsensors
+
+import (
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FanState is what a fan's reading means, since 0 RPM can be a semi-passive fan idling as well as a dead one.
+//
+//go:generate stringer -type=FanState -trimprefix=Fan
+type FanState int
+
+const (
+	FanUnknown  FanState = iota // No reading, and no fault reported
+	FanSpinning                 // At or above its minimum, if it has one
+	FanStopped                  // 0 RPM, but not being driven, eg a semi-passive fan at low load
+	FanBelowMin                 // Spinning, but slower than its minimum
+	FanFailed                   // Faulted, or not turning despite being driven
+)
+
+// classifyFan works out a fan's state from its reading, its min limit (0 if it has none), its alarm and fault flags, and the duty of the PWM output driving it (-1 if unknown).
+func classifyFan(rpm, min float64, alarm, fault bool, duty int) FanState {
+	switch {
+	case fault:
+		return FanFailed
+	case math.IsNaN(rpm):
+		return FanUnknown
+	case rpm == 0 && duty > 0:
+		return FanFailed
+	case rpm == 0 && alarm && min > 0:
+		return FanFailed
+	case rpm == 0:
+		return FanStopped
+	case alarm || min > 0 && rpm < min:
+		return FanBelowMin
+	default:
+		return FanSpinning
+	}
+}
+
+// readFanDuty reads the duty of the PWM output with the same number as a fan, which is the one usually driving it, or -1.
+func readFanDuty(dir, fan string) int {
+	n, ok := strings.CutPrefix(fan, "fan")
+	if !ok {
+		return -1
+	}
+	duty, err := strconv.Atoi(readSysfsString(filepath.Join(dir, "pwm"+n)))
+	if err != nil {
+		return -1
+	}
+	return duty
+}
+
+// setDuty reclassifies a fan given the duty of the PWM output driving it.
+func (s *FanSensor) setDuty(duty int) {
+	s.State = classifyFan(s.Value, s.Min, s.alarm, s.fault, duty)
+}
@@ -0,0 +1,44 @@
+package lmsensors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClassifyFan(t *testing.T) {
+	for _, c := range []struct {
+		rpm, min     float64
+		alarm, fault bool
+		duty         int
+		want         FanState
+	}{
+		{1200, 0, false, false, -1, FanSpinning},
+		{1200, 600, false, false, 128, FanSpinning},
+		{400, 600, false, false, -1, FanBelowMin},
+		{400, 0, true, false, -1, FanBelowMin},
+		{0, 0, false, false, -1, FanStopped},
+		{0, 0, false, false, 0, FanStopped}, // Semi-passive
+		{0, 0, false, false, 128, FanFailed},
+		{0, 600, true, false, -1, FanFailed},
+		{1200, 0, false, true, -1, FanFailed},
+		{NoValue, 0, false, false, -1, FanUnknown},
+	} {
+		if got := classifyFan(c.rpm, c.min, c.alarm, c.fault, c.duty); got != c.want {
+			t.Errorf("classifyFan(%v, %v, %t, %t, %d) = %v, want %v", c.rpm, c.min, c.alarm, c.fault, c.duty, got, c.want)
+		}
+	}
+}
+
+func TestReadFanDuty(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pwm2"), []byte("153\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if d := readFanDuty(dir, "fan2"); d != 153 {
+		t.Errorf("wrong duty: %d", d)
+	}
+	if d := readFanDuty(dir, "fan1"); d != -1 {
+		t.Errorf("duty for fan without pwm: %d", d)
+	}
+}
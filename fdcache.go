@@ -0,0 +1,147 @@
+package lmsensors
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// attrFile is a sysfs attribute held open, so that rereading it is a single pread rather than an open, read and close.
+// sysfs regenerates an attribute's contents on every read from the start, so the readings are never stale.
+type attrFile struct {
+	path string
+	f    *os.File
+}
+
+// read reads the whole attribute into buf.
+// If that fails, eg with ENODEV because the device has been unplugged, the file is reopened and read again once, so that a device that's come back under the same path, or a driver that's been reloaded, is picked up; if it's gone for good, that gives ENOENT.
+func (a *attrFile) read(buf []byte) ([]byte, error) {
+	if a.f != nil {
+		if n, err := a.f.ReadAt(buf, 0); err == nil || errors.Is(err, io.EOF) {
+			return buf[:n], nil
+		}
+		a.close()
+	}
+	f, err := os.Open(a.path)
+	if err != nil {
+		return nil, err
+	}
+	a.f = f
+	n, err := f.ReadAt(buf, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		a.close()
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+func (a *attrFile) close() error {
+	if a.f == nil {
+		return nil
+	}
+	err := a.f.Close()
+	a.f = nil
+	return err
+}
+
+// attrCache holds open the attributes [GetSysfs] reads, by path, when [SetSysfsFDCache] is on.
+type attrCache struct {
+	mu    sync.Mutex
+	files map[string]*attrFile
+	devs  map[string]string // Where each hwmon device's class link pointed when it was last listed, eg hwmon2 to ../../devices/pci0000:00/0000:00:18.3/hwmon/hwmon2
+	buf   [4096]byte        // A page, which is all sysfs will ever give
+
+	closed bool // By [SetSysfsFDCache], while a GetSysfs might still be using it
+}
+
+var fdCache atomic.Pointer[attrCache]
+
+// SetSysfsFDCache keeps the attributes [GetSysfs] reads open between calls, so that polling it often doesn't pay for opening and closing every one every time.
+// A file that fails to read is reopened, and those of devices that have gone, or been replaced by another with the same hwmon number, are closed, so hotplugging is handled.
+// It costs a file descriptor for every attribute of every chip, a few hundred on a typical desktop. Turning it off closes them, once any GetSysfs under way has finished with them.
+func SetSysfsFDCache(on bool) {
+	if !on {
+		if c := fdCache.Swap(nil); c != nil {
+			c.close()
+		}
+		return
+	}
+	fdCache.CompareAndSwap(nil, &attrCache{files: map[string]*attrFile{}, devs: map[string]string{}})
+}
+
+// readString reads an attribute, trimmed, or "" if it can't be read, like [readSysfsString].
+func (c *attrCache) readString(path string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return readSysfsString(path)
+	}
+	a, ok := c.files[path]
+	if !ok {
+		a = &attrFile{path: path}
+		c.files[path] = a
+	}
+	p, err := a.read(c.buf[:])
+	if err != nil {
+		delete(c.files, path) // Gone, or unreadable, eg a write-only attribute; look again next time
+		return ""
+	}
+	return strings.TrimSpace(string(p))
+}
+
+// prune closes the files of the devices that aren't among devs any more, or have been replaced, eg by a chip plugged in after another was unplugged.
+func (c *attrCache) prune(devs []HwmonDevice) {
+	seen := make(map[string]string, len(devs))
+	for _, dev := range devs {
+		target, _ := os.Readlink(dev.Path)
+		seen[dev.Path] = target
+	}
+	c.mu.Lock()
+	var gone []string
+	for path, target := range c.devs {
+		if t, ok := seen[path]; !ok || t != target {
+			gone = append(gone, path+string(os.PathSeparator))
+		}
+	}
+	c.devs = seen
+	c.mu.Unlock()
+	if len(gone) != 0 {
+		c.invalidate(func(path string) bool {
+			for _, prefix := range gone {
+				if strings.HasPrefix(path, prefix) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+}
+
+func (c *attrCache) close() {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	c.invalidate(func(string) bool { return true })
+}
+
+func (c *attrCache) invalidate(match func(path string) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for path, a := range c.files {
+		if match(path) {
+			a.close()
+			delete(c.files, path)
+		}
+	}
+}
+
+// readAttr reads a chip attribute through the cache, if it's on, ie not nil.
+func readAttr(c *attrCache, path string) string {
+	if c != nil {
+		return c.readString(path)
+	}
+	return readSysfsString(path)
+}
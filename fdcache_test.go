@@ -0,0 +1,94 @@
+package lmsensors
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSysfsFDCache(t *testing.T) {
+	root := t.TempDir()
+	defer func() { SysfsRoot = "/sys" }()
+	SysfsRoot = root
+	SetSysfsFDCache(true)
+	defer SetSysfsFDCache(false)
+
+	// Like the kernel's, the class device is a link to the device's own directory.
+	plug := func(parent, temp string) {
+		dir := filepath.Join(root, "devices", parent, "hwmon", "hwmon0")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for attr, v := range map[string]string{"name": parent, "temp1_input": temp} {
+			if err := os.WriteFile(filepath.Join(dir, attr), []byte(v+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		link := filepath.Join(root, "class", "hwmon", "hwmon0")
+		_ = os.Remove(link)
+		if err := os.MkdirAll(filepath.Dir(link), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join("..", "..", "devices", parent, "hwmon", "hwmon0"), link); err != nil {
+			t.Fatal(err)
+		}
+	}
+	temp := func(chip string) float64 {
+		t.Helper()
+		sys, err := GetSysfs()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, ok := sys.Chips[chip]
+		if !ok {
+			t.Fatalf("no %s: %v", chip, sys.Chips)
+		}
+		return c.Sensors["temp1"].Reading()
+	}
+
+	plug("k10temp", "40000")
+	if v := temp("k10temp-virtual-0"); v != 40 || len(fdCache.Load().files) != 1 {
+		t.Fatalf("read %v, %d cached", v, len(fdCache.Load().files))
+	}
+	var cached *os.File
+	for _, a := range fdCache.Load().files {
+		cached = a.f
+	}
+	if err := os.WriteFile(filepath.Join(root, "devices", "k10temp", "hwmon", "hwmon0", "temp1_input"), []byte("41000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if v := temp("k10temp-virtual-0"); v != 41 {
+		t.Errorf("reread %v", v)
+	}
+	for _, a := range fdCache.Load().files {
+		if a.f != cached {
+			t.Error("reopened")
+		}
+	}
+
+	// A file that fails, as one of an unplugged device does, is reopened.
+	cached.Close()
+	if v := temp("k10temp-virtual-0"); v != 41 {
+		t.Errorf("reopened read %v", v)
+	}
+
+	// Another chip taking over the hwmon number closes the first's files.
+	for _, a := range fdCache.Load().files {
+		cached = a.f
+	}
+	plug("coretemp", "50000")
+	if v := temp("coretemp-virtual-0"); v != 50 || len(fdCache.Load().files) != 1 {
+		t.Errorf("replaced read %v, %d cached", v, len(fdCache.Load().files))
+	}
+	if _, err := cached.ReadAt(make([]byte, 1), 0); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("kept the first chip's file: %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(root, "class", "hwmon", "hwmon0")); err != nil {
+		t.Fatal(err)
+	}
+	if sys, err := GetSysfs(); err != nil || len(sys.Chips) != 0 || len(fdCache.Load().files) != 0 {
+		t.Errorf("unplugged: %v, %d cached: %v", sys.Chips, len(fdCache.Load().files), err)
+	}
+}
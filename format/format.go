@@ -0,0 +1,16 @@
+// Package format writes readings in the formats other monitoring systems take, so this package can feed them directly.
+package format
+
+import (
+	"math"
+	"strconv"
+)
+
+// number formats a float compactly, without an exponent for everyday values.
+func number(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func known(v float64) bool {
+	return !math.IsNaN(v)
+}
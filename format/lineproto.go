@@ -0,0 +1,54 @@
+package format
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// LineProtocol writes a snapshot in InfluxDB's line protocol, which Telegraf takes, one line per valid reading, like
+//
+//	sensors,chip=k10temp-pci-00c3,chip_type=k10temp,sensor=Tctl,type=Temperature value=42.25,alarm=false,max=80,limit="InRange" 1700000000000000000
+//
+// The limits are only there if they were read, see [lmsensors.WithLimitCheck]. Telegraf adds the host tag itself.
+func LineProtocol(w io.Writer, snap *lmsensors.Snapshot) error {
+	types := map[string]string{}
+	for c := range snap.Chips {
+		types[c.ID] = c.Type
+	}
+	bw := bufio.NewWriter(w)
+	for r := range snap.Readings {
+		if !r.Valid {
+			continue
+		}
+		bw.WriteString("sensors,chip=" + tagEscaper.Replace(r.Chip))
+		if t := types[r.Chip]; t != "" {
+			bw.WriteString(",chip_type=" + tagEscaper.Replace(t))
+		}
+		bw.WriteString(",sensor=" + tagEscaper.Replace(r.Sensor) + ",type=" + r.Type.String())
+		bw.WriteString(" value=" + number(r.Value) + ",alarm=" + strconv.FormatBool(r.Alarm))
+		for _, f := range []struct {
+			name string
+			v    float64
+		}{{"min", r.Min}, {"max", r.Max}, {"crit", r.Crit}} {
+			if known(f.v) {
+				bw.WriteString("," + f.name + "=" + number(f.v))
+			}
+		}
+		if r.Limit != lmsensors.LimitUnknown {
+			bw.WriteString(",limit=\"" + r.Limit.String() + "\"")
+		}
+		at := r.Time
+		if at.IsZero() {
+			at = snap.Time()
+		}
+		bw.WriteString(" " + strconv.FormatInt(at.UnixNano(), 10) + "\n")
+	}
+	return bw.Flush()
+}
+
+// tagEscaper escapes tag keys and values.
+var tagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
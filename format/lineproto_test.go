@@ -0,0 +1,43 @@
+package format
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// alarming is a fan in alarm, which can't be made otherwise outside lmsensors.
+type alarming struct{ *lmsensors.FanSensor }
+
+func (alarming) Alarm() bool { return true }
+
+func snapshot() *lmsensors.Snapshot {
+	at := time.Unix(1700000000, 0)
+	s := &lmsensors.TempSensor{}
+	s.Name, s.Value, s.Time = "Tctl", 42.25, at
+	max := 80.0
+	s.Limits = &lmsensors.Limits{Max: &max, State: lmsensors.LimitInRange}
+	f := &lmsensors.FanSensor{}
+	f.Name, f.Value, f.Time = "CPU Fan", 1200, at
+	gone := &lmsensors.VoltageSensor{}
+	gone.Name, gone.Value = "in0", lmsensors.NoValue
+	return lmsensors.NewSnapshot(&lmsensors.System{Chips: map[string]*lmsensors.Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Type: "k10temp", Sensors: map[string]lmsensors.Sensor{"Tctl": s}},
+		"nct6798-isa-0290": {ID: "nct6798-isa-0290", Type: "nct6798", Sensors: map[string]lmsensors.Sensor{"CPU Fan": alarming{f}, "in0": gone}},
+	}}, at)
+}
+
+func TestLineProtocol(t *testing.T) {
+	var b strings.Builder
+	if err := LineProtocol(&b, snapshot()); err != nil {
+		t.Fatal(err)
+	}
+	want := `sensors,chip=k10temp-pci-00c3,chip_type=k10temp,sensor=Tctl,type=Temperature value=42.25,alarm=false,max=80,limit="InRange" 1700000000000000000
+sensors,chip=nct6798-isa-0290,chip_type=nct6798,sensor=CPU\ Fan,type=Fan value=1200,alarm=true 1700000000000000000
+`
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
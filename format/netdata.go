@@ -0,0 +1,124 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// netdataUnits are the units netdata's own charts use for each type of sensor.
+var netdataUnits = map[lmsensors.LmSensorType]string{
+	lmsensors.Temperature: "Celsius",
+	lmsensors.Voltage:     "Volts",
+	lmsensors.Fan:         "RPM",
+	lmsensors.Power:       "Watts",
+	lmsensors.Energy:      "Joules",
+	lmsensors.Current:     "Amperes",
+	lmsensors.Humidity:    "percentage",
+}
+
+// Netdata writes readings in netdata's external plugin protocol, for running as a plugin under its plugins.d.
+// Each chip gets a chart for each type of sensor it has, with a dimension per sensor, and a chart of its sensors' alarms; the charts are defined before the first reading, and again whenever a chip's sensors change.
+type Netdata struct {
+	W           io.Writer
+	UpdateEvery int // Seconds between readings, which netdata passes as the plugin's first argument
+
+	defined map[string]string // The definition each chart was last written with
+	last    time.Time
+}
+
+// netdataID replaces what netdata doesn't allow in chart and dimension IDs.
+var netdataID = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// quote quotes a netdata parameter, which can't itself contain the quotes.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "") + "'"
+}
+
+type netdataChart struct {
+	id, title, units, context string
+	readings                  []lmsensors.Reading
+	alarms                    bool
+}
+
+func (n *Netdata) Write(snap *lmsensors.Snapshot) error {
+	var charts []*netdataChart
+	byID := map[string]*netdataChart{}
+	chart := func(id string, mk func() *netdataChart) *netdataChart {
+		c, ok := byID[id]
+		if !ok {
+			c = mk()
+			c.id = id
+			byID[id] = c
+			charts = append(charts, c)
+		}
+		return c
+	}
+	for r := range snap.Readings {
+		chip := netdataID.ReplaceAllString(r.Chip, "_")
+		typ := strings.ToLower(r.Type.String())
+		units, ok := netdataUnits[r.Type]
+		if !ok {
+			units = r.Unit
+		}
+		c := chart("sensors."+chip+"_"+typ, func() *netdataChart {
+			return &netdataChart{title: r.Chip + " " + r.Type.String(), units: units, context: "sensors." + typ}
+		})
+		c.readings = append(c.readings, r)
+		a := chart("sensors."+chip+"_alarms", func() *netdataChart {
+			return &netdataChart{title: r.Chip + " Alarms", units: "boolean", context: "sensors.alarms", alarms: true}
+		})
+		a.readings = append(a.readings, r)
+	}
+
+	bw := bufio.NewWriter(n.W)
+	if n.defined == nil {
+		n.defined = map[string]string{}
+	}
+	for i, c := range charts {
+		var def strings.Builder
+		family := strings.TrimPrefix(c.context, "sensors.")
+		fmt.Fprintf(&def, "CHART %s '' %s %s %s %s line %d %d '' go-lmsensors\n", c.id, quote(c.title), quote(c.units), quote(family), quote(c.context), 70000+i, n.UpdateEvery)
+		divisor := 1000 // The values are sent in thousandths, as they have to be integers
+		if c.alarms {
+			divisor = 1
+		}
+		for _, r := range c.readings {
+			fmt.Fprintf(&def, "DIMENSION %s %s absolute 1 %d\n", netdataID.ReplaceAllString(r.Sensor, "_"), quote(r.Sensor), divisor)
+		}
+		if n.defined[c.id] != def.String() {
+			bw.WriteString(def.String())
+			n.defined[c.id] = def.String()
+		}
+	}
+
+	begin := ""
+	if !n.last.IsZero() {
+		begin = fmt.Sprintf(" %d", snap.Time().Sub(n.last).Microseconds())
+	}
+	n.last = snap.Time()
+	for _, c := range charts {
+		bw.WriteString("BEGIN " + c.id + begin + "\n")
+		for _, r := range c.readings {
+			id := netdataID.ReplaceAllString(r.Sensor, "_")
+			switch {
+			case c.alarms && r.Alarm:
+				bw.WriteString("SET " + id + " = 1\n")
+			case c.alarms:
+				bw.WriteString("SET " + id + " = 0\n")
+			case r.Valid:
+				fmt.Fprintf(bw, "SET %s = %d\n", id, int64(math.Round(r.Value*1000)))
+			default:
+				bw.WriteString("SET " + id + " = \n") // An empty value is a gap
+			}
+		}
+		bw.WriteString("END\n")
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,54 @@
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNetdata(t *testing.T) {
+	var b strings.Builder
+	n := &Netdata{W: &b, UpdateEvery: 1}
+	if err := n.Write(snapshot()); err != nil {
+		t.Fatal(err)
+	}
+	want := `CHART sensors.k10temp-pci-00c3_temperature '' 'k10temp-pci-00c3 Temperature' 'Celsius' 'temperature' 'sensors.temperature' line 70000 1 '' go-lmsensors
+DIMENSION Tctl 'Tctl' absolute 1 1000
+CHART sensors.k10temp-pci-00c3_alarms '' 'k10temp-pci-00c3 Alarms' 'boolean' 'alarms' 'sensors.alarms' line 70001 1 '' go-lmsensors
+DIMENSION Tctl 'Tctl' absolute 1 1
+CHART sensors.nct6798-isa-0290_fan '' 'nct6798-isa-0290 Fan' 'RPM' 'fan' 'sensors.fan' line 70002 1 '' go-lmsensors
+DIMENSION CPU_Fan 'CPU Fan' absolute 1 1000
+CHART sensors.nct6798-isa-0290_alarms '' 'nct6798-isa-0290 Alarms' 'boolean' 'alarms' 'sensors.alarms' line 70003 1 '' go-lmsensors
+DIMENSION CPU_Fan 'CPU Fan' absolute 1 1
+DIMENSION in0 'in0' absolute 1 1
+CHART sensors.nct6798-isa-0290_voltage '' 'nct6798-isa-0290 Voltage' 'Volts' 'voltage' 'sensors.voltage' line 70004 1 '' go-lmsensors
+DIMENSION in0 'in0' absolute 1 1000
+BEGIN sensors.k10temp-pci-00c3_temperature
+SET Tctl = 42250
+END
+BEGIN sensors.k10temp-pci-00c3_alarms
+SET Tctl = 0
+END
+BEGIN sensors.nct6798-isa-0290_fan
+SET CPU_Fan = 1200000
+END
+BEGIN sensors.nct6798-isa-0290_alarms
+SET CPU_Fan = 1
+SET in0 = 0
+END
+BEGIN sensors.nct6798-isa-0290_voltage
+SET in0 = 
+END
+`
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+
+	// The charts are only defined once.
+	b.Reset()
+	if err := n.Write(snapshot()); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "CHART") || !strings.HasPrefix(b.String(), "BEGIN sensors.k10temp-pci-00c3_temperature 0\n") {
+		t.Errorf("second write:\n%s", b.String())
+	}
+}
@@ -0,0 +1,80 @@
+package format
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// OpenMetricsContentType is the Content-Type to serve [OpenMetrics] with.
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// promFamily is how a metric family is described.
+type promFamily struct {
+	name, typ, unit, help string
+}
+
+// promFamilies are the metric families of [promNames] and [promAlarm], in the order they're written.
+var promFamilies = []promFamily{
+	{"lmsensors_temperature_celsius", "gauge", "celsius", "Temperature sensor readings."},
+	{"lmsensors_voltage_volts", "gauge", "volts", "Voltage sensor readings."},
+	{"lmsensors_fan_rpm", "gauge", "rpm", "Fan speed readings."},
+	{"lmsensors_power_watts", "gauge", "watts", "Power sensor readings."},
+	{"lmsensors_energy_joules", "counter", "joules", "Energy counter readings."},
+	{"lmsensors_current_amperes", "gauge", "amperes", "Current sensor readings."},
+	{"lmsensors_humidity_percent", "gauge", "percent", "Humidity sensor readings."},
+	{promAlarm, "gauge", "", "Whether each sensor is in alarm."},
+}
+
+// OpenMetrics writes a snapshot in the OpenMetrics text format, which Prometheus scrapes, without needing its client library.
+// The series are those of [RemoteWrite], without timestamps, since they're meant to be scraped as they are; labels are added to all of them.
+// Families with no samples are left out.
+func OpenMetrics(w io.Writer, snap *lmsensors.Snapshot, labels map[string]string) error {
+	byFamily := map[string][]promSample{}
+	for _, s := range promSamples(snap, labels) {
+		f := strings.TrimSuffix(s.name, "_total")
+		byFamily[f] = append(byFamily[f], s)
+	}
+	bw := bufio.NewWriter(w)
+	for _, f := range promFamilies {
+		samples := byFamily[f.name]
+		if len(samples) == 0 {
+			continue
+		}
+		bw.WriteString("# TYPE " + f.name + " " + f.typ + "\n")
+		if f.unit != "" {
+			bw.WriteString("# UNIT " + f.name + " " + f.unit + "\n")
+		}
+		bw.WriteString("# HELP " + f.name + " " + f.help + "\n")
+		for _, s := range samples {
+			bw.WriteString(s.name + "{")
+			for i, l := range s.labels {
+				if i != 0 {
+					bw.WriteByte(',')
+				}
+				bw.WriteString(l.name + `="` + labelEscaper.Replace(l.value) + `"`)
+			}
+			bw.WriteString("} " + omNumber(s.value) + "\n")
+		}
+	}
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func omNumber(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
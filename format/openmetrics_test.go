@@ -0,0 +1,50 @@
+package format
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+func TestOpenMetrics(t *testing.T) {
+	var b strings.Builder
+	if err := OpenMetrics(&b, snapshot(), map[string]string{"rack": `a"1`}); err != nil {
+		t.Fatal(err)
+	}
+	want := `# TYPE lmsensors_temperature_celsius gauge
+# UNIT lmsensors_temperature_celsius celsius
+# HELP lmsensors_temperature_celsius Temperature sensor readings.
+lmsensors_temperature_celsius{chip="k10temp-pci-00c3",rack="a\"1",sensor="Tctl"} 42.25
+# TYPE lmsensors_fan_rpm gauge
+# UNIT lmsensors_fan_rpm rpm
+# HELP lmsensors_fan_rpm Fan speed readings.
+lmsensors_fan_rpm{chip="nct6798-isa-0290",rack="a\"1",sensor="CPU Fan"} 1200
+# TYPE lmsensors_alarm gauge
+# HELP lmsensors_alarm Whether each sensor is in alarm.
+lmsensors_alarm{chip="k10temp-pci-00c3",rack="a\"1",sensor="Tctl"} 0
+lmsensors_alarm{chip="nct6798-isa-0290",rack="a\"1",sensor="CPU Fan"} 1
+# EOF
+`
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestOpenMetricsCounter(t *testing.T) {
+	// An energy counter, known by its feature type, as its unit can't be set outside lmsensors.
+	e := &lmsensors.GenericSensor{FeatureType: lmsensors.Energy}
+	e.Name, e.Value = "energy1", 1234.5
+	snap := lmsensors.NewSnapshot(&lmsensors.System{Chips: map[string]*lmsensors.Chip{
+		"ina238-i2c-1-40": {ID: "ina238-i2c-1-40", Sensors: map[string]lmsensors.Sensor{"energy1": e}},
+	}}, time.Unix(1700000000, 0))
+	var b strings.Builder
+	if err := OpenMetrics(&b, snap, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(b.String(), "# TYPE lmsensors_energy_joules counter\n# UNIT lmsensors_energy_joules joules\n") ||
+		!strings.Contains(b.String(), "\nlmsensors_energy_joules_total{chip=\"ina238-i2c-1-40\",sensor=\"energy1\"} 1234.5\n") {
+		t.Errorf("got\n%s", b.String())
+	}
+}
@@ -0,0 +1,61 @@
+package format
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// promNames are the Prometheus metric names of each type of sensor's readings, in base units as Prometheus likes.
+var promNames = map[lmsensors.LmSensorType]string{
+	lmsensors.Temperature: "lmsensors_temperature_celsius",
+	lmsensors.Voltage:     "lmsensors_voltage_volts",
+	lmsensors.Fan:         "lmsensors_fan_rpm",
+	lmsensors.Power:       "lmsensors_power_watts",
+	lmsensors.Energy:      "lmsensors_energy_joules_total",
+	lmsensors.Current:     "lmsensors_current_amperes",
+	lmsensors.Humidity:    "lmsensors_humidity_percent",
+}
+
+// promAlarm is the metric of whether each sensor is in alarm, 0 or 1.
+const promAlarm = "lmsensors_alarm"
+
+type promLabel struct{ name, value string }
+
+// promSample is one sample of one series.
+type promSample struct {
+	name   string
+	labels []promLabel // Sorted by name, including labels given by the caller
+	value  float64
+	time   time.Time
+}
+
+// promSamples turns a snapshot into samples, a value and an alarm series per valid reading, labelled with its chip and sensor on top of the given labels.
+func promSamples(snap *lmsensors.Snapshot, labels map[string]string) []promSample {
+	var samples []promSample
+	for r := range snap.Readings {
+		name, ok := promNames[r.FeatureType]
+		if !ok || !r.Valid {
+			continue
+		}
+		ls := make([]promLabel, 0, len(labels)+2)
+		for n, v := range labels {
+			if n != "chip" && n != "sensor" {
+				ls = append(ls, promLabel{n, v})
+			}
+		}
+		ls = append(ls, promLabel{"chip", r.Chip}, promLabel{"sensor", r.Sensor})
+		sort.Slice(ls, func(i, j int) bool { return ls[i].name < ls[j].name })
+		at := r.Time
+		if at.IsZero() {
+			at = snap.Time()
+		}
+		alarm := 0.0
+		if r.Alarm {
+			alarm = 1
+		}
+		samples = append(samples, promSample{name, ls, r.Value, at}, promSample{promAlarm, ls, alarm, at})
+	}
+	return samples
+}
@@ -0,0 +1,44 @@
+package format
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// RemoteWrite encodes a snapshot as a Prometheus remote write request, a snappy-compressed WriteRequest protobuf, ready to POST.
+// There's a series per valid reading, eg lmsensors_temperature_celsius{chip="k10temp-pci-00c3",sensor="Tctl"}, and one of whether it's in alarm, lmsensors_alarm; labels are added to all of them, eg instance.
+func RemoteWrite(snap *lmsensors.Snapshot, labels map[string]string) []byte {
+	var req []byte
+	for _, s := range promSamples(snap, labels) {
+		// Labels have to be sorted, and __name__ isn't necessarily first.
+		ls := append([]promLabel{{"__name__", s.name}}, s.labels...)
+		sort.Slice(ls, func(i, j int) bool { return ls[i].name < ls[j].name })
+		var ts []byte
+		for _, l := range ls {
+			ts = protoBytes(ts, 1, protoLabel(nil, l.name, l.value))
+		}
+		var sample []byte
+		sample = binary.AppendUvarint(sample, 1<<3|1) // value, a double
+		sample = binary.LittleEndian.AppendUint64(sample, math.Float64bits(s.value))
+		sample = binary.AppendUvarint(sample, 2<<3|0) // timestamp, an int64 of milliseconds
+		sample = binary.AppendUvarint(sample, uint64(s.time.UnixMilli()))
+		ts = protoBytes(ts, 2, sample)
+		req = protoBytes(req, 1, ts)
+	}
+	return snappyEncode(req)
+}
+
+// protoBytes appends a length-delimited field, ie a string or an embedded message, in the protobuf wire format.
+func protoBytes(buf []byte, field int, p []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field)<<3|2)
+	buf = binary.AppendUvarint(buf, uint64(len(p)))
+	return append(buf, p...)
+}
+
+func protoLabel(buf []byte, name, value string) []byte {
+	buf = protoBytes(buf, 1, []byte(name))
+	return protoBytes(buf, 2, []byte(value))
+}
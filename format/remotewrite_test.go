@@ -0,0 +1,132 @@
+package format
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// snappyDecode is the decoder from the snappy format description.
+func snappyDecode(src []byte) ([]byte, error) {
+	n, k := binary.Uvarint(src)
+	if k <= 0 {
+		return nil, errors.New("bad length")
+	}
+	src = src[k:]
+	var dst []byte
+	for len(src) > 0 {
+		tag := src[0]
+		switch tag & 3 {
+		case 0:
+			l := int(tag >> 2)
+			src = src[1:]
+			switch l {
+			case 60:
+				l, src = int(src[0]), src[1:]
+			case 61:
+				l, src = int(binary.LittleEndian.Uint16(src)), src[2:]
+			}
+			l++
+			if l > len(src) {
+				return nil, errors.New("literal overruns")
+			}
+			dst, src = append(dst, src[:l]...), src[l:]
+		case 2:
+			l, off := int(tag>>2)+1, int(binary.LittleEndian.Uint16(src[1:]))
+			src = src[3:]
+			if off == 0 || off > len(dst) {
+				return nil, fmt.Errorf("bad offset %d", off)
+			}
+			for range l {
+				dst = append(dst, dst[len(dst)-off])
+			}
+		default:
+			return nil, fmt.Errorf("unexpected tag %x", tag)
+		}
+	}
+	if uint64(len(dst)) != n {
+		return nil, fmt.Errorf("got %d bytes, want %d", len(dst), n)
+	}
+	return dst, nil
+}
+
+func TestSnappy(t *testing.T) {
+	random := make([]byte, 100000)
+	for i := range random {
+		random[i] = byte(rand.N(256))
+	}
+	for _, src := range [][]byte{
+		nil,
+		[]byte("abc"),
+		bytes.Repeat([]byte("lmsensors_temperature_celsius"), 1000),
+		random,
+		append(bytes.Repeat([]byte{0}, 70000), random[:300]...),
+	} {
+		enc := snappyEncode(src)
+		dec, err := snappyDecode(enc)
+		if err != nil || !bytes.Equal(dec, src) {
+			t.Errorf("%d bytes didn't round-trip: %v", len(src), err)
+		}
+	}
+	if enc := snappyEncode(bytes.Repeat([]byte("abcd"), 1000)); len(enc) > 300 {
+		t.Errorf("didn't compress: %d bytes", len(enc))
+	}
+}
+
+// protoFields splits a protobuf message into its fields, by number, with varints and fixed64s as their values and length-delimited fields as their bytes.
+func protoFields(t *testing.T, p []byte) map[int][]any {
+	t.Helper()
+	fields := map[int][]any{}
+	for len(p) > 0 {
+		key, n := binary.Uvarint(p)
+		p = p[n:]
+		switch key & 7 {
+		case 0:
+			v, n := binary.Uvarint(p)
+			fields[int(key>>3)] = append(fields[int(key>>3)], v)
+			p = p[n:]
+		case 1:
+			fields[int(key>>3)] = append(fields[int(key>>3)], math.Float64frombits(binary.LittleEndian.Uint64(p)))
+			p = p[8:]
+		case 2:
+			l, n := binary.Uvarint(p)
+			fields[int(key>>3)] = append(fields[int(key>>3)], p[n:n+int(l)])
+			p = p[n+int(l):]
+		default:
+			t.Fatalf("unexpected wire type %d", key&7)
+		}
+	}
+	return fields
+}
+
+func TestRemoteWrite(t *testing.T) {
+	req, err := snappyDecode(RemoteWrite(snapshot(), map[string]string{"instance": "box", "Zone": "attic"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, ts := range protoFields(t, req)[1] {
+		f := protoFields(t, ts.([]byte))
+		var labels []string
+		for _, l := range f[1] {
+			lf := protoFields(t, l.([]byte))
+			labels = append(labels, fmt.Sprintf("%s=%s", lf[1][0], lf[2][0]))
+		}
+		s := protoFields(t, f[2][0].([]byte))
+		got = append(got, fmt.Sprintf("%s %v %v", strings.Join(labels, ","), s[1][0], s[2][0]))
+	}
+	want := []string{
+		"Zone=attic,__name__=lmsensors_temperature_celsius,chip=k10temp-pci-00c3,instance=box,sensor=Tctl 42.25 1700000000000",
+		"Zone=attic,__name__=lmsensors_alarm,chip=k10temp-pci-00c3,instance=box,sensor=Tctl 0 1700000000000",
+		"Zone=attic,__name__=lmsensors_fan_rpm,chip=nct6798-isa-0290,instance=box,sensor=CPU Fan 1200 1700000000000",
+		"Zone=attic,__name__=lmsensors_alarm,chip=nct6798-isa-0290,instance=box,sensor=CPU Fan 1 1700000000000",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,60 @@
+package format
+
+import "encoding/binary"
+
+// snappyEncode compresses src in the snappy block format, the framing-less one Prometheus remote write uses.
+// It's a simple greedy compressor; it doesn't compress as well as the reference one, but any snappy decoder can read it.
+func snappyEncode(src []byte) []byte {
+	dst := binary.AppendUvarint(nil, uint64(len(src)))
+	const minMatch = 4
+	var table [1 << 14]int32 // Where each hash of four bytes was last seen, plus one so zero is empty
+	hash := func(i int) uint32 {
+		return (binary.LittleEndian.Uint32(src[i:]) * 0x1e35a7bd) >> (32 - 14)
+	}
+	lit := 0 // Start of the pending literal
+	for i := 0; i+minMatch <= len(src); {
+		h := hash(i)
+		cand := int(table[h]) - 1
+		table[h] = int32(i + 1)
+		if cand < 0 || i-cand > 0xffff || binary.LittleEndian.Uint32(src[cand:]) != binary.LittleEndian.Uint32(src[i:]) {
+			i++
+			continue
+		}
+		n := minMatch
+		for i+n < len(src) && src[cand+n] == src[i+n] {
+			n++
+		}
+		dst = snappyLiteral(dst, src[lit:i])
+		dst = snappyCopy(dst, i-cand, n)
+		i += n
+		lit = i
+	}
+	return snappyLiteral(dst, src[lit:])
+}
+
+func snappyLiteral(dst, lit []byte) []byte {
+	for len(lit) > 0 {
+		chunk := lit[:min(len(lit), 1<<16)]
+		switch n := len(chunk) - 1; {
+		case n < 60:
+			dst = append(dst, byte(n)<<2)
+		case n < 1<<8:
+			dst = append(dst, 60<<2, byte(n))
+		default:
+			dst = append(dst, 61<<2, byte(n), byte(n>>8))
+		}
+		dst = append(dst, chunk...)
+		lit = lit[len(chunk):]
+	}
+	return dst
+}
+
+// snappyCopy appends copies with two-byte offsets, which take up to 64 bytes each.
+func snappyCopy(dst []byte, offset, n int) []byte {
+	for n > 0 {
+		l := min(n, 64)
+		dst = append(dst, byte(l-1)<<2|2, byte(offset), byte(offset>>8))
+		n -= l
+	}
+	return dst
+}
@@ -2,7 +2,11 @@ module github.com/mt-inside/go-lmsensors
 
 go 1.24.0
 
-require github.com/mt-inside/go-usvc v0.0.7
+require (
+	github.com/BurntSushi/toml v1.5.0
+	github.com/mt-inside/go-usvc v0.0.7
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
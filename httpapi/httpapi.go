@@ -0,0 +1,174 @@
+// Package httpapi serves readings over HTTP: the latest snapshot as JSON, and a live stream of the changes as Server-Sent Events, for web dashboards.
+//
+// Wire it up to a [lmsensors.Watcher] with eg
+//
+//	s := httpapi.NewServer()
+//	w := lmsensors.NewWatcher(time.Second, s.Watch())
+//	http.Handle("/sensors/", http.StripPrefix("/sensors", s))
+//
+// Set its Fans to a [fancontrol.Handler] to serve, and change, the fans through it too.
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/fancontrol"
+)
+
+// Keepalive is how often an idle event stream gets a comment, so that proxies don't time it out.
+var Keepalive = 15 * time.Second
+
+// Server serves the readings it's given. GET / is the latest [lmsensors.Snapshot], as JSON, and GET /events is an event stream.
+// Each stream starts with a "snapshot" event, with the whole snapshot, and then has a "delta" event for each later poll, with the readings that changed and the ones that went away, see [lmsensors.Snapshot.Changes].
+// A client that can't keep up is sent the changes since the last event it got, rather than every poll's.
+// With Fans set, /fans/ is it, see [fancontrol.Handler].
+type Server struct {
+	Fans *fancontrol.Handler // Set before serving
+
+	mu     sync.Mutex
+	latest *lmsensors.Snapshot
+	seq    uint64
+	subs   map[chan struct{}]struct{}
+}
+
+// NewServer makes a [Server] with no readings yet.
+func NewServer() *Server {
+	return &Server{subs: map[chan struct{}]struct{}{}}
+}
+
+// Watch returns an option that publishes every poll of a [lmsensors.Watcher].
+func (s *Server) Watch() lmsensors.WatcherOption {
+	return lmsensors.OnPoll(func(sys *lmsensors.System, _ error) {
+		s.Publish(sys)
+	})
+}
+
+// Publish makes a reading the latest, and tells the streams about it.
+func (s *Server) Publish(sys *lmsensors.System) {
+	snap := lmsensors.NewSnapshot(sys, sys.Time)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latest = snap
+	s.seq++
+	for sub := range s.subs {
+		select {
+		case sub <- struct{}{}:
+		default: // Already woken
+		}
+	}
+}
+
+func (s *Server) current() (*lmsensors.Snapshot, uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.latest, s.seq
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.Fans != nil && (r.URL.Path == "/fans" || strings.HasPrefix(r.URL.Path, "/fans/")) {
+		http.StripPrefix("/fans", s.Fans).ServeHTTP(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	switch r.URL.Path {
+	case "/", "":
+		snap, _ := s.current()
+		if snap == nil {
+			http.Error(w, "no readings yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(snap)
+	case "/events":
+		s.stream(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// delta is the data of a "delta" event.
+type delta struct {
+	Time    time.Time
+	Changed []lmsensors.Reading
+	Removed []key
+}
+
+type key struct {
+	Chip   string
+	Sensor string
+}
+
+func (s *Server) stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	wake := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.subs[wake] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, wake)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepalive := time.NewTicker(Keepalive)
+	defer keepalive.Stop()
+	var sent *lmsensors.Snapshot
+	for {
+		if snap, seq := s.current(); snap != nil && snap != sent {
+			var err error
+			if sent == nil {
+				err = writeEvent(w, "snapshot", seq, snap)
+			} else {
+				changed, removed := snap.Changes(sent)
+				d := delta{Time: snap.Time(), Changed: changed}
+				for _, r := range removed {
+					d.Removed = append(d.Removed, key{r.Chip, r.Sensor})
+				}
+				err = writeEvent(w, "delta", seq, d)
+			}
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+			sent = snap
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-wake:
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, name string, id uint64, v any) error {
+	p, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\nid: %d\ndata: %s\n\n", name, id, p)
+	return err
+}
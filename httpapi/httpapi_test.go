@@ -0,0 +1,114 @@
+package httpapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/fancontrol"
+)
+
+func system(tctl float64) *lmsensors.System {
+	s := &lmsensors.TempSensor{}
+	s.Name, s.Value = "Tctl", tctl
+	v := &lmsensors.VoltageSensor{}
+	v.Name, v.Value = "Vcore", 1.1
+	return &lmsensors.System{Time: time.Now(), Chips: map[string]*lmsensors.Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]lmsensors.Sensor{"Tctl": s, "Vcore": v}},
+	}}
+}
+
+func TestSnapshot(t *testing.T) {
+	s := NewServer()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("before the first poll: %d", rec.Code)
+	}
+
+	s.Publish(system(42))
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	var snap struct{ Readings []struct{ Sensor string } }
+	if err := json.Unmarshal(rec.Body.Bytes(), &snap); err != nil || len(snap.Readings) != 2 {
+		t.Errorf("snapshot: %v %s", err, rec.Body)
+	}
+}
+
+func TestFans(t *testing.T) {
+	s := NewServer()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fans/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("without fans: %d", rec.Code)
+	}
+
+	s.Fans = &fancontrol.Handler{PWMs: []fancontrol.PWM{}}
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fans/", nil))
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("fans: %d %s", rec.Code, rec.Body)
+	}
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/fans/nct6798/pwm1", strings.NewReader(`{"duty": 255}`)))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("unauthorized put: %d", rec.Code)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	s := NewServer()
+	s.Publish(system(42))
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("content type %q", ct)
+	}
+
+	lines := bufio.NewScanner(resp.Body)
+	next := func() (string, string) {
+		t.Helper()
+		var event, data string
+		for lines.Scan() {
+			switch k, v, _ := strings.Cut(lines.Text(), ": "); k {
+			case "event":
+				event = v
+			case "data":
+				data = v
+			case "":
+				if event != "" {
+					return event, data
+				}
+			}
+		}
+		t.Fatal("stream ended", lines.Err())
+		return "", ""
+	}
+
+	if ev, data := next(); ev != "snapshot" || !strings.Contains(data, `"Vcore"`) {
+		t.Errorf("first event: %s %s", ev, data)
+	}
+	s.Publish(system(43))
+	ev, data := next()
+	var d delta
+	if err := json.Unmarshal([]byte(data), &d); ev != "delta" || err != nil {
+		t.Fatalf("second event: %s %s", ev, data)
+	}
+	if len(d.Changed) != 1 || d.Changed[0].Sensor != "Tctl" || d.Changed[0].Value != 43 || len(d.Removed) != 0 {
+		t.Errorf("delta: %+v", d)
+	}
+}
@@ -0,0 +1,87 @@
+package lmsensors
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// TestMachines reads the fixture sysfs trees in testdata/machines, which are trimmed copies of real machines, and compares the whole System against golden files.
+// Run with -update to accept a change in behaviour.
+func TestMachines(t *testing.T) {
+	machines, err := filepath.Glob("testdata/machines/*/class")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { SysfsRoot = "/sys" }()
+	for _, m := range machines {
+		root := filepath.Dir(m)
+		t.Run(filepath.Base(root), func(t *testing.T) {
+			SysfsRoot = root
+			sys, err := GetSysfs()
+			if err != nil {
+				t.Fatal(err)
+			}
+			sys.Stats = CollectionStats{}
+			sys.Time = time.Time{}
+			for _, chip := range sys.Chips {
+				chip.Stats = CollectionStats{}
+				for _, s := range chip.Sensors {
+					switch s := s.(type) {
+					case interface{ base() *baseSensor }:
+						s.base().Time = time.Time{}
+					case *IntrusionSensor:
+						s.Time = time.Time{}
+					}
+				}
+			}
+			got, err := json.MarshalIndent(sys, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := root + ".golden.json"
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("output differs from %s; run with -update if that's intended:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+func TestSysfsTimes(t *testing.T) {
+	defer func() { SysfsRoot = "/sys" }()
+	SysfsRoot = filepath.Join("testdata", "machines", "laptop")
+	before := time.Now()
+	sys, err := GetSysfs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := time.Now()
+	if sys.Time.Before(before) || sys.Time.After(after) {
+		t.Errorf("system time %v not within the read", sys.Time)
+	}
+	for _, chip := range sys.Chips {
+		for name, s := range chip.Sensors {
+			if ts := TimeOf(s); ts.Before(sys.Time) || ts.After(after) {
+				t.Errorf("%s/%s: time %v not within the read", chip.ID, name, ts)
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package lmsensors
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	sf "github.com/mt-inside/go-lmsensors/subfeature"
+)
+
+// Clear resets the hardware's chassis intrusion latch, by writing 0 to its alarm attribute, so that it can catch the next intrusion.
+// The reading isn't changed until the sensor is read again.
+// The write goes through the [AuditFunc], and isn't made in dry-run mode, see [SetDryRun]. Only sensors read by this package can be cleared.
+func (s *IntrusionSensor) Clear() error {
+	return s.feature.write(sf.INTRUSION_ALARM, "alarm", 0)
+}
+
+// IntrusionRecord is the history of a chassis intrusion sensor, since the raw latch only says whether it's tripped, not when or how often.
+type IntrusionRecord struct {
+	Chip    string
+	Sensor  string
+	First   time.Time // When it first tripped
+	Last    time.Time // When it last tripped
+	Count   int       // How many times it's tripped; it can only trip again after being cleared, see [IntrusionSensor.Clear]
+	Latched bool      // Whether it's currently tripped
+}
+
+type intrusionDetector struct {
+	fn func(IntrusionRecord)
+
+	mu      sync.Mutex
+	records map[string]*IntrusionRecord
+}
+
+// WithIntrusionHistory keeps an [IntrusionRecord] for every chassis intrusion sensor that's tripped, see [Watcher.Intrusions].
+// fn, if not nil, is called each time one trips.
+func WithIntrusionHistory(fn func(IntrusionRecord)) WatcherOption {
+	return func(w *Watcher) {
+		w.intrusions = &intrusionDetector{fn: fn, records: map[string]*IntrusionRecord{}}
+	}
+}
+
+func (d *intrusionDetector) observe(sys *System) {
+	d.mu.Lock()
+	var tripped []IntrusionRecord
+	for _, chip := range sys.Chips {
+		for name, sensor := range chip.Sensors {
+			if _, ok := sensor.(*IntrusionSensor); !ok || !Valid(sensor) {
+				continue // A failed read says nothing about the latch, so leaves the record as it was
+			}
+			key := sensorKey(chip.ID, name)
+			rec, ok := d.records[key]
+			switch {
+			case sensor.Alarm() && (!ok || !rec.Latched):
+				if !ok {
+					rec = &IntrusionRecord{Chip: chip.ID, Sensor: name}
+					d.records[key] = rec
+				}
+				at := TimeOf(sensor)
+				if at.IsZero() {
+					at = sys.Time
+				}
+				if rec.First.IsZero() {
+					rec.First = at
+				}
+				rec.Last = at
+				rec.Count++
+				rec.Latched = true
+				tripped = append(tripped, *rec)
+			case !sensor.Alarm() && ok:
+				rec.Latched = false
+			}
+		}
+	}
+	d.mu.Unlock()
+	if d.fn != nil {
+		for _, rec := range tripped {
+			d.fn(rec)
+		}
+	}
+}
+
+// Intrusions is the history of every chassis intrusion sensor that's tripped since the Watcher started, ordered by chip then sensor, if it's been made [WithIntrusionHistory].
+// It's safe to call from any goroutine.
+func (w *Watcher) Intrusions() []IntrusionRecord {
+	if w.intrusions == nil {
+		return nil
+	}
+	d := w.intrusions
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	recs := make([]IntrusionRecord, 0, len(d.records))
+	for _, rec := range d.records {
+		recs = append(recs, *rec)
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		if recs[i].Chip != recs[j].Chip {
+			return recs[i].Chip < recs[j].Chip
+		}
+		return recs[i].Sensor < recs[j].Sensor
+	})
+	return recs
+}
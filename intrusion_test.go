@@ -0,0 +1,82 @@
+package lmsensors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIntrusionHistory(t *testing.T) {
+	var trips []IntrusionRecord
+	w := NewWatcher(time.Second, WithIntrusionHistory(func(r IntrusionRecord) { trips = append(trips, r) }))
+	start := time.Now()
+	poll := func(alarm bool, at time.Time) {
+		w.observe(&System{Time: at, Chips: map[string]*Chip{
+			"nct6798-isa-0290": {ID: "nct6798-isa-0290", Sensors: map[string]Sensor{
+				"intrusion0": &IntrusionSensor{Name: "intrusion0", alarm: alarm, Time: at},
+			}},
+		}})
+	}
+
+	poll(false, start)
+	if len(w.Intrusions()) != 0 {
+		t.Error("history before a trip")
+	}
+	poll(true, start.Add(time.Second))
+	poll(true, start.Add(2*time.Second)) // Still latched
+	poll(false, start.Add(3*time.Second))
+	poll(true, start.Add(4*time.Second))
+
+	recs := w.Intrusions()
+	if len(recs) != 1 {
+		t.Fatalf("wrong history: %v", recs)
+	}
+	r := recs[0]
+	if r.Count != 2 || !r.Latched || !r.First.Equal(start.Add(time.Second)) || !r.Last.Equal(start.Add(4*time.Second)) {
+		t.Errorf("wrong record: %+v", r)
+	}
+	if len(trips) != 2 || trips[0].Count != 1 {
+		t.Errorf("wrong trips: %v", trips)
+	}
+}
+
+func TestIntrusionFailedRead(t *testing.T) {
+	var trips []IntrusionRecord
+	w := NewWatcher(time.Second, WithIntrusionHistory(func(r IntrusionRecord) { trips = append(trips, r) }))
+	for _, s := range []*IntrusionSensor{{alarm: true}, {failed: true}, {alarm: true}} {
+		s.Name = "intrusion0"
+		w.observe(&System{Time: time.Now(), Chips: map[string]*Chip{
+			"nct6798-isa-0290": {ID: "nct6798-isa-0290", Sensors: map[string]Sensor{"intrusion0": s}},
+		}})
+	}
+	if recs := w.Intrusions(); len(trips) != 1 || len(recs) != 1 || recs[0].Count != 1 || !recs[0].Latched {
+		t.Errorf("a failed read counted as a clear: %v, %v", trips, recs)
+	}
+}
+
+func TestIntrusionClear(t *testing.T) {
+	if err := (&IntrusionSensor{}).Clear(); err == nil {
+		t.Error("cleared a sensor with nowhere to write")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "intrusion0_alarm")
+	if err := os.WriteFile(path, []byte("1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var events []WriteEvent
+	SetAuditFunc(func(ev WriteEvent) { events = append(events, ev) })
+	defer SetAuditFunc(nil)
+
+	s := &IntrusionSensor{Name: "intrusion0", alarm: true, feature: featureRef{"nct6798-isa-0290", "nct6798", dir, "intrusion0"}}
+	if err := s.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := os.ReadFile(path); string(p) != "0" {
+		t.Errorf("latch not cleared: %q", p)
+	}
+	if len(events) != 1 || events[0].Old != 1 || events[0].New != 0 || events[0].Attr != "intrusion0_alarm" {
+		t.Errorf("wrong audit: %+v", events)
+	}
+}
@@ -0,0 +1,81 @@
+package lmsensors
+
+import (
+	"iter"
+	"slices"
+	"sort"
+
+	sf "github.com/mt-inside/go-lmsensors/subfeature"
+)
+
+// The iterators in this package are methods and functions that are ranged over directly, eg `for _, chip := range Chips`.
+// These return the same iterators as values, so they can be passed to the iter, slices and maps packages, or stored.
+
+// ChipSeq returns [Chips] as an [iter.Seq2].
+func ChipSeq() iter.Seq2[uint32, ChipPtr] {
+	return Chips
+}
+
+// FeatureSeq returns [ChipPtr.Features] as an [iter.Seq2].
+func (chip ChipPtr) FeatureSeq() iter.Seq2[uint32, Feature] {
+	return chip.Features
+}
+
+// SubFeatureSeq returns [Feature.SubFeatures] as an [iter.Seq].
+func (feat Feature) SubFeatureSeq() iter.Seq[sf.SubFeature] {
+	return feat.SubFeatures
+}
+
+// ValueSeq returns [Feature.Values] as an [iter.Seq2].
+func (feat Feature) ValueSeq() iter.Seq2[sf.SubFeature, float64] {
+	return feat.Values
+}
+
+// Values drops the keys of an [iter.Seq2], eg to collect the chips from [ChipSeq] with [slices.Collect].
+func Values[K, V any](seq iter.Seq2[K, V]) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range seq {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// AllChips returns all the chips detected by [Init].
+func AllChips() []ChipPtr {
+	return slices.Collect(Values(ChipSeq()))
+}
+
+// SensorsByType groups the chip's sensors by their type, each group sorted by name.
+func (c *Chip) SensorsByType() map[LmSensorType][]Sensor {
+	byType := map[LmSensorType][]Sensor{}
+	for _, s := range c.Sensors {
+		if s == nil {
+			continue
+		}
+		byType[s.Type()] = append(byType[s.Type()], s)
+	}
+	for _, ss := range byType {
+		sort.Slice(ss, func(i, j int) bool { return ss[i].GetName() < ss[j].GetName() })
+	}
+	return byType
+}
+
+// SubfeaturesOf returns every subfeature reading of a sensor, by sysfs attribute, if it was read [WithSubfeatures].
+func SubfeaturesOf(s Sensor) map[string]float64 {
+	if subs := subfeaturesOf(s); subs != nil {
+		return *subs
+	}
+	return nil
+}
+
+func subfeaturesOf(s Sensor) *map[string]float64 {
+	switch s := s.(type) {
+	case interface{ base() *baseSensor }:
+		return &s.base().Subfeatures
+	case *IntrusionSensor:
+		return &s.Subfeatures
+	}
+	return nil
+}
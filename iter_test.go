@@ -0,0 +1,59 @@
+package lmsensors
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestValues(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+	got := slices.Sorted(Values(maps.All(m)))
+	if !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("wrong values: %v", got)
+	}
+	for range Values(maps.All(m)) {
+		break // Stopping early mustn't panic
+	}
+}
+
+func TestAllChips(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Skip(err)
+	}
+	defer Cleanup()
+	n := 0
+	for range Chips {
+		n++
+	}
+	if chips := AllChips(); len(chips) != n {
+		t.Errorf("collected %d chips, ranged over %d", len(chips), n)
+	}
+}
+
+func TestSensorsByType(t *testing.T) {
+	chip := tempSystem(40).Chips["k10temp-pci-00c3"]
+	chip.Sensors["Tccd1"] = &TempSensor{baseSensor: baseSensor{Name: "Tccd1", Value: 38}}
+	chip.Sensors["broken"] = nil
+	byType := chip.SensorsByType()
+	temps := byType[Temperature]
+	if len(byType) != 2 || len(temps) != 2 || temps[0].GetName() != "Tccd1" || len(byType[Voltage]) != 1 {
+		t.Errorf("wrong grouping: %v", byType)
+	}
+}
+
+func TestAllSubfeatures(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Skip(err)
+	}
+	defer Cleanup()
+	for _, chip := range AllChips() {
+		for _, feat := range chip.Features {
+			for _, info := range feat.AllSubfeatures() {
+				if info.Attr == "" || feat.Attr(info.Type) != info.Attr {
+					t.Errorf("%s/%s: bad subfeature info %+v", chip, feat.Name(), info)
+				}
+			}
+		}
+	}
+}
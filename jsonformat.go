@@ -0,0 +1,117 @@
+package lmsensors
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"strconv"
+)
+
+// JSONVersion is the version of the JSON encoding of [System]s, and their sensors, that this package produces; [JSONSchema] describes it.
+//   - Version 1 has no version number, and leaves the sensors' types, units and alarms out; see [JSONCompat].
+//   - Version 2 gives the version as the System's Version field, and each sensor's Type (by name, eg "Temperature"), Unit and Alarm.
+//
+// Versions only ever add fields, so parsers should ignore ones they don't know.
+const JSONVersion = 2
+
+// JSONSchema is a JSON Schema, draft 2020-12, of the encoding of [System]s, for parsers in other languages to work from.
+//
+//go:embed schema/system.json
+var JSONSchema []byte
+
+// system is a System without its MarshalJSON.
+type system System
+
+func (sys System) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Version int
+		system
+	}{JSONVersion, system(sys)})
+}
+
+// JSONCompat wraps a System so that it encodes in version 1 of the format, see [JSONVersion], for consumers that can't cope with version 2's additions, eg
+//
+//	json.NewEncoder(w).Encode(lmsensors.JSONCompat(sys))
+//
+// It's only version 2's fields that are left out, so fields added since version 1 that aren't versioned, eg the System's Time and Stats and the sensors' Limits, are still there.
+func JSONCompat(sys *System) json.Marshaler {
+	return compatSystem{sys}
+}
+
+type compatSystem struct{ *System }
+
+// chipFields is a Chip, for its fields to be embedded.
+type chipFields Chip
+
+type compatChip struct {
+	*chipFields
+	Sensors map[string]compatSensor
+}
+
+type compatSensor struct{ Sensor }
+
+func (c compatSystem) MarshalJSON() ([]byte, error) {
+	if c.System == nil {
+		return []byte("null"), nil
+	}
+	chips := make(map[string]*compatChip, len(c.Chips))
+	for id, chip := range c.Chips {
+		if chip == nil {
+			chips[id] = nil
+			continue
+		}
+		cc := &compatChip{(*chipFields)(chip), make(map[string]compatSensor, len(chip.Sensors))}
+		for name, s := range chip.Sensors {
+			cc.Sensors[name] = compatSensor{s}
+		}
+		chips[id] = cc
+	}
+	// The outer Chips hides the embedded one.
+	return json.Marshal(struct {
+		system
+		Chips map[string]*compatChip
+	}{system(*c.System), chips})
+}
+
+func (s compatSensor) MarshalJSON() ([]byte, error) {
+	switch v := s.Sensor.(type) {
+	case nil:
+		return []byte("null"), nil
+	case *TempSensor, *VoltageSensor, *FanSensor, *CurrentSensor, *GenericSensor, *IntrusionSensor:
+		return encodeSensor(v, false)
+	case *UnimplementedSensor:
+		return json.Marshal((*unimplementedSensor)(v))
+	}
+	return json.Marshal(s.Sensor)
+}
+
+// sensorFields writes the fields version 2 adds to every sensor.
+func sensorFields(buf *bytes.Buffer, s Sensor, first bool) {
+	if !first {
+		buf.WriteByte(',')
+	}
+	buf.WriteString(`"Type":` + strconv.Quote(s.Type().String()) + `,"Unit":` + strconv.Quote(s.Unit()) + `,"Alarm":` + strconv.FormatBool(s.Alarm()))
+}
+
+func (s *IntrusionSensor) MarshalJSON() ([]byte, error) {
+	return marshalSensor(s)
+}
+
+// unimplementedSensor is an UnimplementedSensor without its MarshalJSON.
+type unimplementedSensor UnimplementedSensor
+
+func (s *UnimplementedSensor) MarshalJSON() ([]byte, error) {
+	p, err := json.Marshal((*unimplementedSensor)(s))
+	if err != nil {
+		return p, err
+	}
+	var buf bytes.Buffer
+	buf.Write(p[:len(p)-1])
+	if len(p) > 2 {
+		buf.WriteByte(',')
+	}
+	buf.WriteString(`"Name":` + strconv.Quote(s.GetName()))
+	sensorFields(&buf, s, false)
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
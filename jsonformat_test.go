@@ -0,0 +1,189 @@
+package lmsensors
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validate checks a decoded JSON value against the parts of JSON Schema that [JSONSchema] uses.
+func validate(schema map[string]any, defs map[string]any, v any, at string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		return validate(defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any), defs, v, at)
+	}
+	if c, ok := schema["const"]; ok && c != v {
+		return fmt.Errorf("%s: %v isn't %v", at, v, c)
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			found = found || e == v
+		}
+		if !found {
+			return fmt.Errorf("%s: %v isn't one of %v", at, v, enum)
+		}
+	}
+	if typ, ok := schema["type"]; ok {
+		types, ok := typ.([]any)
+		if !ok {
+			types = []any{typ}
+		}
+		matched := false
+		for _, t := range types {
+			switch t {
+			case "object":
+				_, ok = v.(map[string]any)
+			case "string":
+				_, ok = v.(string)
+			case "boolean":
+				_, ok = v.(bool)
+			case "number":
+				_, ok = v.(float64)
+			case "integer":
+				f, isNum := v.(float64)
+				ok = isNum && f == math.Trunc(f)
+			case "null":
+				ok = v == nil
+			}
+			matched = matched || ok
+		}
+		if !matched {
+			return fmt.Errorf("%s: %v isn't %v", at, v, typ)
+		}
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+	for _, r := range asSlice(schema["required"]) {
+		if _, ok := obj[r.(string)]; !ok {
+			return fmt.Errorf("%s: no %s", at, r)
+		}
+	}
+	props, _ := schema["properties"].(map[string]any)
+	additional, _ := schema["additionalProperties"].(map[string]any)
+	for k, fv := range obj {
+		sub, ok := props[k].(map[string]any)
+		switch {
+		case ok:
+		case additional != nil:
+			sub = additional
+		case props != nil:
+			return fmt.Errorf("%s: %s isn't in the schema", at, k) // Allowed, but the schema should be complete for what this package produces
+		default:
+			continue
+		}
+		if err := validate(sub, defs, fv, at+"."+k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func asSlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
+
+func schemaSystem() *System {
+	max := 80.0
+	tctl := &TempSensor{baseSensor: baseSensor{Name: "Tctl", Value: 42.5, Time: time.Unix(1700000000, 0), Limits: &Limits{Max: &max, State: LimitInRange}}, TempType: Unknown, TempTypeRaw: -1}
+	fan := &FanSensor{baseSensor: baseSensor{Name: "fan2", Value: NoValue, Subfeatures: map[string]float64{"fan2_input": NoValue}, alarm: true}, State: FanUnknown}
+	return &System{Time: time.Unix(1700000000, 0), Chips: map[string]*Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Type: "k10temp", Bus: "pci", Sensors: map[string]Sensor{"Tctl": tctl}},
+		"nct6798-isa-0290": {ID: "nct6798-isa-0290", Type: "nct6798", Bus: "isa", Sensors: map[string]Sensor{
+			"fan2":      fan,
+			"intrusion": &IntrusionSensor{Name: "intrusion0", alarm: true},
+			"energy1":   &GenericSensor{baseSensor: baseSensor{Name: "energy1", Value: 12.5}, FeatureType: Energy, unit: "J"},
+			"in0":       &VoltageSensor{baseSensor{Name: "in0", Value: 1.05}},
+			"curr1":     &CurrentSensor{baseSensor{Name: "curr1", Value: 0.5}},
+		}, AdapterDevice: &AdapterDevice{Name: "SMBus", PCI: &PCIAddress{Bus: 0x14}}, Stats: CollectionStats{SubFeatures: map[string]time.Duration{"fan2_input": time.Millisecond}}},
+	}}
+}
+
+func TestJSONSchema(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal(JSONSchema, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if v := schema["properties"].(map[string]any)["Version"].(map[string]any)["const"]; v != float64(JSONVersion) {
+		t.Errorf("schema is of version %v", v)
+	}
+
+	p, err := json.Marshal(schemaSystem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v any
+	if err := json.Unmarshal(p, &v); err != nil {
+		t.Fatal(err)
+	}
+	if err := validate(schema, schema["$defs"].(map[string]any), v, "$"); err != nil {
+		t.Errorf("%v, in\n%s", err, p)
+	}
+	sensors := v.(map[string]any)["Chips"].(map[string]any)["nct6798-isa-0290"].(map[string]any)["Sensors"].(map[string]any)
+	if fan := sensors["fan2"].(map[string]any); fan["Type"] != "Fan" || fan["Unit"] != "min⁻¹" || fan["Value"] != nil {
+		t.Errorf("wrong fan: %v", fan)
+	}
+	if in := sensors["intrusion"].(map[string]any); in["Type"] != "Intrusion" || in["Alarm"] != true {
+		t.Errorf("wrong intrusion sensor: %v", in)
+	}
+
+	// And every machine in the testdata.
+	goldens, _ := filepath.Glob("testdata/machines/*.golden.json")
+	for _, g := range goldens {
+		p, err := os.ReadFile(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v any
+		if err := json.Unmarshal(p, &v); err != nil {
+			t.Fatal(err)
+		}
+		if err := validate(schema, schema["$defs"].(map[string]any), v, "$"); err != nil {
+			t.Errorf("%s: %v", g, err)
+		}
+	}
+}
+
+func TestJSONCompat(t *testing.T) {
+	sys := schemaSystem()
+	p, err := json.Marshal(JSONCompat(sys))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v struct {
+		Version *int
+		Time    time.Time
+		Chips   map[string]struct {
+			Type    string
+			Sensors map[string]json.RawMessage
+		}
+	}
+	if err := json.Unmarshal(p, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Version != nil {
+		t.Error("version 1 has a version")
+	}
+	if !v.Time.Equal(sys.Time) || v.Chips["nct6798-isa-0290"].Type != "nct6798" {
+		t.Errorf("wrong version 1 system: %s", p)
+	}
+	sensors := v.Chips["nct6798-isa-0290"].Sensors
+	if s := string(sensors["in0"]); s != `{"Name":"in0","Value":1.05}` {
+		t.Errorf("wrong version 1 sensor: %s", s)
+	}
+	if s := string(sensors["intrusion"]); s != `{"Name":"intrusion0","Beep":false}` {
+		t.Errorf("wrong version 1 intrusion sensor: %s", s)
+	}
+
+	// It's only that encoding; the rest are still version 2.
+	if p, _ := json.Marshal(&VoltageSensor{baseSensor{Name: "in0", Value: 1.032}}); !strings.Contains(string(p), `"Type":"Voltage"`) {
+		t.Errorf("version 1 leaked: %s", p)
+	}
+}
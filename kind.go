@@ -0,0 +1,88 @@
+package lmsensors
+
+import "strings"
+
+// ChipKind is the sort of hardware a chip monitors, for grouping sensors in UIs.
+//
+//go:generate stringer -type=ChipKind -trimprefix=Kind
+type ChipKind int
+
+const (
+	KindUnknown     ChipKind = iota
+	KindCPU                  // CPU package or core temperatures
+	KindGPU                  // Graphics card
+	KindMotherboard          // SuperIO chips, embedded controllers, ACPI thermal zones
+	KindDrive                // NVMe, SATA, etc drives
+	KindPSU                  // Power supplies
+	KindMemory               // DIMM temperature sensors
+	KindChassis              // Fan controllers, pumps, etc that aren't on the motherboard
+	KindBattery              // Laptop and UPS batteries
+)
+
+var kindPrefixes = map[string]ChipKind{
+	"coretemp":    KindCPU,
+	"k10temp":     KindCPU,
+	"k8temp":      KindCPU,
+	"zenpower":    KindCPU,
+	"via_cputemp": KindCPU,
+	"cpu_thermal": KindCPU,
+
+	"amdgpu":  KindGPU,
+	"radeon":  KindGPU,
+	"nouveau": KindGPU,
+	"i915":    KindGPU,
+	"xe":      KindGPU,
+
+	"acpitz":           KindMotherboard,
+	"asus_wmi_sensors": KindMotherboard,
+	"asus_ec_sensors":  KindMotherboard,
+	"gigabyte_wmi":     KindMotherboard,
+	"thinkpad":         KindMotherboard,
+	"dell_smm":         KindMotherboard,
+	"pch_cannonlake":   KindMotherboard,
+
+	"nvme":      KindDrive,
+	"drivetemp": KindDrive,
+
+	"corsairpsu": KindPSU,
+
+	"jc42":    KindMemory,
+	"spd5118": KindMemory,
+
+	"corsaircpro":           KindChassis,
+	"nzxt_smart2":           KindChassis,
+	"nzxt_kraken2":          KindChassis,
+	"nzxt_kraken3":          KindChassis,
+	"aquacomputer_d5next":   KindChassis,
+	"aquacomputer_octo":     KindChassis,
+	"aquacomputer_quadro":   KindChassis,
+	"aquacomputer_farbwerk": KindChassis,
+}
+
+// superIOPrefixes are the prefixes of families of SuperIO chips, which have many models.
+var superIOPrefixes = []string{"nct", "it87", "it86", "w83", "f71", "f81", "sch5", "dme1737"}
+
+// Kind classifies the chip by its driver prefix, and failing that its bus.
+func (c *Chip) Kind() ChipKind {
+	if k, ok := kindPrefixes[c.Type]; ok {
+		return k
+	}
+	for _, p := range superIOPrefixes {
+		if strings.HasPrefix(c.Type, p) {
+			return KindMotherboard
+		}
+	}
+	switch {
+	case strings.HasPrefix(c.Type, "BAT"):
+		return KindBattery
+	case strings.Contains(c.Type, "psu") || strings.Contains(c.Type, "pmbus"):
+		return KindPSU
+	}
+	switch {
+	case c.Bus == "isa":
+		return KindMotherboard
+	case strings.HasPrefix(c.Bus, "scsi"):
+		return KindDrive
+	}
+	return KindUnknown
+}
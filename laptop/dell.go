@@ -0,0 +1,15 @@
+package laptop
+
+import "errors"
+
+// dellSMM is dell-smm-hwmon. Its fans only have a few speeds (usually off, low and high), which it maps the duty to, and the BIOS adjusts them itself on top of anything set manually.
+// On the models it knows how to, the driver can turn the BIOS's control off, for every fan at once, through pwm1_enable.
+var dellSMM = fanDriver{full: -1, manual: 1, auto: 2, globalEnable: true}
+
+// checkGlobalEnable refuses manual control if the BIOS can't be stopped from overriding it, since the fan would then be set by whichever wrote last.
+func (f FanControl) checkGlobalEnable() error {
+	if _, err := f.dev.ReadAttribute("pwm1_enable"); err != nil {
+		return errors.New(f.Driver + ": the BIOS's fan control can't be turned off on this model, so it would override manual control")
+	}
+	return nil
+}
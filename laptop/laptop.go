@@ -0,0 +1,149 @@
+// Package laptop is a simple facade over what laptop fan tools need: the CPU and battery temperatures, the fan speeds, and control of the fans of the drivers that allow it.
+package laptop
+
+import (
+	"math"
+	"sort"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Fan is the reading of one fan.
+type Fan struct {
+	Chip  string
+	Name  string
+	RPM   float64 // [lmsensors.NoValue] if it couldn't be read
+	State lmsensors.FanState
+}
+
+// Status is the state of a laptop, as read by [Read].
+type Status struct {
+	CPUTemp     float64 // The hottest CPU temperature, or the ACPI thermal zone's if there's no CPU sensor; [lmsensors.NoValue] if there's neither
+	BatteryTemp float64 // The hottest battery's temperature, or [lmsensors.NoValue]
+	Fans        []Fan   // Sorted by chip, then name
+}
+
+// Read picks the laptop's vital signs out of a reading.
+func Read(sys *lmsensors.System) Status {
+	st := Status{CPUTemp: lmsensors.NoValue, BatteryTemp: lmsensors.NoValue}
+	zone := lmsensors.NoValue
+	for _, chip := range sys.Chips {
+		for _, s := range chip.Sensors {
+			switch s := s.(type) {
+			case *lmsensors.TempSensor:
+				switch {
+				case !s.Valid():
+				case chip.Kind() == lmsensors.KindCPU:
+					st.CPUTemp = hottest(st.CPUTemp, s.Value)
+				case chip.Kind() == lmsensors.KindBattery:
+					st.BatteryTemp = hottest(st.BatteryTemp, s.Value)
+				case chip.Type == "acpitz":
+					zone = hottest(zone, s.Value)
+				}
+			case *lmsensors.FanSensor:
+				st.Fans = append(st.Fans, Fan{chip.ID, s.Name, s.Value, s.State})
+			}
+		}
+	}
+	if math.IsNaN(st.CPUTemp) {
+		st.CPUTemp = zone
+	}
+	sort.Slice(st.Fans, func(i, j int) bool {
+		if st.Fans[i].Chip != st.Fans[j].Chip {
+			return st.Fans[i].Chip < st.Fans[j].Chip
+		}
+		return st.Fans[i].Name < st.Fans[j].Name
+	})
+	return st
+}
+
+func hottest(a, b float64) float64 {
+	if math.IsNaN(a) || b > a {
+		return b
+	}
+	return a
+}
+
+// fanDriver is how a laptop driver's fans are controlled.
+type fanDriver struct {
+	full, manual, auto int  // pwm*_enable values; full is -1 for drivers without a full speed mode, which then get manual at 255
+	globalEnable       bool // Whether pwm1_enable switches every fan on the chip between manual and auto, rather than each having its own
+}
+
+// fanDrivers are the laptop drivers whose fans can be controlled, by hwmon name.
+var fanDrivers = map[string]fanDriver{
+	"thinkpad": {full: 0, manual: 1, auto: 2}, // thinkpad_acpi; full is "disengaged", which is faster than level 7
+	"asus":     {full: 0, manual: 1, auto: 2}, // asus-nb-wmi
+	"dell_smm": dellSMM,
+}
+
+// FanControl controls one laptop fan, through its PWM output.
+type FanControl struct {
+	Driver  string // The hwmon name, eg "thinkpad"
+	Channel int    // The PWM channel, eg 1 for pwm1
+
+	dev    lmsensors.HwmonDevice
+	driver fanDriver
+}
+
+// Controls finds the fans that can be controlled, on drivers this package knows about.
+// Like all writes, the ones made through them go through the [lmsensors.AuditFunc], and aren't made in dry-run mode.
+func Controls() ([]FanControl, error) {
+	devs, err := lmsensors.HwmonDevices()
+	if err != nil {
+		return nil, err
+	}
+	var fcs []FanControl
+	for _, dev := range devs {
+		driver, ok := fanDrivers[dev.Name]
+		if !ok {
+			continue
+		}
+		chans, err := dev.PWMs()
+		if err != nil {
+			return nil, err
+		}
+		for _, ch := range chans {
+			fcs = append(fcs, FanControl{Driver: dev.Name, Channel: ch, dev: dev, driver: driver})
+		}
+	}
+	return fcs, nil
+}
+
+// enableChannel is the channel whose pwm*_enable controls the fan's mode.
+func (f FanControl) enableChannel() int {
+	if f.driver.globalEnable {
+		return 1
+	}
+	return f.Channel
+}
+
+// Auto hands the fan back to the firmware, which is how laptops boot.
+// On drivers where one switch covers every fan, eg dell_smm, it does so for all of them.
+func (f FanControl) Auto() error {
+	return f.dev.SetPWMMode(f.enableChannel(), f.driver.auto)
+}
+
+// FullSpeed runs the fan flat out.
+func (f FanControl) FullSpeed() error {
+	if f.driver.full < 0 {
+		return f.SetDuty(255)
+	}
+	return f.dev.SetPWMMode(f.enableChannel(), f.driver.full)
+}
+
+// SetDuty takes manual control of the fan, at the given duty cycle, where 255 is full speed.
+// Remember to call [FanControl.Auto] when done, or the fan stays where it's left whatever the temperature.
+// On drivers where one switch covers every fan, eg dell_smm, the others are left at whatever they were last set to.
+func (f FanControl) SetDuty(duty uint8) error {
+	if !f.driver.globalEnable {
+		return f.dev.SetPWM(f.Channel, duty)
+	}
+	if err := f.checkGlobalEnable(); err != nil {
+		return err
+	}
+	if err := f.dev.SetPWMMode(1, f.driver.manual); err != nil {
+		return err
+	}
+	return f.dev.SetPWMDuty(f.Channel, duty)
+}
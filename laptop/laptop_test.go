@@ -0,0 +1,154 @@
+package laptop
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+func TestRead(t *testing.T) {
+	defer func() { lmsensors.SysfsRoot = "/sys" }()
+	lmsensors.SysfsRoot = filepath.Join("..", "testdata", "machines", "laptop")
+	sys, err := lmsensors.GetSysfs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := Read(sys)
+	// There's no CPU chip, so it's the thermal zone.
+	if st.CPUTemp != 46 || !math.IsNaN(st.BatteryTemp) {
+		t.Errorf("wrong temperatures: %+v", st)
+	}
+	if len(st.Fans) != 1 || st.Fans[0].RPM != 2900 || st.Fans[0].State != lmsensors.FanSpinning {
+		t.Errorf("wrong fans: %+v", st.Fans)
+	}
+}
+
+func TestControls(t *testing.T) {
+	root := t.TempDir()
+	defer func() { lmsensors.SysfsRoot = "/sys" }()
+	lmsensors.SysfsRoot = root
+	dir := filepath.Join(root, "class", "hwmon", "hwmon2")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for attr, val := range map[string]string{"name": "thinkpad\n", "pwm1": "128\n", "pwm1_enable": "2\n", "fan1_input": "2900\n"} {
+		if err := os.WriteFile(filepath.Join(dir, attr), []byte(val), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fcs, err := Controls()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fcs) != 1 || fcs[0].Driver != "thinkpad" || fcs[0].Channel != 1 {
+		t.Fatalf("wrong controls: %+v", fcs)
+	}
+	read := func(attr string) string {
+		p, _ := os.ReadFile(filepath.Join(dir, attr))
+		return string(p)
+	}
+	if err := fcs[0].FullSpeed(); err != nil || read("pwm1_enable") != "0" {
+		t.Errorf("full speed: %v %q", err, read("pwm1_enable"))
+	}
+	if err := fcs[0].SetDuty(200); err != nil || read("pwm1_enable") != "1" || read("pwm1") != "200" {
+		t.Errorf("manual: %v %q %q", err, read("pwm1_enable"), read("pwm1"))
+	}
+	if err := fcs[0].Auto(); err != nil || read("pwm1_enable") != "2" {
+		t.Errorf("auto: %v %q", err, read("pwm1_enable"))
+	}
+}
+
+func TestThinkPad(t *testing.T) {
+	root := t.TempDir()
+	defer func() { lmsensors.SysfsRoot = "/sys" }()
+	lmsensors.SysfsRoot = root
+	dir := filepath.Join(root, "class", "hwmon", "hwmon2")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for attr, val := range map[string]string{"name": "thinkpad\n", "pwm1": "128\n", "pwm1_enable": "2\n"} {
+		if err := os.WriteFile(filepath.Join(dir, attr), []byte(val), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func(p string) { thinkpadFanPath = p }(thinkpadFanPath)
+	thinkpadFanPath = filepath.Join(root, "fan")
+	status := "status:\t\tenabled\nspeed:\t\t2900\nlevel:\t\tauto\n"
+	if err := os.WriteFile(thinkpadFanPath, []byte(status), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := ReadThinkPadFan()
+	if err != nil || !st.Enabled || st.RPM != 2900 || st.Level != "auto" || st.Controllable {
+		t.Errorf("wrong status: %+v %v", st, err)
+	}
+	fcs, err := Controls()
+	if err != nil || len(fcs) != 1 {
+		t.Fatalf("wrong controls: %+v %v", fcs, err)
+	}
+	if err := fcs[0].SetLevel(3); err == nil {
+		t.Error("set level with fan control disabled")
+	}
+
+	status += "commands:\tlevel <level> (<level> is 0-7, auto, disengaged, full-speed)\ncommands:\twatchdog <timeout> (<timeout> is 0 (off), 1-120 (seconds))\n"
+	if err := os.WriteFile(thinkpadFanPath, []byte(status), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fcs[0].SetLevel(8); err == nil {
+		t.Error("set out of range level")
+	}
+	if err := fcs[0].SetLevel(3); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := os.ReadFile(filepath.Join(dir, "pwm1")); string(p) != "109" {
+		t.Errorf("wrong duty for level 3: %q", p)
+	}
+	if err := fcs[0].Watchdog(30 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := os.ReadFile(thinkpadFanPath); string(p) != "watchdog 30" {
+		t.Errorf("wrong watchdog command: %q", p)
+	}
+}
+
+func TestDellSMM(t *testing.T) {
+	root := t.TempDir()
+	defer func() { lmsensors.SysfsRoot = "/sys" }()
+	lmsensors.SysfsRoot = root
+	dir := filepath.Join(root, "class", "hwmon", "hwmon3")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for attr, val := range map[string]string{"name": "dell_smm\n", "pwm1": "128\n", "pwm2": "128\n"} {
+		if err := os.WriteFile(filepath.Join(dir, attr), []byte(val), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(attr string) string {
+		p, _ := os.ReadFile(filepath.Join(dir, attr))
+		return string(p)
+	}
+
+	fcs, err := Controls()
+	if err != nil || len(fcs) != 2 {
+		t.Fatalf("wrong controls: %+v %v", fcs, err)
+	}
+	if err := fcs[1].SetDuty(255); err == nil {
+		t.Error("took control on a model where the BIOS can't be turned off")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "pwm1_enable"), []byte("2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fcs[1].FullSpeed(); err != nil || read("pwm1_enable") != "1" || read("pwm2") != "255" {
+		t.Errorf("full speed: %v %q %q", err, read("pwm1_enable"), read("pwm2"))
+	}
+	if err := fcs[1].Auto(); err != nil || read("pwm1_enable") != "2" {
+		t.Errorf("auto: %v %q", err, read("pwm1_enable"))
+	}
+}
@@ -0,0 +1,97 @@
+package laptop
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// thinkpadFanPath is thinkpad_acpi's procfs interface to the fan.
+var thinkpadFanPath = "/proc/acpi/ibm/fan"
+
+// ThinkPadFan is the state of a ThinkPad's fan, as thinkpad_acpi reports it.
+type ThinkPadFan struct {
+	Enabled      bool
+	RPM          int
+	Level        string // "auto", "full-speed", "disengaged", or "0" to "7"
+	Controllable bool   // Whether thinkpad_acpi was loaded with fan_control=1, without which the fan can only be read
+}
+
+// ReadThinkPadFan reads /proc/acpi/ibm/fan.
+func ReadThinkPadFan() (ThinkPadFan, error) {
+	f, err := os.Open(thinkpadFanPath)
+	if err != nil {
+		return ThinkPadFan{}, err
+	}
+	defer f.Close()
+	var st ThinkPadFan
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		key, val, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+		val = strings.TrimSpace(val)
+		switch strings.TrimSpace(key) {
+		case "status":
+			st.Enabled = val == "enabled"
+		case "speed":
+			st.RPM, _ = strconv.Atoi(val)
+		case "level":
+			st.Level = val
+		case "commands":
+			// Only listed when control is allowed
+			st.Controllable = true
+		}
+	}
+	return st, sc.Err()
+}
+
+// SetLevel sets a ThinkPad fan to one of the firmware's fixed levels, from 0 (off) to 7 (the fastest the firmware regulates; see [FanControl.FullSpeed] for faster).
+// thinkpad_acpi maps the PWM duty to these levels; this picks the duty that maps exactly, and checks that fan control is allowed first, see [ThinkPadFan].
+func (f FanControl) SetLevel(level int) error {
+	if f.Driver != "thinkpad" {
+		return fmt.Errorf("%s: fan levels: %w", f.Driver, errors.ErrUnsupported)
+	}
+	if level < 0 || level > 7 {
+		return fmt.Errorf("thinkpad fan level %d out of range 0-7", level)
+	}
+	if err := checkThinkPadControl(); err != nil {
+		return err
+	}
+	return f.SetDuty(uint8(level * 255 / 7))
+}
+
+// Watchdog arms the ThinkPad firmware's fan watchdog, which puts the fan back to auto if it's not set again within d, so that a crashed fan controller doesn't leave it off.
+// d is rounded up to seconds, and can be at most 120s; 0 disarms it.
+func (f FanControl) Watchdog(d time.Duration) error {
+	if f.Driver != "thinkpad" {
+		return fmt.Errorf("%s: fan watchdog: %w", f.Driver, errors.ErrUnsupported)
+	}
+	secs := int((d + time.Second - 1) / time.Second)
+	if secs < 0 || secs > 120 {
+		return fmt.Errorf("thinkpad fan watchdog %s out of range 0-120s", d)
+	}
+	if err := checkThinkPadControl(); err != nil {
+		return err
+	}
+	ev := lmsensors.WriteEvent{Chip: "thinkpad", Prefix: "thinkpad", Attr: "watchdog", Path: thinkpadFanPath, New: float64(secs)}
+	return lmsensors.WriteAudited(ev, func() error {
+		return os.WriteFile(thinkpadFanPath, []byte("watchdog "+strconv.Itoa(secs)), 0)
+	})
+}
+
+// checkThinkPadControl fails if thinkpad_acpi says it won't accept fan commands; if procfs can't be read, the hwmon write will tell.
+func checkThinkPadControl() error {
+	st, err := ReadThinkPadFan()
+	if err == nil && !st.Controllable {
+		return errors.New("thinkpad_acpi fan control is disabled; load it with fan_control=1")
+	}
+	return nil
+}
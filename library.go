@@ -0,0 +1,155 @@
+package lmsensors
+
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <sensors/sensors.h>
+// #cgo LDFLAGS: -lsensors
+import "C"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNotInitialised is returned by a [Library] that's used before its [Library.Init], or after its [Library.Cleanup].
+var ErrNotInitialised = errors.New("libsensors is not initialised")
+
+// Library is an instance of libsensors with its own config, so that independent components in one process can use different ones.
+// The package-level functions, like [Init] and [Get], use a default Library.
+//
+// libsensors keeps its state in globals, so only one config can actually be loaded at once.
+// Each Library reloads its own before it's used if another Library has been used since, and their uses are serialised by a process-wide lock.
+// Reloading invalidates any [ChipPtr]s and [Feature]s got from the other Library, so only use those inside [Library.Do].
+type Library struct {
+	config      string // "" for the default /etc/sensors3.conf and /etc/sensors.d
+	initialised bool
+}
+
+var (
+	libMu          sync.Mutex
+	loaded         *Library // Whose config libsensors has loaded, if anyone's
+	defaultLibrary = &Library{}
+)
+
+// NewLibrary makes a [Library] that reads its config from path, or from the default /etc/sensors3.conf and /etc/sensors.d if it's "".
+// Like the package, it must be [Library.Init]ialised before it's used.
+func NewLibrary(path string) *Library {
+	return &Library{config: path}
+}
+
+// Init loads the Library's config. Calling it again, or after [Library.Cleanup], reloads the config from disk.
+func (l *Library) Init() error {
+	libMu.Lock()
+	defer libMu.Unlock()
+	return l.init(l.config)
+}
+
+func (l *Library) init(config string) error {
+	forgetMachine()
+	l.unload()
+	l.config = config
+	if err := l.load(); err != nil {
+		return err
+	}
+	l.initialised = true
+	return nil
+}
+
+// Cleanup releases the Library. It can't be used again until the next [Library.Init].
+func (l *Library) Cleanup() {
+	libMu.Lock()
+	defer libMu.Unlock()
+	l.initialised = false
+	l.unload()
+}
+
+// load makes libsensors use the Library's config, unloading anyone else's. libMu must be held.
+func (l *Library) load() error {
+	if loaded == l {
+		return nil
+	}
+	if loaded != nil {
+		loaded.unload()
+	}
+	if l.config == "" {
+		if cerr := C.sensors_init(nil); cerr != 0 {
+			return fmt.Errorf("can't configure libsensors: sensors_init() return code: %d", cerr)
+		}
+		logger.Debug("libsensors initialised")
+	} else {
+		cpath := C.CString(l.config)
+		defer free(cpath)
+		mode := C.CString("r")
+		defer free(mode)
+		f := C.fopen(cpath, mode)
+		if f == nil {
+			return fmt.Errorf("can't open libsensors config %s", l.config)
+		}
+		defer C.fclose(f)
+		if cerr := C.sensors_init(f); cerr != 0 {
+			return fmt.Errorf("can't configure libsensors from %s: sensors_init() return code: %d", l.config, cerr)
+		}
+		logger.Debug("libsensors initialised", "config", l.config)
+	}
+	loaded = l
+	return nil
+}
+
+// unload releases libsensors, if it has the Library's config loaded. libMu must be held.
+func (l *Library) unload() {
+	if loaded != l {
+		return
+	}
+	C.sensors_cleanup()
+	loaded = nil
+	logger.Debug("libsensors cleaned up")
+}
+
+// Do calls fn with the Library's config loaded, and no other Library able to load theirs.
+// It's for using [Chips], [GetChip] and [Feature]s, which don't take the lock themselves; fn mustn't call any of the Library's other methods.
+func (l *Library) Do(fn func() error) error {
+	libMu.Lock()
+	defer libMu.Unlock()
+	if !l.initialised {
+		if l != defaultLibrary {
+			return ErrNotInitialised
+		}
+		// Compatibility: the package-level functions have always done nothing much before Init, rather than failing.
+	} else if err := l.load(); err != nil {
+		return err
+	}
+	return fn()
+}
+
+// Get is [Get], with the Library's config.
+func (l *Library) Get(opts ...Option) (*System, error) {
+	return l.GetContext(context.Background(), opts...)
+}
+
+// GetContext is [GetContext], with the Library's config.
+func (l *Library) GetContext(ctx context.Context, opts ...Option) (*System, error) {
+	var o getOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return l.get(ctx, o)
+}
+
+// GetRaw is [GetRaw], with the Library's config.
+func (l *Library) GetRaw() (*System, error) {
+	return l.get(context.Background(), getOptions{raw: true})
+}
+
+func (l *Library) get(ctx context.Context, opts getOptions) (*System, error) {
+	var sys *System
+	var err error
+	if lerr := l.Do(func() error {
+		sys, err = get(ctx, opts)
+		return nil
+	}); lerr != nil {
+		return &System{Chips: map[string]*Chip{}}, lerr
+	}
+	return sys, err
+}
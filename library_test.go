@@ -0,0 +1,56 @@
+package lmsensors
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLibrary(t *testing.T) {
+	dir := t.TempDir()
+	var libs []*Library
+	for _, name := range []string{"a.conf", "b.conf"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("# "+name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		libs = append(libs, NewLibrary(path))
+	}
+	a, b := libs[0], libs[1]
+
+	if _, err := a.Get(); !errors.Is(err, ErrNotInitialised) {
+		t.Fatalf("expected ErrNotInitialised before Init, got %v", err)
+	}
+	for _, l := range libs {
+		if err := l.Init(); err != nil {
+			t.Fatal(err)
+		}
+		defer l.Cleanup()
+	}
+	for _, l := range []*Library{a, b, a} {
+		if _, err := l.Get(); err != nil && !errors.Is(err, ErrSensorAny) {
+			t.Fatal(err)
+		}
+		if loaded != l {
+			t.Errorf("expected %s to be loaded", l.config)
+		}
+	}
+
+	// The default library reloads its own config too
+	if err := Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer Cleanup()
+	if _, err := Get(); err != nil && !errors.Is(err, ErrSensorAny) {
+		t.Fatal(err)
+	}
+	if loaded != defaultLibrary {
+		t.Error("expected the default library to be loaded")
+	}
+
+	b.Cleanup()
+	if _, err := b.Get(); !errors.Is(err, ErrNotInitialised) {
+		t.Errorf("expected ErrNotInitialised after Cleanup, got %v", err)
+	}
+}
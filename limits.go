@@ -0,0 +1,101 @@
+package lmsensors
+
+import (
+	"math"
+
+	sf "github.com/mt-inside/go-lmsensors/subfeature"
+)
+
+// LimitState is how a reading compares with its sensor's limits.
+//
+//go:generate stringer -type=LimitState -trimprefix=Limit
+type LimitState int
+
+const (
+	LimitUnknown   LimitState = iota // No reading, or no limits to compare it with
+	LimitInRange                     // Within all the limits it has
+	LimitBelowMin                    // Below its min
+	LimitAboveMax                    // Above its max, but not its crit
+	LimitAboveCrit                   // Above its crit
+)
+
+// Limits are a sensor's hardware limits, and how its reading compares with them, see [WithLimitCheck].
+type Limits struct {
+	Min   *float64 `json:",omitempty"`
+	Max   *float64 `json:",omitempty"`
+	Crit  *float64 `json:",omitempty"`
+	State LimitState
+}
+
+// limitSubFeatures are the min, max and crit subfeatures of each type of feature, where it has them.
+var limitSubFeatures = map[LmSensorType][]sf.SubFeature{
+	Voltage:     {sf.IN_MIN, sf.IN_MAX, sf.IN_CRIT},
+	Fan:         {sf.FAN_MIN, sf.FAN_MAX},
+	Temperature: {sf.TEMP_MIN, sf.TEMP_MAX, sf.TEMP_CRIT},
+	Power:       {sf.POWER_MIN, sf.POWER_MAX, sf.POWER_CRIT},
+	Current:     {sf.CURR_MIN, sf.CURR_MAX, sf.CURR_CRIT},
+}
+
+// WithLimitCheck also reads each sensor's min, max and crit limits, and compares its reading with them, see [LimitsOf].
+// The comparison is made here rather than trusting the chip's alarm flags, as cheap Super I/O chips often have broken alarm registers.
+func WithLimitCheck() Option {
+	return func(o *getOptions) {
+		o.limits = true
+	}
+}
+
+// LimitsOf returns a sensor's limits, and how its reading compares with them, if it was read [WithLimitCheck] and has any; otherwise nil.
+func LimitsOf(s Sensor) *Limits {
+	if b, ok := s.(interface{ base() *baseSensor }); ok {
+		return b.base().Limits
+	}
+	return nil
+}
+
+// readLimits reads the limits of a sensor of type typ with get, and compares its reading with them; it's nil if there aren't any.
+func readLimits(typ LmSensorType, val float64, get func(sf.SubFeature) (float64, error)) *Limits {
+	var l Limits
+	dst := []**float64{&l.Min, &l.Max, &l.Crit}
+	for i, sub := range limitSubFeatures[typ] {
+		v, err := get(sub)
+		if err != nil || math.IsNaN(v) {
+			continue
+		}
+		*dst[i] = &v
+	}
+	if l.Min == nil && l.Max == nil && l.Crit == nil {
+		return nil
+	}
+	l.State = compareLimits(val, l)
+	return &l
+}
+
+func compareLimits(val float64, l Limits) LimitState {
+	switch {
+	case math.IsNaN(val):
+		return LimitUnknown
+	case l.Crit != nil && val > *l.Crit:
+		return LimitAboveCrit
+	case l.Max != nil && val > *l.Max:
+		return LimitAboveMax
+	case l.Min != nil && val < *l.Min:
+		return LimitBelowMin
+	default:
+		return LimitInRange
+	}
+}
+
+// clone deep-copies the limits, for [Chip.Clone].
+func (l *Limits) clone() *Limits {
+	if l == nil {
+		return nil
+	}
+	c := *l
+	for _, p := range []**float64{&c.Min, &c.Max, &c.Crit} {
+		if *p != nil {
+			v := **p
+			*p = &v
+		}
+	}
+	return &c
+}
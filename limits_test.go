@@ -0,0 +1,65 @@
+package lmsensors
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	sf "github.com/mt-inside/go-lmsensors/subfeature"
+)
+
+func TestReadLimits(t *testing.T) {
+	subs := map[sf.SubFeature]float64{sf.TEMP_MAX: 80, sf.TEMP_CRIT: 100}
+	get := func(sub sf.SubFeature) (float64, error) {
+		v, ok := subs[sub]
+		if !ok {
+			return 0, sub
+		}
+		return v, nil
+	}
+	for _, tc := range []struct {
+		val  float64
+		want LimitState
+	}{
+		{40, LimitInRange},
+		{80, LimitInRange},
+		{85, LimitAboveMax},
+		{105, LimitAboveCrit},
+		{NoValue, LimitUnknown},
+	} {
+		l := readLimits(Temperature, tc.val, get)
+		if l == nil || l.Min != nil || *l.Max != 80 || *l.Crit != 100 || l.State != tc.want {
+			t.Errorf("%v: got %+v, want %s", tc.val, l, tc.want)
+		}
+	}
+
+	subs = map[sf.SubFeature]float64{sf.IN_MIN: 11.4, sf.IN_MAX: 12.6}
+	if l := readLimits(Voltage, 10.9, get); l == nil || l.State != LimitBelowMin {
+		t.Errorf("undervoltage: got %+v", l)
+	}
+	subs = map[sf.SubFeature]float64{sf.IN_MIN: math.NaN()}
+	if l := readLimits(Voltage, 12, get); l != nil {
+		t.Errorf("expected no limits, got %+v", l)
+	}
+}
+
+func TestCloneLimits(t *testing.T) {
+	max := 80.0
+	ts := &TempSensor{baseSensor: baseSensor{Name: "Tctl", Value: 40, Limits: &Limits{Max: &max, State: LimitInRange}}}
+	c := (&Chip{Sensors: map[string]Sensor{"Tctl": ts}}).Clone()
+	l := LimitsOf(c.Sensors["Tctl"])
+	*l.Max = 90
+	if max != 80 || LimitsOf(ts).Max == l.Max {
+		t.Error("Clone aliased the limits")
+	}
+}
+
+func TestSnapshotLimits(t *testing.T) {
+	max := 80.0
+	ts := &TempSensor{baseSensor: baseSensor{Name: "Tctl", Value: 40, Limits: &Limits{Max: &max, State: LimitInRange}}}
+	snap := NewSnapshot(&System{Chips: map[string]*Chip{"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]Sensor{"Tctl": ts}}}}, time.Now())
+	r, _ := snap.Reading("k10temp-pci-00c3", "Tctl")
+	if r.Max != 80 || !math.IsNaN(r.Min) || !math.IsNaN(r.Crit) || r.Limit != LimitInRange {
+		t.Errorf("wrong limits: %+v", r)
+	}
+}
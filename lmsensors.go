@@ -6,19 +6,23 @@
 
 package lmsensors
 
+// #include <stdio.h>
 // #include <stdlib.h>
 // #include <sensors/sensors.h>
 // #cgo LDFLAGS: -lsensors
 import "C"
 
 import (
+	"context"
 	"fmt"
+	"maps"
 	"math"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 	"unsafe"
 
 	"github.com/mt-inside/go-lmsensors/bus"
@@ -51,6 +55,11 @@ const (
 // System contains all the chips, and all their sensors, in the system
 type System struct {
 	Chips map[string]*Chip
+	Time  time.Time `json:",omitzero"` // When the sweep started; each sensor has its own time too, see [TimeOf]
+
+	Stats CollectionStats
+
+	Throttling *Throttling `json:",omitempty"` // The CPUs' thermal throttling underway, see [WithThrottleDetection]
 }
 
 // Chip represents a hardware monitoring chip, which has one or more sensors attached, possibly of different types.
@@ -60,19 +69,43 @@ type Chip struct {
 	Bus     string
 	Address string
 	Adapter string
+	Path    string
+
+	AdapterDevice *AdapterDevice `json:",omitempty"` // For i2c chips, when it can be found
+
+	BoardVendor string // From DMI; the same for all chips in a system
+	BoardName   string // From DMI; the same for all chips in a system
+	ACPIPath    string // ACPI namespace path of the device, eg \_TZ_.TZ00, if it has one
 
 	Sensors map[string]Sensor
+
+	Stats CollectionStats
 }
 
 func (c *Chip) String() string {
 	return fmt.Sprintf("%s at %s:%s", c.Type, c.Bus, c.Address)
 }
 
+// DisplayName is a more meaningful name for the chip than its ID, for chips like "acpitz-acpi-0" that are otherwise opaque.
+func (c *Chip) DisplayName() string {
+	var ret strings.Builder
+	ret.WriteString(c.Type)
+	if c.ACPIPath != "" {
+		fmt.Fprintf(&ret, " (%s)", c.ACPIPath)
+	}
+	if board := strings.TrimSpace(c.BoardVendor + " " + c.BoardName); board != "" {
+		fmt.Fprintf(&ret, " on %s", board)
+	}
+	return ret.String()
+}
+
 // Sensor represents one monitoring sensor, its type (temperature, voltage, etc), and its reading.
 type Sensor interface {
 	fmt.Stringer
 
 	GetName() string
+	Type() LmSensorType
+	Reading() float64 // The value at full precision, as opposed to Rendered()
 	Rendered() string
 	Unit() string
 	Alarm() bool
@@ -81,12 +114,40 @@ type Sensor interface {
 type baseSensor struct {
 	Name  string
 	Value float64
+	Time  time.Time `json:",omitzero"` // When the value was read, which can be well after [System.Time] if earlier chips were slow
+
+	Subfeatures map[string]float64 `json:",omitempty"` // Every readable subfeature by sysfs attribute, eg temp1_max; only with [WithSubfeatures]
+	Limits      *Limits            `json:",omitempty"` // Only with [WithLimitCheck]
+
+	alarm   bool
+	beep    bool
+	beepSub sf.SubFeature // The type's beep subfeature, if it has one
+	hasBeep bool
+	feature featureRef // For SetBeep
 }
 
 func (s *baseSensor) GetName() string {
 	return s.Name
 }
 
+func (s *baseSensor) Reading() float64 {
+	return s.Value
+}
+
+// Valid is whether the sensor has a reading, as opposed to a failed or implausible one, see [NoValue].
+func (s *baseSensor) Valid() bool {
+	return !math.IsNaN(s.Value)
+}
+
+// Alarm is the hardware's alarm flag for the sensor, eg temp1_alarm, or false if it doesn't have one.
+func (s *baseSensor) Alarm() bool {
+	return s.alarm
+}
+
+func (s *baseSensor) base() *baseSensor {
+	return s
+}
+
 // LmTempType is the type of temperature sensor (eg Thermistor or Diode)
 //
 //go:generate stringer -type=LmTempType
@@ -105,29 +166,61 @@ const (
 	Unknown LmTempType = math.MaxInt32
 )
 
+// Known is whether the type is one defined by the sysfs ABI, as opposed to an unreadable or vendor-specific one.
+func (t LmTempType) Known() bool {
+	return t >= Disabled && t <= IntelPECI
+}
+
+// RegisterTempTypes maps the raw temperature types reported by a driver, for drivers whose numbering doesn't follow the sysfs ABI.
+// prefix is the chip prefix, eg "it87". Unmapped values are passed through.
+// It sets the TempTypes of the driver's [Quirk]. Sensors already read keep the types they were given.
+func RegisterTempTypes(prefix string, types map[int]LmTempType) {
+	types = maps.Clone(types)
+	updateQuirk(prefix, func(q *Quirk) { q.TempTypes = types })
+}
+
+// parseTempType interprets a temp*_type value, which should be a small non-negative integer, but comes through libsensors as a float.
+func parseTempType(prefix string, value float64) (LmTempType, int) {
+	if math.IsNaN(value) || value < 0 || value > math.MaxInt16 || value != math.Trunc(value) {
+		return Unknown, -1
+	}
+	raw := int(value)
+	if t, ok := quirkOf(prefix).TempTypes[raw]; ok {
+		return t, raw
+	}
+	return LmTempType(raw), raw
+}
+
 type TempSensor struct {
 	baseSensor
 
-	TempType LmTempType
+	TempType    LmTempType
+	TempTypeRaw int // As reported by the driver, before any mapping, or -1 if it didn't report one
+
+	Crit      float64 // The hardware's critical limit, or 0 if it doesn't have one
+	Emergency float64 // The hardware's emergency limit, above crit, or 0 if it doesn't have one
 }
 
 func (s *TempSensor) Rendered() string {
-	return strconv.FormatFloat(s.Value, 'f', 0, 64)
+	return formatValue(s.Value, decimalsOf(Temperature))
 }
 
 func (s *TempSensor) Unit() string {
 	return "°C"
 }
 
-func (s *TempSensor) Alarm() bool {
-	return false
+func (s *TempSensor) Type() LmSensorType {
+	return Temperature
 }
 
 func (s *TempSensor) String() string {
 	var ret strings.Builder
-	fmt.Fprintf(&ret, "%s: %s%s", s.Name, s.Rendered(), s.Unit())
-	if s.TempType != Unknown {
+	fmt.Fprintf(&ret, "%s: %s", s.Name, render(s))
+	switch {
+	case s.TempType.Known():
 		fmt.Fprintf(&ret, " (%s)", s.TempType)
+	case s.TempType != Unknown:
+		fmt.Fprintf(&ret, " (type %d)", s.TempType)
 	}
 	return ret.String()
 }
@@ -137,39 +230,44 @@ type VoltageSensor struct {
 }
 
 func (s *VoltageSensor) Rendered() string {
-	return strconv.FormatFloat(s.Value, 'f', 2, 64)
+	return formatValue(s.Value, decimalsOf(Voltage))
 }
 
 func (s *VoltageSensor) Unit() string {
 	return "V"
 }
 
-func (s *VoltageSensor) Alarm() bool {
-	return false
+func (s *VoltageSensor) Type() LmSensorType {
+	return Voltage
 }
 
 func (s *VoltageSensor) String() string {
-	return fmt.Sprintf("%s: %s%s", s.Name, s.Rendered(), s.Unit())
+	return fmt.Sprintf("%s: %s", s.Name, render(s))
 }
 
 type FanSensor struct {
 	baseSensor
+
+	Min   float64  // The hardware's minimum speed, or 0 if it doesn't have one
+	State FanState // See [FanState]; it uses the duty of the PWM output with the same number, when there is one
+
+	fault bool
 }
 
 func (s *FanSensor) Rendered() string {
-	return strconv.FormatFloat(s.Value, 'f', 0, 64)
+	return formatValue(s.Value, decimalsOf(Fan))
 }
 
 func (s *FanSensor) Unit() string {
 	return "min⁻¹"
 }
 
-func (s *FanSensor) Alarm() bool {
-	return false
+func (s *FanSensor) Type() LmSensorType {
+	return Fan
 }
 
 func (s *FanSensor) String() string {
-	return fmt.Sprintf("%s: %s%s", s.Name, s.Rendered(), s.Unit())
+	return fmt.Sprintf("%s: %s", s.Name, render(s))
 }
 
 type CurrentSensor struct {
@@ -177,32 +275,57 @@ type CurrentSensor struct {
 }
 
 func (s *CurrentSensor) Rendered() string {
-	return strconv.FormatFloat(s.Value, 'f', 2, 64)
+	return formatValue(s.Value, decimalsOf(Current))
 }
 
 func (s *CurrentSensor) Unit() string {
 	return "A"
 }
 
-func (s *CurrentSensor) Alarm() bool {
-	return false
+func (s *CurrentSensor) Type() LmSensorType {
+	return Current
 }
 
 func (s *CurrentSensor) String() string {
-	return fmt.Sprintf("%s: %s%s", s.Name, s.Rendered(), s.Unit())
+	return fmt.Sprintf("%s: %s", s.Name, render(s))
 }
 
 type IntrusionSensor struct {
 	Name  string
 	Beep  bool
 	alarm bool
+	Time  time.Time `json:",omitzero"`
+
+	failed bool // The read failed, so alarm means nothing
+
+	Subfeatures map[string]float64 `json:",omitempty"` // Only with [WithSubfeatures]
+
+	feature featureRef // For Clear and SetBeep
 }
 
 func (s *IntrusionSensor) GetName() string {
 	return s.Name
 }
 
+func (s *IntrusionSensor) Reading() float64 {
+	if s.failed {
+		return NoValue
+	}
+	if s.alarm {
+		return 1
+	}
+	return 0
+}
+
+// Valid is whether the alarm was read; one that couldn't be isn't in alarm.
+func (s *IntrusionSensor) Valid() bool {
+	return !s.failed
+}
+
 func (s *IntrusionSensor) Rendered() string {
+	if s.failed {
+		return "N/A"
+	}
 	return strconv.FormatBool(s.Beep)
 }
 
@@ -210,14 +333,21 @@ func (s *IntrusionSensor) Unit() string {
 	return ""
 }
 
+func (s *IntrusionSensor) Type() LmSensorType {
+	return Intrusion
+}
+
 func (s *IntrusionSensor) Alarm() bool {
 	return s.alarm
 }
 
 func (s *IntrusionSensor) String() string {
-	return fmt.Sprintf("%s: %s", s.Name, s.Rendered())
+	return fmt.Sprintf("%s: %s", s.Name, render(s))
 }
 
+// UnimplementedSensor is no longer returned; unknown types of feature are read as a [GenericSensor].
+//
+// Deprecated: use [GenericSensor].
 type UnimplementedSensor struct {
 	Feature
 }
@@ -226,6 +356,10 @@ func (s *UnimplementedSensor) GetName() string {
 	return s.Name()
 }
 
+func (s *UnimplementedSensor) Reading() float64 {
+	return 0
+}
+
 func (s *UnimplementedSensor) Rendered() string {
 	return "0.00"
 }
@@ -243,34 +377,92 @@ func (s *UnimplementedSensor) String() string {
 }
 
 // Init initialises the underlying lmsensors library, eg loading its database of sensor names and curves.
+// It, and the rest of the package-level functions, use a default [Library]; use [NewLibrary] for another config alongside it.
 func Init() error {
-	cerr := C.sensors_init(nil)
-	if cerr != 0 {
-		return fmt.Errorf("can't configure libsensors: sensors_init() return code: %d", cerr)
-	}
+	libMu.Lock()
+	defer libMu.Unlock()
+	return defaultLibrary.init("")
+}
 
-	return nil
+// InitFile is [Init], but with the config read from path rather than the default /etc/sensors3.conf and /etc/sensors.d.
+func InitFile(path string) error {
+	libMu.Lock()
+	defer libMu.Unlock()
+	return defaultLibrary.init(path)
 }
 
 // Cleanup release the memory allocted for underlying lmsensors library. You can't access anything after this, until the next [Init] call!
 // You may call Cleanup then call [Init] again in order to reload a new config file from disk.
 func Cleanup() {
-	C.sensors_cleanup()
+	defaultLibrary.Cleanup()
 }
 
 // Get fetches all the chips, all their sensors, and all their values.
 // Get returns an error whenever there are any sensors failed to read, while other sensors value would be available in [System].
-func Get() (*System, error) {
-	sys := &System{Chips: make(map[string]*Chip)}
-	return sys, collectError(func(yield func(string, error) bool) {
+func Get(opts ...Option) (*System, error) {
+	return defaultLibrary.Get(opts...)
+}
+
+// GetContext is [Get], with spans recorded in the trace in ctx, if a [Tracer] has been set.
+func GetContext(ctx context.Context, opts ...Option) (*System, error) {
+	return defaultLibrary.GetContext(ctx, opts...)
+}
+
+// GetRaw is [Get], but with the values read straight from sysfs, bypassing any compute statements in the libsensors config.
+// It's the equivalent of `sensors -u` without the config applied, for debugging wrong scaling.
+func GetRaw() (*System, error) {
+	return defaultLibrary.GetRaw()
+}
+
+// getOptions are the options with which chips are read.
+type getOptions struct {
+	raw             bool
+	sysfsExtras     bool
+	subfeatures     bool
+	synthesizePower bool
+	limits          bool
+	only            func(chip string) bool // If set, only the chips it's true for are read
+}
+
+// Option changes what [Get] reads.
+type Option func(*getOptions)
+
+// WithSysfsAttributes also reads the numeric sysfs attributes of each chip that libsensors ignores, eg fan curves, as [GenericSensor]s named after the attribute.
+func WithSysfsAttributes() Option {
+	return func(o *getOptions) {
+		o.sysfsExtras = true
+	}
+}
+
+// WithSubfeatures also reads every subfeature of each feature, eg its limits and alarms as well as its input, like `sensors -u`.
+// They're in each sensor's Subfeatures, see [SubfeaturesOf].
+func WithSubfeatures() Option {
+	return func(o *getOptions) {
+		o.subfeatures = true
+	}
+}
+
+func get(ctx context.Context, opts getOptions) (*System, error) {
+	ctx, span := tracer.Start(ctx, "lmsensors.Get")
+	defer span.End()
+	start := time.Now()
+	sys := &System{Chips: make(map[string]*Chip), Time: start}
+	err := collectError(func(yield func(string, error) bool) {
 		for _, chipptr := range Chips {
-			chip, err := chipptr.Chip()
+			if opts.only != nil && !opts.only(chipptr.Name()) {
+				continue
+			}
+			chip, err := chipptr.chip(ctx, opts)
 			sys.Chips[chip.ID] = &chip
 			if err != nil && !yield("chip="+chip.ID, err) {
 				return
 			}
 		}
 	})
+	sys.Stats.Duration = time.Since(start)
+	span.SetAttribute("lmsensors.chips", len(sys.Chips))
+	span.SetAttribute("lmsensors.errors", errCount(err))
+	return sys, err
 }
 
 type ChipPtr struct {
@@ -322,31 +514,89 @@ func (chip ChipPtr) Adapter() string {
 
 // Chip will return an error if any of its sensors failed to read. However, the returned [Chip] struct is still valid in such case.
 func (chip ChipPtr) Chip() (Chip, error) {
+	return chip.chip(context.Background(), getOptions{})
+}
+
+func (chip ChipPtr) chip(ctx context.Context, opts getOptions) (Chip, error) {
+	_, span := tracer.Start(ctx, "lmsensors.Chip")
+	defer span.End()
+	start := time.Now()
 	ch := Chip{
 		ID:      chip.Name(),
 		Type:    chip.Prefix(),
 		Bus:     chip.Bus(),
 		Address: chip.Addr(),
 		Adapter: chip.Adapter(),
+		Path:    chip.Path(),
 		Sensors: make(map[string]Sensor),
+		Stats:   CollectionStats{SubFeatures: make(map[string]time.Duration)},
+	}
+	ch.BoardVendor, ch.BoardName = dmiBoard()
+	ch.ACPIPath = acpiPath(ch.Path)
+	if chip.ptr.bus._type == C.SENSORS_BUS_TYPE_I2C {
+		ch.AdapterDevice = adapterDevice(int(chip.ptr.bus.nr))
 	}
-	return ch, collectError(func(yield func(string, error) bool) {
+	span.SetAttribute("lmsensors.chip", ch.ID)
+	err := collectError(func(yield func(string, error) bool) {
 		for _, feat := range chip.Features {
+			feat.stats = &ch.Stats
+			feat.raw = opts.raw
 			reading, err := feat.Sensor()
 			name := feat.Label()
+			if reading != nil && opts.subfeatures {
+				if subs := subfeaturesOf(reading); subs != nil {
+					*subs = feat.readSubfeatures()
+				}
+			}
+			switch s := reading.(type) {
+			case *FanSensor:
+				s.setDuty(readFanDuty(ch.Path, feat.Name()))
+			case *IntrusionSensor:
+				s.feature = featureRef{ch.ID, ch.Type, ch.Path, feat.Name()}
+			}
+			if b, ok := reading.(interface{ base() *baseSensor }); ok {
+				b.base().feature = featureRef{ch.ID, ch.Type, ch.Path, feat.Name()}
+				if opts.limits {
+					b.base().Limits = readLimits(feat.Type(), b.base().Value, feat.GetValue)
+				}
+			}
 			ch.Sensors[name] = reading
-			if err != nil && !yield("feature="+name, err) {
-				return
+			if err != nil {
+				logger.Warn("can't read feature", "chip", ch.ID, "feature", name, "error", err)
+				if !yield("feature="+name, err) {
+					return
+				}
 			}
 		}
 	})
+	if opts.sysfsExtras {
+		known := map[string]bool{}
+		for _, feat := range chip.Features {
+			for sf0 := range feat.subfeatures {
+				known[C.GoString(sf0.name)] = true
+			}
+		}
+		for name, s := range sysfsExtras(ch.Path, known) {
+			if _, ok := ch.Sensors[name]; !ok {
+				ch.Sensors[name] = s
+			}
+		}
+	}
+	applyQuirk(&ch)
+	if opts.synthesizePower {
+		synthesizePower(&ch)
+	}
+	ch.Stats.Duration = time.Since(start)
+	span.SetAttribute("lmsensors.sensors", len(ch.Sensors))
+	span.SetAttribute("lmsensors.errors", errCount(err))
+	return ch, err
 }
 
 // Feature is an iterator for range over all features for the chip, and it's the only way to create a valid [Feature] object.
 func (chip ChipPtr) Features(yield func(uint32, Feature) bool) {
 	i := C.int(0)
 	for feature := C.sensors_get_features(chip.ptr, &i); feature != nil; feature = C.sensors_get_features(chip.ptr, &i) {
-		if !yield(uint32(i), Feature{chip, feature}) {
+		if !yield(uint32(i), Feature{Chip: chip, ptr: feature}) {
 			return
 		}
 	}
@@ -378,22 +628,15 @@ func (chip ChipPtr) hasNR() bool {
 	}
 }
 
-const hwmon_dir = "/sys/class/hwmon"
-
 func (chip ChipPtr) searchSetPath() {
-	entries, err := os.ReadDir(hwmon_dir)
+	devs, err := HwmonDevices()
 	if err != nil {
 		return
 	}
 	prefix := chip.Prefix()
-	for _, e := range entries {
-		p, err := os.ReadFile(filepath.Join(hwmon_dir, e.Name(), "name"))
-		if err != nil || len(p) == 0 {
-			continue
-		}
-		p = p[:len(p)-1]
-		if string(p) == prefix {
-			chip.ptr.path = C.CString(filepath.Join(hwmon_dir, e.Name()))
+	for _, dev := range devs {
+		if dev.Name == prefix {
+			chip.ptr.path = C.CString(dev.Path)
 			runtime.AddCleanup(chip.ptr, free, chip.ptr.path)
 			break
 		}
@@ -431,6 +674,9 @@ func GetChip(name string) (ChipPtr, error) {
 type Feature struct {
 	Chip ChipPtr
 	ptr  *C.struct_sensors_feature
+
+	stats *CollectionStats // Where to record read latencies, if anywhere
+	raw   bool             // Whether to read values from sysfs directly, skipping the compute mapping
 }
 
 // Name return the original name of a sensor.
@@ -452,9 +698,27 @@ func (feat Feature) Type() LmSensorType {
 	return LmSensorType(feat.ptr._type)
 }
 
+// Number is the feature's index within its chip, as used by libsensors.
+func (feat Feature) Number() int {
+	return int(feat.ptr.number)
+}
+
 func (feat Feature) getValue(sf0 *C.struct_sensors_subfeature) (float64, error) {
 	var val C.double
-	cerr := C.sensors_get_value(feat.Chip.ptr, sf0.number, &val)
+	if feat.stats != nil {
+		defer feat.stats.record(C.GoString(sf0.name), time.Now())
+	}
+	if feat.raw {
+		return feat.rawValue(sf0)
+	}
+	policy := currentRetryPolicy()
+	cerr := C.int(policy.retry(func() SensorErrCode {
+		code := SensorErrCode(C.sensors_get_value(feat.Chip.ptr, sf0.number, &val))
+		if code != 0 && policy.Attempts > 1 {
+			logger.Debug("subfeature read failed", "chip", feat.Chip.Name(), "subfeature", C.GoString(sf0.name), "error", code)
+		}
+		return code
+	}))
 	if cerr != 0 {
 		return 0, sensorErr{sf.SubFeature(sf0._type), cerr}
 	}
@@ -469,18 +733,123 @@ func (feat Feature) GetValue(sub sf.SubFeature) (float64, error) {
 	return feat.getValue(sf)
 }
 
+// RawValue reads a subfeature straight from sysfs, bypassing any compute statements in the libsensors config.
+// Only the kernel's fixed scaling (eg millidegrees to degrees) is applied.
+func (feat Feature) RawValue(sub sf.SubFeature) (float64, error) {
+	sf0 := C.sensors_get_subfeature(feat.Chip.ptr, feat.ptr, C.sensors_subfeature_type(sub))
+	if sf0 == nil {
+		return 0, sub
+	}
+	return feat.rawValue(sf0)
+}
+
+func (feat Feature) rawValue(sf0 *C.struct_sensors_subfeature) (float64, error) {
+	sub := sf.SubFeature(sf0._type)
+	p, err := os.ReadFile(filepath.Join(feat.Chip.Path(), C.GoString(sf0.name)))
+	if err != nil {
+		return 0, sensorErr{sub, C.int(ErrSensorKernel)}
+	}
+	val, err := strconv.ParseFloat(strings.TrimSpace(string(p)), 64)
+	if err != nil {
+		return 0, sensorErr{sub, C.int(ErrSensorIO)}
+	}
+	return val / sub.Scaling(), nil
+}
+
+// DetailedReading is both views of a subfeature, so that the transformation applied by the config can be verified.
+type DetailedReading struct {
+	SubFeature sf.SubFeature
+	Attr       string  // sysfs attribute, eg in0_input
+	Raw        float64 // Straight from sysfs, see [Feature.RawValue]
+	Value      float64 // After the config's compute statement
+}
+
+// ReadDetailed reads every subfeature both raw and computed.
+// Subfeatures that fail either read are left out, and the errors returned.
+func (feat Feature) ReadDetailed() ([]DetailedReading, error) {
+	computed := feat
+	computed.raw = false
+	var rs []DetailedReading
+	return rs, collectError(func(yield func(string, error) bool) {
+		i := C.int(0)
+		for sf0 := C.sensors_get_all_subfeatures(feat.Chip.ptr, feat.ptr, &i); sf0 != nil; sf0 = C.sensors_get_all_subfeatures(feat.Chip.ptr, feat.ptr, &i) {
+			r := DetailedReading{SubFeature: sf.SubFeature(sf0._type), Attr: C.GoString(sf0.name)}
+			var err error
+			if r.Raw, err = feat.rawValue(sf0); err == nil {
+				r.Value, err = computed.getValue(sf0)
+			}
+			if err != nil {
+				if !yield("subfeature="+r.Attr, err) {
+					return
+				}
+				continue
+			}
+			rs = append(rs, r)
+		}
+	})
+}
+
+// SetValue writes a subfeature, eg a limit, through libsensors, so the config's set statements' inverse compute mapping applies.
+// The write goes through the [AuditFunc], and isn't made in dry-run mode, see [SetDryRun].
 func (feat Feature) SetValue(sub sf.SubFeature, val float64) error {
 	sf0 := C.sensors_get_subfeature(feat.Chip.ptr, feat.ptr, C.sensors_subfeature_type(sub))
 	if sf0 == nil {
 		return sub
 	}
-	cerr := C.sensors_set_value(feat.Chip.ptr, sf0.number, C.double(val))
-	if cerr != 0 {
-		return setSensorErr{sensorErr{sf.SubFeature(sf0._type), cerr}}
+	attr := C.GoString(sf0.name)
+	if err := beforeWrite(feat.Chip.Name(), feat.Chip.Prefix(), feat.Chip.Path(), attr); err != nil {
+		return err
 	}
-	return nil
+	ev := WriteEvent{Chip: feat.Chip.Name(), Prefix: feat.Chip.Prefix(), Attr: attr, Path: filepath.Join(feat.Chip.Path(), attr), SubFeature: sub, New: val}
+	ev.Old, ev.OldErr = feat.getValue(sf0)
+	return audit(ev, func() error {
+		cerr := C.sensors_set_value(feat.Chip.ptr, sf0.number, C.double(val))
+		if cerr != 0 {
+			return setSensorErr{sensorErr{sf.SubFeature(sf0._type), cerr}}
+		}
+		return nil
+	})
+}
+
+// inputSubFeatures are the subfeatures holding the reading of each type of feature, in order of preference.
+var inputSubFeatures = map[LmSensorType][]sf.SubFeature{
+	Voltage:     {sf.IN_INPUT},
+	Fan:         {sf.FAN_INPUT},
+	Temperature: {sf.TEMP_INPUT},
+	Power:       {sf.POWER_INPUT, sf.POWER_AVERAGE},
+	Energy:      {sf.ENERGY_INPUT},
+	Current:     {sf.CURR_INPUT},
+	Humidity:    {sf.HUMIDITY_INPUT},
+	VID:         {sf.VID},
+	Intrusion:   {sf.INTRUSION_ALARM},
+	BeepEnable:  {sf.BEEP_ENABLE},
+}
+
+// alarmSubFeatures are the alarm subfeatures of each type of feature that can have one; intrusion's is its reading.
+var alarmSubFeatures = map[LmSensorType]sf.SubFeature{
+	Voltage:     sf.IN_ALARM,
+	Fan:         sf.FAN_ALARM,
+	Temperature: sf.TEMP_ALARM,
+	Power:       sf.POWER_ALARM,
+	Current:     sf.CURR_ALARM,
+}
+
+// InputValue reads the subfeature holding the feature's actual reading, eg temp1_input for a temperature.
+// If the feature doesn't have one, it falls back to [Feature.FirstValue].
+func (feat Feature) InputValue() (sf.SubFeature, float64, error) {
+	for _, sub := range inputSubFeatures[feat.Type()] {
+		sf0 := C.sensors_get_subfeature(feat.Chip.ptr, feat.ptr, C.sensors_subfeature_type(sub))
+		if sf0 == nil {
+			continue
+		}
+		val, err := feat.getValue(sf0)
+		return sub, val, err
+	}
+	return feat.FirstValue()
 }
 
+// FirstValue reads whichever subfeature libsensors enumerates first, which is usually, but not necessarily, the input.
+// Use [Feature.InputValue] for the reading.
 func (feat Feature) FirstValue() (sub sf.SubFeature, val float64, err error) {
 	i := C.int(0)
 	sf0 := C.sensors_get_all_subfeatures(feat.Chip.ptr, feat.ptr, &i)
@@ -493,6 +862,65 @@ func (feat Feature) FirstValue() (sub sf.SubFeature, val float64, err error) {
 	return
 }
 
+func (feat Feature) subfeatures(yield func(*C.struct_sensors_subfeature) bool) {
+	i := C.int(0)
+	for sf0 := C.sensors_get_all_subfeatures(feat.Chip.ptr, feat.ptr, &i); sf0 != nil; sf0 = C.sensors_get_all_subfeatures(feat.Chip.ptr, feat.ptr, &i) {
+		if !yield(sf0) {
+			return
+		}
+	}
+}
+
+// Attr is the sysfs attribute a subfeature is read from, eg temp1_input, or "" if the feature doesn't have it.
+func (feat Feature) Attr(sub sf.SubFeature) string {
+	sf0 := C.sensors_get_subfeature(feat.Chip.ptr, feat.ptr, C.sensors_subfeature_type(sub))
+	if sf0 == nil {
+		return ""
+	}
+	return C.GoString(sf0.name)
+}
+
+// readSubfeatures reads all the subfeatures that can be read, by sysfs attribute.
+func (feat Feature) readSubfeatures() map[string]float64 {
+	vals := map[string]float64{}
+	for sf0 := range feat.subfeatures {
+		if sf0.flags&C.SENSORS_MODE_R == 0 {
+			continue
+		}
+		val, err := feat.getValue(sf0)
+		if err != nil {
+			logger.Debug("skipping unreadable subfeature", "chip", feat.Chip.Name(), "feature", feat.Name(), "error", err)
+			continue
+		}
+		vals[C.GoString(sf0.name)] = val
+	}
+	return vals
+}
+
+// SubFeatureInfo describes a subfeature, without reading it.
+type SubFeatureInfo struct {
+	Type     sf.SubFeature
+	Attr     string // sysfs attribute, eg temp1_input
+	Readable bool
+	Writable bool
+	Computed bool // Whether the config's compute statement for the feature applies to it
+}
+
+// AllSubfeatures describes all the feature's subfeatures, in the order libsensors enumerates them.
+func (feat Feature) AllSubfeatures() []SubFeatureInfo {
+	var infos []SubFeatureInfo
+	for sf0 := range feat.subfeatures {
+		infos = append(infos, SubFeatureInfo{
+			Type:     sf.SubFeature(sf0._type),
+			Attr:     C.GoString(sf0.name),
+			Readable: sf0.flags&C.SENSORS_MODE_R != 0,
+			Writable: sf0.flags&C.SENSORS_MODE_W != 0,
+			Computed: sf0.flags&C.SENSORS_COMPUTE_MAPPING != 0,
+		})
+	}
+	return infos
+}
+
 // SubFeatures is an iterator for range over all subfeatures without reading it's value.
 func (feat Feature) SubFeatures(yield func(sf.SubFeature) bool) {
 	i := C.int(0)
@@ -511,6 +939,7 @@ func (feat Feature) Values(yield func(sf.SubFeature, float64) bool) {
 	for sf0 := C.sensors_get_all_subfeatures(feat.Chip.ptr, feat.ptr, &i); sf0 != nil; sf0 = C.sensors_get_all_subfeatures(feat.Chip.ptr, feat.ptr, &i) {
 		val, err = feat.getValue(sf0)
 		if err != nil {
+			logger.Debug("skipping unreadable subfeature", "chip", feat.Chip.Name(), "feature", feat.Name(), "error", err)
 			continue
 		}
 		if !yield(sf.SubFeature(sf0._type), val) {
@@ -520,34 +949,62 @@ func (feat Feature) Values(yield func(sf.SubFeature, float64) bool) {
 }
 
 // Sensor read sensor data into a [Sensor] interface.
-func (feat Feature) Sensor() (reading Sensor, err error) {
+// If the reading fails, the error is returned along with a sensor that isn't [Valid], rather than one reading 0.
+func (feat Feature) Sensor() (Sensor, error) {
 	base := baseSensor{
 		Name: feat.Label(),
 	}
-	_, base.Value, err = feat.FirstValue()
+	sub, val, err := feat.InputValue()
+	base.Time = time.Now()
 	if err != nil {
-		return
+		base.Value = NoValue
+		return newSensor(feat.Chip.Prefix(), feat.Type(), sub, base, feat.GetValue), err
+	}
+	base.Value = sanitize(feat.Type(), val)
+	if math.IsNaN(base.Value) {
+		logger.Debug("implausible reading", "chip", feat.Chip.Name(), "feature", feat.Name(), "value", val)
 	}
-	switch feat.Type() {
+	return newSensor(feat.Chip.Prefix(), feat.Type(), sub, base, feat.GetValue), nil
+}
+
+// newSensor builds the typed [Sensor] for a feature.
+// Every case returns, rather than assigning to a shared variable, so that the compiler catches a branch that forgets to.
+// prefix is the chip's, sub is the subfeature the value in base was read from, and get reads the extra subfeatures some types have; it's a parameter so this can be tested without hardware.
+func newSensor(prefix string, typ LmSensorType, sub sf.SubFeature, base baseSensor, get func(sf.SubFeature) (float64, error)) Sensor {
+	if beepSub, ok := beepSubFeatures[typ]; ok {
+		base.beepSub = beepSub
+		if v, err := get(beepSub); err == nil {
+			base.beep, base.hasBeep = v != 0, true
+		}
+	}
+	if alarmSub, ok := alarmSubFeatures[typ]; ok {
+		v, _ := get(alarmSub)
+		base.alarm = v != 0
+	}
+	switch typ {
 	case Temperature:
-		ts := &TempSensor{base, Unknown}
-		reading = ts
-		value, err := feat.GetValue(sf.TEMP_TYPE)
-		if err == nil {
-			ts.TempType = LmTempType(value)
+		ts := &TempSensor{baseSensor: base, TempType: Unknown, TempTypeRaw: -1}
+		if value, err := get(sf.TEMP_TYPE); err == nil {
+			ts.TempType, ts.TempTypeRaw = parseTempType(prefix, value)
 		}
+		ts.Crit, _ = get(sf.TEMP_CRIT)
+		ts.Emergency, _ = get(sf.TEMP_EMERGENCY)
+		return ts
 	case Voltage:
-		reading = &VoltageSensor{base}
+		return &VoltageSensor{base}
 	case Fan:
-		reading = &FanSensor{base}
+		fs := &FanSensor{baseSensor: base}
+		fs.Min, _ = get(sf.FAN_MIN)
+		fault, _ := get(sf.FAN_FAULT)
+		fs.fault = fault != 0
+		fs.setDuty(-1)
+		return fs
 	case Current:
-		reading = &CurrentSensor{base}
+		return &CurrentSensor{base}
 	case Intrusion:
-		is := &IntrusionSensor{base.Name, false, base.Value != 0}
-		value, _ := feat.GetValue(sf.INTRUSION_BEEP)
-		is.Beep = value != 0
+		beep, _ := get(sf.INTRUSION_BEEP)
+		return &IntrusionSensor{Name: base.Name, Beep: beep != 0, alarm: base.Valid() && base.Value != 0, Time: base.Time, failed: !base.Valid()}
 	default:
-		reading = &UnimplementedSensor{feat}
+		return &GenericSensor{baseSensor: base, FeatureType: typ, unit: unitGuess(sub)}
 	}
-	return
 }
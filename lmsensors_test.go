@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	sf "github.com/mt-inside/go-lmsensors/subfeature"
@@ -32,6 +35,8 @@ func TestGet(t *testing.T) {
 		fmt.Println(se.Code().String(), se.SubFeature().String())
 	}
 
+	fmt.Println("took", info.Stats.Duration, "slowest chips", info.SlowestChips(3))
+
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "    ")
 	err = encoder.Encode(info)
@@ -61,7 +66,9 @@ func TestFeature(t *testing.T) {
 	defer Cleanup()
 	for _, chip := range Chips {
 		for _, feat := range chip.Features {
-			fmt.Println(chip.Name(), chip.Path(), feat.Name(), feat.Label(), feat.Type())
+			fmt.Println(chip.Name(), chip.Path(), feat.Number(), feat.Name(), feat.Label(), feat.Type())
+			sub, val, err := feat.InputValue()
+			fmt.Println("input:", sub, val, err)
 			for sub, value := range feat.Values {
 				fmt.Println(sub, value)
 			}
@@ -131,3 +138,171 @@ func TestSetValue(t *testing.T) {
 		}
 	}
 }
+
+func TestDiagnose(t *testing.T) {
+	err := Init()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer Cleanup()
+	d := Diagnose()
+	if d.Chips == 0 && len(d.Problems) == 0 {
+		t.Error("no chips but no problems reported")
+	}
+	fmt.Println(d)
+}
+
+func TestHwmonDevices(t *testing.T) {
+	fmt.Println("in container:", InContainer())
+	devs, err := HwmonDevices()
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, dev := range devs {
+		attrs, err := dev.Attributes()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		fmt.Println(dev.Name, dev.Path, attrs)
+	}
+}
+
+func TestHwmonDevicesFixture(t *testing.T) {
+	defer func(root string) { SysfsRoot = root }(SysfsRoot)
+	SysfsRoot = "testdata/sysfs"
+
+	devs, err := HwmonDevices()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(devs) != 3 || devs[0].Name != "k10temp" || devs[1].Name != "it87" || devs[2].Name != "acpitz" {
+		t.Errorf("wrong devices: %v", devs)
+		return
+	}
+	attrs, err := devs[0].Attributes()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if strings.Join(attrs, ",") != "temp1_input,temp1_label" {
+		t.Errorf("wrong attributes: %v", attrs)
+	}
+	val, err := devs[1].ReadAttribute("fan1_input")
+	if err != nil || val != "1200" {
+		t.Errorf("wrong value: %q %v", val, err)
+	}
+
+	ch := Chip{Type: "acpitz", ACPIPath: acpiPath(devs[2].Path)}
+	ch.BoardVendor, ch.BoardName = dmiBoard()
+	if name := ch.DisplayName(); name != `acpitz (\_TZ_.TZ00) on ASUSTeK COMPUTER INC. PRIME X570-P` {
+		t.Errorf("wrong display name: %s", name)
+	}
+}
+
+func TestDMIBoardCached(t *testing.T) {
+	defer func() { SysfsRoot = "/sys" }()
+	SysfsRoot = t.TempDir()
+	dir := filepath.Join(SysfsRoot, "class", "dmi", "id")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	setBoard := func(name string) {
+		if err := os.WriteFile(filepath.Join(dir, "board_name"), []byte(name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setBoard("PRIME X570-P")
+	if _, name := dmiBoard(); name != "PRIME X570-P" {
+		t.Fatalf("wrong board: %s", name)
+	}
+	setBoard("ROG STRIX B550-F")
+	if _, name := dmiBoard(); name != "PRIME X570-P" {
+		t.Errorf("board reread: %s", name)
+	}
+	if err := Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer Cleanup()
+	if _, name := dmiBoard(); name != "ROG STRIX B550-F" {
+		t.Errorf("board not reread after Init: %s", name)
+	}
+}
+
+func TestDevices(t *testing.T) {
+	err := Init()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer Cleanup()
+	info, _ := Get()
+	for _, dev := range info.Devices() {
+		fmt.Println(dev.ID, dev.Path, dev.Chips)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	SetLogger(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer SetLogger(nil)
+	err := Init()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer Cleanup()
+	_, _ = Get()
+}
+
+func TestGetRaw(t *testing.T) {
+	err := Init()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer Cleanup()
+	for _, chip := range Chips {
+		for _, feat := range chip.Features {
+			rs, err := feat.ReadDetailed()
+			if err != nil && !errors.Is(err, ErrSensorAny) {
+				t.Error(err)
+				return
+			}
+			for _, r := range rs {
+				fmt.Println(chip.Name(), r.Attr, r.Raw, r.Value)
+			}
+		}
+	}
+
+	info, err := GetRaw()
+	if err != nil && !errors.Is(err, ErrSensorAny) {
+		t.Error(err)
+		return
+	}
+	for _, chip := range info.Chips {
+		fmt.Println(chip.ID)
+		for _, reading := range chip.Sensors {
+			fmt.Println("  " + reading.String())
+		}
+	}
+}
+
+func TestGetWithSubfeatures(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Skip(err)
+	}
+	defer Cleanup()
+	sys, _ := Get(WithSubfeatures())
+	for _, chip := range sys.Chips {
+		for name, s := range chip.Sensors {
+			if s == nil || s.Type() == Intrusion {
+				continue
+			}
+			if len(SubfeaturesOf(s)) == 0 {
+				t.Errorf("%s/%s: no subfeatures", chip.ID, name)
+			}
+		}
+	}
+}
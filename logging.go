@@ -0,0 +1,49 @@
+package lmsensors
+
+import "sync/atomic"
+
+// Logger is told about non-fatal conditions, like subfeatures that fail to read, which otherwise only show up in aggregated errors, or not at all.
+// It's a subset of [log/slog.Logger], so one of those can be passed straight to [SetLogger].
+// args are alternating keys and values, as for slog.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+// packageLogger forwards to whichever [Logger] was last set, so that it can be swapped while the package is in use.
+type packageLogger struct {
+	l atomic.Pointer[Logger]
+}
+
+func (p *packageLogger) get() Logger {
+	if l := p.l.Load(); l != nil {
+		return *l
+	}
+	return nopLogger{}
+}
+
+func (p *packageLogger) Debug(msg string, args ...any) { p.get().Debug(msg, args...) }
+func (p *packageLogger) Info(msg string, args ...any)  { p.get().Info(msg, args...) }
+func (p *packageLogger) Warn(msg string, args ...any)  { p.get().Warn(msg, args...) }
+func (p *packageLogger) Error(msg string, args ...any) { p.get().Error(msg, args...) }
+
+var logger = &packageLogger{}
+
+// SetLogger sets the [Logger] used by the package; nil turns logging off, which is the default.
+// It can be called at any time, eg to turn on debug logging while chasing a problem.
+func SetLogger(l Logger) {
+	if l == nil {
+		logger.l.Store(nil)
+		return
+	}
+	logger.l.Store(&l)
+}
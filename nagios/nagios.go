@@ -0,0 +1,215 @@
+// Package nagios checks readings against thresholds, producing Nagios plugin output, so that they can be alerted on through NRPE, Icinga and the like.
+package nagios
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Status is a check's result, which is also its plugin exit code.
+type Status int
+
+const (
+	OK Status = iota
+	Warning
+	Critical
+	Unknown
+)
+
+var statusNames = [...]string{"OK", "WARNING", "CRITICAL", "UNKNOWN"}
+
+func (s Status) String() string {
+	if s < 0 || int(s) >= len(statusNames) {
+		return "Status(" + strconv.Itoa(int(s)) + ")"
+	}
+	return statusNames[s]
+}
+
+// worse is the more severe of two statuses; Unknown is worse than OK, but not than a real alert.
+func worse(a, b Status) Status {
+	rank := func(s Status) int {
+		if s == Unknown {
+			return 1
+		}
+		return int(s) * 2
+	}
+	if rank(b) > rank(a) {
+		return b
+	}
+	return a
+}
+
+// Range is a threshold in the Nagios plugin range syntax: "10" alerts outside 0 to 10, "10:" below 10, "~:10" above 10, "10:20" outside 10 to 20, and "@10:20" inside it.
+type Range struct {
+	Start, End float64 // Either can be infinite
+	Inside     bool    // Alert inside the range, rather than outside
+
+	text string
+}
+
+// ParseRange parses the Nagios plugin range syntax, see [Range].
+func ParseRange(s string) (Range, error) {
+	r := Range{Start: 0, End: math.Inf(1), text: s}
+	body, inside := strings.CutPrefix(s, "@")
+	r.Inside = inside
+	start, end, hasColon := strings.Cut(body, ":")
+	if !hasColon {
+		start, end = "0", body
+	}
+	var err error
+	switch start {
+	case "~":
+		r.Start = math.Inf(-1)
+	case "":
+	default:
+		if r.Start, err = strconv.ParseFloat(start, 64); err != nil {
+			return Range{}, fmt.Errorf("bad range %q: %w", s, err)
+		}
+	}
+	if end != "" {
+		if r.End, err = strconv.ParseFloat(end, 64); err != nil {
+			return Range{}, fmt.Errorf("bad range %q: %w", s, err)
+		}
+	} else if !hasColon {
+		return Range{}, fmt.Errorf("bad range %q: empty", s)
+	}
+	if r.Start > r.End {
+		return Range{}, fmt.Errorf("bad range %q: start is after end", s)
+	}
+	return r, nil
+}
+
+// Alert is whether v should raise an alert.
+func (r Range) Alert(v float64) bool {
+	in := v >= r.Start && v <= r.End
+	return in == r.Inside
+}
+
+func (r Range) String() string {
+	return r.text
+}
+
+// Check checks a snapshot's readings against thresholds.
+type Check struct {
+	Name    string               // The plugin output's prefix; defaults to "SENSORS"
+	Sensors []lmsensors.Selector // The sensors to check; none checks all of them
+
+	// Warning and Critical are the thresholds. If neither is set, readings are checked against their hardware limits instead, see [lmsensors.WithLimitCheck]: outside min or max is a warning, and above crit is critical.
+	Warning, Critical *Range
+
+	IgnoreAlarms bool // Don't count the hardware's alarm flags as critical
+}
+
+// Result is the outcome of a [Check].
+type Result struct {
+	Status   Status
+	Problems []string // Each sensor that isn't OK, eg "k10temp-pci-00c3/Tctl is 91 (critical)"
+	Checked  int      // How many sensors were checked
+	Perfdata []string // Each sensor's performance data, eg 'k10temp-pci-00c3/Tctl'=91;70;85;;
+	name     string
+}
+
+// String is the plugin output, eg
+//
+//	SENSORS CRITICAL - k10temp-pci-00c3/Tctl is 91 (critical) | 'k10temp-pci-00c3/Tctl'=91;70;85;;
+func (r Result) String() string {
+	var b strings.Builder
+	b.WriteString(r.name + " " + r.Status.String() + " - ")
+	switch {
+	case len(r.Problems) != 0:
+		b.WriteString(strings.Join(r.Problems, ", "))
+	case r.Checked == 0:
+		b.WriteString("no matching sensors")
+	default:
+		fmt.Fprintf(&b, "%d sensor(s) OK", r.Checked)
+	}
+	if len(r.Perfdata) != 0 {
+		b.WriteString(" | " + strings.Join(r.Perfdata, " "))
+	}
+	return b.String()
+}
+
+func (c Check) matches(chip, sensor string) bool {
+	if len(c.Sensors) == 0 {
+		return true
+	}
+	for _, sel := range c.Sensors {
+		if sel.Match(chip, sensor) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run checks the readings. No matching sensors is Unknown, as is a matching sensor without a valid reading, unless another is worse.
+func (c Check) Run(snap *lmsensors.Snapshot) Result {
+	res := Result{name: c.Name}
+	if res.name == "" {
+		res.name = "SENSORS"
+	}
+	for r := range snap.Readings {
+		if !c.matches(r.Chip, r.Sensor) {
+			continue
+		}
+		res.Checked++
+		label := r.Chip + "/" + r.Sensor
+		if !r.Valid {
+			res.Status = worse(res.Status, Unknown)
+			res.Problems = append(res.Problems, label+" has no reading")
+			continue
+		}
+		status, warn, crit := c.status(r)
+		if r.Alarm && !c.IgnoreAlarms {
+			status = Critical
+		}
+		res.Status = worse(res.Status, status)
+		value := strconv.FormatFloat(r.Value, 'f', -1, 64)
+		switch {
+		case r.Alarm && !c.IgnoreAlarms:
+			res.Problems = append(res.Problems, label+" is "+value+" (hardware alarm)")
+		case status != OK:
+			res.Problems = append(res.Problems, label+" is "+value+" ("+strings.ToLower(status.String())+")")
+		}
+		res.Perfdata = append(res.Perfdata, "'"+strings.ReplaceAll(label, "'", "''")+"'="+value+";"+warn+";"+crit+";;")
+	}
+	if res.Checked == 0 {
+		res.Status = Unknown
+	}
+	return res
+}
+
+// status checks one reading, also giving its warning and critical thresholds for the perfdata.
+func (c Check) status(r lmsensors.Reading) (status Status, warn, crit string) {
+	if c.Warning == nil && c.Critical == nil {
+		switch r.Limit {
+		case lmsensors.LimitBelowMin, lmsensors.LimitAboveMax:
+			status = Warning
+		case lmsensors.LimitAboveCrit:
+			status = Critical
+		}
+		if !math.IsNaN(r.Max) {
+			warn = strconv.FormatFloat(r.Max, 'f', -1, 64)
+		}
+		if !math.IsNaN(r.Crit) {
+			crit = strconv.FormatFloat(r.Crit, 'f', -1, 64)
+		}
+		return status, warn, crit
+	}
+	if c.Warning != nil {
+		warn = c.Warning.String()
+		if c.Warning.Alert(r.Value) {
+			status = Warning
+		}
+	}
+	if c.Critical != nil {
+		crit = c.Critical.String()
+		if c.Critical.Alert(r.Value) {
+			status = Critical
+		}
+	}
+	return status, warn, crit
+}
@@ -0,0 +1,87 @@
+package nagios
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+func TestParseRange(t *testing.T) {
+	for _, tc := range []struct {
+		r      string
+		alerts []float64
+		ok     []float64
+	}{
+		{"10", []float64{-1, 11}, []float64{0, 10}},
+		{"10:", []float64{9.9}, []float64{10, 1e9}},
+		{"~:10", []float64{10.1}, []float64{-1e9, 10}},
+		{"10:20", []float64{9, 21}, []float64{10, 20}},
+		{"@10:20", []float64{10, 15, 20}, []float64{9, 21}},
+	} {
+		r, err := ParseRange(tc.r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range tc.alerts {
+			if !r.Alert(v) {
+				t.Errorf("%s: %v should alert", tc.r, v)
+			}
+		}
+		for _, v := range tc.ok {
+			if r.Alert(v) {
+				t.Errorf("%s: %v shouldn't alert", tc.r, v)
+			}
+		}
+	}
+	for _, bad := range []string{"", "x", "20:10", "1:x"} {
+		if _, err := ParseRange(bad); err == nil {
+			t.Errorf("%q should be an error", bad)
+		}
+	}
+}
+
+func snapshot(tctl float64) *lmsensors.Snapshot {
+	s := &lmsensors.TempSensor{}
+	s.Name, s.Value = "Tctl", tctl
+	max, crit := 80.0, 95.0
+	state := lmsensors.LimitInRange
+	if tctl > crit {
+		state = lmsensors.LimitAboveCrit
+	}
+	s.Limits = &lmsensors.Limits{Max: &max, Crit: &crit, State: state}
+	v := &lmsensors.VoltageSensor{}
+	v.Name, v.Value = "in0", 1.1
+	return lmsensors.NewSnapshot(&lmsensors.System{Chips: map[string]*lmsensors.Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]lmsensors.Sensor{"Tctl": s}},
+		"nct6798-isa-0290": {ID: "nct6798-isa-0290", Sensors: map[string]lmsensors.Sensor{"in0": v}},
+	}}, time.Now())
+}
+
+func TestCheck(t *testing.T) {
+	warn, _ := ParseRange("70")
+	crit, _ := ParseRange("85")
+	c := Check{Sensors: []lmsensors.Selector{"k10temp-*/*"}, Warning: &warn, Critical: &crit}
+	for _, tc := range []struct {
+		tctl float64
+		want string
+	}{
+		{42, "SENSORS OK - 1 sensor(s) OK | 'k10temp-pci-00c3/Tctl'=42;70;85;;"},
+		{75, "SENSORS WARNING - k10temp-pci-00c3/Tctl is 75 (warning) | 'k10temp-pci-00c3/Tctl'=75;70;85;;"},
+		{90, "SENSORS CRITICAL - k10temp-pci-00c3/Tctl is 90 (critical) | 'k10temp-pci-00c3/Tctl'=90;70;85;;"},
+	} {
+		if got := c.Run(snapshot(tc.tctl)).String(); got != tc.want {
+			t.Errorf("got  %s\nwant %s", got, tc.want)
+		}
+	}
+
+	// Without thresholds, the hardware's limits are used.
+	res := Check{Name: "THERMAL", Sensors: []lmsensors.Selector{"*/Tctl"}}.Run(snapshot(96))
+	if want := "THERMAL CRITICAL - k10temp-pci-00c3/Tctl is 96 (critical) | 'k10temp-pci-00c3/Tctl'=96;80;95;;"; res.String() != want || res.Status != Critical {
+		t.Errorf("got  %s\nwant %s", res, want)
+	}
+
+	if res := (Check{Sensors: []lmsensors.Selector{"nvme-*/*"}}).Run(snapshot(42)); res.Status != Unknown {
+		t.Errorf("no sensors: %s", res)
+	}
+}
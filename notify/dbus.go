@@ -0,0 +1,297 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// This is just enough of the D-Bus wire protocol to call methods on the session bus, so that desktop notifications don't need a D-Bus library.
+
+// dbusMessage is a D-Bus message; the header fields are by their codes, eg 1 for the path.
+type dbusMessage struct {
+	typ    byte // 1 method call, 2 method return, 3 error, 4 signal
+	serial uint32
+	fields map[byte]any // string or uint32
+	sig    string       // of the body
+	body   []byte       // already encoded, little-endian, from offset 0 of an 8-aligned buffer
+}
+
+const (
+	dbusPath        = 1
+	dbusInterface   = 2
+	dbusMember      = 3
+	dbusErrorName   = 4
+	dbusReplySerial = 5
+	dbusDestination = 6
+	dbusSignature   = 8
+)
+
+// dbusEncoder builds little-endian D-Bus values, padding to each type's alignment.
+type dbusEncoder struct {
+	buf []byte
+}
+
+func (e *dbusEncoder) align(n int) {
+	for len(e.buf)%n != 0 {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *dbusEncoder) byte(b byte) { e.buf = append(e.buf, b) }
+
+func (e *dbusEncoder) uint32(v uint32) {
+	e.align(4)
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, v)
+}
+
+func (e *dbusEncoder) string(s string) {
+	e.uint32(uint32(len(s)))
+	e.buf = append(append(e.buf, s...), 0)
+}
+
+func (e *dbusEncoder) signature(s string) {
+	e.buf = append(append(append(e.buf, byte(len(s))), s...), 0)
+}
+
+// array encodes an array of elements aligned to n, with elems appending them.
+func (e *dbusEncoder) array(n int, elems func()) {
+	e.uint32(0)
+	at := len(e.buf)
+	e.align(n)
+	start := len(e.buf)
+	elems()
+	binary.LittleEndian.PutUint32(e.buf[at-4:], uint32(len(e.buf)-start))
+}
+
+func (m *dbusMessage) encode() []byte {
+	e := &dbusEncoder{}
+	e.buf = append(e.buf, 'l', m.typ, 0, 1)
+	e.uint32(uint32(len(m.body)))
+	e.uint32(m.serial)
+	fields := m.fields
+	if m.sig != "" {
+		fields[dbusSignature] = dbusSig(m.sig)
+	}
+	e.array(8, func() {
+		for _, code := range []byte{dbusPath, dbusInterface, dbusMember, dbusErrorName, dbusReplySerial, dbusDestination, dbusSignature} {
+			v, ok := fields[code]
+			if !ok {
+				continue
+			}
+			e.align(8)
+			e.byte(code)
+			switch v := v.(type) {
+			case dbusSig:
+				e.signature("g")
+				e.signature(string(v))
+			case uint32:
+				e.signature("u")
+				e.uint32(v)
+			case string:
+				if code == dbusPath {
+					e.signature("o")
+				} else {
+					e.signature("s")
+				}
+				e.string(v)
+			}
+		}
+	})
+	e.align(8)
+	return append(e.buf, m.body...)
+}
+
+type dbusSig string
+
+// dbusDecoder reads little-endian D-Bus values, remembering the first error.
+type dbusDecoder struct {
+	buf []byte
+	off int
+	err error
+}
+
+func (d *dbusDecoder) align(n int) {
+	for d.off%n != 0 {
+		d.off++
+	}
+}
+
+func (d *dbusDecoder) take(n int) []byte {
+	if d.err != nil || d.off+n > len(d.buf) {
+		d.err = errors.New("dbus: short message")
+		return make([]byte, n)
+	}
+	b := d.buf[d.off : d.off+n]
+	d.off += n
+	return b
+}
+
+func (d *dbusDecoder) uint32() uint32 {
+	d.align(4)
+	return binary.LittleEndian.Uint32(d.take(4))
+}
+
+func (d *dbusDecoder) string() string {
+	n := int(d.uint32())
+	s := string(d.take(n))
+	d.take(1)
+	return s
+}
+
+func (d *dbusDecoder) signature() string {
+	n := int(d.take(1)[0])
+	s := string(d.take(n))
+	d.take(1)
+	return s
+}
+
+// readDBusMessage reads a little-endian message, which is all this package asks for, and all servers send back to it.
+func readDBusMessage(r io.Reader) (*dbusMessage, error) {
+	var fixed [16]byte
+	if _, err := io.ReadFull(r, fixed[:]); err != nil {
+		return nil, err
+	}
+	if fixed[0] != 'l' {
+		return nil, errors.New("dbus: big-endian message")
+	}
+	bodyLen, fieldsLen := binary.LittleEndian.Uint32(fixed[4:]), binary.LittleEndian.Uint32(fixed[12:])
+	headerLen := (16 + int(fieldsLen) + 7) &^ 7
+	rest := make([]byte, headerLen-16+int(bodyLen))
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, err
+	}
+	m := &dbusMessage{typ: fixed[1], serial: binary.LittleEndian.Uint32(fixed[8:]), fields: map[byte]any{}}
+	d := &dbusDecoder{buf: append(fixed[:], rest...), off: 16}
+	for d.off < 16+int(fieldsLen) && d.err == nil {
+		d.align(8)
+		code := d.take(1)[0]
+		switch sig := d.signature(); sig {
+		case "o", "s":
+			m.fields[code] = d.string()
+		case "g":
+			m.fields[code] = dbusSig(d.signature())
+		case "u":
+			m.fields[code] = d.uint32()
+		default:
+			return nil, fmt.Errorf("dbus: unexpected header field type %q", sig)
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	m.body = d.buf[headerLen:]
+	if sig, ok := m.fields[dbusSignature].(dbusSig); ok {
+		m.sig = string(sig)
+	}
+	return m, nil
+}
+
+// dbusConn is a connection to a bus, authenticated and said hello on.
+type dbusConn struct {
+	conn   net.Conn
+	r      *bufio.Reader
+	serial uint32
+}
+
+// sessionBus finds the session bus's socket, from $DBUS_SESSION_BUS_ADDRESS or failing that where systemd puts it.
+func sessionBus(addr string) (network, path string, err error) {
+	if addr == "" {
+		addr = os.Getenv("DBUS_SESSION_BUS_ADDRESS")
+	}
+	if addr == "" {
+		return "unix", "/run/user/" + strconv.Itoa(os.Getuid()) + "/bus", nil
+	}
+	for _, a := range strings.Split(addr, ";") {
+		kind, params, _ := strings.Cut(a, ":")
+		if kind != "unix" {
+			continue
+		}
+		for _, p := range strings.Split(params, ",") {
+			switch k, v, _ := strings.Cut(p, "="); k {
+			case "path":
+				return "unix", v, nil
+			case "abstract":
+				return "unix", "@" + v, nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("dbus: no usable unix address in %q", addr)
+}
+
+func dialDBus(addr string) (*dbusConn, error) {
+	network, path, err := sessionBus(addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.Dial(network, path)
+	if err != nil {
+		return nil, err
+	}
+	c := &dbusConn{conn: conn, r: bufio.NewReader(conn)}
+	if err := c.auth(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if _, err := c.call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "Hello", "", nil); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *dbusConn) auth() error {
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+	if _, err := io.WriteString(c.conn, "\x00AUTH EXTERNAL "+uid+"\r\n"); err != nil {
+		return err
+	}
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "OK ") {
+		return fmt.Errorf("dbus: authentication failed: %s", strings.TrimSpace(line))
+	}
+	_, err = io.WriteString(c.conn, "BEGIN\r\n")
+	return err
+}
+
+// call calls a method, and waits for its reply, skipping any signals, eg NameAcquired after Hello.
+func (c *dbusConn) call(dest, path, iface, member, sig string, body []byte) (*dbusMessage, error) {
+	c.serial++
+	m := &dbusMessage{typ: 1, serial: c.serial, sig: sig, body: body, fields: map[byte]any{
+		dbusPath: path, dbusInterface: iface, dbusMember: member, dbusDestination: dest,
+	}}
+	if _, err := c.conn.Write(m.encode()); err != nil {
+		return nil, err
+	}
+	for {
+		reply, err := readDBusMessage(c.r)
+		if err != nil {
+			return nil, err
+		}
+		if serial, _ := reply.fields[dbusReplySerial].(uint32); serial != m.serial {
+			continue
+		}
+		if reply.typ == 3 {
+			name, _ := reply.fields[dbusErrorName].(string)
+			d := &dbusDecoder{buf: reply.body}
+			if strings.HasPrefix(reply.sig, "s") {
+				return nil, fmt.Errorf("dbus: %s: %s", name, d.string())
+			}
+			return nil, fmt.Errorf("dbus: %s", name)
+		}
+		return reply, nil
+	}
+}
+
+func (c *dbusConn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Desktop pops up a desktop notification for each event, through the freedesktop notification service on the user's session bus, so desktop users hear about eg their CPU hitting crit without running an applet.
+// Raised alarms are critical, which most desktops keep on screen until they're dismissed; if Cleared, an alarm's clearing replaces its popup.
+type Desktop struct {
+	Bus     string   // The session bus's address; defaults to $DBUS_SESSION_BUS_ADDRESS, and failing that /run/user/<uid>/bus
+	Rules   []string // Only alarms from these thresholds, by name, with "" for the hardware's own; all of them if empty
+	Cleared bool     // Also notify when alarms clear
+	Expire  time.Duration
+
+	mu  sync.Mutex
+	ids map[string]uint32 // Each sensor's last popup, to replace
+}
+
+const (
+	urgencyNormal   = 1
+	urgencyCritical = 2
+)
+
+func (d *Desktop) Notify(ctx context.Context, ev lmsensors.AlarmEvent) error {
+	if (!ev.Raised && !d.Cleared) || (len(d.Rules) != 0 && !slices.Contains(d.Rules, ev.Rule)) {
+		return nil
+	}
+	c, err := dialDBus(d.Bus)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if dl, ok := ctx.Deadline(); ok {
+		_ = c.conn.SetDeadline(dl)
+	}
+
+	key := ev.Chip + "/" + ev.Sensor
+	d.mu.Lock()
+	replaces := d.ids[key]
+	d.mu.Unlock()
+
+	summary := fmt.Sprintf("%s alarm %s", ev.Sensor, state(ev))
+	body := fmt.Sprintf("%s is %g", key, ev.Value)
+	if ev.Rule != "" {
+		body += " (" + ev.Rule + ")"
+	}
+	urgency := byte(urgencyNormal)
+	if ev.Raised {
+		urgency = urgencyCritical
+	}
+	expire := int32(-1) // The server's default
+	if d.Expire > 0 {
+		expire = int32(d.Expire.Milliseconds())
+	}
+
+	e := &dbusEncoder{}
+	e.string("go-lmsensors")
+	e.uint32(replaces)
+	e.string("dialog-warning")
+	e.string(summary)
+	e.string(body)
+	e.array(4, func() {}) // No actions
+	e.array(8, func() {
+		e.align(8)
+		e.string("urgency")
+		e.signature("y")
+		e.byte(urgency)
+	})
+	e.uint32(uint32(expire))
+	reply, err := c.call("org.freedesktop.Notifications", "/org/freedesktop/Notifications", "org.freedesktop.Notifications", "Notify", "susssasa{sv}i", e.buf)
+	if err != nil {
+		return err
+	}
+	id := (&dbusDecoder{buf: reply.body}).uint32()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.ids == nil {
+		d.ids = map[string]uint32{}
+	}
+	d.ids[key] = id
+	return nil
+}
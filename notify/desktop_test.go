@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type popup struct {
+	replaces         uint32
+	summary, body    string
+	urgency          byte
+	signature, actor string
+}
+
+// fakeBus is a session bus with a notification server on it, recording the popups.
+func fakeBus(t *testing.T) (string, <-chan popup) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bus")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	popups := make(chan popup, 10)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveBus(conn, popups)
+		}
+	}()
+	return "unix:path=" + path, popups
+}
+
+func serveBus(conn net.Conn, popups chan<- popup) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	if line, _ := r.ReadString('\n'); !strings.HasPrefix(line, "\x00AUTH EXTERNAL ") {
+		return
+	}
+	conn.Write([]byte("OK 0123456789abcdef\r\n"))
+	if line, _ := r.ReadString('\n'); line != "BEGIN\r\n" {
+		return
+	}
+	var serial uint32 = 100
+	reply := func(to *dbusMessage, sig string, body []byte) {
+		serial++
+		m := &dbusMessage{typ: 2, serial: serial, sig: sig, body: body, fields: map[byte]any{dbusReplySerial: to.serial}}
+		conn.Write(m.encode())
+	}
+	for {
+		m, err := readDBusMessage(r)
+		if err != nil {
+			return
+		}
+		switch m.fields[dbusMember] {
+		case "Hello":
+			// A signal first, which the client has to skip.
+			serial++
+			e := &dbusEncoder{}
+			e.string(":1.5")
+			sig := &dbusMessage{typ: 4, serial: serial, sig: "s", body: e.buf, fields: map[byte]any{dbusPath: "/org/freedesktop/DBus", dbusMember: "NameAcquired"}}
+			conn.Write(sig.encode())
+			reply(m, "s", e.buf)
+		case "Notify":
+			d := &dbusDecoder{buf: m.body}
+			var p popup
+			p.signature, p.actor = m.sig, d.string()
+			p.replaces = d.uint32()
+			d.string()
+			p.summary, p.body = d.string(), d.string()
+			d.uint32() // No actions
+			d.uint32()
+			d.align(8)
+			d.string()
+			d.signature()
+			p.urgency = d.take(1)[0]
+			popups <- p
+			e := &dbusEncoder{}
+			e.uint32(7)
+			reply(m, "u", e.buf)
+		}
+	}
+}
+
+func TestDesktop(t *testing.T) {
+	bus, popups := fakeBus(t)
+	d := &Desktop{Bus: bus, Cleared: true}
+	ctx := context.Background()
+	if err := d.Notify(ctx, event); err != nil {
+		t.Fatal(err)
+	}
+	p := <-popups
+	if p.signature != "susssasa{sv}i" || p.actor != "go-lmsensors" || p.summary != "Tctl alarm raised" || p.body != "k10temp-pci-00c3/Tctl is 95.5 (cpu hot)" || p.urgency != urgencyCritical || p.replaces != 0 {
+		t.Errorf("raised: %+v", p)
+	}
+
+	cleared := event
+	cleared.Raised = false
+	if err := d.Notify(ctx, cleared); err != nil {
+		t.Fatal(err)
+	}
+	if p := <-popups; p.replaces != 7 || p.urgency != urgencyNormal || p.summary != "Tctl alarm cleared" {
+		t.Errorf("cleared: %+v", p)
+	}
+
+	// Filtered out, so no bus needed.
+	d = &Desktop{Bus: "unix:path=/nonexistent", Rules: []string{"gpu hot"}}
+	if err := d.Notify(ctx, event); err != nil {
+		t.Error(err)
+	}
+}
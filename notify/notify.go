@@ -0,0 +1,255 @@
+// Package notify acts on alarm events: running a command, POSTing a webhook, sending an email, or popping up a desktop notification.
+//
+// Wire it up to a [lmsensors.Watcher] with eg
+//
+//	ns, err := notify.FromConfig(cfg.Notify)
+//	w := lmsensors.NewWatcher(time.Second, cfg.AlarmDetection(notify.All(ctx, log, ns...)))
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/config"
+)
+
+// Notifier is something that can be told about an alarm event.
+type Notifier interface {
+	Notify(ctx context.Context, ev lmsensors.AlarmEvent) error
+}
+
+// Func adapts a function to a [Notifier].
+type Func func(ctx context.Context, ev lmsensors.AlarmEvent) error
+
+func (f Func) Notify(ctx context.Context, ev lmsensors.AlarmEvent) error {
+	return f(ctx, ev)
+}
+
+// Timeout bounds each notification sent by [All].
+var Timeout = 30 * time.Second
+
+// All returns an alarm callback that tells every notifier about each event.
+// Each notification is sent on its own goroutine, so a slow webhook doesn't hold up polling; failures are logged, if log isn't nil.
+func All(ctx context.Context, log lmsensors.Logger, ns ...Notifier) func(lmsensors.AlarmEvent) {
+	return func(ev lmsensors.AlarmEvent) {
+		for _, n := range ns {
+			go func() {
+				ctx, cancel := context.WithTimeout(ctx, Timeout)
+				defer cancel()
+				if err := n.Notify(ctx, ev); err != nil && log != nil {
+					log.Error("alarm notification failed", "chip", ev.Chip, "sensor", ev.Sensor, "error", err)
+				}
+			}()
+		}
+	}
+}
+
+// FromConfig makes the notifiers described by a config file's notify section.
+func FromConfig(actions []config.Action) ([]Notifier, error) {
+	var ns []Notifier
+	for i, a := range actions {
+		switch {
+		case len(a.Exec) != 0:
+			c, err := Command(a.Exec...)
+			if err != nil {
+				return nil, fmt.Errorf("notify %d: %w", i, err)
+			}
+			ns = append(ns, c)
+		case a.Webhook != nil:
+			w, err := NewWebhook(a.Webhook.URL, a.Webhook.Template)
+			if err != nil {
+				return nil, fmt.Errorf("notify %d: %w", i, err)
+			}
+			w.Headers = a.Webhook.Headers
+			ns = append(ns, w)
+		case a.Email != nil:
+			ns = append(ns, &Email{
+				Server:   a.Email.Server,
+				Username: a.Email.Username,
+				Password: a.Email.Password,
+				From:     a.Email.From,
+				To:       a.Email.To,
+			})
+		case a.Desktop != nil:
+			ns = append(ns, &Desktop{Rules: a.Desktop.Rules, Cleared: a.Desktop.Cleared, Expire: time.Duration(a.Desktop.Expire)})
+		default:
+			return nil, fmt.Errorf("notify %d: no action", i)
+		}
+	}
+	return ns, nil
+}
+
+// state is how events are described to the outside world.
+func state(ev lmsensors.AlarmEvent) string {
+	if ev.Raised {
+		return "raised"
+	}
+	return "cleared"
+}
+
+// Command runs a command for each event; it's not run through a shell.
+// The event is in its environment, as LMSENSORS_CHIP, LMSENSORS_SENSOR, LMSENSORS_RULE, LMSENSORS_VALUE and LMSENSORS_STATE (raised or cleared).
+// argv is the command and its arguments, so can't be empty.
+func Command(argv ...string) (Notifier, error) {
+	if len(argv) == 0 {
+		return nil, errors.New("no command")
+	}
+	return Func(func(ctx context.Context, ev lmsensors.AlarmEvent) error {
+		cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+		cmd.Env = append(os.Environ(),
+			"LMSENSORS_CHIP="+ev.Chip,
+			"LMSENSORS_SENSOR="+ev.Sensor,
+			"LMSENSORS_RULE="+ev.Rule,
+			"LMSENSORS_VALUE="+strconv.FormatFloat(ev.Value, 'f', -1, 64),
+			"LMSENSORS_STATE="+state(ev),
+		)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s: %w: %s", argv[0], err, bytes.TrimSpace(out))
+		}
+		return nil
+	}), nil
+}
+
+// Webhook POSTs each event to a URL.
+type Webhook struct {
+	URL      string
+	Template *template.Template // Renders the body from a [lmsensors.AlarmEvent]; nil for the default JSON
+	Headers  map[string]string
+	Client   *http.Client // Defaults to http.DefaultClient
+}
+
+// templateFuncs are available in webhook templates: json quotes a value, and state is "raised" or "cleared".
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		p, err := json.Marshal(v)
+		return string(p), err
+	},
+	"state": state,
+}
+
+// NewWebhook makes a [Webhook], parsing its body template if one's given, eg
+//
+//	{"text": "{{.Chip}}/{{.Sensor}} is {{state .}} at {{.Value}}"}
+func NewWebhook(url, tmpl string) (*Webhook, error) {
+	w := &Webhook{URL: url}
+	if tmpl != "" {
+		t, err := template.New("webhook").Funcs(templateFuncs).Parse(tmpl)
+		if err != nil {
+			return nil, err
+		}
+		w.Template = t
+	}
+	return w, nil
+}
+
+// eventJSON is the default webhook body.
+type eventJSON struct {
+	Chip   string   `json:"chip"`
+	Sensor string   `json:"sensor"`
+	Rule   string   `json:"rule,omitempty"`
+	Value  *float64 `json:"value"` // null for a failed reading, which JSON can't hold as NaN
+	State  string   `json:"state"`
+}
+
+func (w *Webhook) body(ev lmsensors.AlarmEvent) ([]byte, error) {
+	if w.Template == nil {
+		var value *float64
+		if !math.IsNaN(ev.Value) && !math.IsInf(ev.Value, 0) {
+			value = &ev.Value
+		}
+		return json.Marshal(eventJSON{ev.Chip, ev.Sensor, ev.Rule, value, state(ev)})
+	}
+	var buf bytes.Buffer
+	if err := w.Template.Execute(&buf, ev); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (w *Webhook) Notify(ctx context.Context, ev lmsensors.AlarmEvent) error {
+	body, err := w.body(ev)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s: %s", w.URL, resp.Status)
+	}
+	return nil
+}
+
+// Email sends each event by SMTP, authenticating with PLAIN if a username is given.
+type Email struct {
+	Server   string // host:port
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+func (e *Email) message(ev lmsensors.AlarmEvent) []byte {
+	subject := fmt.Sprintf("%s/%s alarm %s", ev.Chip, ev.Sensor, state(ev))
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", subject)
+	b.WriteString("\r\n")
+	fmt.Fprintf(&b, "Chip: %s\r\nSensor: %s\r\n", ev.Chip, ev.Sensor)
+	if ev.Rule != "" {
+		fmt.Fprintf(&b, "Rule: %s\r\n", ev.Rule)
+	}
+	fmt.Fprintf(&b, "Value: %g\r\nState: %s\r\n", ev.Value, state(ev))
+	return []byte(b.String())
+}
+
+func (e *Email) Notify(ctx context.Context, ev lmsensors.AlarmEvent) error {
+	if len(e.To) == 0 {
+		return errors.New("email: no recipients")
+	}
+	var auth smtp.Auth
+	if e.Username != "" {
+		host, _, _ := strings.Cut(e.Server, ":")
+		auth = smtp.PlainAuth("", e.Username, e.Password, host)
+	}
+	// net/smtp doesn't take a context; run it aside so we at least return on cancellation.
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(e.Server, auth, e.From, e.To, e.message(ev))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
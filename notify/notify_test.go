@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/config"
+)
+
+var event = lmsensors.AlarmEvent{Chip: "k10temp-pci-00c3", Sensor: "Tctl", Rule: "cpu hot", Value: 95.5, Raised: true}
+
+func TestWebhook(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(p))
+		if r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+
+	ns, err := FromConfig([]config.Action{
+		{Webhook: &config.WebhookAction{URL: srv.URL, Headers: map[string]string{"X-Token": "secret"}}},
+		{Webhook: &config.WebhookAction{URL: srv.URL, Template: `{"text": {{json (printf "%s is %s" .Sensor (state .))}}}`}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ns[0].Notify(context.Background(), event); err != nil {
+		t.Error(err)
+	}
+	if err := ns[1].Notify(context.Background(), event); err == nil {
+		t.Error("no error for forbidden")
+	}
+
+	var def map[string]any
+	if err := json.Unmarshal([]byte(bodies[0]), &def); err != nil || def["state"] != "raised" || def["value"] != 95.5 {
+		t.Errorf("wrong default body: %s", bodies[0])
+	}
+	if bodies[1] != `{"text": "Tctl is raised"}` {
+		t.Errorf("wrong templated body: %s", bodies[1])
+	}
+
+	failed := event
+	failed.Value, failed.Raised = lmsensors.NoValue, false
+	if err := ns[0].Notify(context.Background(), failed); err != nil {
+		t.Fatalf("failed reading: %v", err)
+	}
+	if err := json.Unmarshal([]byte(bodies[2]), &def); err != nil || def["value"] != nil || def["state"] != "cleared" {
+		t.Errorf("wrong default body for a failed reading: %s", bodies[2])
+	}
+}
+
+func TestCommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	n, err := Command("sh", "-c", `echo "$LMSENSORS_SENSOR $LMSENSORS_STATE $LMSENSORS_VALUE" > `+out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	p, _ := os.ReadFile(out)
+	if got := strings.TrimSpace(string(p)); got != "Tctl raised 95.5" {
+		t.Errorf("wrong environment: %q", got)
+	}
+
+	if n, _ := Command("false"); n.Notify(context.Background(), event) == nil {
+		t.Error("no error for failing command")
+	}
+	if _, err := Command(); err == nil {
+		t.Error("no error for no command")
+	}
+}
+
+func TestEmailMessage(t *testing.T) {
+	e := &Email{From: "sensors@example.com", To: []string{"ops@example.com"}}
+	msg := string(e.message(event))
+	for _, want := range []string{"Subject: k10temp-pci-00c3/Tctl alarm raised\r\n", "Rule: cpu hot\r\n", "Value: 95.5\r\n"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message doesn't contain %q:\n%s", want, msg)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package lmsensors
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// powerLabels are the ways voltage and current sensors on the same rail are labelled, and how to label their product, most specific first.
+// They cover the defaults (in1, curr1), pmbus (vin, iin; vout1, iout1), corsair-psu (v_out +12v, curr +12v), and the common VRM labels (Vcore, Icore).
+var powerLabels = []struct {
+	v, i, p string
+}{
+	{"v_in", "curr in", "power in"},
+	{"v_out ", "curr ", "power "},
+	{"vin", "iin", "pin"},
+	{"vout", "iout", "pout"},
+	{"in", "curr", "power"},
+	{"v", "i", "p"},
+}
+
+// powerSuffixes are like powerLabels, for labels like "VDDCR_CPU Voltage".
+var powerSuffixes = []struct {
+	v, i, p string
+}{
+	{" voltage", " current", " power"},
+}
+
+// WithSynthesizedPower adds a power sensor, the product of voltage and current, for each rail of chips that measure both but don't report power themselves, as many VRM controllers don't.
+// Rails are paired up by the labels of their sensors, eg "Vcore" and "Icore" make "Pcore", and "in1" and "curr1" make "power1".
+func WithSynthesizedPower() Option {
+	return func(o *getOptions) {
+		o.synthesizePower = true
+	}
+}
+
+// synthesizePower adds the power sensors described by [WithSynthesizedPower] to a chip.
+func synthesizePower(ch *Chip) {
+	currents := map[string]Sensor{}
+	for name, s := range ch.Sensors {
+		if g, ok := s.(*GenericSensor); ok && g.FeatureType == Power {
+			return // Reports its own
+		}
+		if _, ok := s.(*CurrentSensor); ok {
+			currents[strings.ToLower(name)] = s
+		}
+	}
+	if len(currents) == 0 {
+		return
+	}
+	var powers []Sensor
+	for name, v := range ch.Sensors {
+		if _, ok := v.(*VoltageSensor); !ok {
+			continue
+		}
+		i, pname, ok := pairCurrent(name, currents)
+		if !ok {
+			continue
+		}
+		if _, clash := ch.Sensors[pname]; clash {
+			continue
+		}
+		p := &GenericSensor{baseSensor: baseSensor{Name: pname, Value: NoValue}, FeatureType: Power, unit: "W"}
+		if Valid(v) && Valid(i) {
+			p.Value = v.Reading() * i.Reading()
+		}
+		p.Time = later(TimeOf(v), TimeOf(i))
+		powers = append(powers, p)
+	}
+	for _, p := range powers {
+		ch.Sensors[p.GetName()] = p
+	}
+}
+
+// pairCurrent finds the current sensor on the same rail as a voltage one, and makes the name of their power sensor.
+func pairCurrent(voltage string, currents map[string]Sensor) (Sensor, string, bool) {
+	l := strings.ToLower(voltage)
+	for _, pl := range powerLabels {
+		rest, ok := strings.CutPrefix(l, pl.v)
+		if !ok || rest == "" {
+			continue
+		}
+		if i, ok := currents[pl.i+rest]; ok {
+			return i, matchCase(voltage[:len(pl.v)], pl.p) + voltage[len(pl.v):], true
+		}
+	}
+	for _, ps := range powerSuffixes {
+		rest, ok := strings.CutSuffix(l, ps.v)
+		if !ok || rest == "" {
+			continue
+		}
+		if i, ok := currents[rest+ps.i]; ok {
+			return i, voltage[:len(rest)] + matchCase(voltage[len(rest):], ps.p), true
+		}
+	}
+	return nil, "", false
+}
+
+// matchCase makes s upper case if like is, or capitalised if like is, because labels come in all styles, eg "VIN", "Vcore", "vout1".
+func matchCase(like, s string) string {
+	letters := strings.TrimSpace(like)
+	switch {
+	case len(letters) > 1 && letters == strings.ToUpper(letters):
+		return strings.ToUpper(s)
+	case letters != "" && unicode.IsUpper(rune(letters[0])):
+		lead := len(s) - len(strings.TrimLeft(s, " "))
+		return s[:lead] + strings.ToUpper(s[lead:lead+1]) + s[lead+1:]
+	}
+	return s
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,47 @@
+package lmsensors
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSynthesizePower(t *testing.T) {
+	volt := func(name string, v float64) Sensor { return &VoltageSensor{baseSensor{Name: name, Value: v}} }
+	curr := func(name string, v float64) Sensor { return &CurrentSensor{baseSensor{Name: name, Value: v}} }
+	ch := &Chip{ID: "tps53679-i2c-7-60", Sensors: map[string]Sensor{
+		"VIN":               volt("VIN", 12),
+		"IIN":               curr("IIN", 2),
+		"Vcore":             volt("Vcore", 1.2),
+		"Icore":             curr("Icore", 50),
+		"in1":               volt("in1", 3.3),
+		"curr1":             curr("curr1", NoValue),
+		"VDDCR_SOC Voltage": volt("VDDCR_SOC Voltage", 1.0),
+		"VDDCR_SOC Current": curr("VDDCR_SOC Current", 10),
+		"Vmem":              volt("Vmem", 1.1), // No current
+	}}
+	synthesizePower(ch)
+	for name, want := range map[string]float64{"PIN": 24, "Pcore": 60, "power1": NoValue, "VDDCR_SOC Power": 10} {
+		s, ok := ch.Sensors[name]
+		if !ok {
+			t.Errorf("no %s: %v", name, ch.Sensors)
+			continue
+		}
+		if got := s.Reading(); !(got == want || math.IsNaN(got) && math.IsNaN(want)) || s.Unit() != "W" {
+			t.Errorf("%s: %v %s", name, got, s.Unit())
+		}
+	}
+	if len(ch.Sensors) != 13 {
+		t.Errorf("wrong sensors: %v", ch.Sensors)
+	}
+
+	// Chips that report power are left alone.
+	ch = &Chip{ID: "ina238-i2c-1-40", Sensors: map[string]Sensor{
+		"in1":    volt("in1", 12),
+		"curr1":  curr("curr1", 2),
+		"power1": &GenericSensor{baseSensor: baseSensor{Name: "power1", Value: 24}, FeatureType: Power, unit: "W"},
+	}}
+	synthesizePower(ch)
+	if len(ch.Sensors) != 3 {
+		t.Errorf("power synthesized for chip with its own: %v", ch.Sensors)
+	}
+}
@@ -0,0 +1,174 @@
+package lmsensors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ChipAccess is which of a chip's sysfs attributes the current user can read and write.
+type ChipAccess struct {
+	ID     string
+	Prefix string // eg nct6798
+	Path   string
+
+	Readable   []string // Attributes, eg temp1_input
+	Unreadable []string
+	Writable   []string // Of the attributes that can be written at all, eg pwm1 or temp1_max
+	Unwritable []string
+}
+
+// PreflightReport is what the current user can do with each chip, see [Preflight].
+type PreflightReport struct {
+	Chips    []ChipAccess
+	Degraded []string // Human readable descriptions of what won't work
+}
+
+// Preflight checks, for each chip, which attributes the current user can read and write, and summarises what won't work because of it, so that applications can warn at startup rather than fail later.
+// It walks sysfs under [SysfsRoot] directly, so it doesn't need [Init].
+func Preflight() (*PreflightReport, error) {
+	devs, err := HwmonDevices()
+	if err != nil {
+		return nil, err
+	}
+	r := &PreflightReport{}
+	if readOnly.Load() {
+		r.degradedf("read-only mode is on, so nothing can be written")
+	}
+	for _, dev := range devs {
+		ca, err := chipAccess(dev)
+		if err != nil {
+			logger.Debug("skipping hwmon device", "path", dev.Path, "error", err)
+			continue
+		}
+		r.Chips = append(r.Chips, ca)
+	}
+	sort.Slice(r.Chips, func(i, j int) bool { return r.Chips[i].ID < r.Chips[j].ID })
+	for _, ca := range r.Chips {
+		r.degraded(ca)
+	}
+	if len(r.Chips) == 0 {
+		r.degradedf("no chips under %s; see Diagnose", hwmonDir())
+	}
+	return r, nil
+}
+
+func chipAccess(dev HwmonDevice) (ChipAccess, error) {
+	t, nr, addr, err := sysfsBus(dev.Path)
+	if err != nil {
+		return ChipAccess{}, err
+	}
+	id, _ := sysfsChipName(dev.Name, t, nr, addr)
+	ca := ChipAccess{ID: id, Prefix: dev.Name, Path: dev.attrDir}
+	attrs, err := dev.Attributes()
+	if err != nil {
+		return ChipAccess{}, err
+	}
+	for _, attr := range attrs {
+		path := filepath.Join(dev.attrDir, attr)
+		if denied(access(path, 4 /* R_OK */)) {
+			ca.Unreadable = append(ca.Unreadable, attr)
+		} else {
+			ca.Readable = append(ca.Readable, attr)
+		}
+		if st, err := os.Stat(path); err != nil || st.Mode().Perm()&0o222 == 0 {
+			continue // Read-only for everyone, eg temp1_input
+		}
+		if denied(access(path, 2 /* W_OK */)) {
+			ca.Unwritable = append(ca.Unwritable, attr)
+		} else {
+			ca.Writable = append(ca.Writable, attr)
+		}
+	}
+	return ca, nil
+}
+
+// writeCapabilities are what writing each kind of attribute is for, in the order they're reported.
+var writeCapabilities = []struct {
+	name  string
+	match func(attr string) bool
+}{
+	{"fan control", func(attr string) bool { return strings.HasPrefix(attr, "pwm") }},
+	{"beeps", func(attr string) bool { return strings.HasSuffix(attr, "_beep") || attr == "beep_enable" }},
+	{"clearing intrusion alarms", func(attr string) bool { return strings.HasPrefix(attr, "intrusion") }},
+	{"setting limits", func(attr string) bool { return true }},
+}
+
+func (r *PreflightReport) degraded(ca ChipAccess) {
+	if len(ca.Unreadable) != 0 {
+		r.degradedf("%s: can't read %s, so those sensors will be missing or incomplete", ca.ID, examples(ca.Unreadable))
+	}
+	byCap := map[string][]string{}
+	for _, attr := range ca.Unwritable {
+		for _, c := range writeCapabilities {
+			if c.match(attr) {
+				byCap[c.name] = append(byCap[c.name], attr)
+				break
+			}
+		}
+	}
+	for _, c := range writeCapabilities {
+		if attrs := byCap[c.name]; len(attrs) != 0 {
+			r.degradedf("%s: %s won't work, as %s can't be written; run as root, or see UdevRules", ca.ID, c.name, examples(attrs))
+		}
+	}
+}
+
+// examples lists the first few attributes.
+func examples(attrs []string) string {
+	if len(attrs) > 3 {
+		return strings.Join(attrs[:3], ", ") + fmt.Sprintf(" and %d more", len(attrs)-3)
+	}
+	return strings.Join(attrs, ", ")
+}
+
+func (r *PreflightReport) degradedf(format string, args ...any) {
+	r.Degraded = append(r.Degraded, fmt.Sprintf(format, args...))
+}
+
+// OK is true when everything can be read and written.
+func (r *PreflightReport) OK() bool {
+	return len(r.Degraded) == 0
+}
+
+// Grants are the [Grant]s that would make all the unwritable attributes writable, for [UdevRules].
+func (r *PreflightReport) Grants() []Grant {
+	byPrefix := map[string]map[string]bool{}
+	for _, ca := range r.Chips {
+		for _, attr := range ca.Unwritable {
+			if byPrefix[ca.Prefix] == nil {
+				byPrefix[ca.Prefix] = map[string]bool{}
+			}
+			byPrefix[ca.Prefix][attr] = true
+		}
+	}
+	grants := make([]Grant, 0, len(byPrefix))
+	for prefix, attrs := range byPrefix {
+		g := Grant{Prefix: prefix}
+		for attr := range attrs {
+			g.Attrs = append(g.Attrs, attr)
+		}
+		sort.Strings(g.Attrs)
+		grants = append(grants, g)
+	}
+	sort.Slice(grants, func(i, j int) bool { return grants[i].Prefix < grants[j].Prefix })
+	return grants
+}
+
+func (r *PreflightReport) String() string {
+	var ret strings.Builder
+	var readable, unreadable, writable, unwritable int
+	for _, ca := range r.Chips {
+		readable += len(ca.Readable)
+		unreadable += len(ca.Unreadable)
+		writable += len(ca.Writable)
+		unwritable += len(ca.Unwritable)
+	}
+	fmt.Fprintf(&ret, "chips=%d readable=%d unreadable=%d writable=%d unwritable=%d", len(r.Chips), readable, unreadable, writable, unwritable)
+	for _, d := range r.Degraded {
+		ret.WriteString("\n  " + d)
+	}
+	return ret.String()
+}
@@ -0,0 +1,61 @@
+package lmsensors
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestPreflight(t *testing.T) {
+	root := t.TempDir()
+	defer func() { SysfsRoot = "/sys" }()
+	SysfsRoot = root
+	dir := filepath.Join(root, "class", "hwmon", "hwmon0")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for attr, mode := range map[string]os.FileMode{"name": 0o444, "temp1_input": 0o444, "temp1_max": 0o644, "fan1_input": 0o444, "pwm1": 0o644, "pwm1_enable": 0o644} {
+		if err := os.WriteFile(filepath.Join(dir, attr), []byte("1\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "name"), []byte("nct6798\n"), 0o444); err != nil {
+		t.Fatal(err)
+	}
+
+	// Root can do anything, so pretend not to be
+	defer func(orig func(string, uint32) error) { access = orig }(access)
+	access = func(path string, mode uint32) error {
+		switch attr := filepath.Base(path); {
+		case mode == 4 && attr == "fan1_input", mode == 2 && strings.HasPrefix(attr, "pwm"):
+			return syscall.EACCES
+		}
+		return nil
+	}
+
+	r, err := Preflight()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ChipAccess{
+		ID:         "nct6798-virtual-0",
+		Prefix:     "nct6798",
+		Path:       dir,
+		Readable:   []string{"pwm1", "pwm1_enable", "temp1_input", "temp1_max"},
+		Unreadable: []string{"fan1_input"},
+		Writable:   []string{"temp1_max"},
+		Unwritable: []string{"pwm1", "pwm1_enable"},
+	}
+	if len(r.Chips) != 1 || !reflect.DeepEqual(r.Chips[0], want) {
+		t.Errorf("got %+v\nwant %+v", r.Chips, want)
+	}
+	if r.OK() || len(r.Degraded) != 2 || !strings.Contains(r.Degraded[0], "fan1_input") || !strings.Contains(r.Degraded[1], "fan control") {
+		t.Errorf("wrong summary: %s", r)
+	}
+	if g := r.Grants(); !reflect.DeepEqual(g, []Grant{{"nct6798", []string{"pwm1", "pwm1_enable"}}}) {
+		t.Errorf("wrong grants: %+v", g)
+	}
+}
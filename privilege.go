@@ -0,0 +1,45 @@
+package lmsensors
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"syscall"
+)
+
+// ErrNeedsPrivilege matches, with [errors.Is], every [PrivilegeError].
+var ErrNeedsPrivilege = errors.New("needs privilege")
+
+// PrivilegeError is returned by writes to sysfs files the current user isn't allowed to write, which usually means not being root.
+type PrivilegeError struct {
+	Path     string // The sysfs file
+	Prefix   string // The chip's prefix, eg nct6798
+	UdevRule string // A udev rule that would let the sensors group write it instead
+}
+
+func (e *PrivilegeError) Error() string {
+	return fmt.Sprintf("can't write %s: permission denied; run as root, or grant access with a udev rule like: %s", e.Path, e.UdevRule)
+}
+
+func (e *PrivilegeError) Is(err error) bool {
+	return err == ErrNeedsPrivilege
+}
+
+const atEaccess = 0x200 // AT_EACCESS, ie check with the effective uid and gid
+
+// access checks whether the effective user can access path with mode, eg W_OK; it's a variable so that tests running as root can be denied.
+var access = func(path string, mode uint32) error {
+	return syscall.Faccessat(-100 /* AT_FDCWD */, path, mode, atEaccess)
+}
+
+func denied(err error) bool {
+	return errors.Is(err, syscall.EACCES) || errors.Is(err, syscall.EPERM)
+}
+
+// checkWritable returns a [PrivilegeError] if the effective user can't write path.
+func checkWritable(prefix, path string) error {
+	if !denied(access(path, 2 /* W_OK */)) {
+		return nil // Other problems, eg a missing file, are for the write itself to report
+	}
+	return &PrivilegeError{Path: path, Prefix: prefix, UdevRule: udevRule("sensors", prefix, filepath.Base(path))}
+}
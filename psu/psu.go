@@ -0,0 +1,150 @@
+// Package psu assembles the sensors of PMBus-style power supplies (corsair-psu, the pmbus drivers) into a structured report.
+package psu
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Rail is one output of a power supply, eg +12V, or one PMBus page.
+// Values a PSU doesn't report are 0; Power is computed from Voltage and Current if it's not reported.
+type Rail struct {
+	Name    string
+	Voltage float64
+	Current float64
+	Power   float64
+}
+
+// Report is the state of one power supply.
+// Values a PSU doesn't report, and can't be computed, are 0.
+type Report struct {
+	Chip string
+
+	InputVoltage float64
+	InputCurrent float64
+	InputPower   float64 // Reported, or computed from InputVoltage and InputCurrent
+	OutputPower  float64 // Reported total, or the sum of the rails
+	Efficiency   float64 // OutputPower / InputPower
+
+	Rails []Rail // Sorted by name
+}
+
+// Reports makes a [Report] for every PSU chip in the system, sorted by chip ID.
+func Reports(sys *lmsensors.System) []Report {
+	var rs []Report
+	for _, chip := range sys.Chips {
+		if chip.Kind() != lmsensors.KindPSU {
+			continue
+		}
+		rs = append(rs, FromChip(chip))
+	}
+	sort.Slice(rs, func(i, j int) bool { return rs[i].Chip < rs[j].Chip })
+	return rs
+}
+
+type direction int
+
+const (
+	output direction = iota
+	input
+	total
+)
+
+// labelPrefixes are how the corsair-psu ("v_out +12v", "curr in", "power total") and pmbus ("vout1", "iin", "pin") drivers label their sensors.
+var labelPrefixes = []struct {
+	prefix   string
+	quantity lmsensors.LmSensorType
+	dir      direction
+}{
+	{"v_in", lmsensors.Voltage, input},
+	{"vin", lmsensors.Voltage, input},
+	{"v_out", lmsensors.Voltage, output},
+	{"vout", lmsensors.Voltage, output},
+	{"curr in", lmsensors.Current, input},
+	{"iin", lmsensors.Current, input},
+	{"curr", lmsensors.Current, output},
+	{"iout", lmsensors.Current, output},
+	{"power total", lmsensors.Power, total},
+	{"power in", lmsensors.Power, input},
+	{"pin", lmsensors.Power, input},
+	{"pout", lmsensors.Power, output},
+	{"power", lmsensors.Power, output},
+}
+
+func parseLabel(label string) (quantity lmsensors.LmSensorType, dir direction, rail string, ok bool) {
+	l := strings.ToLower(strings.TrimSpace(label))
+	for _, p := range labelPrefixes {
+		if rest, found := strings.CutPrefix(l, p.prefix); found {
+			return p.quantity, p.dir, strings.TrimSpace(rest), true
+		}
+	}
+	return 0, 0, "", false
+}
+
+// FromChip assembles a [Report] from a PSU chip's sensors.
+func FromChip(chip *lmsensors.Chip) Report {
+	r := Report{Chip: chip.ID}
+	rails := map[string]*Rail{}
+	var totalPower float64
+	for _, s := range chip.Sensors {
+		if !lmsensors.Valid(s) {
+			continue
+		}
+		quantity, dir, name, ok := parseLabel(s.GetName())
+		if !ok || lmsensors.FeatureTypeOf(s) != quantity {
+			continue
+		}
+		v := s.Reading()
+		switch dir {
+		case input:
+			switch quantity {
+			case lmsensors.Voltage:
+				r.InputVoltage = v
+			case lmsensors.Current:
+				r.InputCurrent = v
+			case lmsensors.Power:
+				r.InputPower = v
+			}
+		case total:
+			totalPower = v
+		case output:
+			rail, ok := rails[name]
+			if !ok {
+				rail = &Rail{Name: name}
+				rails[name] = rail
+			}
+			switch quantity {
+			case lmsensors.Voltage:
+				rail.Voltage = v
+			case lmsensors.Current:
+				rail.Current = v
+			case lmsensors.Power:
+				rail.Power = v
+			}
+		}
+	}
+
+	var railPower float64
+	for _, rail := range rails {
+		if rail.Power == 0 {
+			rail.Power = rail.Voltage * rail.Current
+		}
+		railPower += rail.Power
+		r.Rails = append(r.Rails, *rail)
+	}
+	sort.Slice(r.Rails, func(i, j int) bool { return r.Rails[i].Name < r.Rails[j].Name })
+
+	if r.InputPower == 0 {
+		r.InputPower = r.InputVoltage * r.InputCurrent
+	}
+	r.OutputPower = totalPower
+	if r.OutputPower == 0 {
+		r.OutputPower = railPower
+	}
+	if r.InputPower != 0 {
+		r.Efficiency = r.OutputPower / r.InputPower
+	}
+	return r
+}
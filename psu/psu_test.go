@@ -0,0 +1,74 @@
+package psu
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+func volts(name string, v float64) lmsensors.Sensor {
+	s := &lmsensors.VoltageSensor{}
+	s.Name, s.Value = name, v
+	return s
+}
+
+func amps(name string, v float64) lmsensors.Sensor {
+	s := &lmsensors.CurrentSensor{}
+	s.Name, s.Value = name, v
+	return s
+}
+
+func watts(name string, v float64) lmsensors.Sensor {
+	s := &lmsensors.GenericSensor{FeatureType: lmsensors.Power}
+	s.Name, s.Value = name, v
+	return s
+}
+
+func chip(id, typ string, sensors ...lmsensors.Sensor) *lmsensors.Chip {
+	c := &lmsensors.Chip{ID: id, Type: typ, Sensors: map[string]lmsensors.Sensor{}}
+	for _, s := range sensors {
+		c.Sensors[s.GetName()] = s
+	}
+	return c
+}
+
+func TestCorsair(t *testing.T) {
+	r := FromChip(chip("corsairpsu-hid-3-1", "corsairpsu",
+		volts("v_in", 230), amps("curr in", 1.2),
+		volts("v_out +12v", 12.1), amps("curr +12v", 15), watts("power +12v", 181.5),
+		volts("v_out +5v", 5), amps("curr +5v", 2),
+		watts("power total", 230),
+	))
+	if r.InputPower != 276 || r.OutputPower != 230 || math.Abs(r.Efficiency-230.0/276) > 1e-9 {
+		t.Errorf("wrong totals: %+v", r)
+	}
+	if len(r.Rails) != 2 || r.Rails[0].Name != "+12v" || r.Rails[0].Power != 181.5 || r.Rails[1].Name != "+5v" || r.Rails[1].Power != 10 {
+		t.Errorf("wrong rails: %+v", r.Rails)
+	}
+}
+
+func TestPMBus(t *testing.T) {
+	r := FromChip(chip("pmbus-i2c-3-58", "pmbus",
+		volts("vin", 120), watts("pin", 500),
+		volts("vout1", 12), amps("iout1", 20), watts("pout1", 240),
+		volts("vout2", 12), amps("iout2", 20),
+	))
+	if r.InputPower != 500 || r.OutputPower != 480 || r.Efficiency != 0.96 {
+		t.Errorf("wrong totals: %+v", r)
+	}
+	if len(r.Rails) != 2 || r.Rails[0].Name != "1" || r.Rails[1].Power != 240 {
+		t.Errorf("wrong rails: %+v", r.Rails)
+	}
+}
+
+func TestReports(t *testing.T) {
+	sys := &lmsensors.System{Chips: map[string]*lmsensors.Chip{
+		"pmbus-i2c-3-58":   chip("pmbus-i2c-3-58", "pmbus", watts("pin", 100)),
+		"k10temp-pci-00c3": chip("k10temp-pci-00c3", "k10temp"),
+	}}
+	rs := Reports(sys)
+	if len(rs) != 1 || rs[0].Chip != "pmbus-i2c-3-58" || rs[0].InputPower != 100 {
+		t.Errorf("wrong reports: %+v", rs)
+	}
+}
@@ -0,0 +1,150 @@
+package lmsensors
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PWMs lists the device's PWM output channels, eg 1 for pwm1.
+func (d HwmonDevice) PWMs() ([]int, error) {
+	ms, err := fanMappings(d.attrDir)
+	if err != nil {
+		return nil, err
+	}
+	chans := make([]int, len(ms))
+	for i, m := range ms {
+		chans[i] = m.PWM
+	}
+	return chans, nil
+}
+
+// SetPWM puts a PWM output into manual mode at the given duty cycle, where 255 is full speed.
+// libsensors doesn't cover PWMs, so this writes sysfs directly; the writes go through the [AuditFunc], and aren't made in dry-run mode, see [SetDryRun].
+func (d HwmonDevice) SetPWM(channel int, duty uint8) error {
+	if err := d.SetPWMMode(channel, 1); err != nil {
+		return err
+	}
+	return d.SetPWMDuty(channel, duty)
+}
+
+// SetPWMDuty writes a PWM output's duty cycle, without changing its mode, for drivers where that's done some other way.
+// The write goes through the [AuditFunc], and isn't made in dry-run mode, see [SetDryRun].
+func (d HwmonDevice) SetPWMDuty(channel int, duty uint8) error {
+	return d.writeAttribute("pwm"+strconv.Itoa(channel), float64(duty))
+}
+
+// SetPWMMode writes a PWM output's raw pwm*_enable mode, eg to hand control back to the firmware. The modes are driver-specific, see [FanMapping].
+// The write goes through the [AuditFunc], and isn't made in dry-run mode, see [SetDryRun].
+func (d HwmonDevice) SetPWMMode(channel int, mode int) error {
+	return d.writeAttribute("pwm"+strconv.Itoa(channel)+"_enable", float64(mode))
+}
+
+// pwmModes are the pwm*_enable modes of drivers with more than the generic ABI's, see [Quirk.PWMModes].
+var pwmModes = map[string][]int{
+	"nct6775": {0, 1, 2, 3, 4, 5}, // Full speed, manual, Thermal Cruise, Fan Speed Cruise, SmartFan III and IV
+	"nct6776": {0, 1, 2, 3, 4, 5},
+	"nct6779": {0, 1, 2, 3, 4, 5},
+	"nct6791": {0, 1, 2, 3, 4, 5},
+	"nct6792": {0, 1, 2, 3, 4, 5},
+	"nct6793": {0, 1, 2, 3, 4, 5},
+	"nct6795": {0, 1, 2, 3, 4, 5},
+	"nct6796": {0, 1, 2, 3, 4, 5},
+	"nct6797": {0, 1, 2, 3, 4, 5},
+	"nct6798": {0, 1, 2, 3, 4, 5},
+	"nct6799": {0, 1, 2, 3, 4, 5},
+}
+
+// PWMModes lists the raw pwm*_enable modes the device's driver takes, for [HwmonDevice.SetPWMMode]: its [Quirk.PWMModes], or else the generic ABI's 0 for full speed, 1 for manual and 2 for automatic.
+func (d HwmonDevice) PWMModes() []int {
+	if ms := quirkOf(d.Name).PWMModes; ms != nil {
+		return ms
+	}
+	if ms, ok := pwmModes[d.Name]; ok {
+		return ms
+	}
+	return []int{0, 1, 2}
+}
+
+func (d HwmonDevice) writeAttribute(attr string, val float64) error {
+	return writeAttribute(filepath.Base(d.Path), d.Name, d.attrDir, attr, val)
+}
+
+// writeAttribute writes a sysfs attribute directly, through [audit], after anything the driver's [Quirk] needs doing first.
+func writeAttribute(chip, prefix, dir, attr string, val float64) error {
+	if err := beforeWrite(chip, prefix, dir, attr); err != nil {
+		return err
+	}
+	return writeAttributeRaw(chip, prefix, dir, attr, val)
+}
+
+func writeAttributeRaw(chip, prefix, dir, attr string, val float64) error {
+	path := filepath.Join(dir, attr)
+	ev := WriteEvent{Chip: chip, Prefix: prefix, Attr: attr, Path: path, New: val}
+	old, err := os.ReadFile(path)
+	ev.OldErr = err
+	if err == nil {
+		ev.Old, ev.OldErr = strconv.ParseFloat(strings.TrimSpace(string(old)), 64)
+	}
+	return audit(ev, func() error {
+		return os.WriteFile(path, []byte(strconv.FormatFloat(val, 'f', -1, 64)), 0)
+	})
+}
+
+// Slew limits how fast a duty changes, as abrupt changes in fan speed are audible, and some controllers misbehave on large jumps.
+// The zero value doesn't limit anything.
+type Slew struct {
+	Rate float64 // The most the duty can change by, in percent of full speed per second, eg 10 to take 10s from stopped to full; 0 for no limit
+
+	duty float64
+	at   time.Time
+}
+
+// Limit is the duty to write, at now, on the way to target; the first call, and any after a [Slew.Reset], go straight to target.
+func (s *Slew) Limit(target uint8, now time.Time) uint8 {
+	next := float64(target)
+	if s.Rate > 0 && !s.at.IsZero() {
+		step := s.Rate / 100 * 255 * now.Sub(s.at).Seconds()
+		next = math.Max(s.duty-step, math.Min(s.duty+step, next))
+	}
+	s.duty, s.at = next, now
+	return uint8(math.Round(next))
+}
+
+// Reset makes the next [Slew.Limit] start from duty at now, eg after the duty's been changed some other way.
+func (s *Slew) Reset(duty uint8, now time.Time) {
+	s.duty, s.at = float64(duty), now
+}
+
+// RampPWMDuty moves a PWM output's duty to duty at no more than rate percent of full speed per second, writing it every tenth of a second until it's there, or ctx is done.
+// Like [HwmonDevice.SetPWMDuty], it doesn't change the mode.
+func (d HwmonDevice) RampPWMDuty(ctx context.Context, channel int, duty uint8, rate float64) error {
+	cur, err := d.ReadAttribute("pwm" + strconv.Itoa(channel))
+	if err != nil {
+		return err
+	}
+	from, err := strconv.Atoi(cur)
+	if err != nil {
+		return err
+	}
+	s := Slew{Rate: rate}
+	s.Reset(uint8(from), time.Now())
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+	for at := uint8(from); at != duty; {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-tick.C:
+			at = s.Limit(duty, now)
+			if err := d.SetPWMDuty(channel, at); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
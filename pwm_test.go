@@ -0,0 +1,80 @@
+package lmsensors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSetPWM(t *testing.T) {
+	dir := t.TempDir()
+	for attr, val := range map[string]string{"pwm1": "100\n", "pwm1_enable": "2\n"} {
+		if err := os.WriteFile(filepath.Join(dir, attr), []byte(val), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var evs []WriteEvent
+	SetAuditFunc(func(ev WriteEvent) { evs = append(evs, ev) })
+	defer SetAuditFunc(nil)
+
+	d := HwmonDevice{Name: "it87", Path: "/sys/class/hwmon/hwmon1", attrDir: dir}
+	if chans, err := d.PWMs(); err != nil || len(chans) != 1 || chans[0] != 1 {
+		t.Errorf("wrong PWMs: %v %v", chans, err)
+	}
+	if err := d.SetPWM(1, 255); err != nil {
+		t.Fatal(err)
+	}
+	for attr, want := range map[string]string{"pwm1": "255", "pwm1_enable": "1"} {
+		if got, _ := d.ReadAttribute(attr); got != want {
+			t.Errorf("%s is %q, want %q", attr, got, want)
+		}
+	}
+	if len(evs) != 2 || evs[0].Attr != "pwm1_enable" || evs[0].Old != 2 || evs[1].Old != 100 || evs[1].Chip != "hwmon1" {
+		t.Errorf("wrong audit events: %+v", evs)
+	}
+}
+
+func TestSlew(t *testing.T) {
+	s := Slew{Rate: 10} // 25.5 duty per second
+	start := time.Now()
+	if d := s.Limit(64, start); d != 64 {
+		t.Errorf("first limit should go straight there, got %d", d)
+	}
+	for i, want := range []uint8{90, 115, 141, 166, 192, 217, 243, 255, 255} {
+		if d := s.Limit(255, start.Add(time.Duration(i+1)*time.Second)); d != want {
+			t.Errorf("step %d: got %d, want %d", i, d, want)
+		}
+	}
+	s.Reset(100, start)
+	if d := s.Limit(0, start.Add(2*time.Second)); d != 49 {
+		t.Errorf("down from reset: got %d", d)
+	}
+	if d := (&Slew{}).Limit(200, start); d != 200 {
+		t.Errorf("zero Slew limited: %d", d)
+	}
+}
+
+func TestRampPWMDuty(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pwm1"), []byte("100\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var duties []float64
+	SetAuditFunc(func(ev WriteEvent) { duties = append(duties, ev.New) })
+	defer SetAuditFunc(nil)
+	d := HwmonDevice{Name: "it87", Path: "/sys/class/hwmon/hwmon1", attrDir: dir}
+	if err := d.RampPWMDuty(context.Background(), 1, 150, 100); err != nil {
+		t.Fatal(err)
+	}
+	// 25.5 per tenth of a second, give or take the ticker's jitter
+	if len(duties) < 2 || duties[len(duties)-1] != 150 {
+		t.Errorf("wrong ramp: %v", duties)
+	}
+	for _, v := range duties {
+		if v < 100 || v > 150 {
+			t.Errorf("overshot: %v", duties)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package lmsensors
+
+import (
+	"path"
+	"sync"
+)
+
+// Quirk is how to work around the problems of one driver, so that hardware-specific fixes don't spread through the rest of the package.
+// Everything is optional.
+type Quirk struct {
+	Labels map[string]string // Better labels for sensors, from the driver's, eg "temp3" to "VRM"; a libsensors config's labels are applied first
+	Ignore []string          // Globs, as for [path.Match], of the labels of sensors the driver reports but that are bogus, eg unconnected inputs; matched before relabelling
+
+	TempTypes         map[int]LmTempType // See [RegisterTempTypes]
+	EnergyCounterBits uint               // See [RegisterEnergyCounterBits]
+	ThrottleTemp      float64            // °C the CPUs throttle at, if the driver doesn't report it as a limit; see [WithThrottleDetection]
+	AlarmNotify       bool               // Whether the driver notifies changes to its alarm attributes, so that an [AlarmNotifier] needn't read them on a timer
+	PWMModes          []int              // The pwm*_enable modes the driver takes, if they're not the generic ABI's; see [HwmonDevice.PWMModes]
+
+	// BeforeWrite, if set, is called before every write to one of the chip's attributes, eg to unlock its registers, or to put a PWM output in a mode that accepts the write.
+	// It can make writes of its own through write, which go through the [AuditFunc] too.
+	BeforeWrite func(attr string, write func(attr string, val float64) error) error
+}
+
+var (
+	quirksMu sync.RWMutex
+	quirks   = map[string]Quirk{}
+)
+
+// RegisterQuirk sets the [Quirk] for a driver, replacing any already registered for it, including those made by [RegisterTempTypes] and [RegisterEnergyCounterBits].
+// prefix is the chip prefix, eg "it87".
+// Chips already read keep the labels they were given, so register quirks before the first read for the readings to be consistent.
+func RegisterQuirk(prefix string, q Quirk) {
+	quirksMu.Lock()
+	defer quirksMu.Unlock()
+	quirks[prefix] = q
+}
+
+// QuirkFor returns the [Quirk] registered for a driver, if any.
+func QuirkFor(prefix string) (Quirk, bool) {
+	quirksMu.RLock()
+	defer quirksMu.RUnlock()
+	q, ok := quirks[prefix]
+	return q, ok
+}
+
+// quirkOf is the driver's [Quirk], or the zero one.
+func quirkOf(prefix string) Quirk {
+	q, _ := QuirkFor(prefix)
+	return q
+}
+
+// updateQuirk changes one field of a driver's [Quirk], for the Register functions that set them one at a time.
+func updateQuirk(prefix string, fn func(*Quirk)) {
+	quirksMu.Lock()
+	defer quirksMu.Unlock()
+	q := quirks[prefix]
+	fn(&q)
+	quirks[prefix] = q
+}
+
+// applyQuirk drops a chip's bogus sensors, and relabels the rest.
+func applyQuirk(ch *Chip) {
+	q, ok := QuirkFor(ch.Type)
+	if !ok {
+		return
+	}
+	for name := range ch.Sensors {
+		for _, glob := range q.Ignore {
+			if ok, _ := path.Match(glob, name); ok {
+				delete(ch.Sensors, name)
+				break
+			}
+		}
+	}
+	for from, to := range q.Labels {
+		s, ok := ch.Sensors[from]
+		if !ok {
+			continue
+		}
+		delete(ch.Sensors, from)
+		setName(s, to)
+		ch.Sensors[to] = s
+	}
+}
+
+func setName(s Sensor, name string) {
+	switch s := s.(type) {
+	case interface{ base() *baseSensor }:
+		s.base().Name = name
+	case *IntrusionSensor:
+		s.Name = name
+	}
+}
+
+// beforeWrite runs the driver's [Quirk.BeforeWrite], if it has one.
+func beforeWrite(chip, prefix, dir, attr string) error {
+	q := quirkOf(prefix)
+	if q.BeforeWrite == nil {
+		return nil
+	}
+	return q.BeforeWrite(attr, func(attr string, val float64) error {
+		return writeAttributeRaw(chip, prefix, dir, attr, val)
+	})
+}
@@ -0,0 +1,81 @@
+package lmsensors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuirk(t *testing.T) {
+	RegisterQuirk("quirky", Quirk{
+		Labels: map[string]string{"temp3": "VRM"},
+		Ignore: []string{"in1?"},
+	})
+	defer delete(quirks, "quirky")
+
+	ch := &Chip{ID: "quirky-isa-0290", Type: "quirky", Sensors: map[string]Sensor{
+		"temp3": &TempSensor{baseSensor: baseSensor{Name: "temp3", Value: 60}},
+		"in0":   &VoltageSensor{baseSensor{Name: "in0", Value: 1.2}},
+		"in12":  &VoltageSensor{baseSensor{Name: "in12", Value: 0}},
+	}}
+	applyQuirk(ch)
+	if len(ch.Sensors) != 2 || ch.Sensors["in0"] == nil || ch.Sensors["VRM"] == nil || ch.Sensors["VRM"].GetName() != "VRM" {
+		t.Errorf("quirk not applied: %v", ch.Sensors)
+	}
+}
+
+func TestQuirkBeforeWrite(t *testing.T) {
+	dir := t.TempDir()
+	for attr, val := range map[string]string{"pwm1": "100\n", "lock": "1\n"} {
+		if err := os.WriteFile(filepath.Join(dir, attr), []byte(val), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	RegisterQuirk("quirky", Quirk{
+		BeforeWrite: func(attr string, write func(string, float64) error) error {
+			return write("lock", 0)
+		},
+	})
+	defer delete(quirks, "quirky")
+	var attrs []string
+	SetAuditFunc(func(ev WriteEvent) { attrs = append(attrs, ev.Attr) })
+	defer SetAuditFunc(nil)
+
+	d := HwmonDevice{Name: "quirky", Path: "/sys/class/hwmon/hwmon1", attrDir: dir}
+	if err := d.SetPWMDuty(1, 200); err != nil {
+		t.Fatal(err)
+	}
+	if len(attrs) != 2 || attrs[0] != "lock" || attrs[1] != "pwm1" {
+		t.Errorf("wrong write order: %v", attrs)
+	}
+}
+
+// The settings can be changed while the package is in use; run with -race.
+func TestSettingsConcurrent(t *testing.T) {
+	defer delete(quirks, "quirky")
+	defer SetLogger(nil)
+	defer SetRetryPolicy(RetryPolicy{})
+	defer SetDecimals(Temperature, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 100 {
+			RegisterEnergyCounterBits("quirky", uint(32+i%32))
+			SetLogger(nopLogger{})
+			SetRetryPolicy(RetryPolicy{Attempts: i})
+			SetDecimals(Temperature, i%3)
+		}
+	}()
+	s := &TempSensor{}
+	s.Value = 40
+	for range 100 {
+		_ = energyWrap("quirky")
+		logger.Debug("reading")
+		_ = currentRetryPolicy().Attempts
+		_ = s.String()
+	}
+	<-done
+	if q, _ := QuirkFor("quirky"); q.EnergyCounterBits == 0 {
+		t.Errorf("bits not registered: %+v", q)
+	}
+}
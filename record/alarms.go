@@ -0,0 +1,218 @@
+package record
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// AlarmEvent is a spell of a sensor in alarm, as kept by an [AlarmBackend].
+type AlarmEvent struct {
+	Chip, Sensor string
+	Kind         string  // The name of the [lmsensors.Threshold] that raised it, or empty for the hardware's alarm flag
+	Value        float64 // When it was raised; [lmsensors.NoValue] if it wasn't valid
+	Started      time.Time
+	Cleared      time.Time // Zero while it's still raised
+}
+
+// Duration is how long it lasted, or has so far.
+func (e AlarmEvent) Duration() time.Duration {
+	if e.Cleared.IsZero() {
+		return time.Since(e.Started)
+	}
+	return e.Cleared.Sub(e.Started)
+}
+
+type alarmEventJSON struct {
+	Chip, Sensor, Kind string
+	Value              *float64
+	Started            time.Time
+	Cleared            *time.Time
+}
+
+// MarshalJSON encodes a Value of [lmsensors.NoValue], and a zero Cleared, as null.
+func (e AlarmEvent) MarshalJSON() ([]byte, error) {
+	j := alarmEventJSON{Chip: e.Chip, Sensor: e.Sensor, Kind: e.Kind, Started: e.Started}
+	if !math.IsNaN(e.Value) {
+		j.Value = &e.Value
+	}
+	if !e.Cleared.IsZero() {
+		j.Cleared = &e.Cleared
+	}
+	return json.Marshal(j)
+}
+
+func (e *AlarmEvent) UnmarshalJSON(p []byte) error {
+	var j alarmEventJSON
+	if err := json.Unmarshal(p, &j); err != nil {
+		return err
+	}
+	*e = AlarmEvent{Chip: j.Chip, Sensor: j.Sensor, Kind: j.Kind, Value: lmsensors.NoValue, Started: j.Started}
+	if j.Value != nil {
+		e.Value = *j.Value
+	}
+	if j.Cleared != nil {
+		e.Cleared = *j.Cleared
+	}
+	return nil
+}
+
+// AlarmFilter picks out alarm events for ListAlarmEvents; its zero value picks them all.
+// Eg, how often the VRM overheated last week is
+//
+//	AlarmFilter{Sensor: "nct6798-*/VRM*", From: time.Now().AddDate(0, 0, -7)}
+type AlarmFilter struct {
+	Sensor      lmsensors.Selector // Any sensor if it's empty
+	Kinds       []string           // Any kind if it's empty
+	From, To    time.Time          // When they were raised, inclusive and exclusive; either can be zero, for no bound
+	MinDuration time.Duration      // Leaving out blips
+}
+
+// Match is whether the filter picks out the event, for [AlarmBackend]s that can't do it all in their queries.
+func (f AlarmFilter) Match(e AlarmEvent) bool {
+	return (f.Sensor == "" || f.Sensor.Match(e.Chip, e.Sensor)) &&
+		(len(f.Kinds) == 0 || slices.Contains(f.Kinds, e.Kind)) &&
+		!e.Started.Before(f.From) && (f.To.IsZero() || e.Started.Before(f.To)) &&
+		e.Duration() >= f.MinDuration
+}
+
+// AlarmBackend is a [Backend] that keeps alarm events too, which a [Recorder] writes to if it's given them.
+// [Store] and [SQL] are ones, and so is the sqlite module's; they're all kept regardless of pruning, as they're few, and wanted for longer.
+type AlarmBackend interface {
+	Backend
+	// RaiseAlarm starts an event, which has a zero Cleared.
+	RaiseAlarm(e AlarmEvent) error
+	// ClearAlarm ends the sensor's raised event of the kind, if it has one.
+	ClearAlarm(chip, sensor, kind string, at time.Time) error
+	// ListAlarmEvents returns the events the filter picks out, by when they were raised.
+	ListAlarmEvents(f AlarmFilter) ([]AlarmEvent, error)
+}
+
+var (
+	_ AlarmBackend = (*Store)(nil)
+	_ AlarmBackend = (*SQL)(nil)
+)
+
+// Alarm queues an alarm being raised or cleared, for the next [Recorder.Add] to record, along with when it was, if its backend's an [AlarmBackend].
+// Give it to [lmsensors.WithAlarmDetection] or [lmsensors.WithThresholds] alongside [Recorder.Watch], whose Add comes later in the same poll.
+func (r *Recorder) Alarm(e lmsensors.AlarmEvent) {
+	r.alarms = append(r.alarms, e)
+}
+
+func (r *Recorder) recordAlarms(at time.Time) error {
+	alarms := r.alarms
+	r.alarms = nil
+	b, ok := r.Backend.(AlarmBackend)
+	if !ok {
+		return nil
+	}
+	var errs []error
+	for _, e := range alarms {
+		if e.Raised {
+			errs = append(errs, b.RaiseAlarm(AlarmEvent{Chip: e.Chip, Sensor: e.Sensor, Kind: e.Rule, Value: e.Value, Started: at}))
+		} else {
+			errs = append(errs, b.ClearAlarm(e.Chip, e.Sensor, e.Rule, at))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// alarmsFile, in a Store's Dir, has a line of JSON for each event each time it changes, the latest superseding the others.
+const alarmsFile = "alarms.jsonl"
+
+// RaiseAlarm starts an event, which has a zero Cleared.
+func (s *Store) RaiseAlarm(e AlarmEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.Cleared = time.Time{}
+	s.alarms = append(s.alarms, e)
+	return s.logAlarm(e)
+}
+
+// ClearAlarm ends the sensor's raised event of the kind, if it has one.
+func (s *Store) ClearAlarm(chip, sensor, kind string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.alarms) - 1; i >= 0; i-- {
+		e := &s.alarms[i]
+		if e.Chip == chip && e.Sensor == sensor && e.Kind == kind && e.Cleared.IsZero() {
+			e.Cleared = at
+			return s.logAlarm(*e)
+		}
+	}
+	return nil
+}
+
+// ListAlarmEvents returns the events the filter picks out, by when they were raised.
+func (s *Store) ListAlarmEvents(f AlarmFilter) ([]AlarmEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var es []AlarmEvent
+	for _, e := range s.alarms {
+		if f.Match(e) {
+			es = append(es, e)
+		}
+	}
+	slices.SortStableFunc(es, func(a, b AlarmEvent) int { return a.Started.Compare(b.Started) })
+	return es, nil
+}
+
+func (s *Store) logAlarm(e AlarmEvent) error {
+	if s.opts.Dir == "" {
+		return nil
+	}
+	p, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(s.opts.Dir, alarmsFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(p, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if s.wal != nil && s.opts.Sync != SyncNever {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// loadAlarms reads the events in Dir; a torn last line is dropped.
+func (s *Store) loadAlarms() error {
+	f, err := os.Open(filepath.Join(s.opts.Dir, alarmsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	index := map[AlarmEvent]int{} // By everything that identifies it
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e AlarmEvent
+		if json.Unmarshal(sc.Bytes(), &e) != nil {
+			continue
+		}
+		id := AlarmEvent{Chip: e.Chip, Sensor: e.Sensor, Kind: e.Kind, Started: e.Started}
+		if i, ok := index[id]; ok {
+			s.alarms[i] = e
+			continue
+		}
+		index[id] = len(s.alarms)
+		s.alarms = append(s.alarms, e)
+	}
+	return sc.Err()
+}
@@ -0,0 +1,82 @@
+package record
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+func TestAlarmEvents(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Recorder{Backend: s}
+	poll := func(i int, alarms ...lmsensors.AlarmEvent) {
+		t.Helper()
+		for _, e := range alarms {
+			r.Alarm(e)
+		}
+		if err := r.Add(snapshot(start.Add(time.Duration(i)*time.Minute), 80)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	vrm := lmsensors.AlarmEvent{Chip: "nct6798-isa-0290", Sensor: "VRM", Rule: "vrm-hot", Value: 95, Raised: true}
+	fan := lmsensors.AlarmEvent{Chip: "nct6798-isa-0290", Sensor: "fan2", Value: lmsensors.NoValue, Raised: true}
+	poll(0, vrm)
+	poll(1)
+	vrm.Raised = false
+	poll(2, vrm, fan)
+	vrm.Raised = true
+	poll(10, vrm)
+	vrm.Raised = false
+	poll(11, vrm)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// They survive reopening.
+	if s, err = Open(Options{Dir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	all, err := s.ListAlarmEvents(AlarmFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].Duration() != 2*time.Minute || all[0].Value != 95 || all[1].Sensor != "fan2" || !all[1].Cleared.IsZero() || !math.IsNaN(all[1].Value) {
+		t.Fatalf("wrong events: %+v", all)
+	}
+
+	for _, c := range []struct {
+		f    AlarmFilter
+		want int
+	}{
+		{AlarmFilter{Sensor: "nct6798-*/VRM*"}, 2},
+		{AlarmFilter{Kinds: []string{""}}, 1},
+		{AlarmFilter{From: start.Add(5 * time.Minute)}, 1},
+		{AlarmFilter{To: start.Add(5 * time.Minute)}, 2},
+		{AlarmFilter{Sensor: "VRM", MinDuration: 90 * time.Second}, 1},
+	} {
+		if es, _ := s.ListAlarmEvents(c.f); len(es) != c.want {
+			t.Errorf("%+v: got %+v, want %d", c.f, es, c.want)
+		}
+	}
+
+	// And being saved.
+	var buf bytes.Buffer
+	if err := s.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if es, _ := loaded.ListAlarmEvents(AlarmFilter{}); len(es) != 3 || !es[2].Started.Equal(start.Add(10*time.Minute)) {
+		t.Errorf("wrong events after loading: %+v", es)
+	}
+}
@@ -0,0 +1,86 @@
+package record
+
+import (
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Backend is somewhere to keep history, so that a [Recorder] can write to a database of the program's choosing, eg ClickHouse or TimescaleDB, rather than a [Store].
+// [Store] and [SQL] are built in, and the sqlite module has one ready-made for SQLite.
+type Backend interface {
+	// Append records samples of a sensor, by chip/sensor.
+	Append(key string, samples ...Sample) error
+	// Query downsamples a sensor's samples from from, inclusive, to to, exclusive, into a point per step, as [Store.Query] does.
+	Query(key string, from, to time.Time, step time.Duration) ([]Point, error)
+	// Prune drops all the samples from before before.
+	Prune(before time.Time) error
+}
+
+var (
+	_ Backend = (*Store)(nil)
+	_ Backend = (*SQL)(nil)
+)
+
+// Recorder writes the readings of a [lmsensors.Watcher] through to a [Backend].
+// Backends with an Add method, like [Store], are given each snapshot whole, so that they can keep its chips and sensors too.
+type Recorder struct {
+	Backend   Backend
+	Retention time.Duration // If it's set, older samples are pruned, at most once a minute
+
+	pruned time.Time
+	alarms []lmsensors.AlarmEvent // Since the last Add
+}
+
+type adder interface {
+	Add(*lmsensors.Snapshot) error
+}
+
+// pruneInterval is how often a [Recorder] prunes.
+const pruneInterval = time.Minute
+
+// Watch returns an option that records every poll of a [lmsensors.Watcher].
+func (r *Recorder) Watch() lmsensors.WatcherOption {
+	return lmsensors.OnPoll(func(sys *lmsensors.System, _ error) {
+		_ = r.Add(lmsensors.NewSnapshot(sys, sys.Time))
+	})
+}
+
+// Add records the valid readings in a snapshot, and any alarms since the last, and prunes if it's due.
+func (r *Recorder) Add(snap *lmsensors.Snapshot) error {
+	if err := r.recordAlarms(snap.Time()); err != nil {
+		return err
+	}
+	if a, ok := r.Backend.(adder); ok {
+		if err := a.Add(snap); err != nil {
+			return err
+		}
+	} else {
+		for rd := range snap.Readings {
+			if smp, ok := sampleOf(snap, rd); ok {
+				if err := r.Backend.Append(rd.Chip+"/"+rd.Sensor, smp); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if now := snap.Time(); r.Retention > 0 && now.Sub(r.pruned) >= pruneInterval {
+		if err := r.Backend.Prune(now.Add(-r.Retention)); err != nil {
+			return err
+		}
+		r.pruned = now
+	}
+	return nil
+}
+
+// sampleOf is a reading's sample, if it's valid.
+func sampleOf(snap *lmsensors.Snapshot, r lmsensors.Reading) (Sample, bool) {
+	if !r.Valid {
+		return Sample{}, false
+	}
+	at := r.Time
+	if at.IsZero() {
+		at = snap.Time()
+	}
+	return Sample{at, r.Value}, true
+}
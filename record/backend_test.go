@@ -0,0 +1,63 @@
+package record
+
+import (
+	"testing"
+	"time"
+)
+
+// samples is a [Backend] that just keeps what it's given.
+type samples struct {
+	got    map[string][]Sample
+	pruned []time.Time
+}
+
+func (b *samples) Append(key string, smps ...Sample) error {
+	b.got[key] = append(b.got[key], smps...)
+	return nil
+}
+
+func (b *samples) Query(string, time.Time, time.Time, time.Duration) ([]Point, error) {
+	return nil, nil
+}
+
+func (b *samples) Prune(before time.Time) error {
+	b.pruned = append(b.pruned, before)
+	return nil
+}
+
+func TestRecorder(t *testing.T) {
+	b := &samples{got: map[string][]Sample{}}
+	r := &Recorder{Backend: b, Retention: time.Hour}
+	for i := range 90 {
+		if err := r.Add(snapshot(start.Add(time.Duration(i)*time.Second), 40+float64(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tctl := b.got["k10temp-pci-00c3/Tctl"]; len(b.got) != 1 || len(tctl) != 90 || tctl[89].Value != 129 {
+		t.Errorf("wrong samples: %v", b.got)
+	}
+	if len(b.pruned) != 2 || !b.pruned[1].Equal(start.Add(time.Minute-time.Hour)) {
+		t.Errorf("wrong prunes: %v", b.pruned)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	s, err := Open(Options{Retention: time.Minute, Tiers: []Tier{{10 * time.Second, time.Hour}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fill(t, s, 90)
+	// The samples from 30s to 80s are in the ring.
+	if err := s.Prune(start.Add(80 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	ps, _ := s.Query("k10temp-pci-00c3/Tctl", start, start.Add(time.Hour), 0)
+	if len(ps) != 10 || ps[0].Avg != 120 {
+		t.Errorf("wrong points after pruning: %+v", ps)
+	}
+	// Neither has history from before, so it's from the tier, which has lost the steps up to 80s as well.
+	ps, _ = s.Query("k10temp-pci-00c3/Tctl", start.Add(-time.Hour), start.Add(time.Hour), time.Minute)
+	if len(ps) != 1 || ps[0].Count != 10 || ps[0].Min != 120 {
+		t.Errorf("wrong tier after pruning: %+v", ps)
+	}
+}
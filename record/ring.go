@@ -0,0 +1,158 @@
+package record
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// ring is a circular buffer of fixed-size records, either in memory or in a file mapped into it, so that it survives restarts; changes to a mapped file are in the page cache as soon as they're made, so survive the process crashing, but not the machine.
+// Its first ringHeader bytes are a header: the magic, the record size, the capacity, and the number of records ever pushed, from which the oldest's slot follows.
+type ring struct {
+	buf    []byte
+	size   int
+	cap    uint64
+	mapped bool
+}
+
+const (
+	ringMagic  = "LMSR"
+	ringHeader = 24
+)
+
+func newRing(size int, cap uint64) *ring {
+	r := &ring{buf: make([]byte, ringHeader+uint64(size)*cap), size: size, cap: cap}
+	r.init()
+	return r
+}
+
+func (r *ring) init() {
+	copy(r.buf, ringMagic)
+	binary.LittleEndian.PutUint32(r.buf[4:], uint32(r.size))
+	binary.LittleEndian.PutUint64(r.buf[8:], r.cap)
+}
+
+// mapRing maps a ring file, making it if it doesn't exist. One of a different size or capacity is replaced, keeping its newest records.
+func mapRing(path string, size int, cap uint64) (*ring, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // The mapping stays
+	length := ringHeader + int64(size)*int64(cap)
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var old *ring
+	if st.Size() != 0 {
+		p := make([]byte, min(st.Size(), ringHeader))
+		if _, err := f.ReadAt(p, 0); err != nil {
+			return nil, err
+		}
+		if st.Size() < ringHeader || string(p[:4]) != ringMagic {
+			return nil, fmt.Errorf("%s isn't a ring file", path)
+		}
+		if int(binary.LittleEndian.Uint32(p[4:])) != size {
+			return nil, fmt.Errorf("%s has records of a different size", path)
+		}
+		if st.Size() != length {
+			// Resized, so read the old records out before changing it.
+			p := make([]byte, st.Size())
+			if _, err := f.ReadAt(p, 0); err != nil {
+				return nil, err
+			}
+			old = &ring{buf: p, size: size, cap: binary.LittleEndian.Uint64(p[8:])}
+			if uint64(len(p)) != ringHeader+uint64(size)*old.cap {
+				return nil, fmt.Errorf("%s is truncated", path)
+			}
+		}
+	}
+	if st.Size() != length {
+		if err := f.Truncate(0); err != nil {
+			return nil, err
+		}
+		if err := f.Truncate(length); err != nil {
+			return nil, err
+		}
+	}
+	buf, err := syscall.Mmap(int(f.Fd()), 0, int(length), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	r := &ring{buf: buf, size: size, cap: cap, mapped: true}
+	if st.Size() != length {
+		r.init()
+	}
+	if old != nil {
+		for i := old.len() - min(old.len(), cap); i < old.len(); i++ {
+			r.push(old.at(i))
+		}
+	}
+	return r, nil
+}
+
+// pushed is the number of records ever pushed.
+func (r *ring) pushed() uint64 {
+	return binary.LittleEndian.Uint64(r.buf[16:])
+}
+
+// len is the number of records held.
+func (r *ring) len() uint64 {
+	return min(r.pushed(), r.cap)
+}
+
+// at returns the ith oldest record held.
+func (r *ring) at(i uint64) []byte {
+	slot := (r.pushed() - r.len() + i) % r.cap
+	off := ringHeader + slot*uint64(r.size)
+	return r.buf[off : off+uint64(r.size)]
+}
+
+// push adds a record, overwriting the oldest if it's full.
+func (r *ring) push(rec []byte) {
+	n := r.pushed()
+	off := ringHeader + (n%r.cap)*uint64(r.size)
+	copy(r.buf[off:off+uint64(r.size)], rec)
+	binary.LittleEndian.PutUint64(r.buf[16:], n+1)
+}
+
+// drop removes the n oldest records.
+func (r *ring) drop(n uint64) {
+	if n == 0 {
+		return
+	}
+	keep := make([]byte, 0, (r.len()-n)*uint64(r.size))
+	for i := n; i < r.len(); i++ {
+		keep = append(keep, r.at(i)...)
+	}
+	binary.LittleEndian.PutUint64(r.buf[16:], 0)
+	for len(keep) > 0 {
+		r.push(keep[:r.size])
+		keep = keep[r.size:]
+	}
+}
+
+// sync writes a mapped ring's changes to disk.
+func (r *ring) sync() error {
+	if !r.mapped {
+		return nil
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_MSYNC, uintptr(unsafe.Pointer(&r.buf[0])), uintptr(len(r.buf)), syscall.MS_SYNC)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func (r *ring) close() error {
+	if !r.mapped {
+		return nil
+	}
+	err := syscall.Munmap(r.buf)
+	r.buf = nil
+	return err
+}
@@ -0,0 +1,250 @@
+package record
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Topology is the chips and sensors a [Store] has been given readings of, so that a saved [Session] can be made sense of on a machine without them.
+type Topology struct {
+	Chips   []lmsensors.ChipInfo // By ID
+	Sensors []SensorInfo         // By chip, then name
+}
+
+// SensorInfo is what a sensor is, as of its latest reading.
+type SensorInfo struct {
+	Chip, Name     string
+	Type           lmsensors.LmSensorType
+	Unit           string
+	Min, Max, Crit float64 // Each [lmsensors.NoValue] if it doesn't have it or it wasn't read
+}
+
+// Key is how the [Store] knows the sensor, eg for [Store.Query].
+func (i SensorInfo) Key() string {
+	return i.Chip + "/" + i.Name
+}
+
+type sensorInfoJSON struct {
+	Chip, Name     string
+	Type           lmsensors.LmSensorType
+	Unit           string
+	Min, Max, Crit *float64
+}
+
+// MarshalJSON encodes limits of [lmsensors.NoValue] as null.
+func (i SensorInfo) MarshalJSON() ([]byte, error) {
+	limit := func(v float64) *float64 {
+		if math.IsNaN(v) {
+			return nil
+		}
+		return &v
+	}
+	return json.Marshal(sensorInfoJSON{i.Chip, i.Name, i.Type, i.Unit, limit(i.Min), limit(i.Max), limit(i.Crit)})
+}
+
+func (i *SensorInfo) UnmarshalJSON(p []byte) error {
+	var j sensorInfoJSON
+	if err := json.Unmarshal(p, &j); err != nil {
+		return err
+	}
+	limit := func(v *float64) float64 {
+		if v == nil {
+			return lmsensors.NoValue
+		}
+		return *v
+	}
+	*i = SensorInfo{j.Chip, j.Name, j.Type, j.Unit, limit(j.Min), limit(j.Max), limit(j.Crit)}
+	return nil
+}
+
+// Topology returns the chips and sensors that have been recorded.
+func (s *Store) Topology() Topology {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var t Topology
+	for _, c := range s.chips {
+		t.Chips = append(t.Chips, c)
+	}
+	sort.Slice(t.Chips, func(i, j int) bool { return t.Chips[i].ID < t.Chips[j].ID })
+	for _, i := range s.sensors {
+		t.Sensors = append(t.Sensors, i)
+	}
+	sort.Slice(t.Sensors, func(i, j int) bool {
+		a, b := t.Sensors[i], t.Sensors[j]
+		return a.Chip < b.Chip || a.Chip == b.Chip && a.Name < b.Name
+	})
+	return t
+}
+
+// Session is a recording, eg of a thermal test run, loaded by [Load] to be analyzed with the same [Store] methods it was recorded with.
+type Session struct {
+	Host  string // Where it was recorded
+	Saved time.Time
+	*Store
+}
+
+// A session file is gzipped: the magic and version, then a header, as JSON prefixed with its length as a uvarint, then for each of its series, each of their rings' records, oldest first, prefixed with their number as a uvarint.
+const (
+	sessionMagic   = "LMSS"
+	sessionVersion = 1
+)
+
+type sessionHeader struct {
+	Saved               time.Time
+	Host                string
+	Retention, Interval time.Duration
+	Tiers               []Tier
+	Topology            Topology
+	Alarms              []AlarmEvent
+	Series              []string // The order they follow in
+}
+
+// ErrSession is returned for files that weren't written by [Store.Save], or are corrupt.
+var ErrSession = errors.New("not a recorded session")
+
+// Save writes everything in the store, its [Topology] and its alarm events, to w, for [Load].
+func (s *Store) Save(w io.Writer) error {
+	topo := s.Topology()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	hdr := sessionHeader{Saved: time.Now(), Retention: s.opts.Retention, Interval: s.opts.Interval, Tiers: s.opts.Tiers, Topology: topo, Alarms: s.alarms}
+	hdr.Host, _ = os.Hostname()
+	for key := range s.series {
+		hdr.Series = append(hdr.Series, key)
+	}
+	sort.Strings(hdr.Series)
+	p, err := json.Marshal(hdr)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(w)
+	bw := bufio.NewWriter(zw)
+	bw.WriteString(sessionMagic)
+	bw.WriteByte(sessionVersion)
+	bw.Write(binary.AppendUvarint(nil, uint64(len(p))))
+	bw.Write(p)
+	for _, key := range hdr.Series {
+		ser := s.series[key]
+		for _, r := range append([]*ring{ser.raw}, ser.tiers...) {
+			bw.Write(binary.AppendUvarint(nil, r.len()))
+			for i := range r.len() {
+				bw.Write(r.at(i))
+			}
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// SaveFile saves the store to a file, replacing it only once it's been written in full.
+func (s *Store) SaveFile(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".session-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := s.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Load reads a session written by [Store.Save] into a new in-memory store, with the same retention as the one it was saved from.
+func Load(r io.Reader) (*Session, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSession, err)
+	}
+	br := bufio.NewReader(zr)
+	magic := make([]byte, len(sessionMagic)+1)
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic[:len(sessionMagic)]) != sessionMagic {
+		return nil, ErrSession
+	}
+	if magic[len(sessionMagic)] != sessionVersion {
+		return nil, fmt.Errorf("unsupported session version %d", magic[len(sessionMagic)])
+	}
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, truncated(err)
+	}
+	if n > 1<<30 {
+		return nil, fmt.Errorf("%w: header of %d bytes", ErrSession, n)
+	}
+	p := make([]byte, n)
+	if _, err := io.ReadFull(br, p); err != nil {
+		return nil, truncated(err)
+	}
+	var hdr sessionHeader
+	if err := json.Unmarshal(p, &hdr); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSession, err)
+	}
+
+	s, err := Open(Options{Retention: hdr.Retention, Interval: hdr.Interval, Tiers: hdr.Tiers})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSession, err)
+	}
+	for _, key := range hdr.Series {
+		ser, _ := s.newSeries(key) // Can't fail in memory
+		for _, r := range append([]*ring{ser.raw}, ser.tiers...) {
+			n, err := binary.ReadUvarint(br)
+			if err != nil {
+				return nil, truncated(err)
+			}
+			if n > r.cap {
+				return nil, fmt.Errorf("%w: %d records of %s for %d places", ErrSession, n, key, r.cap)
+			}
+			rec := make([]byte, r.size)
+			for range n {
+				if _, err := io.ReadFull(br, rec); err != nil {
+					return nil, truncated(err)
+				}
+				r.push(rec)
+			}
+		}
+		s.series[key] = ser
+	}
+	for _, c := range hdr.Topology.Chips {
+		s.chips[c.ID] = c
+	}
+	for _, i := range hdr.Topology.Sensors {
+		s.sensors[i.Key()] = i
+	}
+	s.alarms = hdr.Alarms
+	return &Session{Host: hdr.Host, Saved: hdr.Saved, Store: s}, nil
+}
+
+// LoadFile loads a session from a file.
+func LoadFile(path string) (*Session, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+func truncated(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("%w: %w", ErrSession, err)
+}
@@ -0,0 +1,64 @@
+package record
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+func TestSession(t *testing.T) {
+	s, err := Open(Options{Retention: time.Minute, Tiers: []Tier{{10 * time.Second, 10 * time.Minute}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fill(t, s, 90)
+	path := filepath.Join(t.TempDir(), "run.lmss")
+	if err := s.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Host == "" || time.Since(loaded.Saved) > time.Minute {
+		t.Errorf("wrong session details: %q at %s", loaded.Host, loaded.Saved)
+	}
+
+	topo := loaded.Topology()
+	if len(topo.Chips) != 1 || topo.Chips[0].ID != "k10temp-pci-00c3" || len(topo.Sensors) != 2 {
+		t.Fatalf("wrong topology: %+v", topo)
+	}
+	if in0 := topo.Sensors[1]; in0.Key() != "k10temp-pci-00c3/in0" || in0.Type != lmsensors.Voltage || !math.IsNaN(in0.Crit) {
+		t.Errorf("wrong sensor: %+v", in0)
+	}
+	if !reflect.DeepEqual(loaded.Keys(), s.Keys()) {
+		t.Errorf("wrong keys: %v", loaded.Keys())
+	}
+	for _, step := range []time.Duration{0, 10 * time.Second} {
+		want, _ := s.Query("k10temp-pci-00c3/Tctl", start, start.Add(time.Hour), step)
+		got, err := loaded.Query("k10temp-pci-00c3/Tctl", start, start.Add(time.Hour), step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("by %s: got %+v, want %+v", step, got, want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := s.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(bytes.NewReader(buf.Bytes()[:buf.Len()/2])); err == nil {
+		t.Error("no error for a truncated session")
+	}
+	if _, err := Load(bytes.NewReader([]byte("nope"))); !errors.Is(err, ErrSession) {
+		t.Errorf("wrong error for a non-session: %v", err)
+	}
+}
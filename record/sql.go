@@ -0,0 +1,173 @@
+package record
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// SQL keeps history in a table in a [database/sql] database, through whichever driver the program imports.
+// Its queries are written for SQLite, whose drivers mostly need cgo; the sqlite module is a ready-made backend with a pure-Go one, and a schema of its own.
+// Downsampling is done by the database.
+type SQL struct {
+	db *sql.DB
+}
+
+// NewSQL makes the tables it needs, lmsensors_samples and lmsensors_alarm_events, if they don't exist. The database is still the caller's to close.
+func NewSQL(db *sql.DB) (*SQL, error) {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS lmsensors_samples (
+			sensor TEXT NOT NULL,
+			time INTEGER NOT NULL, -- Unix nanoseconds
+			value REAL NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS lmsensors_samples_sensor_time ON lmsensors_samples (sensor, time);
+		CREATE TABLE IF NOT EXISTS lmsensors_alarm_events (
+			chip TEXT NOT NULL,
+			sensor TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			value REAL,
+			started INTEGER NOT NULL,
+			cleared INTEGER
+		);
+		CREATE INDEX IF NOT EXISTS lmsensors_alarm_events_started ON lmsensors_alarm_events (started);
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return &SQL{db}, nil
+}
+
+// Append records samples of a sensor, by chip/sensor, in one transaction.
+func (s *SQL) Append(key string, samples ...Sample) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(`INSERT INTO lmsensors_samples (sensor, time, value) VALUES (?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, smp := range samples {
+		if _, err := stmt.Exec(key, smp.Time.UnixNano(), smp.Value); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Query downsamples a sensor's samples as [Store.Query] does.
+func (s *SQL) Query(key string, from, to time.Time, step time.Duration) ([]Point, error) {
+	if step < 0 {
+		return nil, fmt.Errorf("negative step %s", step)
+	}
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if step == 0 {
+		rows, err = s.db.Query(`
+			SELECT time, value, value, value, 1 FROM lmsensors_samples
+			WHERE sensor = ? AND time >= ? AND time < ? ORDER BY time`,
+			key, from.UnixNano(), to.UnixNano())
+	} else {
+		rows, err = s.db.Query(`
+			SELECT ? + (time - ?) / ? * ? AS start, MIN(value), MAX(value), AVG(value), COUNT(*) FROM lmsensors_samples
+			WHERE sensor = ? AND time >= ? AND time < ? GROUP BY start ORDER BY start`,
+			from.UnixNano(), from.UnixNano(), int64(step), int64(step), key, from.UnixNano(), to.UnixNano())
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var points []Point
+	for rows.Next() {
+		var (
+			p     Point
+			start int64
+		)
+		if err := rows.Scan(&start, &p.Min, &p.Max, &p.Avg, &p.Count); err != nil {
+			return nil, err
+		}
+		p.Time = time.Unix(0, start)
+		points = append(points, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(points) == 0 {
+		err := s.db.QueryRow(`SELECT 1 FROM lmsensors_samples WHERE sensor = ? LIMIT 1`, key).Scan(new(int))
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("no history of %s", key)
+		}
+		return nil, err
+	}
+	return points, nil
+}
+
+// Prune drops all the samples from before before.
+func (s *SQL) Prune(before time.Time) error {
+	_, err := s.db.Exec(`DELETE FROM lmsensors_samples WHERE time < ?`, before.UnixNano())
+	return err
+}
+
+// RaiseAlarm starts an event, which has a zero Cleared.
+func (s *SQL) RaiseAlarm(e AlarmEvent) error {
+	_, err := s.db.Exec(`INSERT INTO lmsensors_alarm_events (chip, sensor, kind, value, started) VALUES (?, ?, ?, ?, ?)`,
+		e.Chip, e.Sensor, e.Kind, sql.NullFloat64{Float64: e.Value, Valid: !math.IsNaN(e.Value)}, e.Started.UnixNano())
+	return err
+}
+
+// ClearAlarm ends the sensor's raised event of the kind, if it has one.
+func (s *SQL) ClearAlarm(chip, sensor, kind string, at time.Time) error {
+	_, err := s.db.Exec(`UPDATE lmsensors_alarm_events SET cleared = ? WHERE chip = ? AND sensor = ? AND kind = ? AND cleared IS NULL`,
+		at.UnixNano(), chip, sensor, kind)
+	return err
+}
+
+// ListAlarmEvents returns the events the filter picks out, by when they were raised.
+func (s *SQL) ListAlarmEvents(f AlarmFilter) ([]AlarmEvent, error) {
+	from, to := int64(math.MinInt64), int64(math.MaxInt64)
+	if !f.From.IsZero() {
+		from = f.From.UnixNano()
+	}
+	if !f.To.IsZero() {
+		to = f.To.UnixNano()
+	}
+	rows, err := s.db.Query(`
+		SELECT chip, sensor, kind, value, started, cleared FROM lmsensors_alarm_events
+		WHERE started >= ? AND started < ? ORDER BY started`,
+		from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var es []AlarmEvent
+	for rows.Next() {
+		var (
+			e       AlarmEvent
+			value   sql.NullFloat64
+			started int64
+			cleared sql.NullInt64
+		)
+		if err := rows.Scan(&e.Chip, &e.Sensor, &e.Kind, &value, &started, &cleared); err != nil {
+			return nil, err
+		}
+		e.Value, e.Started = lmsensors.NoValue, time.Unix(0, started)
+		if value.Valid {
+			e.Value = value.Float64
+		}
+		if cleared.Valid {
+			e.Cleared = time.Unix(0, cleared.Int64)
+		}
+		if f.Match(e) {
+			es = append(es, e)
+		}
+	}
+	return es, rows.Err()
+}
@@ -0,0 +1,381 @@
+// Package record keeps a history of readings in the process, so that small tools can draw graphs without an external database.
+package record
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Sample is one reading of a sensor.
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+// Point is the samples in a step of a [Store.Query].
+type Point struct {
+	Time          time.Time // The start of the step
+	Min, Max, Avg float64
+	Count         int // Number of samples
+}
+
+// Options configure a [Store].
+type Options struct {
+	Retention time.Duration // How much history to keep of each sensor; defaults to an hour
+	Interval  time.Duration // How often it's given readings, which with Retention sizes its buffers; defaults to a second
+
+	// Tiers keep averages over longer steps for longer, like RRDtool's archives, eg [DefaultTiers]; by default there are none, so only Retention's worth is kept.
+	Tiers []Tier
+
+	// Dir, if it's set, keeps each sensor's history in files in it, mapped into memory, so that it survives restarts.
+	Dir string
+
+	// WAL, with Dir, also writes every sample to a log in it, synced to disk as Sync says, so that a crash of the machine, rather than just the process, doesn't lose the history in the page cache.
+	// The log is replayed into the history when it's next opened.
+	WAL          bool
+	Sync         SyncPolicy
+	SyncInterval time.Duration // For SyncPeriodic; defaults to a second
+}
+
+// Store keeps the samples of each sensor in a ring buffer, holding Retention/Interval of them, and any [Tier]s of them, and downsamples them on query.
+// Readings at a slower rate than Interval are held for longer than Retention; faster, for less.
+// It's safe to use from multiple goroutines.
+type Store struct {
+	opts Options
+
+	mu      sync.RWMutex
+	series  map[string]*series // By chip/sensor
+	chips   map[string]lmsensors.ChipInfo
+	sensors map[string]SensorInfo // By chip/sensor
+	alarms  []AlarmEvent
+	wal     *wal
+}
+
+// series is the history of one sensor.
+type series struct {
+	raw   *ring
+	tiers []*ring // Of buckets, in the order of Options.Tiers
+}
+
+const sampleSize = 16
+
+// Open makes a [Store], loading the history in opts.Dir if there is one.
+func Open(opts Options) (*Store, error) {
+	if opts.Retention <= 0 {
+		opts.Retention = time.Hour
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	opts.Tiers = slices.Clone(opts.Tiers)
+	slices.SortFunc(opts.Tiers, func(a, b Tier) int { return cmp.Compare(a.Step, b.Step) })
+	if opts.SyncInterval <= 0 {
+		opts.SyncInterval = time.Second
+	}
+	if opts.WAL && opts.Dir == "" {
+		return nil, errors.New("a WAL needs a Dir")
+	}
+	for _, t := range opts.Tiers {
+		if t.Step <= 0 || t.Retention < t.Step {
+			return nil, fmt.Errorf("bad tier: %s steps for %s", t.Step, t.Retention)
+		}
+	}
+	s := &Store{opts: opts, series: map[string]*series{}, chips: map[string]lmsensors.ChipInfo{}, sensors: map[string]SensorInfo{}}
+	if opts.Dir == "" {
+		return s, nil
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(opts.Dir, "*.ring"))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		key, err := url.PathUnescape(strings.TrimSuffix(filepath.Base(f), ".ring"))
+		if err != nil {
+			continue // Not one of ours
+		}
+		ser, err := s.newSeries(key)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.series[key] = ser
+	}
+	if err := s.loadAlarms(); err != nil {
+		s.Close()
+		return nil, err
+	}
+	if opts.WAL {
+		if err := s.openWAL(); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Store) capacity() uint64 {
+	return uint64((s.opts.Retention + s.opts.Interval - 1) / s.opts.Interval)
+}
+
+// Watch returns an option that records every poll of a [lmsensors.Watcher].
+func (s *Store) Watch() lmsensors.WatcherOption {
+	return lmsensors.OnPoll(func(sys *lmsensors.System, _ error) {
+		_ = s.Add(lmsensors.NewSnapshot(sys, sys.Time))
+	})
+}
+
+// Add records the valid readings in a snapshot; the others are gaps.
+// The chips and sensors in it are added to the [Topology], replacing what they were before.
+func (s *Store) Add(snap *lmsensors.Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range snap.Chips {
+		s.chips[c.ID] = c
+	}
+	var batch []staged
+	for r := range snap.Readings {
+		s.sensors[r.Chip+"/"+r.Sensor] = SensorInfo{r.Chip, r.Sensor, r.Type, r.Unit, r.Min, r.Max, r.Crit}
+		smp, ok := sampleOf(snap, r)
+		if !ok {
+			continue
+		}
+		var err error
+		if batch, err = s.stage(batch, r.Chip+"/"+r.Sensor, smp); err != nil {
+			return err
+		}
+	}
+	return s.apply(batch)
+}
+
+// Append records samples of a sensor, by chip/sensor, eg ones read some other way; ones older than its latest are dropped.
+func (s *Store) Append(key string, samples ...Sample) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var batch []staged
+	for _, smp := range samples {
+		var err error
+		if batch, err = s.stage(batch, key, smp); err != nil {
+			return err
+		}
+	}
+	return s.apply(batch)
+}
+
+// staged is a sample that's to go in its series' rings, once it's been logged.
+type staged struct {
+	key string
+	ser *series
+	smp Sample
+}
+
+// stage adds a sample to a batch, unless it's older than its series' latest, counting those already in the batch; the series is made if it's new.
+func (s *Store) stage(batch []staged, key string, smp Sample) ([]staged, error) {
+	ser, ok := s.series[key]
+	if !ok {
+		var err error
+		if ser, err = s.newSeries(key); err != nil {
+			return batch, err
+		}
+		s.series[key] = ser
+	}
+	for i := len(batch) - 1; i >= 0; i-- {
+		if batch[i].ser == ser {
+			if smp.Time.Before(batch[i].smp.Time) {
+				return batch, nil
+			}
+			return append(batch, staged{key, ser, smp}), nil
+		}
+	}
+	if r := ser.raw; r.len() != 0 && smp.Time.Before(decodeSample(r.at(r.len()-1)).Time) {
+		return batch, nil
+	}
+	return append(batch, staged{key, ser, smp}), nil
+}
+
+// apply logs a batch, if there's a WAL, and only once it's written, and synced as the policy says, puts it in the rings, which the OS can write back at any time.
+func (s *Store) apply(batch []staged) error {
+	var rec [sampleSize]byte
+	if s.wal != nil {
+		for _, st := range batch {
+			encodeSample(rec[:], st.smp)
+			s.wal.log(st.key, rec[:])
+		}
+		if err := s.commit(); err != nil {
+			return err
+		}
+	}
+	for _, st := range batch {
+		encodeSample(rec[:], st.smp)
+		st.ser.raw.push(rec[:])
+		for i, t := range s.opts.Tiers {
+			consolidate(st.ser.tiers[i], t.Step, st.smp)
+		}
+	}
+	if s.wal != nil && s.wal.size >= walLimit {
+		return s.checkpoint()
+	}
+	return nil
+}
+
+// newSeries makes a sensor's rings, or maps their files in Dir: the raw samples in eg k10temp-pci-00c3%2FTctl.ring, and each tier in eg k10temp-pci-00c3%2FTctl.1m0s.tier.
+func (s *Store) newSeries(key string) (*series, error) {
+	ser := &series{}
+	if s.opts.Dir == "" {
+		ser.raw = newRing(sampleSize, s.capacity())
+		for _, t := range s.opts.Tiers {
+			ser.tiers = append(ser.tiers, newRing(bucketSize, t.capacity()))
+		}
+		return ser, nil
+	}
+	base := filepath.Join(s.opts.Dir, url.PathEscape(key))
+	var err error
+	if ser.raw, err = mapRing(base+".ring", sampleSize, s.capacity()); err != nil {
+		return nil, err
+	}
+	for _, t := range s.opts.Tiers {
+		r, err := mapRing(base+"."+t.Step.String()+".tier", bucketSize, t.capacity())
+		if err != nil {
+			ser.close()
+			return nil, err
+		}
+		ser.tiers = append(ser.tiers, r)
+	}
+	return ser, nil
+}
+
+func (ser *series) close() error {
+	errs := []error{ser.raw.close()}
+	for _, r := range ser.tiers {
+		errs = append(errs, r.close())
+	}
+	return errors.Join(errs...)
+}
+
+func encodeSample(p []byte, smp Sample) {
+	binary.LittleEndian.PutUint64(p, uint64(smp.Time.UnixNano()))
+	binary.LittleEndian.PutUint64(p[8:], math.Float64bits(smp.Value))
+}
+
+func decodeSample(p []byte) Sample {
+	return Sample{time.Unix(0, int64(binary.LittleEndian.Uint64(p))), math.Float64frombits(binary.LittleEndian.Uint64(p[8:]))}
+}
+
+// Keys lists the sensors there's history of, as chip/sensor, sorted.
+func (s *Store) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]string, 0, len(s.series))
+	for k := range s.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Query downsamples a sensor's samples from from, inclusive, to to, exclusive, into a point per step, starting at from.
+// Steps without samples are left out, so graphs can show the gaps. A step of 0 gives a point per sample.
+// The points come from the finest tier that has history back to from and isn't finer than step, or failing that the coarsest one that isn't, or the raw samples if they're all too coarse.
+func (s *Store) Query(key string, from, to time.Time, step time.Duration) ([]Point, error) {
+	if step < 0 {
+		return nil, fmt.Errorf("negative step %s", step)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ser, ok := s.series[key]
+	if !ok {
+		return nil, fmt.Errorf("no history of %s", key)
+	}
+	r, decode := ser.raw, decodeSamplePoint
+	if n := r.len(); n == 0 || decodeSample(r.at(0)).Time.After(from) {
+		for i, t := range s.opts.Tiers {
+			if t.Step > step {
+				break
+			}
+			r, decode = ser.tiers[i], decodeBucket
+			if n := r.len(); n != 0 && !decodeBucket(r.at(0)).Time.After(from) {
+				break
+			}
+		}
+	}
+
+	n := r.len()
+	i := uint64(sort.Search(int(n), func(i int) bool { return !decode(r.at(uint64(i))).Time.Before(from) }))
+	var points []Point
+	for ; i < n; i++ {
+		b := decode(r.at(i))
+		if !b.Time.Before(to) {
+			break
+		}
+		start := b.Time
+		if step > 0 {
+			start = from.Add(b.Time.Sub(from) / step * step)
+		}
+		if len(points) == 0 || !points[len(points)-1].Time.Equal(start) {
+			points = append(points, Point{Time: start, Min: b.Min, Max: b.Max})
+		}
+		points[len(points)-1].merge(b)
+	}
+	return points, nil
+}
+
+// merge adds b's samples to p's, apart from its time.
+func (p *Point) merge(b Point) {
+	p.Min, p.Max = min(p.Min, b.Min), max(p.Max, b.Max)
+	p.Avg += (b.Avg - p.Avg) * float64(b.Count) / float64(p.Count+b.Count)
+	p.Count += b.Count
+}
+
+func decodeSamplePoint(p []byte) Point {
+	smp := decodeSample(p)
+	return Point{smp.Time, smp.Value, smp.Value, smp.Value, 1}
+}
+
+// Prune drops the samples from before before, and the steps of the tiers that end by then, although they'd go round the rings eventually anyway.
+func (s *Store) Prune(before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ser := range s.series {
+		r := ser.raw
+		r.drop(uint64(sort.Search(int(r.len()), func(i int) bool { return !decodeSample(r.at(uint64(i))).Time.Before(before) })))
+		for i, t := range s.opts.Tiers {
+			r := ser.tiers[i]
+			r.drop(uint64(sort.Search(int(r.len()), func(i int) bool { return decodeBucket(r.at(uint64(i))).Time.Add(t.Step).After(before) })))
+		}
+	}
+	if s.wal != nil {
+		return s.checkpoint() // So the log isn't replayed into the rings that were emptied
+	}
+	return nil
+}
+
+// Close unmaps the files in Dir, syncing them first if there's a WAL; the Store can't be used after.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	if s.wal != nil {
+		errs = append(errs, s.checkpoint(), s.wal.f.Close())
+		s.wal = nil
+	}
+	for _, ser := range s.series {
+		errs = append(errs, ser.close())
+	}
+	s.series = nil
+	return errors.Join(errs...)
+}
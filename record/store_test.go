@@ -0,0 +1,152 @@
+package record
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+var start = time.Unix(1700000000, 0)
+
+func snapshot(at time.Time, temp float64) *lmsensors.Snapshot {
+	s := &lmsensors.TempSensor{}
+	s.Name, s.Value, s.Time = "Tctl", temp, at
+	gone := &lmsensors.VoltageSensor{}
+	gone.Name, gone.Value = "in0", lmsensors.NoValue
+	return lmsensors.NewSnapshot(&lmsensors.System{Chips: map[string]*lmsensors.Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]lmsensors.Sensor{"Tctl": s, "in0": gone}},
+	}}, at)
+}
+
+// fill records a temperature of 40 plus the second, every second for n seconds.
+func fill(t *testing.T, s *Store, n int) {
+	t.Helper()
+	for i := range n {
+		if err := s.Add(snapshot(start.Add(time.Duration(i)*time.Second), 40+float64(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	s, err := Open(Options{Retention: time.Minute, Interval: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fill(t, s, 90)
+
+	if keys := s.Keys(); len(keys) != 1 || keys[0] != "k10temp-pci-00c3/Tctl" {
+		t.Errorf("wrong keys: %v", keys)
+	}
+	// The first 30s have gone round the ring.
+	ps, err := s.Query("k10temp-pci-00c3/Tctl", start, start.Add(time.Hour), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 60 || !ps[0].Time.Equal(start.Add(30*time.Second)) || ps[0].Avg != 70 || ps[59].Avg != 129 {
+		t.Errorf("wrong raw points: %d, %+v, %+v", len(ps), ps[0], ps[len(ps)-1])
+	}
+
+	ps, err = s.Query("k10temp-pci-00c3/Tctl", start.Add(35*time.Second), start.Add(55*time.Second), 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Point{
+		{Time: start.Add(35 * time.Second), Min: 75, Max: 84, Avg: 79.5, Count: 10},
+		{Time: start.Add(45 * time.Second), Min: 85, Max: 94, Avg: 89.5, Count: 10},
+	}
+	if len(ps) != len(want) {
+		t.Fatalf("wrong points: %+v", ps)
+	}
+	for i := range want {
+		if !ps[i].Time.Equal(want[i].Time) || ps[i].Min != want[i].Min || ps[i].Max != want[i].Max || ps[i].Avg != want[i].Avg || ps[i].Count != want[i].Count {
+			t.Errorf("point %d: got %+v, want %+v", i, ps[i], want[i])
+		}
+	}
+
+	if _, err := s.Query("nope/nope", start, start, 0); err == nil {
+		t.Error("no error for an unknown sensor")
+	}
+	// Out of order samples are dropped.
+	_ = s.Append("k10temp-pci-00c3/Tctl", Sample{start, 0})
+	if ps, _ := s.Query("k10temp-pci-00c3/Tctl", start, start.Add(time.Hour), time.Hour); ps[0].Min != 70 {
+		t.Errorf("old sample recorded: %+v", ps)
+	}
+}
+
+func TestMapped(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(Options{Retention: time.Minute, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fill(t, s, 90)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// It survives being reopened, and shrinking keeps the newest.
+	s, err = Open(Options{Retention: 10 * time.Second, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	ps, err := s.Query("k10temp-pci-00c3/Tctl", start, start.Add(time.Hour), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 10 || ps[0].Avg != 120 || ps[9].Avg != 129 {
+		t.Errorf("wrong points after reopening: %+v", ps)
+	}
+	_ = s.Append("k10temp-pci-00c3/Tctl", Sample{start.Add(time.Hour), 1})
+	if ps, _ := s.Query("k10temp-pci-00c3/Tctl", start, start.Add(2*time.Hour), 0); len(ps) != 10 || ps[9].Avg != 1 {
+		t.Errorf("wrong points after appending: %+v", ps)
+	}
+}
+
+func TestTiers(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{Retention: 10 * time.Second, Tiers: []Tier{{30 * time.Second, 5 * time.Minute}, {10 * time.Second, time.Minute}}, Dir: dir}
+	s, err := Open(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t0 := start.Truncate(5 * time.Minute)
+	for i := range 300 {
+		if err := s.Append("k10temp-pci-00c3/Tctl", Sample{t0.Add(time.Duration(i) * time.Second), 40 + float64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = Open(opts); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for _, c := range []struct {
+		from      time.Duration
+		step      time.Duration
+		n, count  int
+		min0, avg float64
+	}{
+		{0, 30 * time.Second, 10, 30, 40, 54.5},                  // Only the 30s tier goes back that far
+		{240 * time.Second, 10 * time.Second, 6, 10, 280, 284.5}, // The 10s tier does
+		{240 * time.Second, 20 * time.Second, 3, 20, 280, 289.5}, // Merging its steps
+		{295 * time.Second, time.Second, 5, 1, 335, 335},         // Raw
+	} {
+		ps, err := s.Query("k10temp-pci-00c3/Tctl", t0.Add(c.from), t0.Add(time.Hour), c.step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ps) != c.n || ps[0].Count != c.count || ps[0].Min != c.min0 || ps[0].Avg != c.avg || ps[0].Max != c.min0+float64(c.count-1) {
+			t.Errorf("from %s by %s: got %d points, first %+v", c.from, c.step, len(ps), ps[0])
+		}
+	}
+
+	if _, err := Open(Options{Tiers: []Tier{{time.Minute, time.Second}}}); err == nil {
+		t.Error("no error for a tier shorter than its step")
+	}
+}
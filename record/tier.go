@@ -0,0 +1,60 @@
+package record
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// Tier is a consolidation of the samples into steps, each with their min, max and average, kept for longer than the samples themselves are.
+type Tier struct {
+	Step      time.Duration // Aligned to the Unix epoch, so eg minutes start on the minute
+	Retention time.Duration
+}
+
+// DefaultTiers are what sensor graphing tools usually keep, with the default hour of samples: minutes for a day, and five minutes for thirty days.
+var DefaultTiers = []Tier{
+	{Step: time.Minute, Retention: 24 * time.Hour},
+	{Step: 5 * time.Minute, Retention: 30 * 24 * time.Hour},
+}
+
+func (t Tier) capacity() uint64 {
+	return uint64((t.Retention + t.Step - 1) / t.Step)
+}
+
+// A bucket is a [Point] in a tier: its start, min, max, average and count.
+const bucketSize = 40
+
+func encodeBucket(p []byte, b Point) {
+	binary.LittleEndian.PutUint64(p, uint64(b.Time.UnixNano()))
+	binary.LittleEndian.PutUint64(p[8:], math.Float64bits(b.Min))
+	binary.LittleEndian.PutUint64(p[16:], math.Float64bits(b.Max))
+	binary.LittleEndian.PutUint64(p[24:], math.Float64bits(b.Avg))
+	binary.LittleEndian.PutUint64(p[32:], uint64(b.Count))
+}
+
+func decodeBucket(p []byte) Point {
+	return Point{
+		Time:  time.Unix(0, int64(binary.LittleEndian.Uint64(p))),
+		Min:   math.Float64frombits(binary.LittleEndian.Uint64(p[8:])),
+		Max:   math.Float64frombits(binary.LittleEndian.Uint64(p[16:])),
+		Avg:   math.Float64frombits(binary.LittleEndian.Uint64(p[24:])),
+		Count: int(binary.LittleEndian.Uint64(p[32:])),
+	}
+}
+
+// consolidate adds a sample to its step's bucket, which is the newest in the ring if it's been started, updating it in place so that it's kept even if the store's closed midway through the step.
+func consolidate(r *ring, step time.Duration, smp Sample) {
+	start := smp.Time.Truncate(step)
+	if n := r.len(); n != 0 {
+		last := r.at(n - 1)
+		if b := decodeBucket(last); b.Time.Equal(start) {
+			b.merge(Point{Min: smp.Value, Max: smp.Value, Avg: smp.Value, Count: 1})
+			encodeBucket(last, b)
+			return
+		}
+	}
+	var rec [bucketSize]byte
+	encodeBucket(rec[:], Point{start, smp.Value, smp.Value, smp.Value, 1})
+	r.push(rec[:])
+}
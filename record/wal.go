@@ -0,0 +1,129 @@
+package record
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SyncPolicy is how often a WAL is synced to disk, trading how much a crash can lose against how much the disk's written to.
+type SyncPolicy int
+
+const (
+	SyncPeriodic SyncPolicy = iota // After an Add or Append once Options.SyncInterval has passed since the last sync, so a crash loses at most that long
+	SyncAlways                     // After every Add or Append, so a crash loses nothing that's been recorded
+	SyncNever                      // Leaving it to the OS, so it only guards against the rings being corrupted midway through a write
+)
+
+// A WAL record is a sensor's key, prefixed with its length as a uvarint, its sample as in the ring, and a CRC-32 of them both, so that a record torn by a crash is spotted and it and everything after dropped.
+const walFile = "wal.log"
+
+// walLimit is how big the log gets before the rings are synced and it's emptied.
+const walLimit = 16 << 20
+
+type wal struct {
+	f      *os.File
+	buf    []byte // Yet to be written
+	size   int
+	synced time.Time
+}
+
+func (w *wal) log(key string, rec []byte) {
+	start := len(w.buf)
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(key)))
+	w.buf = append(w.buf, key...)
+	w.buf = append(w.buf, rec...)
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, crc32.ChecksumIEEE(w.buf[start:]))
+}
+
+// openWAL replays the log into the rings, and then starts it afresh.
+func (s *Store) openWAL() error {
+	path := filepath.Join(s.opts.Dir, walFile)
+	p, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := s.replay(p); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	s.wal = &wal{f: f, synced: time.Now()}
+	return s.checkpoint()
+}
+
+// replay appends the samples in a log that are newer than the ones in their rings, which have them if they were written back before the crash.
+// The tiers might have diverged from the raw samples, though, if only some of their pages were written back.
+func (s *Store) replay(p []byte) error {
+	var batch []staged
+	for len(p) > 0 {
+		n, l := binary.Uvarint(p)
+		if l <= 0 || uint64(len(p)-l) < n+sampleSize+4 {
+			break // Torn
+		}
+		end := l + int(n) + sampleSize
+		if crc32.ChecksumIEEE(p[:end]) != binary.LittleEndian.Uint32(p[end:]) {
+			break
+		}
+		key, smp := string(p[l:l+int(n)]), decodeSample(p[l+int(n):end])
+		p = p[end+4:]
+
+		if ser, ok := s.series[key]; ok {
+			if r := ser.raw; r.len() != 0 && !smp.Time.After(decodeSample(r.at(r.len()-1)).Time) {
+				continue
+			}
+		}
+		var err error
+		if batch, err = s.stage(batch, key, smp); err != nil {
+			return err
+		}
+	}
+	return s.apply(batch) // There's no WAL yet, so it's only put in the rings
+}
+
+// commit writes out what's been logged, syncing it as the policy says.
+func (s *Store) commit() error {
+	w := s.wal
+	if w == nil || len(w.buf) == 0 {
+		return nil
+	}
+	if _, err := w.f.Write(w.buf); err != nil {
+		return err
+	}
+	w.size += len(w.buf)
+	w.buf = w.buf[:0]
+	switch s.opts.Sync {
+	case SyncAlways:
+	case SyncPeriodic:
+		if time.Since(w.synced) < s.opts.SyncInterval {
+			return nil
+		}
+	default:
+		return nil
+	}
+	w.synced = time.Now()
+	return w.f.Sync()
+}
+
+// checkpoint syncs the rings, after which the log isn't needed.
+func (s *Store) checkpoint() error {
+	for _, ser := range s.series {
+		for _, r := range append([]*ring{ser.raw}, ser.tiers...) {
+			if err := r.sync(); err != nil {
+				return err
+			}
+		}
+	}
+	w := s.wal
+	if err := w.f.Truncate(0); err != nil {
+		return err
+	}
+	w.size = 0
+	w.synced = time.Now()
+	return w.f.Sync()
+}
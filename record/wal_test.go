@@ -0,0 +1,95 @@
+package record
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWAL(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{Retention: time.Minute, Tiers: []Tier{{10 * time.Second, time.Hour}}, Dir: dir, WAL: true, Sync: SyncAlways}
+	s, err := Open(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fill(t, s, 20)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if st, err := os.Stat(filepath.Join(dir, walFile)); err != nil || st.Size() != 0 {
+		t.Fatalf("log not emptied on close: %v, %v", st, err)
+	}
+	checkpointed := map[string][]byte{}
+	rings, _ := filepath.Glob(filepath.Join(dir, "*.ring"))
+	tiers, _ := filepath.Glob(filepath.Join(dir, "*.tier"))
+	for _, f := range append(rings, tiers...) {
+		checkpointed[f], _ = os.ReadFile(f)
+	}
+
+	// Record another 20s, and then lose the machine, and the rings' writes since they were synced.
+	if s, err = Open(opts); err != nil {
+		t.Fatal(err)
+	}
+	for i := 20; i < 40; i++ {
+		if err := s.Add(snapshot(start.Add(time.Duration(i)*time.Second), 40+float64(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, ser := range s.series {
+		ser.close()
+	}
+	s.wal.f.Close()
+	for f, p := range checkpointed {
+		if err := os.WriteFile(f, p, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, _ := os.OpenFile(filepath.Join(dir, walFile), os.O_APPEND|os.O_WRONLY, 0)
+	f.Write([]byte{20, 'k', '1', '0'}) // Torn mid-record
+	f.Close()
+
+	if s, err = Open(opts); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	ps, err := s.Query("k10temp-pci-00c3/Tctl", start, start.Add(time.Hour), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 40 || ps[39].Avg != 79 {
+		t.Errorf("wrong points after recovery: %d, last %+v", len(ps), ps[len(ps)-1])
+	}
+	ps, _ = s.Query("k10temp-pci-00c3/Tctl", start.Truncate(10*time.Second), start.Add(time.Hour), 10*time.Second)
+	var n int
+	for _, p := range ps {
+		n += p.Count
+	}
+	if n != 40 {
+		t.Errorf("tier has %d samples after recovery: %+v", n, ps)
+	}
+
+	if _, err := Open(Options{WAL: true}); err == nil {
+		t.Error("no error for a WAL without a Dir")
+	}
+}
+
+func TestWALFirst(t *testing.T) {
+	s, err := Open(Options{Retention: time.Minute, Dir: t.TempDir(), WAL: true, Sync: SyncAlways})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	fill(t, s, 10)
+
+	// A sample that can't be logged isn't recorded at all, so the rings never have what the log doesn't.
+	s.wal.f.Close()
+	if err := s.Add(snapshot(start.Add(10*time.Second), 50)); err == nil {
+		t.Fatal("no error from a failed log write")
+	}
+	ps, err := s.Query("k10temp-pci-00c3/Tctl", start, start.Add(time.Hour), 0)
+	if err != nil || len(ps) != 10 {
+		t.Errorf("recorded without being logged: %d points, %v", len(ps), err)
+	}
+}
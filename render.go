@@ -0,0 +1,76 @@
+package lmsensors
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Renderer formats a sensor's reading, unit and all, eg "1.05V (nominal 1.00V ±5%)".
+// It's used by the String() methods of all the sensors of the type it's registered for, so mustn't call String() itself; Rendered() and Unit() are fine.
+type Renderer func(Sensor) string
+
+// renderMu guards renderers and decimals.
+var renderMu sync.RWMutex
+
+var renderers = map[LmSensorType]Renderer{}
+
+// RegisterRenderer sets the [Renderer] for a type of sensor; nil restores the default, which is Rendered() followed by Unit().
+// It applies to every sensor of the type formatted after it returns, including ones read before.
+func RegisterRenderer(t LmSensorType, r Renderer) {
+	renderMu.Lock()
+	defer renderMu.Unlock()
+	if r == nil {
+		delete(renderers, t)
+		return
+	}
+	renderers[t] = r
+}
+
+func render(s Sensor) string {
+	renderMu.RLock()
+	r, ok := renderers[s.Type()]
+	renderMu.RUnlock()
+	if ok {
+		return r(s)
+	}
+	return s.Rendered() + s.Unit()
+}
+
+// decimals is the number of decimal places each type of sensor is rendered with.
+// Temperatures default to one, as k10temp and coretemp have a resolution of 0.125°C.
+var decimals = map[LmSensorType]int{
+	Temperature: 1,
+	Voltage:     2,
+	Fan:         0,
+	Current:     2,
+	Power:       2,
+	Energy:      2,
+	Humidity:    1,
+}
+
+// SetDecimals sets the number of decimal places Rendered() uses for a type of sensor, eg 0 for a display with no room for tenths of a degree.
+func SetDecimals(t LmSensorType, n int) {
+	renderMu.Lock()
+	defer renderMu.Unlock()
+	decimals[t] = n
+}
+
+func decimalsOf(t LmSensorType) int {
+	renderMu.RLock()
+	defer renderMu.RUnlock()
+	return decimals[t]
+}
+
+// formatValue formats a reading without the "-0" that rounding small negative numbers gives, and [NoValue] as "N/A", like sensors(1).
+func formatValue(v float64, decimals int) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "N/A"
+	}
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	if strings.Trim(s, "-0.") == "" {
+		return strings.TrimPrefix(s, "-")
+	}
+	return s
+}
@@ -0,0 +1,54 @@
+package lmsensors
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestRegisterRenderer(t *testing.T) {
+	s := &VoltageSensor{baseSensor{Name: "Vcore", Value: 1.05}}
+	if str := s.String(); str != "Vcore: 1.05V" {
+		t.Errorf("wrong default rendering: %s", str)
+	}
+
+	RegisterRenderer(Voltage, func(s Sensor) string {
+		v, _ := valueOf(s)
+		return fmt.Sprintf("%s%s (nominal 1.00%s ±%.0f%%)", s.Rendered(), s.Unit(), s.Unit(), math.Round(math.Abs(v-1)*100))
+	})
+	defer RegisterRenderer(Voltage, nil)
+	if str := s.String(); str != "Vcore: 1.05V (nominal 1.00V ±5%)" {
+		t.Errorf("wrong custom rendering: %s", str)
+	}
+	if str := (&FanSensor{baseSensor: baseSensor{Name: "CPU", Value: 1200}}).String(); str != "CPU: 1200min⁻¹" {
+		t.Errorf("renderer applied to wrong type: %s", str)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	cases := []struct {
+		v        float64
+		decimals int
+		want     string
+	}{
+		{45.125, 1, "45.1"},
+		{45.125, 3, "45.125"},
+		{-0.4, 0, "0"},
+		{-0.04, 1, "0.0"},
+		{-12.5, 1, "-12.5"},
+		{-12.6, 0, "-13"},
+		{0, 2, "0.00"},
+	}
+	for _, c := range cases {
+		if got := formatValue(c.v, c.decimals); got != c.want {
+			t.Errorf("formatValue(%v, %d) = %s; want %s", c.v, c.decimals, got, c.want)
+		}
+	}
+
+	s := &TempSensor{baseSensor: baseSensor{Name: "Tctl", Value: 45.125}, TempType: Unknown, TempTypeRaw: -1}
+	SetDecimals(Temperature, 3)
+	defer SetDecimals(Temperature, 1)
+	if r := s.Rendered(); r != "45.125" || s.Reading() != 45.125 {
+		t.Errorf("wrong temperature rendering: %s %v", r, s.Reading())
+	}
+}
@@ -0,0 +1,143 @@
+package report
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Funcs are the functions available to templates, on top of the built-in ones:
+//
+//	ofType TYPE READINGS     the readings of a type, by name, eg "Temperature" or "Fan"
+//	match SELECTOR READINGS  the readings a [lmsensors.Selector] matches, eg "k10temp-*/*"
+//	alarms READINGS          the readings in alarm
+//	valid READINGS           the readings with a value
+//	sortBy FIELD READINGS    sorted by "chip", "sensor", "type" or "value", descending if prefixed with "-"
+//	first N READINGS         the first N readings
+//	convert VALUE FROM TO    converts between units, eg from "°C" to "°F", or "W" to "mW"
+//	number VALUE DECIMALS    formats a value, with "N/A" for [lmsensors.NoValue]
+//
+// Sorting is stable, so the readings are otherwise in their usual order, by chip and then sensor.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"ofType": func(typ string, rs []lmsensors.Reading) []lmsensors.Reading {
+			return filter(rs, func(r lmsensors.Reading) bool { return r.Type.String() == typ })
+		},
+		"match": func(sel string, rs []lmsensors.Reading) ([]lmsensors.Reading, error) {
+			s := lmsensors.Selector(sel)
+			if !s.Valid() {
+				return nil, fmt.Errorf("invalid selector %q", sel)
+			}
+			return filter(rs, func(r lmsensors.Reading) bool { return s.Match(r.Chip, r.Sensor) }), nil
+		},
+		"alarms": func(rs []lmsensors.Reading) []lmsensors.Reading {
+			return filter(rs, func(r lmsensors.Reading) bool { return r.Alarm })
+		},
+		"valid": func(rs []lmsensors.Reading) []lmsensors.Reading {
+			return filter(rs, func(r lmsensors.Reading) bool { return r.Valid })
+		},
+		"sortBy": sortBy,
+		"first": func(n int, rs []lmsensors.Reading) []lmsensors.Reading {
+			return rs[:min(max(n, 0), len(rs))]
+		},
+		"convert": Convert,
+		"number": func(v float64, decimals int) string {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return "N/A"
+			}
+			return strconv.FormatFloat(v, 'f', decimals, 64)
+		},
+	}
+}
+
+func filter(rs []lmsensors.Reading, keep func(lmsensors.Reading) bool) []lmsensors.Reading {
+	var out []lmsensors.Reading
+	for _, r := range rs {
+		if keep(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func sortBy(field string, rs []lmsensors.Reading) ([]lmsensors.Reading, error) {
+	key, desc := strings.CutPrefix(field, "-")
+	var by func(a, b lmsensors.Reading) int
+	switch key {
+	case "chip":
+		by = func(a, b lmsensors.Reading) int { return strings.Compare(a.Chip, b.Chip) }
+	case "sensor":
+		by = func(a, b lmsensors.Reading) int { return strings.Compare(a.Sensor, b.Sensor) }
+	case "type":
+		by = func(a, b lmsensors.Reading) int { return cmp.Compare(a.Type, b.Type) }
+	case "value":
+		by = func(a, b lmsensors.Reading) int { return cmp.Compare(a.Value, b.Value) }
+	default:
+		return nil, fmt.Errorf("can't sort by %q", field)
+	}
+	out := slices.Clone(rs)
+	slices.SortStableFunc(out, func(a, b lmsensors.Reading) int {
+		// Readings without values go last either way.
+		if key == "value" && a.Valid != b.Valid {
+			if a.Valid {
+				return -1
+			}
+			return 1
+		}
+		if desc {
+			return by(b, a)
+		}
+		return by(a, b)
+	})
+	return out, nil
+}
+
+// unit is how to get to and from its dimension's base unit.
+type unit struct {
+	dim              string
+	toBase, fromBase func(float64) float64
+}
+
+func scaled(dim string, factor float64) unit {
+	return unit{dim, func(v float64) float64 { return v * factor }, func(v float64) float64 { return v / factor }}
+}
+
+// units are the units [Convert] knows.
+var units = map[string]unit{
+	"°C":  {"temperature", func(v float64) float64 { return v }, func(v float64) float64 { return v }},
+	"°F":  {"temperature", func(v float64) float64 { return (v - 32) * 5 / 9 }, func(v float64) float64 { return v*9/5 + 32 }},
+	"K":   {"temperature", func(v float64) float64 { return v - 273.15 }, func(v float64) float64 { return v + 273.15 }},
+	"V":   scaled("voltage", 1),
+	"mV":  scaled("voltage", 1e-3),
+	"A":   scaled("current", 1),
+	"mA":  scaled("current", 1e-3),
+	"W":   scaled("power", 1),
+	"mW":  scaled("power", 1e-3),
+	"kW":  scaled("power", 1e3),
+	"J":   scaled("energy", 1),
+	"kJ":  scaled("energy", 1e3),
+	"Wh":  scaled("energy", 3600),
+	"kWh": scaled("energy", 3.6e6),
+}
+
+// Convert converts a value between units of the same dimension, eg from "°C" to "°F", or "J" to "kWh"; [lmsensors.NoValue] stays as it is.
+func Convert(v float64, from, to string) (float64, error) {
+	f, ok := units[from]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", from)
+	}
+	t, ok := units[to]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", to)
+	}
+	if f.dim != t.dim {
+		return 0, fmt.Errorf("can't convert %s, a %s, to %s, a %s", from, f.dim, to, t.dim)
+	}
+	return t.fromBase(f.toBase(v)), nil
+}
@@ -0,0 +1,8 @@
+package report
+
+import "html/template"
+
+// NewHTML parses an html/template template, with [Funcs].
+func NewHTML(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(Funcs()).Parse(text)
+}
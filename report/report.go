@@ -0,0 +1,62 @@
+// Package report renders readings through text/template or html/template templates, for eg emailed or status page reports, with functions for picking out and converting the readings.
+// For bug reports and the like, [Markdown] and [HTML] write ready-made tables.
+//
+// A template is executed with a [Data], eg
+//
+//	{{range sortBy "-value" (ofType "Temperature" .Readings)}}{{.Chip}}/{{.Sensor}}: {{number (convert .Value .Unit "°F") 0}}°F
+//	{{end}}
+package report
+
+import (
+	"io"
+	"os"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Data is what templates are executed with.
+type Data struct {
+	Time     time.Time
+	Host     string
+	Chips    []lmsensors.ChipInfo // Sorted by ID
+	Readings []lmsensors.Reading  // Sorted by chip, then sensor
+	Alarms   []lmsensors.Reading  // The readings in alarm
+	Summary  lmsensors.Summary
+}
+
+// NewData gathers up a reading for templates.
+func NewData(sys *lmsensors.System) Data {
+	snap := lmsensors.NewSnapshot(sys, sys.Time)
+	d := Data{Time: sys.Time, Summary: sys.Summary()}
+	d.Host, _ = os.Hostname()
+	for c := range snap.Chips {
+		d.Chips = append(d.Chips, c)
+	}
+	sort.Slice(d.Chips, func(i, j int) bool { return d.Chips[i].ID < d.Chips[j].ID })
+	for r := range snap.Readings {
+		d.Readings = append(d.Readings, r)
+		if r.Alarm {
+			d.Alarms = append(d.Alarms, r)
+		}
+	}
+	return d
+}
+
+// Template is a parsed text/template or html/template template.
+type Template interface {
+	Execute(w io.Writer, data any) error
+}
+
+// Render executes a template with a reading's [Data].
+// The template must have been given [Funcs] if it uses them, see [New] and [NewHTML].
+func Render(w io.Writer, t Template, sys *lmsensors.System) error {
+	return t.Execute(w, NewData(sys))
+}
+
+// New parses a text/template template, with [Funcs].
+func New(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(Funcs()).Parse(text)
+}
@@ -0,0 +1,122 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+func system() *lmsensors.System {
+	tctl := &lmsensors.TempSensor{}
+	tctl.Name, tctl.Value = "Tctl", 61.5
+	tccd := &lmsensors.TempSensor{}
+	tccd.Name, tccd.Value = "Tccd1", 100
+	fan := &lmsensors.FanSensor{}
+	fan.Name, fan.Value = "fan2", 812
+	vcore := &lmsensors.VoltageSensor{}
+	vcore.Name, vcore.Value = "Vcore", lmsensors.NoValue
+	return &lmsensors.System{Chips: map[string]*lmsensors.Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Type: "k10temp", Bus: "pci", Sensors: map[string]lmsensors.Sensor{"Tctl": tctl, "Tccd1": tccd}},
+		"nct6798-isa-0290": {ID: "nct6798-isa-0290", Type: "nct6798", Bus: "isa", Sensors: map[string]lmsensors.Sensor{"fan2": fan, "Vcore": vcore}},
+	}}
+}
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		tmpl, want string
+	}{
+		{`{{range sortBy "-value" (ofType "Temperature" .Readings)}}{{.Sensor}}={{number (convert .Value .Unit "°F") 1}} {{end}}`, "Tccd1=212.0 Tctl=142.7 "},
+		{`{{range match "nct6798-*/*" .Readings}}{{.Sensor}}={{number .Value 0}} {{end}}`, "Vcore=N/A fan2=812 "},
+		{`{{range first 1 (sortBy "value" (valid .Readings))}}{{.Sensor}}{{end}}`, "Tctl"},
+		{`{{len .Chips}} {{(index .Chips 0).ID}} {{.Summary.CPUTemp}} {{len .Alarms}}`, "2 k10temp-pci-00c3 100 0"},
+		{`{{convert 1.5 "kWh" "J"}}`, "5.4e+06"},
+	}
+	for _, c := range cases {
+		tmpl, err := New("test", c.tmpl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b strings.Builder
+		if err := Render(&b, tmpl, system()); err != nil {
+			t.Errorf("%s: %v", c.tmpl, err)
+			continue
+		}
+		if b.String() != c.want {
+			t.Errorf("%s: got %q, want %q", c.tmpl, b.String(), c.want)
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	for _, text := range []string{
+		`{{convert 1 "°C" "V"}}`,
+		`{{convert 1 "furlongs" "V"}}`,
+		`{{sortBy "colour" .Readings}}`,
+		`{{match "[" .Readings}}`,
+	} {
+		tmpl, err := New("test", text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Render(&strings.Builder{}, tmpl, system()); err == nil {
+			t.Errorf("%s: no error", text)
+		}
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	tmpl, err := NewHTML("test", `<ul>{{range alarms .Readings}}<li>{{.Sensor}}</li>{{else}}<li>All <b>fine</b> on {{.Host | printf "%.0s"}}</li>{{end}}</ul>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := Render(&b, tmpl, system()); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "<ul><li>All <b>fine</b> on </li></ul>" {
+		t.Errorf("got %q", b.String())
+	}
+}
+
+type alarmed struct{ *lmsensors.FanSensor }
+
+func (alarmed) Alarm() bool { return true }
+
+func tableSystem() *lmsensors.System {
+	sys := system()
+	fan := &lmsensors.FanSensor{}
+	fan.Name, fan.Value = "fan1|<cpu>", 0
+	sys.Chips["nct6798-isa-0290"].Sensors["fan1|<cpu>"] = alarmed{fan}
+	delete(sys.Chips, "k10temp-pci-00c3")
+	return sys
+}
+
+func TestMarkdown(t *testing.T) {
+	var b strings.Builder
+	if err := Markdown(&b, tableSystem()); err != nil {
+		t.Fatal(err)
+	}
+	want := `### nct6798-isa-0290 (nct6798)
+
+| Sensor | Value | Min | Max | Crit | Alarm |
+|---|--:|--:|--:|--:|---|
+| Vcore | N/A |  |  |  |  |
+| **fan1\|&lt;cpu&gt;** | **0min⁻¹** |  |  |  | **ALARM** |
+| fan2 | 812min⁻¹ |  |  |  |  |
+`
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	var b strings.Builder
+	if err := HTML(&b, tableSystem()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `<tr class="alarm" style="background-color: #fcc; font-weight: bold"><td>fan1|&lt;cpu&gt;</td><td>0min⁻¹</td><td></td><td></td><td></td><td>ALARM</td></tr>`) ||
+		!strings.Contains(b.String(), "<tr><td>fan2</td><td>812min⁻¹</td>") || !strings.HasPrefix(b.String(), "<table class=\"chip\">\n<caption>nct6798-isa-0290 (nct6798)</caption>") {
+		t.Errorf("got\n%s", b.String())
+	}
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Markdown writes a reading as Markdown, a heading and a table per chip, for pasting into eg bug reports.
+// Sensors in alarm are in bold, and flagged.
+func Markdown(w io.Writer, sys *lmsensors.System) error {
+	d := NewData(sys)
+	var b strings.Builder
+	for i, c := range d.Chips {
+		if i != 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "### %s\n\n", mdEscape(chipTitle(c)))
+		b.WriteString("| Sensor | Value | Min | Max | Crit | Alarm |\n")
+		b.WriteString("|---|--:|--:|--:|--:|---|\n")
+		for _, r := range chipReadings(d.Readings, c.ID) {
+			cells := row(r)
+			for j := range cells {
+				cells[j] = mdEscape(cells[j])
+				if r.Alarm && cells[j] != "" {
+					cells[j] = "**" + cells[j] + "**"
+				}
+			}
+			fmt.Fprintf(&b, "| %s |\n", strings.Join(cells, " | "))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// HTML writes a reading as an HTML fragment, a table per chip, for eg wiki pages documenting a machine.
+// Rows of sensors in alarm have the class "alarm", and are highlighted without needing a stylesheet.
+func HTML(w io.Writer, sys *lmsensors.System) error {
+	d := NewData(sys)
+	var b strings.Builder
+	for _, c := range d.Chips {
+		fmt.Fprintf(&b, "<table class=\"chip\">\n<caption>%s</caption>\n", html.EscapeString(chipTitle(c)))
+		b.WriteString("<tr><th>Sensor</th><th>Value</th><th>Min</th><th>Max</th><th>Crit</th><th>Alarm</th></tr>\n")
+		for _, r := range chipReadings(d.Readings, c.ID) {
+			if r.Alarm {
+				b.WriteString(`<tr class="alarm" style="background-color: #fcc; font-weight: bold">`)
+			} else {
+				b.WriteString("<tr>")
+			}
+			for _, cell := range row(r) {
+				fmt.Fprintf(&b, "<td>%s</td>", html.EscapeString(cell))
+			}
+			b.WriteString("</tr>\n")
+		}
+		b.WriteString("</table>\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// chipTitle is eg "k10temp-pci-00c3 (AMD CPU)", with the display name if it's different.
+func chipTitle(c lmsensors.ChipInfo) string {
+	if c.DisplayName == "" || c.DisplayName == c.ID {
+		return c.ID
+	}
+	return c.ID + " (" + c.DisplayName + ")"
+}
+
+func chipReadings(rs []lmsensors.Reading, chip string) []lmsensors.Reading {
+	return filter(rs, func(r lmsensors.Reading) bool { return r.Chip == chip })
+}
+
+// row is a reading's cells: the sensor, its value, its limits, and whether it's in alarm; empty for what it doesn't have.
+func row(r lmsensors.Reading) []string {
+	value := r.Rendered + r.Unit
+	if !r.Valid {
+		value = "N/A"
+	}
+	alarm := ""
+	if r.Alarm {
+		alarm = "ALARM"
+	}
+	return []string{r.Sensor, value, limit(r.Min, r.Unit), limit(r.Max, r.Unit), limit(r.Crit, r.Unit), alarm}
+}
+
+func limit(v float64, unit string) string {
+	if math.IsNaN(v) {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64) + unit
+}
+
+// mdEscape stops text breaking out of a table cell, or being taken as formatting.
+func mdEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`", "<", "&lt;", ">", "&gt;", "\n", " ").Replace(s)
+}
@@ -0,0 +1,50 @@
+package lmsensors
+
+import (
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy says how reading a subfeature is retried when it fails, as some intermittently return EIO, eg due to SMBus contention with the BIOS.
+type RetryPolicy struct {
+	Attempts int             // Total number of attempts; 0 or 1 means no retries
+	Backoff  time.Duration   // Wait before the first retry, doubling for each one after
+	Codes    []SensorErrCode // Errors worth retrying; if empty, [ErrSensorKernel] and [ErrSensorIO]
+}
+
+var retryPolicy atomic.Pointer[RetryPolicy]
+
+// SetRetryPolicy sets the [RetryPolicy] for all reads; the default is not to retry.
+// Reads already being retried finish under the policy they started with.
+func SetRetryPolicy(p RetryPolicy) {
+	p.Codes = slices.Clone(p.Codes)
+	retryPolicy.Store(&p)
+}
+
+func currentRetryPolicy() RetryPolicy {
+	if p := retryPolicy.Load(); p != nil {
+		return *p
+	}
+	return RetryPolicy{}
+}
+
+func (p RetryPolicy) retryable(code SensorErrCode) bool {
+	if len(p.Codes) == 0 {
+		return code == ErrSensorKernel || code == ErrSensorIO
+	}
+	return slices.Contains(p.Codes, code)
+}
+
+// retry calls read until it succeeds, fails with an error not worth retrying, or runs out of attempts.
+func (p RetryPolicy) retry(read func() SensorErrCode) SensorErrCode {
+	backoff := p.Backoff
+	for attempt := 1; ; attempt++ {
+		code := read()
+		if code == 0 || attempt >= p.Attempts || !p.retryable(code) {
+			return code
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
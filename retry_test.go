@@ -0,0 +1,41 @@
+package lmsensors
+
+import "testing"
+
+func TestRetryPolicy(t *testing.T) {
+	failing := func(code SensorErrCode, failures int) (func() SensorErrCode, *int) {
+		calls := 0
+		return func() SensorErrCode {
+			calls++
+			if calls <= failures {
+				return code
+			}
+			return 0
+		}, &calls
+	}
+
+	read, calls := failing(ErrSensorIO, 2)
+	if code := (RetryPolicy{Attempts: 3}).retry(read); code != 0 || *calls != 3 {
+		t.Errorf("transient error not retried: code=%s calls=%d", code, *calls)
+	}
+
+	read, calls = failing(ErrSensorIO, 5)
+	if code := (RetryPolicy{Attempts: 3}).retry(read); code != ErrSensorIO || *calls != 3 {
+		t.Errorf("attempts not bounded: code=%s calls=%d", code, *calls)
+	}
+
+	read, calls = failing(ErrSensorAccessR, 1)
+	if code := (RetryPolicy{Attempts: 3}).retry(read); code != ErrSensorAccessR || *calls != 1 {
+		t.Errorf("permanent error retried: code=%s calls=%d", code, *calls)
+	}
+
+	read, calls = failing(ErrSensorAccessR, 1)
+	if code := (RetryPolicy{Attempts: 3, Codes: []SensorErrCode{ErrSensorAccessR}}).retry(read); code != 0 || *calls != 2 {
+		t.Errorf("configured code not retried: code=%s calls=%d", code, *calls)
+	}
+
+	read, calls = failing(ErrSensorIO, 1)
+	if code := (RetryPolicy{}).retry(read); code != ErrSensorIO || *calls != 1 {
+		t.Errorf("zero policy retried: code=%s calls=%d", code, *calls)
+	}
+}
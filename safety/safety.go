@@ -0,0 +1,179 @@
+// Package safety is a last line of defence against overheating: it runs emergency actions, like forcing the fans to full speed or powering off, when a temperature stays over its hardware limit.
+package safety
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Trip describes a temperature that's been over its limit for long enough to act.
+type Trip struct {
+	Chip   string
+	Sensor string
+	Value  float64
+	Limit  float64 // The emergency limit if the hardware has one, else crit
+	Since  time.Time
+	Test   bool // In test mode, the actions weren't run
+}
+
+// Action is something to do when a [Trip] happens.
+type Action struct {
+	Name string
+	Run  func(ctx context.Context, t Trip) error
+}
+
+// Guard watches temperatures against their hardware crit and emergency limits.
+type Guard struct {
+	hold    time.Duration
+	actions []Action
+	onTrip  func(Trip, error)
+	test    bool
+
+	over  map[string]time.Time // When each over-limit sensor went over
+	fired map[string]bool
+}
+
+// Option configures a [Guard].
+type Option func(*Guard)
+
+// New makes a [Guard] that trips when a temperature has been over its limit for hold.
+func New(hold time.Duration, opts ...Option) *Guard {
+	g := &Guard{hold: hold, over: map[string]time.Time{}, fired: map[string]bool{}}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// WithAction adds an action to run on every trip. Actions run in the order they were added, and one failing doesn't stop the rest.
+func WithAction(a Action) Option {
+	return func(g *Guard) {
+		g.actions = append(g.actions, a)
+	}
+}
+
+// OnTrip registers a function to be called after every trip, with any errors from the actions.
+func OnTrip(fn func(Trip, error)) Option {
+	return func(g *Guard) {
+		g.onTrip = fn
+	}
+}
+
+// TestMode detects trips and reports them to the [OnTrip] function as usual, but doesn't run the actions.
+// Use it, perhaps with lower limits, to check the wiring before trusting it.
+func TestMode() Option {
+	return func(g *Guard) {
+		g.test = true
+	}
+}
+
+// Watch returns an option that runs the guard on every poll of a [lmsensors.Watcher].
+// Actions run on the polling goroutine, so polling pauses until they're done.
+func (g *Guard) Watch(ctx context.Context) lmsensors.WatcherOption {
+	return lmsensors.OnPoll(func(sys *lmsensors.System, _ error) {
+		g.Observe(ctx, sys, time.Now())
+	})
+}
+
+// limit is the threshold a temperature is held to, preferring emergency, as crit is sometimes where throttling starts.
+func limit(ts *lmsensors.TempSensor) float64 {
+	if ts.Emergency != 0 {
+		return ts.Emergency
+	}
+	return ts.Crit
+}
+
+// Observe checks one reading, taken at now. Each sensor trips once per excursion over its limit.
+func (g *Guard) Observe(ctx context.Context, sys *lmsensors.System, now time.Time) {
+	for _, chip := range sys.Chips {
+		for name, s := range chip.Sensors {
+			ts, ok := s.(*lmsensors.TempSensor)
+			if !ok || ts == nil || !ts.Valid() {
+				continue // A failed read neither ends an excursion nor continues one
+			}
+			key := chip.ID + "/" + name
+			lim := limit(ts)
+			if lim == 0 || ts.Value < lim {
+				delete(g.over, key)
+				delete(g.fired, key)
+				continue
+			}
+			since, ok := g.over[key]
+			if !ok {
+				since = now
+				g.over[key] = now
+			}
+			if g.fired[key] || now.Sub(since) < g.hold {
+				continue
+			}
+			g.fired[key] = true
+			g.trip(ctx, Trip{Chip: chip.ID, Sensor: name, Value: ts.Value, Limit: lim, Since: since, Test: g.test})
+		}
+	}
+}
+
+func (g *Guard) trip(ctx context.Context, t Trip) {
+	var errs []error
+	if !t.Test {
+		for _, a := range g.actions {
+			if err := a.Run(ctx, t); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", a.Name, err))
+			}
+		}
+	}
+	if g.onTrip != nil {
+		g.onTrip(t, errors.Join(errs...))
+	}
+}
+
+// FullSpeed sets every PWM output of every hwmon device to full speed, in manual mode.
+func FullSpeed() Action {
+	return Action{"full speed", func(context.Context, Trip) error {
+		devs, err := lmsensors.HwmonDevices()
+		if err != nil {
+			return err
+		}
+		var errs []error
+		for _, d := range devs {
+			chans, err := d.PWMs()
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			for _, c := range chans {
+				if err := d.SetPWM(c, 255); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+		return errors.Join(errs...)
+	}}
+}
+
+// Command runs a command, not through a shell. argv is the command and its arguments, so can't be empty.
+func Command(name string, argv ...string) (Action, error) {
+	if len(argv) == 0 {
+		return Action{}, fmt.Errorf("%s: no command", name)
+	}
+	return command(name, argv), nil
+}
+
+func command(name string, argv []string) Action {
+	return Action{name, func(ctx context.Context, _ Trip) error {
+		out, err := exec.CommandContext(ctx, argv[0], argv[1:]...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, out)
+		}
+		return nil
+	}}
+}
+
+// Poweroff shuts the machine down through systemd.
+func Poweroff() Action {
+	return command("poweroff", []string{"systemctl", "poweroff"})
+}
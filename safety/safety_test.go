@@ -0,0 +1,93 @@
+package safety
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+func reading(v float64) *lmsensors.System {
+	s := &lmsensors.TempSensor{Crit: 95, Emergency: 105}
+	s.Name, s.Value = "Tctl", v
+	return &lmsensors.System{Chips: map[string]*lmsensors.Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]lmsensors.Sensor{"Tctl": s}},
+	}}
+}
+
+func TestGuard(t *testing.T) {
+	for _, test := range []bool{false, true} {
+		runs := 0
+		var trips []Trip
+		var errs []error
+		opts := []Option{
+			WithAction(Action{"count", func(context.Context, Trip) error { runs++; return nil }}),
+			WithAction(Action{"fail", func(context.Context, Trip) error { return errors.New("nope") }}),
+			OnTrip(func(tr Trip, err error) { trips = append(trips, tr); errs = append(errs, err) }),
+		}
+		if test {
+			opts = append(opts, TestMode())
+		}
+		g := New(10*time.Second, opts...)
+
+		start := time.Now()
+		// Over crit isn't enough; then two excursions over emergency, the first too short.
+		for i, v := range []float64{100, 106, 90, 106, 107, 108, 108} {
+			g.Observe(context.Background(), reading(v), start.Add(time.Duration(i)*5*time.Second))
+		}
+		if len(trips) != 1 || trips[0].Limit != 105 || trips[0].Value != 108 || trips[0].Test != test {
+			t.Fatalf("test=%t: wrong trips: %+v", test, trips)
+		}
+		switch {
+		case test && (runs != 0 || errs[0] != nil):
+			t.Errorf("actions ran in test mode")
+		case !test && (runs != 1 || errs[0] == nil):
+			t.Errorf("wrong action results: runs=%d err=%v", runs, errs[0])
+		}
+	}
+}
+
+func TestGuardFailedRead(t *testing.T) {
+	var trips []Trip
+	g := New(10*time.Second, OnTrip(func(tr Trip, _ error) { trips = append(trips, tr) }))
+	start := time.Now()
+	for i, v := range []float64{106, 107, lmsensors.NoValue, 108, 108, lmsensors.NoValue, 108} {
+		g.Observe(context.Background(), reading(v), start.Add(time.Duration(i)*5*time.Second))
+	}
+	if len(trips) != 1 || !trips[0].Since.Equal(start) || trips[0].Value != 108 {
+		t.Errorf("a failed read restarted the hold: %+v", trips)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	if _, err := Command("nothing"); err == nil {
+		t.Error("no error for no command")
+	}
+	a, err := Command("false", "false")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Run(context.Background(), Trip{}); err == nil {
+		t.Error("no error for failing command")
+	}
+}
+
+func TestFullSpeed(t *testing.T) {
+	lmsensors.SysfsRoot = "../testdata/sysfs"
+	defer func() { lmsensors.SysfsRoot = "/sys" }()
+	lmsensors.SetDryRun(true)
+	defer lmsensors.SetDryRun(false)
+	var evs []lmsensors.WriteEvent
+	lmsensors.SetAuditFunc(func(ev lmsensors.WriteEvent) { evs = append(evs, ev) })
+	defer lmsensors.SetAuditFunc(nil)
+
+	if err := FullSpeed().Run(context.Background(), Trip{}); err != nil {
+		t.Fatal(err)
+	}
+	// it87 has pwm1 and pwm2; each is put in manual mode, then to 255.
+	if len(evs) != 4 || evs[1].Attr != "pwm1" || evs[1].New != 255 || evs[3].Attr != "pwm2" {
+		t.Errorf("wrong writes: %+v", evs)
+	}
+}
@@ -0,0 +1,225 @@
+package lmsensors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Sampler reads a few sensors over and over, much faster than [Get] can, for characterising transients, eg how quickly a CPU heats up when a load starts.
+// It finds their input attributes under [SysfsRoot] once, and keeps them open, so that each reading is a single pread, which is a few microseconds a sensor; intervals of a few milliseconds are fine.
+// If a sensor's chip is unplugged, its readings are [NoValue] until it comes back under the same hwmon device.
+// Many drivers only update their readings every so often, eg once a second, so a fast interval only helps for the ones that read the hardware every time, like k10temp and coretemp.
+// A Sampler isn't safe to use from more than one goroutine at once.
+type Sampler struct {
+	sensors []sampledSensor
+	buf     [32]byte
+}
+
+type sampledSensor struct {
+	key   string
+	typ   LmSensorType
+	file  attrFile
+	scale float64
+}
+
+// NewSampler opens the input attributes of the sensors the selector matches, which are named as [GetSysfs] names them.
+// It fails if there are none; otherwise [Sampler.Close] must be called when done.
+func NewSampler(sel Selector) (*Sampler, error) {
+	sys, err := GetSysfs()
+	if err != nil {
+		return nil, err
+	}
+	s := &Sampler{}
+	for _, chip := range sys.Chips {
+		for name, sensor := range chip.Sensors {
+			b, ok := sensor.(interface{ base() *baseSensor })
+			if !ok || b.base().feature.dir == "" || !sel.Match(chip.ID, name) {
+				continue
+			}
+			ss, err := openSampled(sensorKey(chip.ID, name), b.base().feature)
+			if err != nil {
+				s.Close()
+				return nil, err
+			}
+			s.sensors = append(s.sensors, ss)
+		}
+	}
+	if len(s.sensors) == 0 {
+		return nil, fmt.Errorf("no sensors match %q", sel)
+	}
+	sort.Slice(s.sensors, func(i, j int) bool { return s.sensors[i].key < s.sensors[j].key })
+	return s, nil
+}
+
+func openSampled(key string, f featureRef) (sampledSensor, error) {
+	kind := strings.TrimRight(f.name, "0123456789")
+	inputs, ok := sysfsInputs[kind]
+	if !ok {
+		inputs = []string{"input"}
+	}
+	var err error
+	for _, in := range inputs {
+		path := filepath.Join(f.dir, f.name+"_"+in)
+		var file *os.File
+		if file, err = os.Open(path); err == nil {
+			return sampledSensor{key: key, typ: sysfsFeatureTypes[kind], file: attrFile{path, file}, scale: sysfsScale(kind, in)}, nil
+		}
+	}
+	return sampledSensor{}, err
+}
+
+// Sensors are the chip/sensor keys of the sensors sampled, in the order [Sampler.Read] reads them.
+func (s *Sampler) Sensors() []string {
+	keys := make([]string, len(s.sensors))
+	for i, ss := range s.sensors {
+		keys[i] = ss.key
+	}
+	return keys
+}
+
+// Read reads every sensor once into vals, which must be as long as [Sampler.Sensors]; sensors that can't be read are [NoValue], and the first error is returned.
+func (s *Sampler) Read(vals []float64) error {
+	var first error
+	for i := range s.sensors {
+		v, err := s.sensors[i].read(s.buf[:])
+		if err != nil && first == nil {
+			first = fmt.Errorf("%s: %w", s.sensors[i].key, err)
+		}
+		vals[i] = v
+	}
+	return first
+}
+
+func (ss *sampledSensor) read(buf []byte) (float64, error) {
+	p, err := ss.file.read(buf)
+	if err != nil {
+		return NoValue, err
+	}
+	v, ok := parseAttr(p)
+	if !ok {
+		return NoValue, fmt.Errorf("bad reading %q", p)
+	}
+	return sanitize(ss.typ, float64(v)/ss.scale), nil
+}
+
+// parseAttr parses an integer attribute without allocating, which strconv would for the conversion to a string.
+func parseAttr(p []byte) (int64, bool) {
+	for len(p) > 0 && (p[len(p)-1] == '\n' || p[len(p)-1] == ' ') {
+		p = p[:len(p)-1]
+	}
+	neg := len(p) > 0 && p[0] == '-'
+	if neg {
+		p = p[1:]
+	}
+	if len(p) == 0 {
+		return 0, false
+	}
+	var v int64
+	for _, c := range p {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		v = v*10 + int64(c-'0')
+	}
+	if neg {
+		v = -v
+	}
+	return v, true
+}
+
+// Close closes the attributes.
+func (s *Sampler) Close() error {
+	var errs []error
+	for i := range s.sensors {
+		errs = append(errs, s.sensors[i].file.close())
+	}
+	return errors.Join(errs...)
+}
+
+// SamplerStats is what a [Sampler.Run] cost, to tell how much it disturbed what it was measuring.
+type SamplerStats struct {
+	Samples int           // Taken
+	Missed  int           // Skipped, because a sample, or fn, overran its interval
+	Elapsed time.Duration // Of the whole run
+	Read    time.Duration // Spent in total reading the sensors
+	MaxRead time.Duration // Of one sample
+	MaxLate time.Duration // How late a sample was, at worst, after when it was due
+	CPU     time.Duration // The process' user and system time over the run, which includes anything else it was doing
+}
+
+// MeanRead is the average time a sample took to read.
+func (st SamplerStats) MeanRead() time.Duration {
+	if st.Samples == 0 {
+		return 0
+	}
+	return st.Read / time.Duration(st.Samples)
+}
+
+// Overhead is the process' CPU time as a fraction of one CPU, over the run.
+func (st SamplerStats) Overhead() float64 {
+	if st.Elapsed <= 0 {
+		return 0
+	}
+	return st.CPU.Seconds() / st.Elapsed.Seconds()
+}
+
+func (st SamplerStats) String() string {
+	return fmt.Sprintf("%d samples, %d missed, in %v: %v per sample (max %v), up to %v late, %.1f%% of a CPU",
+		st.Samples, st.Missed, st.Elapsed.Round(time.Millisecond), st.MeanRead(), st.MaxRead, st.MaxLate, 100*st.Overhead())
+}
+
+// Run samples every interval until ctx is done, calling fn with each sample; vals is only valid during the call.
+// Samples are kept to the original schedule, like a [time.Ticker]'s, skipping any that are missed. ctx being done isn't an error.
+func (s *Sampler) Run(ctx context.Context, interval time.Duration, fn func(at time.Time, vals []float64)) (SamplerStats, error) {
+	var st SamplerStats
+	if interval <= 0 {
+		return st, errors.New("interval must be positive")
+	}
+	vals := make([]float64, len(s.sensors))
+	cpu0 := cpuTime()
+	start := time.Now()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	due := start
+	for {
+		select {
+		case <-ctx.Done():
+			st.Elapsed = time.Since(start)
+			st.CPU = cpuTime() - cpu0
+			return st, nil
+		case <-timer.C:
+		}
+		at := time.Now()
+		_ = s.Read(vals) // Failures are NoValue, for fn to see
+		took := time.Since(at)
+		st.Samples++
+		st.Read += took
+		st.MaxRead = max(st.MaxRead, took)
+		st.MaxLate = max(st.MaxLate, at.Sub(due))
+		fn(at, vals)
+
+		due = due.Add(interval)
+		if now := time.Now(); due.Before(now) {
+			missed := now.Sub(due)/interval + 1
+			st.Missed += int(missed)
+			due = due.Add(missed * interval)
+		}
+		timer.Reset(time.Until(due))
+	}
+}
+
+// cpuTime is the process' user and system time so far.
+func cpuTime() time.Duration {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return 0
+	}
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano())
+}
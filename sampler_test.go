@@ -0,0 +1,86 @@
+package lmsensors
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSampler(t *testing.T) {
+	root := t.TempDir()
+	defer func() { SysfsRoot = "/sys" }()
+	SysfsRoot = root
+	dir := filepath.Join(root, "class", "hwmon", "hwmon0")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(attr, v string) {
+		if err := os.WriteFile(filepath.Join(dir, attr), []byte(v+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("name", "k10temp")
+	write("temp1_input", "41250")
+	write("temp1_label", "Tctl")
+	write("temp2_input", "38000")
+	write("temp2_label", "Tccd1")
+	write("fan1_input", "1200")
+
+	if _, err := NewSampler("*/nonesuch"); err == nil {
+		t.Error("sampled nothing")
+	}
+	s, err := NewSampler("k10temp-*/T*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	keys := s.Sensors()
+	if len(keys) != 2 || keys[0] != "k10temp-virtual-0/Tccd1" || keys[1] != "k10temp-virtual-0/Tctl" {
+		t.Fatalf("sensors: %v", keys)
+	}
+	vals := make([]float64, 2)
+	if err := s.Read(vals); err != nil || vals[0] != 38 || vals[1] != 41.25 {
+		t.Fatalf("read %v: %v", vals, err)
+	}
+	// The files stay open, so changes are seen without reopening them, and shorter contents aren't mixed with longer.
+	write("temp1_input", "-5")
+	write("temp2_input", "junk")
+	if err := s.Read(vals); err == nil || !math.IsNaN(vals[0]) || vals[1] != -0.005 {
+		t.Fatalf("reread %v: %v", vals, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var n int
+	st, err := s.Run(ctx, time.Millisecond, func(at time.Time, vals []float64) {
+		n++
+		if vals[1] != -0.005 {
+			t.Errorf("sample %d: %v", n, vals)
+		}
+	})
+	if err != nil || st.Samples != n || n < 5 || st.Elapsed < 40*time.Millisecond || st.MeanRead() <= 0 || st.MaxRead < st.MeanRead() {
+		t.Errorf("%d samples, %v: %v", n, st, err)
+	}
+}
+
+func TestParseAttr(t *testing.T) {
+	for _, c := range []struct {
+		in string
+		v  int64
+		ok bool
+	}{
+		{"41250\n", 41250, true},
+		{"-12", -12, true},
+		{"0", 0, true},
+		{"", 0, false},
+		{"-\n", 0, false},
+		{"4x", 0, false},
+	} {
+		if v, ok := parseAttr([]byte(c.in)); v != c.v || ok != c.ok {
+			t.Errorf("%q: %v %v", c.in, v, ok)
+		}
+	}
+}
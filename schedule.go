@@ -0,0 +1,133 @@
+package lmsensors
+
+import (
+	"context"
+	"math/rand/v2"
+	"path"
+	"time"
+)
+
+type chipInterval struct {
+	sel      Selector
+	interval time.Duration
+}
+
+// WithChipInterval polls the chips the selector matches every interval, rather than the [Watcher]'s own; only the chip part of the selector is used, so write eg "k10temp-*/*".
+// Fast-changing chips, like CPU temperatures, can be polled more often than the rest, and slow ones, like batteries, less.
+// The first matching selector wins.
+// In between their polls, a chip's last reading is carried over into each [System], so the handlers always see every chip; [TimeOf] tells how old a sensor's reading is.
+func WithChipInterval(sel Selector, interval time.Duration) WatcherOption {
+	return func(w *Watcher) {
+		w.chipIntervals = append(w.chipIntervals, chipInterval{sel, interval})
+	}
+}
+
+// matchChip is whether the chip part of the selector matches.
+func (s Selector) matchChip(chip string) bool {
+	chipGlob, _ := s.split()
+	ok, _ := path.Match(chipGlob, chip)
+	return ok
+}
+
+// chipInterval is how often the chip should be polled.
+func (w *Watcher) chipInterval(chip string) time.Duration {
+	for _, ci := range w.chipIntervals {
+		if ci.sel.matchChip(chip) {
+			return ci.interval
+		}
+	}
+	return w.interval
+}
+
+// Tick is how often the Watcher needs polling, which is the shortest interval of any chip; [Watcher.Run] polls this often.
+func (w *Watcher) Tick() time.Duration {
+	tick := w.interval
+	for _, ci := range w.chipIntervals {
+		tick = min(tick, ci.interval)
+	}
+	return tick
+}
+
+// get reads the chips that are due, and carries over the last reading of the rest.
+func (w *Watcher) get(ctx context.Context) (*System, error) {
+	var opts getOptions
+	for _, opt := range w.getOpts {
+		opt(&opts)
+	}
+	if len(w.chipIntervals) == 0 {
+		return w.lib.get(ctx, opts)
+	}
+	now := time.Now()
+	// Ticks are never quite on time, so round to the nearest one.
+	slack := w.Tick()/2 + w.jitter
+	present := map[string]bool{}
+	due := func(chip string) bool {
+		present[chip] = true
+		next, ok := w.next[chip]
+		return !ok || !now.Add(slack).Before(next)
+	}
+	opts.only = due
+	sys, err := w.lib.get(ctx, opts)
+	w.carry(sys, present, now)
+	return sys, err
+}
+
+// carry fills in the chips that are present but weren't due from their last reading, and schedules the ones that were read.
+func (w *Watcher) carry(sys *System, present map[string]bool, now time.Time) {
+	if w.next == nil {
+		w.next = map[string]time.Time{}
+		w.last = map[string]*Chip{}
+	}
+	for id, chip := range sys.Chips {
+		w.next[id] = now.Add(w.chipInterval(id))
+		w.last[id] = chip.Clone() // The handlers might change the one in sys
+	}
+	for id, chip := range w.last {
+		switch _, read := sys.Chips[id]; {
+		case !present[id]: // Gone, eg unplugged
+			delete(w.last, id)
+			delete(w.next, id)
+		case !read:
+			sys.Chips[id] = chip.Clone()
+		}
+	}
+}
+
+// WithAlignment polls on multiples of the interval on the wall clock, eg on the minute for a minute's interval, so that graphs from different hosts line up.
+// Intervals that don't divide a day evenly align to multiples since the zero [time.Time].
+func WithAlignment() WatcherOption {
+	return func(w *Watcher) {
+		w.align = true
+	}
+}
+
+// WithJitter delays every poll by a random amount up to max, so that a fleet of hosts started at the same time doesn't poll, and send, in lockstep.
+// It can be combined with [WithAlignment], to poll shortly after each boundary.
+func WithJitter(max time.Duration) WatcherOption {
+	return func(w *Watcher) {
+		w.jitter = max
+	}
+}
+
+// Next is when the poll after the one at now should happen; [Watcher.Run] sleeps until then.
+// It's for callers running their own loop around [Watcher.Poll], and must be called once per poll.
+func (w *Watcher) Next(now time.Time) time.Time {
+	tick := w.Tick()
+	switch {
+	case w.align:
+		w.base = now.Truncate(tick).Add(tick)
+	case w.base.IsZero():
+		w.base = now.Add(tick)
+	default:
+		// Like a [time.Ticker], keep to the original schedule rather than drifting by however long each poll took, but skip any that have been missed.
+		w.base = w.base.Add(tick)
+		if w.base.Before(now) {
+			w.base = now.Add(tick).Add(-now.Sub(w.base) % tick)
+		}
+	}
+	at := w.base
+	if w.jitter > 0 {
+		at = at.Add(rand.N(w.jitter))
+	}
+	return at
+}
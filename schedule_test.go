@@ -0,0 +1,74 @@
+package lmsensors
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChipInterval(t *testing.T) {
+	w := NewWatcher(10*time.Second, WithChipInterval("k10temp-*/*", time.Second), WithChipInterval("BAT*/*", time.Minute))
+	if w.Tick() != time.Second {
+		t.Errorf("wrong tick: %v", w.Tick())
+	}
+	if w.chipInterval("k10temp-pci-00c3") != time.Second || w.chipInterval("BAT0-acpi-0") != time.Minute || w.chipInterval("nct6775-isa-0290") != 10*time.Second {
+		t.Error("wrong chip intervals")
+	}
+
+	now := time.Now()
+	sys := tempSystem(40)
+	sys.Chips["BAT0-acpi-0"] = &Chip{ID: "BAT0-acpi-0", Sensors: map[string]Sensor{"in0": &VoltageSensor{baseSensor{Name: "in0", Value: 12.5}}}}
+	present := map[string]bool{"k10temp-pci-00c3": true, "BAT0-acpi-0": true}
+	w.carry(sys, present, now)
+	if !w.next["k10temp-pci-00c3"].Equal(now.Add(time.Second)) || !w.next["BAT0-acpi-0"].Equal(now.Add(time.Minute)) {
+		t.Errorf("wrong schedule: %v", w.next)
+	}
+
+	// Only the CPU was due; the battery's last reading is carried over, and is a copy.
+	sys = tempSystem(41)
+	w.carry(sys, present, now.Add(time.Second))
+	bat, ok := sys.Chips["BAT0-acpi-0"]
+	if !ok || bat.Sensors["in0"].Reading() != 12.5 {
+		t.Fatalf("battery not carried over: %v", sys.Chips)
+	}
+	bat.Sensors["in0"].(*VoltageSensor).Value = 0
+	if w.last["BAT0-acpi-0"].Sensors["in0"].Reading() != 12.5 {
+		t.Error("carried chip isn't a copy")
+	}
+
+	// The battery's gone.
+	sys = tempSystem(42)
+	w.carry(sys, map[string]bool{"k10temp-pci-00c3": true}, now.Add(2*time.Second))
+	if _, ok := sys.Chips["BAT0-acpi-0"]; ok || len(w.last) != 1 {
+		t.Error("removed chip still carried over")
+	}
+}
+
+func TestNext(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 3, 0, time.UTC)
+
+	w := NewWatcher(10 * time.Second)
+	if got := w.Next(start); !got.Equal(start.Add(10 * time.Second)) {
+		t.Errorf("first poll: %v", got)
+	}
+	// A slow poll doesn't push the schedule back.
+	if got := w.Next(start.Add(12 * time.Second)); !got.Equal(start.Add(20 * time.Second)) {
+		t.Errorf("after slow poll: %v", got)
+	}
+	// A very slow one skips the missed polls.
+	if got := w.Next(start.Add(45 * time.Second)); !got.Equal(start.Add(50 * time.Second)) {
+		t.Errorf("after missed polls: %v", got)
+	}
+
+	w = NewWatcher(10*time.Second, WithAlignment())
+	if got := w.Next(start); !got.Equal(time.Date(2024, 5, 1, 12, 0, 10, 0, time.UTC)) {
+		t.Errorf("aligned: %v", got)
+	}
+
+	w = NewWatcher(10*time.Second, WithAlignment(), WithJitter(time.Second))
+	for range 20 {
+		got := w.Next(start)
+		if base := time.Date(2024, 5, 1, 12, 0, 10, 0, time.UTC); got.Before(base) || !got.Before(base.Add(time.Second)) {
+			t.Fatalf("jittered: %v", got)
+		}
+	}
+}
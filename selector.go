@@ -0,0 +1,34 @@
+package lmsensors
+
+import (
+	"path"
+	"strings"
+)
+
+// Selector picks out sensors by glob, as "chip/sensor", eg "k10temp-*/T*", or just "sensor" for that sensor on any chip.
+// The globs are those of [path.Match], matched against chip IDs and sensor labels.
+type Selector string
+
+// Valid checks the globs are well-formed.
+func (s Selector) Valid() bool {
+	chip, sensor := s.split()
+	_, err1 := path.Match(chip, "")
+	_, err2 := path.Match(sensor, "")
+	return err1 == nil && err2 == nil
+}
+
+// Match is whether the sensor on the chip is selected.
+func (s Selector) Match(chip, sensor string) bool {
+	chipGlob, sensorGlob := s.split()
+	ok1, _ := path.Match(chipGlob, chip)
+	ok2, _ := path.Match(sensorGlob, sensor)
+	return ok1 && ok2
+}
+
+func (s Selector) split() (chip, sensor string) {
+	chip, sensor, found := strings.Cut(string(s), "/")
+	if !found {
+		return "*", chip
+	}
+	return chip, sensor
+}
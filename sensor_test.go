@@ -0,0 +1,87 @@
+package lmsensors
+
+import (
+	"math"
+	"testing"
+
+	sf "github.com/mt-inside/go-lmsensors/subfeature"
+)
+
+func TestNewSensor(t *testing.T) {
+	extras := map[sf.SubFeature]float64{sf.TEMP_TYPE: float64(ThermalDiode), sf.TEMP_CRIT: 100, sf.INTRUSION_BEEP: 1}
+	get := func(sub sf.SubFeature) (float64, error) {
+		v, ok := extras[sub]
+		if !ok {
+			return 0, sub
+		}
+		return v, nil
+	}
+
+	for _, typ := range []LmSensorType{Temperature, Voltage, Fan, Current, Intrusion} {
+		s := newSensor("", typ, 0, baseSensor{Name: "x", Value: 1}, get)
+		if s == nil {
+			t.Errorf("no sensor for %s", typ)
+			continue
+		}
+		if s.Type() != typ {
+			t.Errorf("wrong type for %s: %s", typ, s.Type())
+		}
+	}
+
+	if ts := newSensor("", Temperature, sf.TEMP_INPUT, baseSensor{}, get).(*TempSensor); ts.TempType != ThermalDiode || ts.Crit != 100 || ts.Emergency != 0 {
+		t.Errorf("wrong temp sensor: %+v", ts)
+	}
+	for typ, sub := range map[LmSensorType]sf.SubFeature{Temperature: sf.TEMP_ALARM, Voltage: sf.IN_ALARM, Fan: sf.FAN_ALARM, Current: sf.CURR_ALARM, Power: sf.POWER_ALARM} {
+		if newSensor("", typ, 0, baseSensor{Value: 1}, get).Alarm() {
+			t.Errorf("%s in alarm without an alarm flag", typ)
+		}
+		extras[sub] = 1
+		if !newSensor("", typ, 0, baseSensor{Value: 1}, get).Alarm() {
+			t.Errorf("%s not in alarm", typ)
+		}
+		delete(extras, sub)
+	}
+	is := newSensor("", Intrusion, sf.INTRUSION_ALARM, baseSensor{Value: 1}, get).(*IntrusionSensor)
+	if !is.Alarm() || !is.Beep {
+		t.Errorf("wrong intrusion sensor: %+v", is)
+	}
+	gs, ok := newSensor("", Power, sf.POWER_INPUT, baseSensor{Name: "PPT", Value: 45.5}, get).(*GenericSensor)
+	if !ok || gs.Type() != Unhandled || gs.FeatureType != Power || gs.String() != "PPT: 45.5W" {
+		t.Errorf("wrong generic sensor: %+v", gs)
+	}
+	delete(extras, sf.TEMP_TYPE)
+	if ts := newSensor("", Temperature, sf.TEMP_INPUT, baseSensor{}, get).(*TempSensor); ts.TempType != Unknown {
+		t.Errorf("wrong temp type when unreadable: %s", ts.TempType)
+	}
+}
+
+func TestParseTempType(t *testing.T) {
+	RegisterTempTypes("quirky", map[int]LmTempType{2: ThermalDiode})
+	defer delete(quirks, "quirky")
+
+	cases := []struct {
+		prefix string
+		value  float64
+		typ    LmTempType
+		raw    int
+	}{
+		{"k10temp", 3, ThermalDiode, 3},
+		{"k10temp", 9, LmTempType(9), 9},
+		{"k10temp", -1, Unknown, -1},
+		{"k10temp", 2.5, Unknown, -1},
+		{"k10temp", math.NaN(), Unknown, -1},
+		{"quirky", 2, ThermalDiode, 2},
+		{"quirky", 4, Thermistor, 4},
+	}
+	for _, c := range cases {
+		typ, raw := parseTempType(c.prefix, c.value)
+		if typ != c.typ || raw != c.raw {
+			t.Errorf("parseTempType(%s, %v) = %s, %d; want %s, %d", c.prefix, c.value, typ, raw, c.typ, c.raw)
+		}
+	}
+
+	s := &TempSensor{baseSensor: baseSensor{Name: "temp1", Value: 30}, TempType: LmTempType(9), TempTypeRaw: 9}
+	if str := s.String(); str != "temp1: 30.0°C (type 9)" {
+		t.Errorf("wrong rendering: %s", str)
+	}
+}
@@ -0,0 +1,111 @@
+package sensorsconf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Expr is an arithmetic expression from a compute or set statement.
+type Expr interface {
+	// Eval works out the expression, with raw as the value of @ and lookup giving the values of other features.
+	Eval(raw float64, lookup func(name string) (float64, bool)) (float64, error)
+	// String formats the expression so it parses back to the same thing, parenthesising every operation.
+	String() string
+}
+
+// Number is a constant.
+type Number float64
+
+// Raw is @, the value being read or written.
+type Raw struct{}
+
+// Var is another feature's value, by name.
+type Var string
+
+// Unary is an operation on one value: - (negation), ^ (e to the power of) or ` (natural logarithm).
+type Unary struct {
+	Op byte
+	X  Expr
+}
+
+// Binary is an arithmetic operation: +, -, * or /.
+type Binary struct {
+	Op   byte
+	X, Y Expr
+}
+
+func (n Number) Eval(float64, func(string) (float64, bool)) (float64, error) {
+	return float64(n), nil
+}
+
+func (n Number) String() string {
+	return strconv.FormatFloat(float64(n), 'f', -1, 64)
+}
+
+func (Raw) Eval(raw float64, _ func(string) (float64, bool)) (float64, error) {
+	return raw, nil
+}
+
+func (Raw) String() string {
+	return "@"
+}
+
+func (v Var) Eval(_ float64, lookup func(string) (float64, bool)) (float64, error) {
+	if lookup != nil {
+		if val, ok := lookup(string(v)); ok {
+			return val, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown feature %q", string(v))
+}
+
+func (v Var) String() string {
+	return string(v)
+}
+
+func (u Unary) Eval(raw float64, lookup func(string) (float64, bool)) (float64, error) {
+	x, err := u.X.Eval(raw, lookup)
+	if err != nil {
+		return 0, err
+	}
+	switch u.Op {
+	case '-':
+		return -x, nil
+	case '^':
+		return math.Exp(x), nil
+	case '`':
+		return math.Log(x), nil
+	}
+	return 0, fmt.Errorf("unknown operator %c", u.Op)
+}
+
+func (u Unary) String() string {
+	return "(" + string(u.Op) + u.X.String() + ")"
+}
+
+func (b Binary) Eval(raw float64, lookup func(string) (float64, bool)) (float64, error) {
+	x, err := b.X.Eval(raw, lookup)
+	if err != nil {
+		return 0, err
+	}
+	y, err := b.Y.Eval(raw, lookup)
+	if err != nil {
+		return 0, err
+	}
+	switch b.Op {
+	case '+':
+		return x + y, nil
+	case '-':
+		return x - y, nil
+	case '*':
+		return x * y, nil
+	case '/':
+		return x / y, nil
+	}
+	return 0, fmt.Errorf("unknown operator %c", b.Op)
+}
+
+func (b Binary) String() string {
+	return "(" + b.X.String() + " " + string(b.Op) + " " + b.Y.String() + ")"
+}
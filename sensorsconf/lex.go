@@ -0,0 +1,153 @@
+package sensorsconf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokEOL
+	tokName   // Bare word, eg a keyword or a feature name
+	tokString // Quoted string, unescaped
+	tokNumber
+	tokOp // One of + - * / ( ) , @ ^ `
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	line int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of file"
+	case tokEOL:
+		return "end of line"
+	case tokString:
+		return strconv.Quote(t.text)
+	default:
+		return t.text
+	}
+}
+
+// SyntaxError is a problem with the config file, at a line.
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+type lexer struct {
+	src  string
+	pos  int
+	line int
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// next reads one token. Like libsensors', the grammar is line-based, but a backslash continues a line.
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			l.pos++
+		case c == '\\' && strings.HasPrefix(l.src[l.pos+1:], "\n"):
+			l.pos += 2
+			l.line++
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		case c == '\n':
+			l.pos++
+			l.line++
+			return token{kind: tokEOL, line: l.line - 1}, nil
+		case c == '"':
+			return l.quoted()
+		case isNameStart(c):
+			start := l.pos
+			for l.pos < len(l.src) && isNameChar(l.src[l.pos]) {
+				l.pos++
+			}
+			return token{kind: tokName, text: l.src[start:l.pos], line: l.line}, nil
+		case isDigit(c) || c == '.':
+			return l.number()
+		case strings.IndexByte("+-*/(),@^`", c) >= 0:
+			l.pos++
+			return token{kind: tokOp, text: string(c), line: l.line}, nil
+		default:
+			return token{}, &SyntaxError{l.line, fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+	return token{kind: tokEOF, line: l.line}, nil
+}
+
+func (l *lexer) number() (token, error) {
+	start := l.pos
+	for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+		l.pos++
+	}
+	text := l.src[start:l.pos]
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return token{}, &SyntaxError{l.line, fmt.Sprintf("bad number %q", text)}
+	}
+	return token{kind: tokNumber, text: text, num: v, line: l.line}, nil
+}
+
+// quoted reads a double-quoted string, which can't span lines, and in which \", \\, \n and \t are escapes.
+func (l *lexer) quoted() (token, error) {
+	line := l.line
+	l.pos++ // Opening quote
+	var ret strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		l.pos++
+		switch c {
+		case '"':
+			return token{kind: tokString, text: ret.String(), line: line}, nil
+		case '\n':
+			return token{}, &SyntaxError{line, "unterminated string"}
+		case '\\':
+			if l.pos == len(l.src) {
+				return token{}, &SyntaxError{line, "unterminated string"}
+			}
+			e := l.src[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				ret.WriteByte('\n')
+			case 't':
+				ret.WriteByte('\t')
+			case '"', '\\':
+				ret.WriteByte(e)
+			default:
+				return token{}, &SyntaxError{line, fmt.Sprintf("unknown escape \\%c", e)}
+			}
+		default:
+			ret.WriteByte(c)
+		}
+	}
+	return token{}, &SyntaxError{line, "unterminated string"}
+}
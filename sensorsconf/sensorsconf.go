@@ -0,0 +1,317 @@
+// Package sensorsconf parses libsensors' config file, sensors3.conf, in pure Go, as documented in sensors.conf(5).
+// It's meant for inspecting and linting configs; libsensors still reads its own.
+package sensorsconf
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mt-inside/go-lmsensors/chipname"
+)
+
+// Config is a whole config file.
+type Config struct {
+	Buses []Bus
+	Chips []Chip
+}
+
+// Bus is a bus statement, which maps a bus number in the file to an adapter by name, so the file works whatever numbers the kernel gives out.
+type Bus struct {
+	Name    string // eg "i2c-0"
+	Adapter string // eg "SMBus I801 adapter at 0400"
+	Line    int
+}
+
+// Chip is a chip statement, and the statements up to the next one, which apply to the chips it names.
+type Chip struct {
+	Names    []chipname.Name
+	Labels   []Label
+	Computes []Compute
+	Sets     []Set
+	Ignores  []Ignore
+	Line     int
+}
+
+// Label renames a feature.
+type Label struct {
+	Feature string
+	Text    string
+	Line    int
+}
+
+// Compute scales a feature: From is applied to values read from the chip, To to values written to it.
+type Compute struct {
+	Feature string
+	From    Expr
+	To      Expr
+	Line    int
+}
+
+// Set writes a feature, eg a limit, when `sensors -s` is run.
+type Set struct {
+	Feature string
+	Value   Expr
+	Line    int
+}
+
+// Ignore hides a feature.
+type Ignore struct {
+	Feature string
+	Line    int
+}
+
+// Load reads and parses a config file.
+func Load(path string) (*Config, error) {
+	p, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := Parse(string(p))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Parse parses the text of a config file. Errors are [*SyntaxError]s.
+func Parse(src string) (*Config, error) {
+	p := &parser{lex: lexer{src: src, line: 1}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	c := &Config{}
+	for p.tok.kind != tokEOF {
+		if err := p.statement(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+type parser struct {
+	lex lexer
+	tok token
+}
+
+func (p *parser) advance() error {
+	t, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = t
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &SyntaxError{p.tok.line, fmt.Sprintf(format, args...)}
+}
+
+// word reads a name or a string; the file format treats them the same.
+func (p *parser) word(what string) (string, error) {
+	if p.tok.kind != tokName && p.tok.kind != tokString {
+		return "", p.errorf("expected %s, got %s", what, p.tok)
+	}
+	s := p.tok.text
+	return s, p.advance()
+}
+
+func (p *parser) endOfLine() error {
+	switch p.tok.kind {
+	case tokEOL:
+		return p.advance()
+	case tokEOF:
+		return nil
+	}
+	return p.errorf("expected end of line, got %s", p.tok)
+}
+
+func (p *parser) statement(c *Config) error {
+	if p.tok.kind == tokEOL {
+		return p.advance()
+	}
+	if p.tok.kind != tokName {
+		return p.errorf("expected a statement, got %s", p.tok)
+	}
+	line := p.tok.line
+	keyword := p.tok.text
+	if err := p.advance(); err != nil {
+		return err
+	}
+
+	if keyword == "bus" {
+		b := Bus{Line: line}
+		var err error
+		if b.Name, err = p.word("bus name"); err != nil {
+			return err
+		}
+		if b.Adapter, err = p.word("adapter name"); err != nil {
+			return err
+		}
+		c.Buses = append(c.Buses, b)
+		return p.endOfLine()
+	}
+	if keyword == "chip" {
+		ch := Chip{Line: line}
+		for p.tok.kind == tokName || p.tok.kind == tokString {
+			n, err := chipname.Parse(p.tok.text)
+			if err != nil {
+				return p.errorf("%v", err)
+			}
+			ch.Names = append(ch.Names, n)
+			if err := p.advance(); err != nil {
+				return err
+			}
+		}
+		if len(ch.Names) == 0 {
+			return p.errorf("chip statement with no chip names")
+		}
+		c.Chips = append(c.Chips, ch)
+		return p.endOfLine()
+	}
+
+	if len(c.Chips) == 0 {
+		return &SyntaxError{line, keyword + " statement before any chip statement"}
+	}
+	ch := &c.Chips[len(c.Chips)-1]
+	feature, err := p.word("feature name")
+	if err != nil {
+		return err
+	}
+	switch keyword {
+	case "label":
+		text, err := p.word("label")
+		if err != nil {
+			return err
+		}
+		ch.Labels = append(ch.Labels, Label{feature, text, line})
+	case "compute":
+		from, err := p.expr()
+		if err != nil {
+			return err
+		}
+		if p.tok.kind != tokOp || p.tok.text != "," {
+			return p.errorf("expected , between compute expressions, got %s", p.tok)
+		}
+		if err := p.advance(); err != nil {
+			return err
+		}
+		to, err := p.expr()
+		if err != nil {
+			return err
+		}
+		ch.Computes = append(ch.Computes, Compute{feature, from, to, line})
+	case "set":
+		v, err := p.expr()
+		if err != nil {
+			return err
+		}
+		ch.Sets = append(ch.Sets, Set{feature, v, line})
+	case "ignore":
+		ch.Ignores = append(ch.Ignores, Ignore{feature, line})
+	default:
+		return &SyntaxError{line, fmt.Sprintf("unknown statement %q", keyword)}
+	}
+	return p.endOfLine()
+}
+
+// maxDepth bounds expression nesting, so hostile input can't blow the stack.
+const maxDepth = 100
+
+// expr parses a sum: terms separated by + and -.
+func (p *parser) expr() (Expr, error) {
+	return p.sum(0)
+}
+
+func (p *parser) isOp(ops string) (byte, bool) {
+	if p.tok.kind != tokOp {
+		return 0, false
+	}
+	for i := 0; i < len(ops); i++ {
+		if p.tok.text[0] == ops[i] {
+			return ops[i], true
+		}
+	}
+	return 0, false
+}
+
+func (p *parser) sum(depth int) (Expr, error) {
+	x, err := p.product(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.isOp("+-")
+		if !ok {
+			return x, nil
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		y, err := p.product(depth)
+		if err != nil {
+			return nil, err
+		}
+		x = Binary{op, x, y}
+	}
+}
+
+func (p *parser) product(depth int) (Expr, error) {
+	x, err := p.unary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.isOp("*/")
+		if !ok {
+			return x, nil
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		y, err := p.unary(depth)
+		if err != nil {
+			return nil, err
+		}
+		x = Binary{op, x, y}
+	}
+}
+
+func (p *parser) unary(depth int) (Expr, error) {
+	if depth > maxDepth {
+		return nil, p.errorf("expression nested too deeply")
+	}
+	switch p.tok.kind {
+	case tokNumber:
+		n := Number(p.tok.num)
+		return n, p.advance()
+	case tokName:
+		v := Var(p.tok.text)
+		return v, p.advance()
+	case tokOp:
+		op := p.tok.text[0]
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		switch op {
+		case '@':
+			return Raw{}, nil
+		case '-', '^', '`':
+			x, err := p.unary(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			return Unary{op, x}, nil
+		case '(':
+			x, err := p.sum(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if p.tok.kind != tokOp || p.tok.text != ")" {
+				return nil, p.errorf("expected ), got %s", p.tok)
+			}
+			return x, p.advance()
+		}
+		return nil, p.errorf("unexpected %c in expression", op)
+	}
+	return nil, p.errorf("expected an expression, got %s", p.tok)
+}
@@ -0,0 +1,114 @@
+package sensorsconf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mt-inside/go-lmsensors/bus"
+)
+
+const example = `# Example from sensors.conf(5)
+bus "i2c-0" "SMBus I801 adapter at 0400"
+
+chip "it87-isa-0290" "it8718-*"
+    label in0 "Vcore"   # CPU core
+    compute in3 ((6.8/10)+1)*@ , @/((6.8/10)+1)
+    set in0_min 1.2 * 0.95
+    ignore \
+        fan3
+
+chip "lm75-i2c-0-48"
+    label temp1 "Board \"ambient\""
+    compute temp1 -@ + in0*2, ` + "`" + `@
+`
+
+func TestParse(t *testing.T) {
+	c, err := Parse(example)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Buses) != 1 || c.Buses[0].Name != "i2c-0" || c.Buses[0].Adapter != "SMBus I801 adapter at 0400" || c.Buses[0].Line != 2 {
+		t.Errorf("wrong buses: %+v", c.Buses)
+	}
+	if len(c.Chips) != 2 {
+		t.Fatalf("wrong chips: %+v", c.Chips)
+	}
+	it := c.Chips[0]
+	if len(it.Names) != 2 || it.Names[0].Bus != bus.ISA || it.Names[0].Addr != 0x290 || it.Names[1].Prefix != "it8718" {
+		t.Errorf("wrong names: %+v", it.Names)
+	}
+	if len(it.Labels) != 1 || it.Labels[0] != (Label{"in0", "Vcore", 5}) {
+		t.Errorf("wrong labels: %+v", it.Labels)
+	}
+	if len(it.Ignores) != 1 || it.Ignores[0].Feature != "fan3" {
+		t.Errorf("wrong ignores: %+v", it.Ignores)
+	}
+	if got := it.Computes[0].From.String(); got != "(((6.8 / 10) + 1) * @)" {
+		t.Errorf("wrong compute: %s", got)
+	}
+	if v, err := it.Computes[0].From.Eval(1, nil); err != nil || v != 1.68 {
+		t.Errorf("wrong compute result: %v %v", v, err)
+	}
+	if v, _ := it.Sets[0].Value.Eval(0, nil); v != 1.2*0.95 {
+		t.Errorf("wrong set: %v", v)
+	}
+
+	lm := c.Chips[1]
+	if lm.Labels[0].Text != `Board "ambient"` || lm.Line != 11 {
+		t.Errorf("wrong label: %+v", lm.Labels)
+	}
+	from := lm.Computes[0].From
+	if _, err := from.Eval(1, nil); err == nil {
+		t.Error("no error for unknown feature")
+	}
+	if v, err := from.Eval(1, func(string) (float64, bool) { return 3, true }); err != nil || v != 5 {
+		t.Errorf("wrong compute with lookup: %v %v", v, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]int{
+		`label in0 "x"`:                   1,
+		"chip \"it87-*\"\n\nlabel in0":    3,
+		"chip \"it87-foo-1\"":             1,
+		"chip \"it87-*\"\ncompute in0 @":  2,
+		"chip \"it87-*\"\nset in0 (1":     2,
+		"chip \"it87-*\"\nlabel in0 \"x":  2,
+		"chip \"it87-*\"\nfrobnicate in0": 2,
+		"bus \"i2c-0\" $":                 1,
+	}
+	for src, line := range cases {
+		_, err := Parse(src)
+		var se *SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("%q: no syntax error: %v", src, err)
+			continue
+		}
+		if se.Line != line {
+			t.Errorf("%q: error on line %d, want %d: %v", src, se.Line, line, err)
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add(example)
+	f.Add("chip \"*\"\nset temp1_max ^(`@ - -3)/(2)\n")
+	f.Fuzz(func(t *testing.T, src string) {
+		c, err := Parse(src)
+		if err != nil {
+			return
+		}
+		// Expressions print back to something that parses to the same thing.
+		for _, ch := range c.Chips {
+			for _, s := range ch.Sets {
+				again, err := Parse("chip \"*\"\nset x " + s.Value.String())
+				if err != nil {
+					t.Fatalf("%s doesn't reparse: %v", s.Value, err)
+				}
+				if got := again.Chips[0].Sets[0].Value.String(); got != s.Value.String() {
+					t.Fatalf("%s reparses as %s", s.Value, got)
+				}
+			}
+		}
+	})
+}
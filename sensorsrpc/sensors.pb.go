@@ -0,0 +1,677 @@
+// The sensors API: the readings of a host's hardware monitoring chips, and a stream of them as they're polled.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: sensors.proto
+
+package sensorsrpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SensorType int32
+
+const (
+	SensorType_SENSOR_TYPE_UNSPECIFIED SensorType = 0
+	SensorType_SENSOR_TYPE_VOLTAGE     SensorType = 1
+	SensorType_SENSOR_TYPE_FAN         SensorType = 2
+	SensorType_SENSOR_TYPE_TEMPERATURE SensorType = 3
+	SensorType_SENSOR_TYPE_POWER       SensorType = 4
+	SensorType_SENSOR_TYPE_ENERGY      SensorType = 5
+	SensorType_SENSOR_TYPE_CURRENT     SensorType = 6
+	SensorType_SENSOR_TYPE_HUMIDITY    SensorType = 7
+	SensorType_SENSOR_TYPE_VID         SensorType = 8
+	SensorType_SENSOR_TYPE_INTRUSION   SensorType = 9
+	SensorType_SENSOR_TYPE_BEEP_ENABLE SensorType = 10
+)
+
+// Enum value maps for SensorType.
+var (
+	SensorType_name = map[int32]string{
+		0:  "SENSOR_TYPE_UNSPECIFIED",
+		1:  "SENSOR_TYPE_VOLTAGE",
+		2:  "SENSOR_TYPE_FAN",
+		3:  "SENSOR_TYPE_TEMPERATURE",
+		4:  "SENSOR_TYPE_POWER",
+		5:  "SENSOR_TYPE_ENERGY",
+		6:  "SENSOR_TYPE_CURRENT",
+		7:  "SENSOR_TYPE_HUMIDITY",
+		8:  "SENSOR_TYPE_VID",
+		9:  "SENSOR_TYPE_INTRUSION",
+		10: "SENSOR_TYPE_BEEP_ENABLE",
+	}
+	SensorType_value = map[string]int32{
+		"SENSOR_TYPE_UNSPECIFIED": 0,
+		"SENSOR_TYPE_VOLTAGE":     1,
+		"SENSOR_TYPE_FAN":         2,
+		"SENSOR_TYPE_TEMPERATURE": 3,
+		"SENSOR_TYPE_POWER":       4,
+		"SENSOR_TYPE_ENERGY":      5,
+		"SENSOR_TYPE_CURRENT":     6,
+		"SENSOR_TYPE_HUMIDITY":    7,
+		"SENSOR_TYPE_VID":         8,
+		"SENSOR_TYPE_INTRUSION":   9,
+		"SENSOR_TYPE_BEEP_ENABLE": 10,
+	}
+)
+
+func (x SensorType) Enum() *SensorType {
+	p := new(SensorType)
+	*p = x
+	return p
+}
+
+func (x SensorType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SensorType) Descriptor() protoreflect.EnumDescriptor {
+	return file_sensors_proto_enumTypes[0].Descriptor()
+}
+
+func (SensorType) Type() protoreflect.EnumType {
+	return &file_sensors_proto_enumTypes[0]
+}
+
+func (x SensorType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SensorType.Descriptor instead.
+func (SensorType) EnumDescriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{0}
+}
+
+type GetRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// eg "k10temp-*/Tctl", matching chip ID and sensor name; none matches everything.
+	Selectors     []string `protobuf:"bytes,1,rep,name=selectors,proto3" json:"selectors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_sensors_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetRequest) GetSelectors() []string {
+	if x != nil {
+		return x.Selectors
+	}
+	return nil
+}
+
+type SubscribeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// As for GetRequest.
+	Selectors     []string `protobuf:"bytes,1,rep,name=selectors,proto3" json:"selectors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_sensors_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SubscribeRequest) GetSelectors() []string {
+	if x != nil {
+		return x.Selectors
+	}
+	return nil
+}
+
+type Snapshot struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Time  *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	// Numbers the readings, from 1.
+	Seq uint64 `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	// Only the chips with a matching sensor.
+	Chips []*Chip `protobuf:"bytes,3,rep,name=chips,proto3" json:"chips,omitempty"`
+	// Ordered by chip, then sensor.
+	Readings []*Reading `protobuf:"bytes,4,rep,name=readings,proto3" json:"readings,omitempty"`
+	// The alarms that were raised or cleared by this reading, and on a stream, by any skipped since the last one sent.
+	Alarms        []*AlarmEvent `protobuf:"bytes,5,rep,name=alarms,proto3" json:"alarms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Snapshot) Reset() {
+	*x = Snapshot{}
+	mi := &file_sensors_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Snapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snapshot) ProtoMessage() {}
+
+func (x *Snapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snapshot.ProtoReflect.Descriptor instead.
+func (*Snapshot) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Snapshot) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Snapshot) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Snapshot) GetChips() []*Chip {
+	if x != nil {
+		return x.Chips
+	}
+	return nil
+}
+
+func (x *Snapshot) GetReadings() []*Reading {
+	if x != nil {
+		return x.Readings
+	}
+	return nil
+}
+
+func (x *Snapshot) GetAlarms() []*AlarmEvent {
+	if x != nil {
+		return x.Alarms
+	}
+	return nil
+}
+
+type Chip struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// eg "k10temp-pci-00c3"
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// eg "k10temp"
+	Type          string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Bus           string `protobuf:"bytes,3,opt,name=bus,proto3" json:"bus,omitempty"`
+	Address       string `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	Adapter       string `protobuf:"bytes,5,opt,name=adapter,proto3" json:"adapter,omitempty"`
+	Path          string `protobuf:"bytes,6,opt,name=path,proto3" json:"path,omitempty"`
+	DisplayName   string `protobuf:"bytes,7,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Chip) Reset() {
+	*x = Chip{}
+	mi := &file_sensors_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Chip) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Chip) ProtoMessage() {}
+
+func (x *Chip) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Chip.ProtoReflect.Descriptor instead.
+func (*Chip) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Chip) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Chip) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Chip) GetBus() string {
+	if x != nil {
+		return x.Bus
+	}
+	return ""
+}
+
+func (x *Chip) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Chip) GetAdapter() string {
+	if x != nil {
+		return x.Adapter
+	}
+	return ""
+}
+
+func (x *Chip) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Chip) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+type Reading struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Chip   string                 `protobuf:"bytes,1,opt,name=chip,proto3" json:"chip,omitempty"`
+	Sensor string                 `protobuf:"bytes,2,opt,name=sensor,proto3" json:"sensor,omitempty"`
+	Type   SensorType             `protobuf:"varint,3,opt,name=type,proto3,enum=lmsensors.v1.SensorType" json:"type,omitempty"`
+	// Only meaningful if valid.
+	Value float64 `protobuf:"fixed64,4,opt,name=value,proto3" json:"value,omitempty"`
+	Valid bool    `protobuf:"varint,5,opt,name=valid,proto3" json:"valid,omitempty"`
+	// eg "42.2"
+	Rendered string `protobuf:"bytes,6,opt,name=rendered,proto3" json:"rendered,omitempty"`
+	// eg "°C"
+	Unit          string                 `protobuf:"bytes,7,opt,name=unit,proto3" json:"unit,omitempty"`
+	Alarm         bool                   `protobuf:"varint,8,opt,name=alarm,proto3" json:"alarm,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reading) Reset() {
+	*x = Reading{}
+	mi := &file_sensors_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reading) ProtoMessage() {}
+
+func (x *Reading) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reading.ProtoReflect.Descriptor instead.
+func (*Reading) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Reading) GetChip() string {
+	if x != nil {
+		return x.Chip
+	}
+	return ""
+}
+
+func (x *Reading) GetSensor() string {
+	if x != nil {
+		return x.Sensor
+	}
+	return ""
+}
+
+func (x *Reading) GetType() SensorType {
+	if x != nil {
+		return x.Type
+	}
+	return SensorType_SENSOR_TYPE_UNSPECIFIED
+}
+
+func (x *Reading) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Reading) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *Reading) GetRendered() string {
+	if x != nil {
+		return x.Rendered
+	}
+	return ""
+}
+
+func (x *Reading) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *Reading) GetAlarm() bool {
+	if x != nil {
+		return x.Alarm
+	}
+	return false
+}
+
+func (x *Reading) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+type AlarmEvent struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Chip   string                 `protobuf:"bytes,1,opt,name=chip,proto3" json:"chip,omitempty"`
+	Sensor string                 `protobuf:"bytes,2,opt,name=sensor,proto3" json:"sensor,omitempty"`
+	// The threshold that raised it, or empty for the hardware's own alarm flag.
+	Rule  string  `protobuf:"bytes,3,opt,name=rule,proto3" json:"rule,omitempty"`
+	Value float64 `protobuf:"fixed64,4,opt,name=value,proto3" json:"value,omitempty"`
+	// False when the alarm clears.
+	Raised        bool `protobuf:"varint,5,opt,name=raised,proto3" json:"raised,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AlarmEvent) Reset() {
+	*x = AlarmEvent{}
+	mi := &file_sensors_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlarmEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlarmEvent) ProtoMessage() {}
+
+func (x *AlarmEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlarmEvent.ProtoReflect.Descriptor instead.
+func (*AlarmEvent) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AlarmEvent) GetChip() string {
+	if x != nil {
+		return x.Chip
+	}
+	return ""
+}
+
+func (x *AlarmEvent) GetSensor() string {
+	if x != nil {
+		return x.Sensor
+	}
+	return ""
+}
+
+func (x *AlarmEvent) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *AlarmEvent) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *AlarmEvent) GetRaised() bool {
+	if x != nil {
+		return x.Raised
+	}
+	return false
+}
+
+var File_sensors_proto protoreflect.FileDescriptor
+
+const file_sensors_proto_rawDesc = "" +
+	"\n" +
+	"\rsensors.proto\x12\flmsensors.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"*\n" +
+	"\n" +
+	"GetRequest\x12\x1c\n" +
+	"\tselectors\x18\x01 \x03(\tR\tselectors\"0\n" +
+	"\x10SubscribeRequest\x12\x1c\n" +
+	"\tselectors\x18\x01 \x03(\tR\tselectors\"\xdb\x01\n" +
+	"\bSnapshot\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x04R\x03seq\x12(\n" +
+	"\x05chips\x18\x03 \x03(\v2\x12.lmsensors.v1.ChipR\x05chips\x121\n" +
+	"\breadings\x18\x04 \x03(\v2\x15.lmsensors.v1.ReadingR\breadings\x120\n" +
+	"\x06alarms\x18\x05 \x03(\v2\x18.lmsensors.v1.AlarmEventR\x06alarms\"\xa7\x01\n" +
+	"\x04Chip\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x10\n" +
+	"\x03bus\x18\x03 \x01(\tR\x03bus\x12\x18\n" +
+	"\aaddress\x18\x04 \x01(\tR\aaddress\x12\x18\n" +
+	"\aadapter\x18\x05 \x01(\tR\aadapter\x12\x12\n" +
+	"\x04path\x18\x06 \x01(\tR\x04path\x12!\n" +
+	"\fdisplay_name\x18\a \x01(\tR\vdisplayName\"\x85\x02\n" +
+	"\aReading\x12\x12\n" +
+	"\x04chip\x18\x01 \x01(\tR\x04chip\x12\x16\n" +
+	"\x06sensor\x18\x02 \x01(\tR\x06sensor\x12,\n" +
+	"\x04type\x18\x03 \x01(\x0e2\x18.lmsensors.v1.SensorTypeR\x04type\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\x01R\x05value\x12\x14\n" +
+	"\x05valid\x18\x05 \x01(\bR\x05valid\x12\x1a\n" +
+	"\brendered\x18\x06 \x01(\tR\brendered\x12\x12\n" +
+	"\x04unit\x18\a \x01(\tR\x04unit\x12\x14\n" +
+	"\x05alarm\x18\b \x01(\bR\x05alarm\x12.\n" +
+	"\x04time\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x04time\"z\n" +
+	"\n" +
+	"AlarmEvent\x12\x12\n" +
+	"\x04chip\x18\x01 \x01(\tR\x04chip\x12\x16\n" +
+	"\x06sensor\x18\x02 \x01(\tR\x06sensor\x12\x12\n" +
+	"\x04rule\x18\x03 \x01(\tR\x04rule\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\x01R\x05value\x12\x16\n" +
+	"\x06raised\x18\x05 \x01(\bR\x06raised*\xa3\x02\n" +
+	"\n" +
+	"SensorType\x12\x1b\n" +
+	"\x17SENSOR_TYPE_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13SENSOR_TYPE_VOLTAGE\x10\x01\x12\x13\n" +
+	"\x0fSENSOR_TYPE_FAN\x10\x02\x12\x1b\n" +
+	"\x17SENSOR_TYPE_TEMPERATURE\x10\x03\x12\x15\n" +
+	"\x11SENSOR_TYPE_POWER\x10\x04\x12\x16\n" +
+	"\x12SENSOR_TYPE_ENERGY\x10\x05\x12\x17\n" +
+	"\x13SENSOR_TYPE_CURRENT\x10\x06\x12\x18\n" +
+	"\x14SENSOR_TYPE_HUMIDITY\x10\a\x12\x13\n" +
+	"\x0fSENSOR_TYPE_VID\x10\b\x12\x19\n" +
+	"\x15SENSOR_TYPE_INTRUSION\x10\t\x12\x1b\n" +
+	"\x17SENSOR_TYPE_BEEP_ENABLE\x10\n" +
+	"2\x89\x01\n" +
+	"\aSensors\x127\n" +
+	"\x03Get\x12\x18.lmsensors.v1.GetRequest\x1a\x16.lmsensors.v1.Snapshot\x12E\n" +
+	"\tSubscribe\x12\x1e.lmsensors.v1.SubscribeRequest\x1a\x16.lmsensors.v1.Snapshot0\x01B.Z,github.com/mt-inside/go-lmsensors/sensorsrpcb\x06proto3"
+
+var (
+	file_sensors_proto_rawDescOnce sync.Once
+	file_sensors_proto_rawDescData []byte
+)
+
+func file_sensors_proto_rawDescGZIP() []byte {
+	file_sensors_proto_rawDescOnce.Do(func() {
+		file_sensors_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_sensors_proto_rawDesc), len(file_sensors_proto_rawDesc)))
+	})
+	return file_sensors_proto_rawDescData
+}
+
+var file_sensors_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_sensors_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_sensors_proto_goTypes = []any{
+	(SensorType)(0),               // 0: lmsensors.v1.SensorType
+	(*GetRequest)(nil),            // 1: lmsensors.v1.GetRequest
+	(*SubscribeRequest)(nil),      // 2: lmsensors.v1.SubscribeRequest
+	(*Snapshot)(nil),              // 3: lmsensors.v1.Snapshot
+	(*Chip)(nil),                  // 4: lmsensors.v1.Chip
+	(*Reading)(nil),               // 5: lmsensors.v1.Reading
+	(*AlarmEvent)(nil),            // 6: lmsensors.v1.AlarmEvent
+	(*timestamppb.Timestamp)(nil), // 7: google.protobuf.Timestamp
+}
+var file_sensors_proto_depIdxs = []int32{
+	7, // 0: lmsensors.v1.Snapshot.time:type_name -> google.protobuf.Timestamp
+	4, // 1: lmsensors.v1.Snapshot.chips:type_name -> lmsensors.v1.Chip
+	5, // 2: lmsensors.v1.Snapshot.readings:type_name -> lmsensors.v1.Reading
+	6, // 3: lmsensors.v1.Snapshot.alarms:type_name -> lmsensors.v1.AlarmEvent
+	0, // 4: lmsensors.v1.Reading.type:type_name -> lmsensors.v1.SensorType
+	7, // 5: lmsensors.v1.Reading.time:type_name -> google.protobuf.Timestamp
+	1, // 6: lmsensors.v1.Sensors.Get:input_type -> lmsensors.v1.GetRequest
+	2, // 7: lmsensors.v1.Sensors.Subscribe:input_type -> lmsensors.v1.SubscribeRequest
+	3, // 8: lmsensors.v1.Sensors.Get:output_type -> lmsensors.v1.Snapshot
+	3, // 9: lmsensors.v1.Sensors.Subscribe:output_type -> lmsensors.v1.Snapshot
+	8, // [8:10] is the sub-list for method output_type
+	6, // [6:8] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_sensors_proto_init() }
+func file_sensors_proto_init() {
+	if File_sensors_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sensors_proto_rawDesc), len(file_sensors_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_sensors_proto_goTypes,
+		DependencyIndexes: file_sensors_proto_depIdxs,
+		EnumInfos:         file_sensors_proto_enumTypes,
+		MessageInfos:      file_sensors_proto_msgTypes,
+	}.Build()
+	File_sensors_proto = out.File
+	file_sensors_proto_goTypes = nil
+	file_sensors_proto_depIdxs = nil
+}
@@ -0,0 +1,171 @@
+// The sensors API: the readings of a host's hardware monitoring chips, and a stream of them as they're polled.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: sensors.proto
+
+package sensorsrpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Sensors_Get_FullMethodName       = "/lmsensors.v1.Sensors/Get"
+	Sensors_Subscribe_FullMethodName = "/lmsensors.v1.Sensors/Subscribe"
+)
+
+// SensorsClient is the client API for Sensors service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SensorsClient interface {
+	// Get returns the latest reading of the sensors matching the selectors.
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Snapshot, error)
+	// Subscribe streams the latest reading of the sensors matching the selectors, and then every later one, until the client goes away.
+	// A client that can't keep up gets the latest reading, rather than every one; seq has a gap when that happens, but no alarms are lost, as they come with the next reading sent.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Snapshot], error)
+}
+
+type sensorsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSensorsClient(cc grpc.ClientConnInterface) SensorsClient {
+	return &sensorsClient{cc}
+}
+
+func (c *sensorsClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Snapshot, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Snapshot)
+	err := c.cc.Invoke(ctx, Sensors_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorsClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Snapshot], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Sensors_ServiceDesc.Streams[0], Sensors_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, Snapshot]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Sensors_SubscribeClient = grpc.ServerStreamingClient[Snapshot]
+
+// SensorsServer is the server API for Sensors service.
+// All implementations must embed UnimplementedSensorsServer
+// for forward compatibility.
+type SensorsServer interface {
+	// Get returns the latest reading of the sensors matching the selectors.
+	Get(context.Context, *GetRequest) (*Snapshot, error)
+	// Subscribe streams the latest reading of the sensors matching the selectors, and then every later one, until the client goes away.
+	// A client that can't keep up gets the latest reading, rather than every one; seq has a gap when that happens, but no alarms are lost, as they come with the next reading sent.
+	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Snapshot]) error
+	mustEmbedUnimplementedSensorsServer()
+}
+
+// UnimplementedSensorsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSensorsServer struct{}
+
+func (UnimplementedSensorsServer) Get(context.Context, *GetRequest) (*Snapshot, error) {
+	return nil, status.Error(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedSensorsServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Snapshot]) error {
+	return status.Error(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedSensorsServer) mustEmbedUnimplementedSensorsServer() {}
+func (UnimplementedSensorsServer) testEmbeddedByValue()                 {}
+
+// UnsafeSensorsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SensorsServer will
+// result in compilation errors.
+type UnsafeSensorsServer interface {
+	mustEmbedUnimplementedSensorsServer()
+}
+
+func RegisterSensorsServer(s grpc.ServiceRegistrar, srv SensorsServer) {
+	// If the following call panics, it indicates UnimplementedSensorsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Sensors_ServiceDesc, srv)
+}
+
+func _Sensors_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorsServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sensors_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorsServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sensors_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SensorsServer).Subscribe(m, &grpc.GenericServerStream[SubscribeRequest, Snapshot]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Sensors_SubscribeServer = grpc.ServerStreamingServer[Snapshot]
+
+// Sensors_ServiceDesc is the grpc.ServiceDesc for Sensors service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Sensors_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "lmsensors.v1.Sensors",
+	HandlerType: (*SensorsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _Sensors_Get_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Sensors_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sensors.proto",
+}
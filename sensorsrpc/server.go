@@ -0,0 +1,229 @@
+// Package sensorsrpc is a gRPC API for readings, so that consumers in other languages can integrate over a stable contract; sensors.proto is the contract, and the rest of the package is generated from it, apart from [Server].
+//
+// Wire it up to a [lmsensors.Watcher] with eg
+//
+//	s := sensorsrpc.NewServer()
+//	w := lmsensors.NewWatcher(time.Second, s.Watch(), lmsensors.WithAlarmDetection(s.Alarm))
+//	sensorsrpc.RegisterSensorsServer(grpcServer, s)
+//
+// It's a module of its own, so that users of the rest don't depend on gRPC.
+package sensorsrpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative sensors.proto
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Server serves the readings it's given, see [Server.Watch].
+type Server struct {
+	UnimplementedSensorsServer
+
+	mu      sync.Mutex
+	latest  *lmsensors.Snapshot
+	seq     uint64
+	alarms  []seqAlarm             // The latest alarmHistory, oldest first, so that subscribers that skip readings still get them
+	pending []lmsensors.AlarmEvent // For the next reading
+	subs    map[chan struct{}]struct{}
+}
+
+// seqAlarm is an alarm event, and the reading it was sent with.
+type seqAlarm struct {
+	seq uint64
+	ev  lmsensors.AlarmEvent
+}
+
+// alarmHistory is how many alarm events are kept for subscribers that have fallen behind; one further behind than that loses the oldest.
+const alarmHistory = 1024
+
+// NewServer makes a [Server] with no readings yet.
+func NewServer() *Server {
+	return &Server{subs: map[chan struct{}]struct{}{}}
+}
+
+// Watch returns an option that publishes every poll of a [lmsensors.Watcher].
+func (s *Server) Watch() lmsensors.WatcherOption {
+	return lmsensors.OnPoll(func(sys *lmsensors.System, _ error) {
+		s.Publish(sys)
+	})
+}
+
+// Alarm records an alarm event, to be sent with the next reading; pass it to [lmsensors.WithAlarmDetection], whose events come before the poll's.
+func (s *Server) Alarm(ev lmsensors.AlarmEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, ev)
+}
+
+// Publish makes a reading the latest, and sends it to the subscribers.
+func (s *Server) Publish(sys *lmsensors.System) {
+	snap := lmsensors.NewSnapshot(sys, sys.Time)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latest = snap
+	s.seq++
+	for _, ev := range s.pending {
+		s.alarms = append(s.alarms, seqAlarm{s.seq, ev})
+	}
+	s.pending = nil
+	if n := len(s.alarms) - alarmHistory; n > 0 {
+		s.alarms = append(s.alarms[:0], s.alarms[n:]...)
+	}
+	for sub := range s.subs {
+		select {
+		case sub <- struct{}{}:
+		default: // Already woken
+		}
+	}
+}
+
+// current is the latest reading, and the alarms sent with the readings after the one numbered after, or just with the latest if after is 0.
+func (s *Server) current(after uint64) (*lmsensors.Snapshot, uint64, []lmsensors.AlarmEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if after == 0 {
+		after = s.seq - 1
+	}
+	var alarms []lmsensors.AlarmEvent
+	for _, a := range s.alarms {
+		if a.seq > after {
+			alarms = append(alarms, a.ev)
+		}
+	}
+	return s.latest, s.seq, alarms
+}
+
+func (s *Server) Get(_ context.Context, req *GetRequest) (*Snapshot, error) {
+	sels, err := selectors(req.GetSelectors())
+	if err != nil {
+		return nil, err
+	}
+	snap, seq, alarms := s.current(0)
+	if snap == nil {
+		return nil, status.Error(codes.Unavailable, "no readings yet")
+	}
+	return convert(snap, seq, alarms, sels), nil
+}
+
+func (s *Server) Subscribe(req *SubscribeRequest, stream grpc.ServerStreamingServer[Snapshot]) error {
+	sels, err := selectors(req.GetSelectors())
+	if err != nil {
+		return err
+	}
+	wake := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.subs[wake] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, wake)
+		s.mu.Unlock()
+	}()
+
+	var sent uint64
+	for {
+		if snap, seq, alarms := s.current(sent); snap != nil && seq != sent {
+			if err := stream.Send(convert(snap, seq, alarms, sels)); err != nil {
+				return err
+			}
+			sent = seq
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-wake:
+		}
+	}
+}
+
+func selectors(ss []string) ([]lmsensors.Selector, error) {
+	sels := make([]lmsensors.Selector, len(ss))
+	for i, s := range ss {
+		sels[i] = lmsensors.Selector(s)
+		if !sels[i].Valid() {
+			return nil, status.Errorf(codes.InvalidArgument, "bad selector: %q", s)
+		}
+	}
+	return sels, nil
+}
+
+func match(sels []lmsensors.Selector, chip, sensor string) bool {
+	if len(sels) == 0 {
+		return true
+	}
+	for _, sel := range sels {
+		if sel.Match(chip, sensor) {
+			return true
+		}
+	}
+	return false
+}
+
+var sensorTypes = map[lmsensors.LmSensorType]SensorType{
+	lmsensors.Voltage:     SensorType_SENSOR_TYPE_VOLTAGE,
+	lmsensors.Fan:         SensorType_SENSOR_TYPE_FAN,
+	lmsensors.Temperature: SensorType_SENSOR_TYPE_TEMPERATURE,
+	lmsensors.Power:       SensorType_SENSOR_TYPE_POWER,
+	lmsensors.Energy:      SensorType_SENSOR_TYPE_ENERGY,
+	lmsensors.Current:     SensorType_SENSOR_TYPE_CURRENT,
+	lmsensors.Humidity:    SensorType_SENSOR_TYPE_HUMIDITY,
+	lmsensors.VID:         SensorType_SENSOR_TYPE_VID,
+	lmsensors.Intrusion:   SensorType_SENSOR_TYPE_INTRUSION,
+	lmsensors.BeepEnable:  SensorType_SENSOR_TYPE_BEEP_ENABLE,
+}
+
+func convert(snap *lmsensors.Snapshot, seq uint64, alarms []lmsensors.AlarmEvent, sels []lmsensors.Selector) *Snapshot {
+	ret := &Snapshot{Time: timestamppb.New(snap.Time()), Seq: seq}
+	chips := map[string]bool{}
+	for r := range snap.Readings {
+		if !match(sels, r.Chip, r.Sensor) {
+			continue
+		}
+		chips[r.Chip] = true
+		pr := &Reading{
+			Chip:     r.Chip,
+			Sensor:   r.Sensor,
+			Type:     sensorTypes[r.Type],
+			Valid:    r.Valid,
+			Rendered: r.Rendered,
+			Unit:     r.Unit,
+			Alarm:    r.Alarm,
+		}
+		if r.Valid {
+			pr.Value = r.Value
+		}
+		if !r.Time.IsZero() {
+			pr.Time = timestamppb.New(r.Time)
+		}
+		ret.Readings = append(ret.Readings, pr)
+	}
+	for c := range snap.Chips {
+		if !chips[c.ID] {
+			continue
+		}
+		ret.Chips = append(ret.Chips, &Chip{
+			Id:          c.ID,
+			Type:        c.Type,
+			Bus:         c.Bus,
+			Address:     c.Address,
+			Adapter:     c.Adapter,
+			Path:        c.Path,
+			DisplayName: c.DisplayName,
+		})
+	}
+	for _, ev := range alarms {
+		if !match(sels, ev.Chip, ev.Sensor) {
+			continue
+		}
+		ret.Alarms = append(ret.Alarms, &AlarmEvent{Chip: ev.Chip, Sensor: ev.Sensor, Rule: ev.Rule, Value: ev.Value, Raised: ev.Raised})
+	}
+	return ret
+}
@@ -0,0 +1,130 @@
+package sensorsrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+func system(tctl float64) *lmsensors.System {
+	s := &lmsensors.TempSensor{}
+	s.Name, s.Value = "Tctl", tctl
+	v := &lmsensors.VoltageSensor{}
+	v.Name, v.Value = "in0", 1.1
+	return &lmsensors.System{Time: time.Now(), Chips: map[string]*lmsensors.Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Type: "k10temp", Sensors: map[string]lmsensors.Sensor{"Tctl": s}},
+		"nct6798-isa-0290": {ID: "nct6798-isa-0290", Type: "nct6798", Sensors: map[string]lmsensors.Sensor{"in0": v}},
+	}}
+}
+
+func client(t *testing.T, s *Server) SensorsClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	RegisterSensorsServer(srv, s)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewSensorsClient(conn)
+}
+
+func TestGet(t *testing.T) {
+	s := NewServer()
+	c := client(t, s)
+	ctx := context.Background()
+	if _, err := c.Get(ctx, &GetRequest{}); status.Code(err) != codes.Unavailable {
+		t.Errorf("before the first poll: %v", err)
+	}
+
+	s.Publish(system(42))
+	snap, err := c.Get(ctx, &GetRequest{Selectors: []string{"k10temp-*/*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Chips) != 1 || snap.Chips[0].Type != "k10temp" || len(snap.Readings) != 1 {
+		t.Fatalf("wrong snapshot: %v", snap)
+	}
+	if r := snap.Readings[0]; r.Type != SensorType_SENSOR_TYPE_TEMPERATURE || r.Value != 42 || !r.Valid {
+		t.Errorf("wrong reading: %v", r)
+	}
+	if _, err := c.Get(ctx, &GetRequest{Selectors: []string{"k10temp-[/*"}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("bad selector: %v", err)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	s := NewServer()
+	c := client(t, s)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.Publish(system(42))
+	stream, err := c.Subscribe(ctx, &SubscribeRequest{Selectors: []string{"*/Tctl"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := stream.Recv()
+	if err != nil || snap.Seq != 1 || len(snap.Readings) != 1 {
+		t.Fatalf("first: %v %v", snap, err)
+	}
+
+	s.Alarm(lmsensors.AlarmEvent{Chip: "k10temp-pci-00c3", Sensor: "Tctl", Rule: "hot", Value: 95, Raised: true})
+	s.Alarm(lmsensors.AlarmEvent{Chip: "nct6798-isa-0290", Sensor: "in0", Raised: true})
+	s.Publish(system(95))
+	snap, err = stream.Recv()
+	if err != nil || snap.Seq != 2 || snap.Readings[0].Value != 95 {
+		t.Fatalf("second: %v %v", snap, err)
+	}
+	if len(snap.Alarms) != 1 || snap.Alarms[0].Rule != "hot" || !snap.Alarms[0].Raised {
+		t.Errorf("alarms: %v", snap.Alarms)
+	}
+}
+
+func TestSubscribeBehind(t *testing.T) {
+	s := NewServer()
+	c := client(t, s)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.Publish(system(42))
+	stream, err := c.Subscribe(ctx, &SubscribeRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	// However many of these the stream skips, the alarms all get through.
+	for i, v := range []float64{95, 96, 80} {
+		s.Alarm(lmsensors.AlarmEvent{Chip: "k10temp-pci-00c3", Sensor: "Tctl", Value: v, Raised: i != 2})
+		s.Publish(system(v))
+	}
+	var alarms []*AlarmEvent
+	for {
+		snap, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		alarms = append(alarms, snap.Alarms...)
+		if snap.Seq == 4 {
+			break
+		}
+	}
+	if len(alarms) != 3 || alarms[0].Value != 95 || alarms[1].Value != 96 || alarms[2].Raised {
+		t.Errorf("alarms: %v", alarms)
+	}
+}
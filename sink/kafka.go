@@ -0,0 +1,161 @@
+package sink
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Message is a Kafka record.
+type Message struct {
+	Key   []byte // The chip and sensor, eg k10temp-pci-00c3/Tctl, so that each sensor's readings stay in order on one partition
+	Value []byte
+}
+
+// Producer is what [Kafka] sends batches of records with; wrap a client library's producer, eg a kafka-go Writer, in one.
+type Producer interface {
+	Produce(ctx context.Context, topic string, msgs []Message) error
+}
+
+// Format is how [Kafka] encodes readings.
+type Format int
+
+const (
+	JSON Format = iota
+	Avro        // In the schema [AvroSchema], in Avro's binary encoding
+)
+
+// AvroSchema is the schema readings are encoded in with [Avro], to register with a schema registry.
+const AvroSchema = `{
+  "type": "record",
+  "name": "Reading",
+  "namespace": "lmsensors",
+  "fields": [
+    {"name": "host", "type": "string"},
+    {"name": "chip", "type": "string"},
+    {"name": "sensor", "type": "string"},
+    {"name": "type", "type": "string"},
+    {"name": "value", "type": ["null", "double"]},
+    {"name": "unit", "type": "string"},
+    {"name": "alarm", "type": "boolean"},
+    {"name": "time", "type": {"type": "long", "logicalType": "timestamp-micros"}}
+  ]
+}`
+
+// Kafka produces a record per reading, batching them up across polls.
+// A batch is produced once it has BatchSize records, or its oldest is FlushInterval old; call [Kafka.Flush] on the way out, so the last batch isn't lost.
+// If producing fails, the batch is kept, to be retried with the next poll's, up to MaxBuffered records, after which the oldest are dropped.
+type Kafka struct {
+	Producer Producer
+	Topic    string // Defaults to "sensors"
+	Host     string // Defaults to the hostname
+	Format   Format
+
+	// SchemaID, if it's not 0, prefixes each Avro record with the Confluent wire format's header, so that consumers can look the schema up in a registry.
+	SchemaID uint32
+
+	BatchSize     int           // Defaults to 500
+	FlushInterval time.Duration // Defaults to every poll
+	MaxBuffered   int           // Defaults to 100 batches' worth
+
+	mu     sync.Mutex
+	batch  []Message
+	oldest time.Time
+}
+
+func (k *Kafka) Send(ctx context.Context, sys *lmsensors.System) error {
+	snap := lmsensors.NewSnapshot(sys, sys.Time)
+	host := hostname(k.Host)
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if len(k.batch) == 0 {
+		k.oldest = time.Now()
+	}
+	for r := range snap.Readings {
+		v, err := k.encode(newReadingJSON(host, r, snap.Time()))
+		if err != nil {
+			return err
+		}
+		k.batch = append(k.batch, Message{Key: []byte(r.Chip + "/" + r.Sensor), Value: v})
+	}
+	if max := k.maxBuffered(); len(k.batch) > max {
+		k.batch = k.batch[len(k.batch)-max:]
+	}
+	if len(k.batch) < k.batchSize() && time.Since(k.oldest) < k.FlushInterval {
+		return nil
+	}
+	return k.flush(ctx)
+}
+
+// Flush produces whatever's batched up.
+func (k *Kafka) Flush(ctx context.Context) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.flush(ctx)
+}
+
+func (k *Kafka) flush(ctx context.Context) error {
+	topic := k.Topic
+	if topic == "" {
+		topic = "sensors"
+	}
+	for len(k.batch) != 0 {
+		n := min(len(k.batch), k.batchSize())
+		if err := k.Producer.Produce(ctx, topic, k.batch[:n]); err != nil {
+			return err
+		}
+		k.batch = k.batch[n:]
+	}
+	k.batch = nil
+	return nil
+}
+
+func (k *Kafka) batchSize() int {
+	if k.BatchSize <= 0 {
+		return 500
+	}
+	return k.BatchSize
+}
+
+func (k *Kafka) maxBuffered() int {
+	if k.MaxBuffered <= 0 {
+		return 100 * k.batchSize()
+	}
+	return k.MaxBuffered
+}
+
+func (k *Kafka) encode(r readingJSON) ([]byte, error) {
+	if k.Format == JSON {
+		return json.Marshal(r)
+	}
+	var buf []byte
+	if k.SchemaID != 0 {
+		buf = binary.BigEndian.AppendUint32(append(buf, 0), k.SchemaID)
+	}
+	for _, s := range []string{r.Host, r.Chip, r.Sensor, r.Type} {
+		buf = avroString(buf, s)
+	}
+	if r.Value == nil {
+		buf = binary.AppendVarint(buf, 0) // The union's null branch
+	} else {
+		buf = binary.AppendVarint(buf, 1)
+		buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(*r.Value))
+	}
+	buf = avroString(buf, r.Unit)
+	if r.Alarm {
+		buf = append(buf, 1)
+	} else {
+		buf = append(buf, 0)
+	}
+	return binary.AppendVarint(buf, r.Time.UnixMicro()), nil
+}
+
+// avroString appends a string in Avro's binary encoding: its length, zig-zag encoded like all Avro ints, which [binary.AppendVarint] is too, then its bytes.
+func avroString(buf []byte, s string) []byte {
+	return append(binary.AppendVarint(buf, int64(len(s))), s...)
+}
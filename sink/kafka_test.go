@@ -0,0 +1,101 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors/daemon"
+)
+
+var _ daemon.Sink = (*Kafka)(nil)
+
+type producer struct {
+	batches [][]Message
+	fail    bool
+}
+
+func (p *producer) Produce(_ context.Context, topic string, msgs []Message) error {
+	if p.fail {
+		return errors.New("broker down")
+	}
+	if topic != "thermals" {
+		return errors.New("wrong topic " + topic)
+	}
+	p.batches = append(p.batches, msgs)
+	return nil
+}
+
+func TestKafkaBatching(t *testing.T) {
+	ctx := context.Background()
+	p := &producer{}
+	k := &Kafka{Producer: p, Topic: "thermals", Host: "box", BatchSize: 3, FlushInterval: time.Hour}
+	if err := k.Send(ctx, system()); err != nil || len(p.batches) != 0 {
+		t.Fatalf("first poll: %v %d", err, len(p.batches))
+	}
+	p.fail = true
+	if err := k.Send(ctx, system()); err == nil {
+		t.Fatal("expected the producer's error")
+	}
+	p.fail = false
+	if err := k.Send(ctx, system()); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.batches) != 2 || len(p.batches[0]) != 3 || len(p.batches[1]) != 3 {
+		t.Fatalf("batches: %v", p.batches)
+	}
+	if string(p.batches[0][0].Key) != "k10temp-pci-00c3/Tctl" {
+		t.Errorf("key %q", p.batches[0][0].Key)
+	}
+	var r readingJSON
+	if err := json.Unmarshal(p.batches[0][0].Value, &r); err != nil || r.Host != "box" || *r.Value != 42.5 {
+		t.Errorf("value %s: %v", p.batches[0][0].Value, err)
+	}
+	if err := k.Flush(ctx); err != nil || len(p.batches) != 2 {
+		t.Errorf("empty flush: %v %d", err, len(p.batches))
+	}
+}
+
+func TestKafkaAvro(t *testing.T) {
+	p := &producer{}
+	k := &Kafka{Producer: p, Topic: "thermals", Host: "box", Format: Avro, SchemaID: 7}
+	if err := k.Send(context.Background(), system()); err != nil {
+		t.Fatal(err)
+	}
+	rec := bytes.NewReader(p.batches[0][0].Value)
+	var header [5]byte
+	_, _ = rec.Read(header[:])
+	if header != [5]byte{0, 0, 0, 0, 7} {
+		t.Errorf("header %v", header)
+	}
+	str := func() string {
+		n, _ := binary.ReadVarint(rec)
+		s := make([]byte, n)
+		_, _ = rec.Read(s)
+		return string(s)
+	}
+	if host, chip, sensor, typ := str(), str(), str(), str(); host != "box" || chip != "k10temp-pci-00c3" || sensor != "Tctl" || typ != "Temperature" {
+		t.Errorf("strings %q %q %q %q", host, chip, sensor, typ)
+	}
+	var bits uint64
+	branch, _ := binary.ReadVarint(rec)
+	_ = binary.Read(rec, binary.LittleEndian, &bits)
+	if branch != 1 || math.Float64frombits(bits) != 42.5 {
+		t.Errorf("value %d %v", branch, math.Float64frombits(bits))
+	}
+	if unit, alarm := str(), must(rec.ReadByte()); unit != "°C" || alarm != 0 {
+		t.Errorf("unit %q alarm %d", unit, alarm)
+	}
+	if us, _ := binary.ReadVarint(rec); us != time.Unix(1700000000, 0).UnixMicro() {
+		t.Errorf("time %d", us)
+	}
+}
+
+func must[T any](v T, _ error) T {
+	return v
+}
@@ -0,0 +1,65 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Publisher is the part of a NATS connection that [NATS] uses; a *nats.Conn, from github.com/nats-io/nats.go, is one.
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATS publishes each reading, as JSON, to a subject per sensor, eg sensors.myhost.k10temp-pci-00c3.Tctl, and alarm events to the sensor's subject with .alarm appended.
+// Characters that NATS doesn't allow in subject tokens, like dots and spaces, are replaced with underscores.
+type NATS struct {
+	Conn   Publisher
+	Prefix string // Defaults to "sensors"
+	Host   string // Defaults to the hostname
+}
+
+func (n *NATS) subject(chip, sensor string) string {
+	prefix := n.Prefix
+	if prefix == "" {
+		prefix = "sensors"
+	}
+	return prefix + "." + subjectToken(hostname(n.Host)) + "." + subjectToken(chip) + "." + subjectToken(sensor)
+}
+
+var subjectReplacer = strings.NewReplacer(".", "_", " ", "_", "\t", "_", "*", "_", ">", "_")
+
+func subjectToken(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return subjectReplacer.Replace(s)
+}
+
+func (n *NATS) Send(ctx context.Context, sys *lmsensors.System) error {
+	snap := lmsensors.NewSnapshot(sys, sys.Time)
+	host := hostname(n.Host)
+	for r := range snap.Readings {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p, err := json.Marshal(newReadingJSON(host, r, snap.Time()))
+		if err != nil {
+			return err
+		}
+		if err := n.Conn.Publish(n.subject(r.Chip, r.Sensor), p); err != nil {
+			return err // Probably the connection, so the rest would fail too
+		}
+	}
+	return nil
+}
+
+func (n *NATS) Notify(_ context.Context, ev lmsensors.AlarmEvent) error {
+	p, err := json.Marshal(newEventJSON(hostname(n.Host), ev))
+	if err != nil {
+		return err
+	}
+	return n.Conn.Publish(n.subject(ev.Chip, ev.Sensor)+".alarm", p)
+}
@@ -0,0 +1,62 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/daemon"
+	"github.com/mt-inside/go-lmsensors/notify"
+)
+
+var (
+	_ daemon.Sink     = (*NATS)(nil)
+	_ notify.Notifier = (*NATS)(nil)
+)
+
+func system() *lmsensors.System {
+	s := &lmsensors.TempSensor{}
+	s.Name, s.Value = "Tctl", 42.5
+	f := &lmsensors.FanSensor{}
+	f.Name, f.Value = "CPU Fan", lmsensors.NoValue
+	return &lmsensors.System{Time: time.Unix(1700000000, 0), Chips: map[string]*lmsensors.Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]lmsensors.Sensor{"Tctl": s}},
+		"nct6798-isa-0290": {ID: "nct6798-isa-0290", Sensors: map[string]lmsensors.Sensor{"CPU Fan": f}},
+	}}
+}
+
+type publisher map[string]readingJSON
+
+func (p publisher) Publish(subject string, data []byte) error {
+	var r readingJSON
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+	p[subject] = r
+	return nil
+}
+
+func TestNATS(t *testing.T) {
+	pub := publisher{}
+	n := &NATS{Conn: pub, Host: "box.example.com"}
+	if err := n.Send(context.Background(), system()); err != nil {
+		t.Fatal(err)
+	}
+	r, ok := pub["sensors.box_example_com.k10temp-pci-00c3.Tctl"]
+	if !ok || *r.Value != 42.5 || r.Type != "Temperature" || !r.Time.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Tctl: %+v in %v", r, pub)
+	}
+	if r, ok := pub["sensors.box_example_com.nct6798-isa-0290.CPU_Fan"]; !ok || r.Value != nil {
+		t.Errorf("invalid fan: %+v in %v", r, pub)
+	}
+
+	ev := lmsensors.AlarmEvent{Chip: "k10temp-pci-00c3", Sensor: "Tctl", Value: 95, Raised: true}
+	if err := n.Notify(context.Background(), ev); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := pub["sensors.box_example_com.k10temp-pci-00c3.Tctl.alarm"]; !ok {
+		t.Errorf("no alarm in %v", pub)
+	}
+}
@@ -0,0 +1,108 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/format"
+)
+
+// RemoteWrite pushes readings to a Prometheus remote write endpoint, eg Mimir's or VictoriaMetrics', so hosts that can't be scraped, like ones behind NAT, can still be graphed.
+// The series are those of [format.RemoteWrite], labelled with instance, the host.
+// If a push fails, it's kept, to be retried before the next poll's, up to MaxBuffered of them, after which the oldest are dropped; ones the endpoint rejects as bad, with a 4xx other than 429, are dropped straight away, since they'd never succeed.
+type RemoteWrite struct {
+	URL    string       // eg "https://mimir.example.com/api/v1/push"
+	Client *http.Client // Defaults to [http.DefaultClient]
+	Host   string       // Defaults to the hostname
+	Labels map[string]string
+	Header http.Header // Added to every request, eg for an Authorization or X-Scope-OrgID
+
+	MaxBuffered int // Defaults to 100 polls' worth
+
+	mu      sync.Mutex
+	pending [][]byte
+}
+
+func (rw *RemoteWrite) Send(ctx context.Context, sys *lmsensors.System) error {
+	labels := map[string]string{"instance": hostname(rw.Host)}
+	for n, v := range rw.Labels {
+		labels[n] = v
+	}
+	body := format.RemoteWrite(lmsensors.NewSnapshot(sys, sys.Time), labels)
+
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	rw.pending = append(rw.pending, body)
+	if max := rw.maxBuffered(); len(rw.pending) > max {
+		rw.pending = rw.pending[len(rw.pending)-max:]
+	}
+	for len(rw.pending) != 0 {
+		err := rw.push(ctx, rw.pending[0])
+		if err != nil && !isPermanent(err) {
+			return err
+		}
+		rw.pending = rw.pending[1:]
+		if err != nil {
+			return err
+		}
+	}
+	rw.pending = nil
+	return nil
+}
+
+// statusError is a push the endpoint refused.
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("remote write: %d %s: %s", e.code, http.StatusText(e.code), e.msg)
+}
+
+func isPermanent(err error) bool {
+	se, ok := err.(statusError)
+	return ok && se.code >= 400 && se.code < 500 && se.code != http.StatusTooManyRequests
+}
+
+func (rw *RemoteWrite) push(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rw.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, vs := range rw.Header {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	req.Header.Set("User-Agent", "go-lmsensors")
+
+	client := rw.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return statusError{resp.StatusCode, string(bytes.TrimSpace(msg))}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (rw *RemoteWrite) maxBuffered() int {
+	if rw.MaxBuffered <= 0 {
+		return 100
+	}
+	return rw.MaxBuffered
+}
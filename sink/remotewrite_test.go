@@ -0,0 +1,52 @@
+package sink
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mt-inside/go-lmsensors/daemon"
+)
+
+var _ daemon.Sink = (*RemoteWrite)(nil)
+
+func TestRemoteWrite(t *testing.T) {
+	var bodies [][]byte
+	statuses := []int{http.StatusServiceUnavailable, http.StatusNoContent, http.StatusNoContent, http.StatusBadRequest, http.StatusNoContent}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "snappy" || r.Header.Get("Content-Type") != "application/x-protobuf" || r.Header.Get("X-Scope-OrgID") != "edge" {
+			t.Errorf("wrong headers: %v", r.Header)
+		}
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, b)
+		status := statuses[0]
+		statuses = statuses[1:]
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	rw := &RemoteWrite{URL: srv.URL, Host: "box", Header: http.Header{"X-Scope-Orgid": {"edge"}}}
+	ctx := context.Background()
+	if err := rw.Send(ctx, system()); err == nil {
+		t.Error("no error from a 503")
+	}
+	// The failed push is retried first.
+	if err := rw.Send(ctx, system()); err != nil {
+		t.Error(err)
+	}
+	if len(bodies) != 3 || string(bodies[0]) != string(bodies[1]) || len(bodies[0]) == 0 {
+		t.Errorf("wrong pushes: %d", len(bodies))
+	}
+	// A 400 isn't retried.
+	if err := rw.Send(ctx, system()); err == nil {
+		t.Error("no error from a 400")
+	}
+	if err := rw.Send(ctx, system()); err != nil {
+		t.Error(err)
+	}
+	if len(bodies) != 5 {
+		t.Errorf("wrong pushes: %d", len(bodies))
+	}
+}
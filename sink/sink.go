@@ -0,0 +1,61 @@
+// Package sink has [github.com/mt-inside/go-lmsensors/daemon.Sink]s that send readings to message buses and metrics stores, for fleets that already run one.
+// None of them depend on a client library; the message bus ones are given a connection through a small interface that the usual clients satisfy.
+// The ones that send alarm events are also [github.com/mt-inside/go-lmsensors/notify.Notifier]s.
+package sink
+
+import (
+	"os"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// readingJSON is how a reading is sent.
+type readingJSON struct {
+	Host   string    `json:"host"`
+	Chip   string    `json:"chip"`
+	Sensor string    `json:"sensor"`
+	Type   string    `json:"type"`
+	Value  *float64  `json:"value"` // null unless valid
+	Unit   string    `json:"unit,omitempty"`
+	Alarm  bool      `json:"alarm,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+func newReadingJSON(host string, r lmsensors.Reading, at time.Time) readingJSON {
+	j := readingJSON{Host: host, Chip: r.Chip, Sensor: r.Sensor, Type: r.Type.String(), Unit: r.Unit, Alarm: r.Alarm, Time: r.Time}
+	if r.Valid {
+		j.Value = &r.Value
+	}
+	if j.Time.IsZero() {
+		j.Time = at
+	}
+	return j
+}
+
+// eventJSON is how an alarm event is sent, like notify's webhooks.
+type eventJSON struct {
+	Host   string  `json:"host"`
+	Chip   string  `json:"chip"`
+	Sensor string  `json:"sensor"`
+	Rule   string  `json:"rule,omitempty"`
+	Value  float64 `json:"value"`
+	State  string  `json:"state"` // raised or cleared
+}
+
+func newEventJSON(host string, ev lmsensors.AlarmEvent) eventJSON {
+	state := "cleared"
+	if ev.Raised {
+		state = "raised"
+	}
+	return eventJSON{host, ev.Chip, ev.Sensor, ev.Rule, ev.Value, state}
+}
+
+// hostname defaults host to the machine's name.
+func hostname(host string) string {
+	if host != "" {
+		return host
+	}
+	h, _ := os.Hostname()
+	return h
+}
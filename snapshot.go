@@ -0,0 +1,198 @@
+package lmsensors
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// Reading is one sensor's reading, copied out of a [System].
+type Reading struct {
+	Chip   string
+	Sensor string
+	Type   LmSensorType
+	// The libsensors feature type, which is Type but for [GenericSensor]s, whose Type is always Unhandled; see [FeatureTypeOf].
+	FeatureType LmSensorType
+	Value       float64 // [NoValue] unless Valid
+	Valid       bool
+	Rendered    string
+	Unit        string
+	Alarm       bool
+	Time        time.Time // When it was read, see [TimeOf]
+
+	// The sensor's limits, each [NoValue] if it doesn't have it or it wasn't read [WithLimitCheck], and how the reading compares with them.
+	Min, Max, Crit float64
+	Limit          LimitState
+}
+
+// ChipInfo is a chip's identity, copied out of a [System], without its sensors.
+type ChipInfo struct {
+	ID          string
+	Type        string
+	Bus         string
+	Address     string
+	Adapter     string
+	Path        string
+	BoardVendor string
+	BoardName   string
+	ACPIPath    string
+	DisplayName string
+}
+
+// Snapshot is an immutable copy of a reading, which, unlike a [System], is safe to share between goroutines, eg between a collector and HTTP handlers.
+// Everything it returns is a copy.
+type Snapshot struct {
+	time     time.Time
+	seq      uint64
+	duration time.Duration
+	chips    []ChipInfo
+	readings []Reading // Sorted by chip, then sensor
+	index    map[string]int
+}
+
+// NewSnapshot copies a reading taken at the given time.
+func NewSnapshot(sys *System, at time.Time) *Snapshot {
+	s := &Snapshot{time: at, duration: sys.Stats.Duration, index: map[string]int{}}
+	for _, chip := range sys.Chips {
+		s.chips = append(s.chips, ChipInfo{
+			ID:          chip.ID,
+			Type:        chip.Type,
+			Bus:         chip.Bus,
+			Address:     chip.Address,
+			Adapter:     chip.Adapter,
+			Path:        chip.Path,
+			BoardVendor: chip.BoardVendor,
+			BoardName:   chip.BoardName,
+			ACPIPath:    chip.ACPIPath,
+			DisplayName: chip.DisplayName(),
+		})
+		for name, sensor := range chip.Sensors {
+			if sensor == nil {
+				continue // Unreadable
+			}
+			r := Reading{
+				Chip:        chip.ID,
+				Sensor:      name,
+				Type:        sensor.Type(),
+				FeatureType: FeatureTypeOf(sensor),
+				Value:       sensor.Reading(),
+				Valid:       Valid(sensor),
+				Rendered:    sensor.Rendered(),
+				Unit:        sensor.Unit(),
+				Alarm:       sensor.Alarm(),
+				Time:        TimeOf(sensor),
+				Min:         NoValue,
+				Max:         NoValue,
+				Crit:        NoValue,
+			}
+			if l := LimitsOf(sensor); l != nil {
+				r.Min, r.Max, r.Crit, r.Limit = orNoValue(l.Min), orNoValue(l.Max), orNoValue(l.Crit), l.State
+			}
+			s.readings = append(s.readings, r)
+		}
+	}
+	sort.Slice(s.chips, func(i, j int) bool { return s.chips[i].ID < s.chips[j].ID })
+	sort.Slice(s.readings, func(i, j int) bool {
+		a, b := s.readings[i], s.readings[j]
+		if a.Chip != b.Chip {
+			return a.Chip < b.Chip
+		}
+		return a.Sensor < b.Sensor
+	})
+	for i, r := range s.readings {
+		s.index[sensorKey(r.Chip, r.Sensor)] = i
+	}
+	return s
+}
+
+func orNoValue(v *float64) float64 {
+	if v == nil {
+		return NoValue
+	}
+	return *v
+}
+
+// Time is when the reading was taken.
+func (s *Snapshot) Time() time.Time {
+	return s.time
+}
+
+// Seq numbers the snapshots a [Watcher] takes, from 1, so a gap means a consumer missed one. It's 0 for snapshots made with [NewSnapshot].
+func (s *Snapshot) Seq() uint64 {
+	return s.seq
+}
+
+// Duration is how long the reading took, which is how much of the interval between snapshots is jitter rather than change.
+func (s *Snapshot) Duration() time.Duration {
+	return s.duration
+}
+
+// Chips is an iterator for range over the chips, in ID order.
+func (s *Snapshot) Chips(yield func(ChipInfo) bool) {
+	for _, c := range s.chips {
+		if !yield(c) {
+			return
+		}
+	}
+}
+
+// Readings is an iterator for range over all the readings, ordered by chip then sensor.
+func (s *Snapshot) Readings(yield func(Reading) bool) {
+	for _, r := range s.readings {
+		if !yield(r) {
+			return
+		}
+	}
+}
+
+// Reading looks up one sensor's reading.
+func (s *Snapshot) Reading(chip, sensor string) (Reading, bool) {
+	i, ok := s.index[sensorKey(chip, sensor)]
+	if !ok {
+		return Reading{}, false
+	}
+	return s.readings[i], true
+}
+
+// Len is the number of readings.
+func (s *Snapshot) Len() int {
+	return len(s.readings)
+}
+
+// Changes lists the readings that differ from prev's, in value, validity or alarm, and the ones prev had that s doesn't, so that consumers can be sent deltas rather than whole snapshots.
+// A nil prev has no readings, so everything has changed.
+func (s *Snapshot) Changes(prev *Snapshot) (changed, removed []Reading) {
+	for _, r := range s.readings {
+		if prev == nil {
+			changed = append(changed, r)
+			continue
+		}
+		p, ok := prev.Reading(r.Chip, r.Sensor)
+		if !ok || p.Valid != r.Valid || p.Alarm != r.Alarm || p.Unit != r.Unit || (r.Valid && p.Value != r.Value) {
+			changed = append(changed, r)
+		}
+	}
+	if prev != nil {
+		for _, p := range prev.readings {
+			if _, ok := s.index[sensorKey(p.Chip, p.Sensor)]; !ok {
+				removed = append(removed, p)
+			}
+		}
+	}
+	return changed, removed
+}
+
+// MarshalJSON encodes a reading with [NoValue] as null.
+func (r Reading) MarshalJSON() ([]byte, error) {
+	return marshalSensor(&r)
+}
+
+// MarshalJSON encodes the snapshot as its time, sequence number, chips and readings.
+func (s *Snapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Time     time.Time
+		Seq      uint64
+		Chips    []ChipInfo
+		Readings []Reading
+	}{s.time, s.seq, s.chips, s.readings})
+}
@@ -0,0 +1,87 @@
+package lmsensors
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	sys := tempSystem(42.25)
+	at := time.Now()
+	snap := NewSnapshot(sys, at)
+
+	// Changing the System afterwards doesn't affect the snapshot.
+	sys.Chips["k10temp-pci-00c3"].Sensors["Tctl"].(*TempSensor).Value = 90
+	delete(sys.Chips, "k10temp-pci-00c3")
+
+	if !snap.Time().Equal(at) || snap.Len() != 2 {
+		t.Errorf("wrong snapshot: %v %d", snap.Time(), snap.Len())
+	}
+	r, ok := snap.Reading("k10temp-pci-00c3", "Tctl")
+	if !ok || r.Value != 42.25 || r.Type != Temperature || r.Rendered != "42.2" || r.Unit != "°C" {
+		t.Errorf("wrong reading: %+v", r)
+	}
+	var names []string
+	for r := range snap.Readings {
+		names = append(names, r.Sensor)
+	}
+	if len(names) != 2 || names[0] != "Tctl" || names[1] != "Vcore" {
+		t.Errorf("wrong order: %v", names)
+	}
+
+	// Readers on other goroutines are fine; run with -race to check.
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range snap.Chips {
+				_ = c.DisplayName
+			}
+			snap.Reading("k10temp-pci-00c3", "Vcore")
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSnapshotSeq(t *testing.T) {
+	w := NewWatcher(time.Second)
+	if w.Snapshot() != nil {
+		t.Fatal("snapshot before the first poll")
+	}
+	for i := range 3 {
+		sys := tempSystem(42)
+		sys.Stats.Duration = time.Duration(i+1) * time.Millisecond
+		w.record(sys)
+		snap := w.Snapshot()
+		if snap.Seq() != uint64(i+1) || snap.Duration() != sys.Stats.Duration {
+			t.Errorf("poll %d: seq %d duration %v", i, snap.Seq(), snap.Duration())
+		}
+	}
+	if NewSnapshot(tempSystem(42), time.Now()).Seq() != 0 {
+		t.Error("standalone snapshot has a sequence number")
+	}
+}
+
+func TestSnapshotChanges(t *testing.T) {
+	prev := NewSnapshot(tempSystem(42), time.Now())
+	changed, removed := prev.Changes(nil)
+	if len(changed) != 2 || removed != nil {
+		t.Errorf("from nothing: %v %v", changed, removed)
+	}
+
+	sys := tempSystem(43)
+	delete(sys.Chips["k10temp-pci-00c3"].Sensors, "Vcore")
+	cur := NewSnapshot(sys, time.Now())
+	changed, removed = cur.Changes(prev)
+	if len(changed) != 1 || changed[0].Sensor != "Tctl" || changed[0].Value != 43 {
+		t.Errorf("changed: %v", changed)
+	}
+	if len(removed) != 1 || removed[0].Sensor != "Vcore" {
+		t.Errorf("removed: %v", removed)
+	}
+	if changed, removed := cur.Changes(cur); changed != nil || removed != nil {
+		t.Errorf("unchanged: %v %v", changed, removed)
+	}
+}
@@ -0,0 +1,383 @@
+package snmp
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// AgentX PDU types, from RFC 2741.
+const (
+	pduOpen       = 1
+	pduClose      = 2
+	pduRegister   = 3
+	pduGet        = 5
+	pduGetNext    = 6
+	pduGetBulk    = 7
+	pduTestSet    = 8
+	pduCommitSet  = 9
+	pduUndoSet    = 10
+	pduCleanupSet = 11
+	pduResponse   = 18
+)
+
+const (
+	flagNonDefaultContext = 0x08
+	flagNetworkByteOrder  = 0x10
+
+	errNotWritable = 17
+)
+
+// Subagent serves readings to an AgentX master agent.
+type Subagent struct {
+	Addr     string                     // The master's socket: a path, or tcp:host:port; defaults to /var/agentx/master
+	Snapshot func() *lmsensors.Snapshot // The readings to serve, eg [lmsensors.Watcher.Snapshot]
+	Timeout  time.Duration              // How long the master should wait for answers; defaults to 5s
+}
+
+// Run connects to the master agent, registers LM-SENSORS-MIB and entPhySensorTable, and answers the master's requests until ctx is done or the connection fails.
+// The master's restarts aren't survived, so run it in a retry loop.
+func (s Subagent) Run(ctx context.Context) error {
+	network, addr := "unix", s.Addr
+	switch {
+	case addr == "":
+		addr = "/var/agentx/master"
+	case strings.HasPrefix(addr, "tcp:"):
+		network, addr = "tcp", strings.TrimPrefix(addr, "tcp:")
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	err = s.serve(conn)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// session is an AgentX session over a connection.
+type session struct {
+	r      *bufio.Reader
+	w      io.Writer
+	id     uint32
+	packet uint32
+}
+
+func (s Subagent) serve(conn io.ReadWriter) error {
+	sess := &session{r: bufio.NewReader(conn), w: conn}
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	secs := byte(min(timeout/time.Second, 255))
+
+	// Open
+	var p []byte
+	p = append(p, secs, 0, 0, 0)
+	p = appendOID(p, LMSensors, false)
+	p = appendString(p, "go-lmsensors")
+	if err := sess.request(pduOpen, p); err != nil {
+		return fmt.Errorf("agentx open: %w", err)
+	}
+	// Register
+	for _, subtree := range []OID{LMSensors, EntitySensor} {
+		p = append([]byte{secs, 127, 0, 0}, appendOID(nil, subtree, false)...)
+		if err := sess.request(pduRegister, p); err != nil {
+			return fmt.Errorf("agentx register %v: %w", subtree, err)
+		}
+	}
+
+	var ix indices
+	for {
+		h, payload, err := sess.read()
+		if err != nil {
+			return err
+		}
+		var vars []variable
+		if s.Snapshot != nil {
+			vars = mib(s.Snapshot(), &ix)
+		}
+		var resp []byte
+		switch h.typ {
+		case pduGet, pduGetNext, pduGetBulk:
+			resp, err = answer(h, payload, vars)
+			if err != nil {
+				return err
+			}
+		case pduTestSet:
+			resp = response(errNotWritable, 1, nil)
+		case pduCommitSet, pduUndoSet, pduCleanupSet:
+			resp = response(0, 0, nil)
+		case pduClose:
+			return errors.New("agentx: closed by the master")
+		default:
+			continue // Nothing else needs answering
+		}
+		h.typ = pduResponse
+		if err := sess.write(h, resp); err != nil {
+			return err
+		}
+	}
+}
+
+type header struct {
+	typ                 byte
+	flags               byte
+	session, trans, pkt uint32
+	order               binary.ByteOrder
+}
+
+// read reads a PDU; its payload is in the byte order its header says.
+func (sess *session) read() (header, []byte, error) {
+	var raw [20]byte
+	if _, err := io.ReadFull(sess.r, raw[:]); err != nil {
+		return header{}, nil, err
+	}
+	h := header{typ: raw[1], flags: raw[2], order: binary.LittleEndian}
+	if h.flags&flagNetworkByteOrder != 0 {
+		h.order = binary.BigEndian
+	}
+	h.session, h.trans, h.pkt = h.order.Uint32(raw[4:]), h.order.Uint32(raw[8:]), h.order.Uint32(raw[12:])
+	payload := make([]byte, h.order.Uint32(raw[16:]))
+	if _, err := io.ReadFull(sess.r, payload); err != nil {
+		return header{}, nil, err
+	}
+	return h, payload, nil
+}
+
+// write writes a PDU, always in network byte order, as is everything this package encodes.
+func (sess *session) write(h header, payload []byte) error {
+	buf := make([]byte, 20, 20+len(payload))
+	buf[0], buf[1], buf[2] = 1, h.typ, flagNetworkByteOrder
+	binary.BigEndian.PutUint32(buf[4:], h.session)
+	binary.BigEndian.PutUint32(buf[8:], h.trans)
+	binary.BigEndian.PutUint32(buf[12:], h.pkt)
+	binary.BigEndian.PutUint32(buf[16:], uint32(len(payload)))
+	_, err := sess.w.Write(append(buf, payload...))
+	return err
+}
+
+// request sends an administrative PDU, and waits for its response, which is an error if it's not a success.
+func (sess *session) request(typ byte, payload []byte) error {
+	sess.packet++
+	if err := sess.write(header{typ: typ, session: sess.id, pkt: sess.packet}, payload); err != nil {
+		return err
+	}
+	h, resp, err := sess.read()
+	if err != nil {
+		return err
+	}
+	if h.typ != pduResponse || len(resp) < 8 {
+		return fmt.Errorf("unexpected PDU type %d", h.typ)
+	}
+	if code := h.order.Uint16(resp[4:]); code != 0 {
+		return fmt.Errorf("error %d", code)
+	}
+	sess.id = h.session
+	return nil
+}
+
+func response(code, index uint16, vars []byte) []byte {
+	p := make([]byte, 8, 8+len(vars))
+	binary.BigEndian.PutUint16(p[4:], code)
+	binary.BigEndian.PutUint16(p[6:], index)
+	return append(p, vars...)
+}
+
+// answer answers a Get, GetNext or GetBulk.
+func answer(h header, payload []byte, vars []variable) ([]byte, error) {
+	d := decoder{buf: payload, order: h.order}
+	if h.flags&flagNonDefaultContext != 0 {
+		d.string() // We only serve the default context, but answer anyway
+	}
+	var nonRepeaters, maxReps int
+	if h.typ == pduGetBulk {
+		nonRepeaters, maxReps = int(d.uint16()), int(d.uint16())
+	}
+	type searchRange struct {
+		start, end OID
+		include    bool
+	}
+	var ranges []searchRange
+	for len(d.buf) > 0 && d.err == nil {
+		start, include := d.oid()
+		end, _ := d.oid()
+		ranges = append(ranges, searchRange{start, end, include})
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	var out []byte
+	switch h.typ {
+	case pduGet:
+		for _, sr := range ranges {
+			out = appendVar(out, get(vars, sr.start))
+		}
+	case pduGetNext:
+		for _, sr := range ranges {
+			out = appendVar(out, next(vars, sr.start, sr.end, sr.include))
+		}
+	case pduGetBulk:
+		for i, sr := range ranges {
+			if i < nonRepeaters {
+				out = appendVar(out, next(vars, sr.start, sr.end, sr.include))
+			}
+		}
+		repeaters := ranges[min(nonRepeaters, len(ranges)):]
+		for range maxReps {
+			done := true
+			for i, sr := range repeaters {
+				v := next(vars, sr.start, sr.end, sr.include)
+				out = appendVar(out, v)
+				if v.typ != typeEndOfMibView {
+					done = false
+				}
+				repeaters[i].start, repeaters[i].include = v.oid, false
+			}
+			if done {
+				break
+			}
+		}
+	}
+	return response(0, 0, out), nil
+}
+
+func get(vars []variable, oid OID) variable {
+	for _, v := range vars {
+		if v.oid.compare(oid) == 0 {
+			return v
+		}
+	}
+	// The table columns exist even when they've no rows, which net-snmp explains as noSuchInstance rather than noSuchObject.
+	if (len(oid) == len(LMSensors)+4 && oid.hasPrefix(LMSensors)) || (len(oid) == len(EntitySensor)+2 && oid.hasPrefix(EntitySensor)) {
+		return variable{oid: oid, typ: typeNoSuchInst}
+	}
+	return variable{oid: oid, typ: typeNoSuchObject}
+}
+
+func next(vars []variable, start, end OID, include bool) variable {
+	for _, v := range vars {
+		c := v.oid.compare(start)
+		if c < 0 || (c == 0 && !include) {
+			continue
+		}
+		if len(end) != 0 && v.oid.compare(end) >= 0 {
+			break
+		}
+		return v
+	}
+	return variable{oid: start, typ: typeEndOfMibView}
+}
+
+// appendOID encodes an OID, using the 1.3.6.1 prefix compression where it can.
+func appendOID(p []byte, oid OID, include bool) []byte {
+	var prefix byte
+	if len(oid) > 5 && oid[:4].compare(OID{1, 3, 6, 1}) == 0 && oid[4] <= 255 {
+		prefix, oid = byte(oid[4]), oid[5:]
+	}
+	inc := byte(0)
+	if include {
+		inc = 1
+	}
+	p = append(p, byte(len(oid)), prefix, inc, 0)
+	for _, sub := range oid {
+		p = binary.BigEndian.AppendUint32(p, sub)
+	}
+	return p
+}
+
+func appendString(p []byte, s string) []byte {
+	p = binary.BigEndian.AppendUint32(p, uint32(len(s)))
+	p = append(p, s...)
+	for len(p)%4 != 0 {
+		p = append(p, 0)
+	}
+	return p
+}
+
+func appendVar(p []byte, v variable) []byte {
+	p = binary.BigEndian.AppendUint16(p, uint16(v.typ))
+	p = append(p, 0, 0)
+	p = appendOID(p, v.oid, false)
+	switch val := v.value.(type) {
+	case int32:
+		p = binary.BigEndian.AppendUint32(p, uint32(val))
+	case uint32:
+		p = binary.BigEndian.AppendUint32(p, val)
+	case string:
+		p = appendString(p, val)
+	}
+	return p
+}
+
+// decoder reads AgentX structures, remembering the first error.
+type decoder struct {
+	buf   []byte
+	order binary.ByteOrder
+	err   error
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil || len(d.buf) < n {
+		d.err = errors.New("agentx: short PDU")
+		d.buf = nil
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) uint16() uint16 {
+	b := d.take(2)
+	if b == nil {
+		return 0
+	}
+	return d.order.Uint16(b)
+}
+
+func (d *decoder) uint32() uint32 {
+	b := d.take(4)
+	if b == nil {
+		return 0
+	}
+	return d.order.Uint32(b)
+}
+
+func (d *decoder) oid() (OID, bool) {
+	h := d.take(4)
+	if h == nil {
+		return nil, false
+	}
+	n, prefix, include := int(h[0]), h[1], h[2] != 0
+	var oid OID
+	if prefix != 0 {
+		oid = OID{1, 3, 6, 1, uint32(prefix)}
+	}
+	for range n {
+		oid = append(oid, d.uint32())
+	}
+	return oid, include
+}
+
+func (d *decoder) string() string {
+	n := int(d.uint32())
+	s := string(d.take(n))
+	d.take((4 - n%4) % 4)
+	return s
+}
@@ -0,0 +1,200 @@
+package snmp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+func snapshot() *lmsensors.Snapshot {
+	t := &lmsensors.TempSensor{}
+	t.Name, t.Value = "Tctl", 42.25
+	f := &lmsensors.FanSensor{}
+	f.Name, f.Value = "fan1", 1200
+	v := &lmsensors.VoltageSensor{}
+	v.Name, v.Value = "in0", 1.1
+	return lmsensors.NewSnapshot(&lmsensors.System{Chips: map[string]*lmsensors.Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]lmsensors.Sensor{"Tctl": t}},
+		"nct6798-isa-0290": {ID: "nct6798-isa-0290", Sensors: map[string]lmsensors.Sensor{"fan1": f, "in0": v}},
+	}}, time.Now())
+}
+
+func column(table, col, row uint32) OID {
+	return append(append(OID{}, LMSensors...), table, 1, col, row)
+}
+
+func TestMIB(t *testing.T) {
+	var ix indices
+	vars := mib(snapshot(), &ix)
+	if len(vars) != 27 {
+		t.Fatalf("%d variables", len(vars))
+	}
+	if v := get(vars, column(2, 3, 1)); v.typ != typeGauge32 || v.value != uint32(42250) {
+		t.Errorf("temperature: %+v", v)
+	}
+	if v := get(vars, column(4, 2, 1)); v.value != "nct6798-isa-0290/in0" {
+		t.Errorf("voltage device: %+v", v)
+	}
+	if v := get(vars, column(2, 3, 2)); v.typ != typeNoSuchInst {
+		t.Errorf("missing row: %+v", v)
+	}
+	if v := next(vars, column(2, 3, 1), nil, false); v.oid.compare(column(3, 1, 1)) != 0 {
+		t.Errorf("next after the temperatures: %v", v.oid)
+	}
+	if v := next(vars, column(4, 3, 1), nil, false); v.typ != typeEndOfMibView {
+		t.Errorf("next after the end: %+v", v)
+	}
+	if v := get(vars, entityColumn(4, 1)); v.value != int32(42250) {
+		t.Errorf("entity temperature: %+v", v)
+	}
+	if v := get(vars, entityColumn(1, 2)); v.value != int32(10) {
+		t.Errorf("entity fan type: %+v", v)
+	}
+	if v := next(vars, entityColumn(6, 3), nil, false); v.oid.compare(column(2, 1, 1)) != 0 {
+		t.Errorf("next after entPhySensorTable: %v", v.oid)
+	}
+}
+
+func entityColumn(col, row uint32) OID {
+	return append(append(OID{}, EntitySensor...), col, row)
+}
+
+func TestMIBStableIndices(t *testing.T) {
+	f1, f2 := &lmsensors.FanSensor{}, &lmsensors.FanSensor{}
+	f1.Name, f1.Value = "fan1", 1200
+	f2.Name, f2.Value = "fan2", 900
+	snap := func(fans ...lmsensors.Sensor) *lmsensors.Snapshot {
+		sensors := map[string]lmsensors.Sensor{}
+		for _, f := range fans {
+			sensors[f.(*lmsensors.FanSensor).Name] = f
+		}
+		return lmsensors.NewSnapshot(&lmsensors.System{Chips: map[string]*lmsensors.Chip{
+			"nct6798-isa-0290": {ID: "nct6798-isa-0290", Sensors: sensors},
+		}}, time.Now())
+	}
+
+	var ix indices
+	mib(snap(f1, f2), &ix)
+	vars := mib(snap(f2), &ix)
+	if v := get(vars, column(3, 2, 2)); v.value != "nct6798-isa-0290/fan2" {
+		t.Errorf("fan2 moved: %+v", v)
+	}
+	if v := get(vars, column(3, 2, 1)); v.typ != typeNoSuchInst {
+		t.Errorf("fan1's row was reused: %+v", v)
+	}
+
+	f1.Value = lmsensors.NoValue
+	vars = mib(snap(f1, f2), &ix)
+	if v := get(vars, column(3, 3, 1)); v.value != uint32(0) {
+		t.Errorf("failed fan: %+v", v)
+	}
+	if v := get(vars, column(3, 3, 2)); v.value != uint32(900) {
+		t.Errorf("fan2 after fan1 failed: %+v", v)
+	}
+	if v := get(vars, entityColumn(5, 1)); v.value != int32(2) {
+		t.Errorf("failed fan's status: %+v", v)
+	}
+}
+
+func TestDecoderShort(t *testing.T) {
+	d := &decoder{buf: []byte{1, 0}, order: binary.BigEndian}
+	if d.uint32() != 0 || d.err == nil {
+		t.Errorf("short read: %v", d.err)
+	}
+	if oid, _ := d.oid(); oid != nil {
+		t.Errorf("oid after an error: %v", oid)
+	}
+}
+
+// master plays the master agent's side of a session.
+type master struct {
+	t    *testing.T
+	sess *session
+}
+
+func (m master) expect(typ byte) header {
+	m.t.Helper()
+	h, _, err := m.sess.read()
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	if h.typ != typ {
+		m.t.Fatalf("got PDU type %d, want %d", h.typ, typ)
+	}
+	return h
+}
+
+func (m master) call(typ byte, payload []byte) *decoder {
+	m.t.Helper()
+	if err := m.sess.write(header{typ: typ, session: 42, pkt: 7}, payload); err != nil {
+		m.t.Fatal(err)
+	}
+	h, resp, err := m.sess.read()
+	if err != nil || h.typ != pduResponse || h.pkt != 7 {
+		m.t.Fatalf("response %+v: %v", h, err)
+	}
+	d := &decoder{buf: resp[8:], order: binary.BigEndian}
+	return d
+}
+
+func (d *decoder) variable() variable {
+	typ := varType(d.uint16())
+	d.uint16()
+	oid, _ := d.oid()
+	v := variable{oid: oid, typ: typ}
+	switch typ {
+	case typeInteger:
+		v.value = int32(d.uint32())
+	case typeGauge32:
+		v.value = d.uint32()
+	case typeOctetString:
+		v.value = d.string()
+	}
+	return v
+}
+
+func TestSubagent(t *testing.T) {
+	conn, other := net.Pipe()
+	defer conn.Close()
+	done := make(chan error, 1)
+	go func() { done <- Subagent{Snapshot: snapshot}.serve(other) }()
+
+	m := master{t, &session{r: bufio.NewReader(conn), w: conn}}
+	for _, typ := range []byte{pduOpen, pduRegister, pduRegister} {
+		h := m.expect(typ)
+		h.typ, h.session = pduResponse, 42
+		if err := m.sess.write(h, response(0, 0, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	null := appendOID(nil, nil, false)
+	d := m.call(pduGet, append(appendOID(nil, column(2, 2, 1), false), null...))
+	if v := d.variable(); v.value != "k10temp-pci-00c3/Tctl" {
+		t.Errorf("get: %+v", v)
+	}
+	d = m.call(pduGetNext, append(appendOID(nil, LMSensors, false), null...))
+	if v := d.variable(); v.oid.compare(column(2, 1, 1)) != 0 || v.value != int32(1) {
+		t.Errorf("getnext: %+v", v)
+	}
+	bulk := binary.BigEndian.AppendUint16(binary.BigEndian.AppendUint16(nil, 0), 3)
+	d = m.call(pduGetBulk, append(append(bulk, appendOID(nil, column(3, 3, 0), false)...), null...))
+	var got []any
+	for range 3 {
+		got = append(got, d.variable().value)
+	}
+	if got[0] != uint32(1200) || got[1] != int32(1) || got[2] != "nct6798-isa-0290/in0" || d.err != nil {
+		t.Errorf("getbulk: %v %v", got, d.err)
+	}
+
+	if err := m.sess.write(header{typ: pduClose, session: 42}, []byte{1, 0, 0, 0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err == nil {
+		t.Error("expected the close to end the session")
+	}
+}
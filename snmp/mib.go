@@ -0,0 +1,160 @@
+// Package snmp is an SNMP subagent for readings, speaking AgentX to the host's master agent, eg net-snmp's snmpd with "master agentx" in its config, so that legacy network monitoring systems can poll the sensors.
+// It serves them in UCD's LM-SENSORS-MIB, as net-snmp's own lmsensors module does, and in ENTITY-SENSOR-MIB's entPhySensorTable, from RFC 3433.
+package snmp
+
+import (
+	"math"
+	"sort"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// OID is an SNMP object identifier.
+type OID []uint32
+
+// LMSensors is the root of LM-SENSORS-MIB, ucdExperimental.16.
+var LMSensors = OID{1, 3, 6, 1, 4, 1, 2021, 13, 16}
+
+// EntitySensor is ENTITY-SENSOR-MIB's entPhySensorEntry, entitySensorMIB.1.1.1.
+var EntitySensor = OID{1, 3, 6, 1, 2, 1, 99, 1, 1, 1}
+
+func (o OID) compare(p OID) int {
+	for i := 0; i < len(o) && i < len(p); i++ {
+		switch {
+		case o[i] < p[i]:
+			return -1
+		case o[i] > p[i]:
+			return 1
+		}
+	}
+	return len(o) - len(p)
+}
+
+func (o OID) hasPrefix(p OID) bool {
+	return len(o) >= len(p) && o[:len(p)].compare(p) == 0
+}
+
+// varType is an AgentX value type.
+type varType uint16
+
+const (
+	typeInteger      varType = 2
+	typeOctetString  varType = 4
+	typeGauge32      varType = 66
+	typeNoSuchObject varType = 128
+	typeNoSuchInst   varType = 129
+	typeEndOfMibView varType = 130
+)
+
+type variable struct {
+	oid   OID
+	typ   varType
+	value any // int32, uint32 or string
+}
+
+// tables are LM-SENSORS-MIB's tables, by the type of sensor in them, with how their values are scaled.
+var tables = []struct {
+	n     uint32
+	typ   lmsensors.LmSensorType
+	scale float64
+}{
+	{2, lmsensors.Temperature, 1000}, // lmTempSensorsTable, in m°C
+	{3, lmsensors.Fan, 1},            // lmFanSensorsTable, in RPM
+	{4, lmsensors.Voltage, 1000},     // lmVoltSensorsTable, in mV
+	{5, lmsensors.Unhandled, 1000},   // lmMiscSensorsTable, everything else, in thousandths
+}
+
+func tableOf(t lmsensors.LmSensorType) int {
+	for i, tbl := range tables[:3] {
+		if tbl.typ == t {
+			return i
+		}
+	}
+	return 3
+}
+
+// indices numbers the rows, from 1, in the order their sensors are first seen, chip then sensor in each snapshot.
+// A sensor keeps its row for the session, even while its readings fail or it's gone, so pollers' rows don't shift under them.
+type indices struct {
+	rows map[uint32]map[string]uint32 // By table, then chip/sensor
+}
+
+func (ix *indices) of(table uint32, r lmsensors.Reading) uint32 {
+	if ix.rows == nil {
+		ix.rows = map[uint32]map[string]uint32{}
+	}
+	rows := ix.rows[table]
+	if rows == nil {
+		rows = map[string]uint32{}
+		ix.rows[table] = rows
+	}
+	key := r.Chip + "/" + r.Sensor
+	idx, ok := rows[key]
+	if !ok {
+		idx = uint32(len(rows) + 1)
+		rows[key] = idx
+	}
+	return idx
+}
+
+// entity is how a type of sensor is described in entPhySensorTable: its EntitySensorDataType and its precision, in decimal places.
+type entity struct {
+	typ       int32
+	precision int32
+}
+
+var entities = map[lmsensors.LmSensorType]entity{
+	lmsensors.Voltage:     {4, 3},  // voltsDC
+	lmsensors.Fan:         {10, 0}, // rpm
+	lmsensors.Temperature: {8, 3},  // celsius
+	lmsensors.Power:       {6, 3},  // watts
+	lmsensors.Current:     {5, 3},  // amperes
+	lmsensors.Humidity:    {9, 1},  // percentRH
+	lmsensors.Intrusion:   {12, 0}, // truthvalue
+}
+
+// mib lays a snapshot out in LM-SENSORS-MIB and entPhySensorTable, in OID order, numbering the rows with ix.
+// Like net-snmp's, LM-SENSORS-MIB's rows are in order of chip then sensor, but the device is chip/sensor, as names repeat across chips; the values are Gauge32s, so negative ones are 0, as are failed readings.
+// entPhySensorTable has every sensor, in units with as many decimal places as their type needs, and failed readings are unavailable.
+func mib(snap *lmsensors.Snapshot, ix *indices) []variable {
+	var vars []variable
+	if snap == nil {
+		return vars
+	}
+	for r := range snap.Readings {
+		tbl := tables[tableOf(r.Type)]
+		idx := ix.of(tbl.n, r)
+		entry := append(append(OID{}, LMSensors...), tbl.n, 1)
+		row := func(col uint32) OID { return append(append(OID{}, entry...), col, idx) }
+		v := 0.0
+		if r.Valid {
+			v = math.Round(r.Value * tbl.scale)
+		}
+		vars = append(vars,
+			variable{row(1), typeInteger, int32(idx)},                             // lmXSensorsIndex
+			variable{row(2), typeOctetString, r.Chip + "/" + r.Sensor},            // lmXSensorsDevice
+			variable{row(3), typeGauge32, uint32(max(0, min(v, math.MaxUint32)))}, // lmXSensorsValue
+		)
+
+		idx = ix.of(0, r)
+		row = func(col uint32) OID { return append(append(OID{}, EntitySensor...), col, idx) }
+		e, ok := entities[r.FeatureType]
+		if !ok {
+			e = entity{1, 3} // other
+		}
+		status, val := int32(2), 0.0 // unavailable
+		if r.Valid {
+			status, val = 1, math.Round(r.Value*math.Pow10(int(e.precision))) // ok
+		}
+		vars = append(vars,
+			variable{row(1), typeInteger, e.typ},                           // entPhySensorType
+			variable{row(2), typeInteger, int32(9)},                        // entPhySensorScale, units
+			variable{row(3), typeInteger, e.precision},                     // entPhySensorPrecision
+			variable{row(4), typeInteger, int32(max(-1e9, min(val, 1e9)))}, // entPhySensorValue
+			variable{row(5), typeInteger, status},                          // entPhySensorOperStatus
+			variable{row(6), typeOctetString, r.Unit},                      // entPhySensorUnitsDisplay
+		)
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].oid.compare(vars[j].oid) < 0 })
+	return vars
+}
@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migrations make the schema, each from the one before; the database's user_version is how many it's had.
+// Append to them, never change them.
+var migrations = []string{
+	`
+	CREATE TABLE sensors (
+		id INTEGER PRIMARY KEY,
+		key TEXT NOT NULL UNIQUE, -- chip/sensor
+		chip TEXT NOT NULL,
+		name TEXT NOT NULL,
+		type INTEGER, -- lmsensors.LmSensorType
+		unit TEXT,
+		min REAL,
+		max REAL,
+		crit REAL
+	);
+	CREATE TABLE samples (
+		sensor INTEGER NOT NULL REFERENCES sensors (id),
+		time INTEGER NOT NULL, -- Unix nanoseconds
+		value REAL NOT NULL,
+		PRIMARY KEY (sensor, time)
+	) WITHOUT ROWID;
+	CREATE INDEX samples_time ON samples (time); -- For pruning
+	`,
+	`
+	CREATE TABLE alarm_events (
+		id INTEGER PRIMARY KEY,
+		sensor INTEGER NOT NULL REFERENCES sensors (id),
+		kind TEXT NOT NULL, -- The threshold rule's name, or empty for the hardware's alarm flag
+		value REAL, -- When it was raised
+		started INTEGER NOT NULL, -- Unix nanoseconds
+		cleared INTEGER -- Unix nanoseconds, or NULL while it's raised
+	);
+	CREATE INDEX alarm_events_started ON alarm_events (started);
+	CREATE INDEX alarm_events_sensor ON alarm_events (sensor, started);
+	`,
+}
+
+// migrate brings the schema up to date, a migration per transaction, so that one that fails leaves it as it was before.
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this package's, %d", version, len(migrations))
+	}
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migrating to schema version %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+// Package sqlite keeps the history of readings in an SQLite database, which is the sweet spot for a single host: it's one file, and it can be queried with anything.
+// It uses a pure-Go driver, so unlike the cgo-based ones it doesn't need SQLite's C library alongside libsensors; it's in its own module so that programs that don't use it don't need the driver.
+//
+// The schema is migrated forwards when the database is opened; see migrate.go for it. Record into it with eg
+//
+//	db, err := sqlite.Open("/var/lib/sensors/history.db")
+//	...
+//	r := &record.Recorder{Backend: db, Retention: 30 * 24 * time.Hour}
+//	w := lmsensors.NewWatcher(time.Second, r.Watch())
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/record"
+	_ "modernc.org/sqlite"
+)
+
+// DB is an SQLite database of history, which is a [record.AlarmBackend].
+// It's safe to use from multiple goroutines, and processes.
+type DB struct {
+	db *sql.DB
+
+	mu      sync.Mutex        // Serialising writes, which SQLite would anyway
+	sensors map[string]sensor // By chip/sensor, as they are in the database
+}
+
+type sensor struct {
+	id   int64
+	info record.SensorInfo
+}
+
+var _ record.AlarmBackend = (*DB)(nil)
+
+// Open opens the database at path, making it if it doesn't exist, and migrating its schema if it's from an older version of the package.
+func Open(path string) (*DB, error) {
+	// The pragmas are per connection, so are in the DSN for the driver to run on each.
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &DB{db: db, sensors: map[string]sensor{}}, nil
+}
+
+// Close closes the database.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Add records the valid readings in a snapshot, and what its sensors are, in one transaction, for a [record.Recorder].
+func (d *DB) Add(snap *lmsensors.Snapshot) error {
+	return d.tx(func(tx *sql.Tx) error {
+		ins, err := tx.Prepare(`INSERT OR REPLACE INTO samples (sensor, time, value) VALUES (?, ?, ?)`)
+		if err != nil {
+			return err
+		}
+		defer ins.Close()
+		for r := range snap.Readings {
+			id, err := d.sensor(tx, record.SensorInfo{Chip: r.Chip, Name: r.Sensor, Type: r.Type, Unit: r.Unit, Min: r.Min, Max: r.Max, Crit: r.Crit}, true)
+			if err != nil {
+				return err
+			}
+			if !r.Valid {
+				continue
+			}
+			at := r.Time
+			if at.IsZero() {
+				at = snap.Time()
+			}
+			if _, err := ins.Exec(id, at.UnixNano(), r.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Append records samples of a sensor, by chip/sensor, in one transaction; ones at the same time as one it has replace it.
+func (d *DB) Append(key string, samples ...record.Sample) error {
+	chip, name, ok := strings.Cut(key, "/")
+	if !ok {
+		return fmt.Errorf("sensor key %q isn't chip/sensor", key)
+	}
+	return d.tx(func(tx *sql.Tx) error {
+		id, err := d.sensor(tx, record.SensorInfo{Chip: chip, Name: name}, false)
+		if err != nil {
+			return err
+		}
+		ins, err := tx.Prepare(`INSERT OR REPLACE INTO samples (sensor, time, value) VALUES (?, ?, ?)`)
+		if err != nil {
+			return err
+		}
+		defer ins.Close()
+		for _, smp := range samples {
+			if _, err := ins.Exec(id, smp.Time.UnixNano(), smp.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// tx runs fn in a transaction, holding the lock on the sensors it knows, which are forgotten if it fails, as they might have been rolled back.
+func (d *DB) tx(fn func(*sql.Tx) error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		d.sensors = map[string]sensor{}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		d.sensors = map[string]sensor{}
+		return err
+	}
+	return nil
+}
+
+// sensor finds a sensor's id, adding it if it's new, and, if update is set, updating what it is if that's changed.
+// Sensors only ever given to [DB.Append] have no type or unit.
+func (d *DB) sensor(tx *sql.Tx, info record.SensorInfo, update bool) (int64, error) {
+	key := info.Key()
+	s, ok := d.sensors[key]
+	if !ok {
+		err := tx.QueryRow(`SELECT id, type, unit, min, max, crit FROM sensors WHERE key = ?`, key).Scan(&s.id, scanType{&s.info.Type}, scanString{&s.info.Unit}, limit{&s.info.Min}, limit{&s.info.Max}, limit{&s.info.Crit})
+		switch {
+		case err == sql.ErrNoRows:
+			if err := tx.QueryRow(`INSERT INTO sensors (key, chip, name) VALUES (?, ?, ?) RETURNING id`, key, info.Chip, info.Name).Scan(&s.id); err != nil {
+				return 0, err
+			}
+			s.info = record.SensorInfo{Type: lmsensors.Unhandled, Min: lmsensors.NoValue, Max: lmsensors.NoValue, Crit: lmsensors.NoValue}
+		case err != nil:
+			return 0, err
+		}
+		s.info.Chip, s.info.Name = info.Chip, info.Name
+	}
+	if update && !same(s.info, info) {
+		_, err := tx.Exec(`UPDATE sensors SET type = ?, unit = ?, min = ?, max = ?, crit = ? WHERE id = ?`,
+			int64(info.Type), info.Unit, null(info.Min), null(info.Max), null(info.Crit), s.id)
+		if err != nil {
+			return 0, err
+		}
+		s.info = info
+	}
+	d.sensors[key] = s
+	return s.id, nil
+}
+
+// Query downsamples a sensor's samples, as [record.Store.Query] does, in the database.
+func (d *DB) Query(key string, from, to time.Time, step time.Duration) ([]record.Point, error) {
+	if step < 0 {
+		return nil, fmt.Errorf("negative step %s", step)
+	}
+	var id int64
+	if err := d.db.QueryRow(`SELECT id FROM sensors WHERE key = ?`, key).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("no history of %s", key)
+		}
+		return nil, err
+	}
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if step == 0 {
+		rows, err = d.db.Query(`
+			SELECT time, value, value, value, 1 FROM samples
+			WHERE sensor = ? AND time >= ? AND time < ? ORDER BY time`,
+			id, from.UnixNano(), to.UnixNano())
+	} else {
+		rows, err = d.db.Query(`
+			SELECT ? + (time - ?) / ? * ? AS start, MIN(value), MAX(value), AVG(value), COUNT(*) FROM samples
+			WHERE sensor = ? AND time >= ? AND time < ? GROUP BY start ORDER BY start`,
+			from.UnixNano(), from.UnixNano(), int64(step), int64(step), id, from.UnixNano(), to.UnixNano())
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var points []record.Point
+	for rows.Next() {
+		var (
+			p     record.Point
+			start int64
+		)
+		if err := rows.Scan(&start, &p.Min, &p.Max, &p.Avg, &p.Count); err != nil {
+			return nil, err
+		}
+		p.Time = time.Unix(0, start)
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// Prune drops all the samples from before before; the alarm events are kept, as for every [record.AlarmBackend].
+func (d *DB) Prune(before time.Time) error {
+	_, err := d.db.Exec(`DELETE FROM samples WHERE time < ?`, before.UnixNano())
+	return err
+}
+
+// Sensors lists the sensors it has history of, by chip, then name, as they were as of their latest reading.
+func (d *DB) Sensors() ([]record.SensorInfo, error) {
+	rows, err := d.db.Query(`SELECT chip, name, type, unit, min, max, crit FROM sensors ORDER BY chip, name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var infos []record.SensorInfo
+	for rows.Next() {
+		var i record.SensorInfo
+		if err := rows.Scan(&i.Chip, &i.Name, scanType{&i.Type}, scanString{&i.Unit}, limit{&i.Min}, limit{&i.Max}, limit{&i.Crit}); err != nil {
+			return nil, err
+		}
+		infos = append(infos, i)
+	}
+	return infos, rows.Err()
+}
+
+// RaiseAlarm starts an event, which has a zero Cleared.
+func (d *DB) RaiseAlarm(e record.AlarmEvent) error {
+	return d.tx(func(tx *sql.Tx) error {
+		id, err := d.sensor(tx, record.SensorInfo{Chip: e.Chip, Name: e.Sensor}, false)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO alarm_events (sensor, kind, value, started) VALUES (?, ?, ?, ?)`, id, e.Kind, null(e.Value), e.Started.UnixNano())
+		return err
+	})
+}
+
+// ClearAlarm ends the sensor's raised event of the kind, if it has one.
+func (d *DB) ClearAlarm(chip, sensor, kind string, at time.Time) error {
+	_, err := d.db.Exec(`
+		UPDATE alarm_events SET cleared = ?
+		WHERE sensor = (SELECT id FROM sensors WHERE key = ?) AND kind = ? AND cleared IS NULL`,
+		at.UnixNano(), chip+"/"+sensor, kind)
+	return err
+}
+
+// ListAlarmEvents returns the events the filter picks out, by when they were raised.
+// The times and minimum duration are in the query, which uses an index on when they were raised.
+func (d *DB) ListAlarmEvents(f record.AlarmFilter) ([]record.AlarmEvent, error) {
+	from, to := int64(math.MinInt64), int64(math.MaxInt64)
+	if !f.From.IsZero() {
+		from = f.From.UnixNano()
+	}
+	if !f.To.IsZero() {
+		to = f.To.UnixNano()
+	}
+	rows, err := d.db.Query(`
+		SELECT s.chip, s.name, e.kind, e.value, e.started, e.cleared FROM alarm_events e JOIN sensors s ON s.id = e.sensor
+		WHERE e.started >= ? AND e.started < ? AND COALESCE(e.cleared, ?) - e.started >= ?
+		ORDER BY e.started`,
+		from, to, time.Now().UnixNano(), int64(f.MinDuration))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var es []record.AlarmEvent
+	for rows.Next() {
+		var (
+			e       record.AlarmEvent
+			started int64
+			cleared sql.NullInt64
+		)
+		if err := rows.Scan(&e.Chip, &e.Sensor, &e.Kind, limit{&e.Value}, &started, &cleared); err != nil {
+			return nil, err
+		}
+		e.Started = time.Unix(0, started)
+		if cleared.Valid {
+			e.Cleared = time.Unix(0, cleared.Int64)
+		}
+		if f.Match(e) {
+			es = append(es, e)
+		}
+	}
+	return es, rows.Err()
+}
+
+// null stores [lmsensors.NoValue] as NULL.
+func null(v float64) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: v, Valid: !math.IsNaN(v)}
+}
+
+// limit scans NULL as [lmsensors.NoValue], for limits and alarm values.
+type limit struct{ v *float64 }
+
+func (l limit) Scan(src any) error {
+	var n sql.NullFloat64
+	if err := n.Scan(src); err != nil {
+		return err
+	}
+	*l.v = lmsensors.NoValue
+	if n.Valid {
+		*l.v = n.Float64
+	}
+	return nil
+}
+
+// scanType scans NULL, for sensors only ever given to [DB.Append], as [lmsensors.Unhandled].
+type scanType struct{ t *lmsensors.LmSensorType }
+
+func (s scanType) Scan(src any) error {
+	var n sql.NullInt64
+	if err := n.Scan(src); err != nil {
+		return err
+	}
+	*s.t = lmsensors.Unhandled
+	if n.Valid {
+		*s.t = lmsensors.LmSensorType(n.Int64)
+	}
+	return nil
+}
+
+type scanString struct{ s *string }
+
+func (s scanString) Scan(src any) error {
+	var n sql.NullString
+	err := n.Scan(src)
+	*s.s = n.String
+	return err
+}
+
+// same is whether two sensors are the same, with any missing limits alike.
+func same(a, b record.SensorInfo) bool {
+	eq := func(x, y float64) bool { return x == y || math.IsNaN(x) && math.IsNaN(y) }
+	return a.Chip == b.Chip && a.Name == b.Name && a.Type == b.Type && a.Unit == b.Unit && eq(a.Min, b.Min) && eq(a.Max, b.Max) && eq(a.Crit, b.Crit)
+}
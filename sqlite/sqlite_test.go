@@ -0,0 +1,228 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/record"
+)
+
+func TestDB(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	start := time.Unix(1700000000, 0)
+	var smps []record.Sample
+	for i := range 90 {
+		smps = append(smps, record.Sample{Time: start.Add(time.Duration(i) * time.Second), Value: 40 + float64(i)})
+	}
+	if err := db.Append("k10temp-pci-00c3/Tctl", smps...); err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := db.Query("k10temp-pci-00c3/Tctl", start.Add(35*time.Second), start.Add(55*time.Second), 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 2 || !ps[0].Time.Equal(start.Add(35*time.Second)) || ps[0].Min != 75 || ps[0].Max != 84 || ps[0].Avg != 79.5 || ps[0].Count != 10 {
+		t.Errorf("wrong points: %+v", ps)
+	}
+	if ps, err := db.Query("k10temp-pci-00c3/Tctl", start, start.Add(time.Hour), 0); err != nil || len(ps) != 90 || ps[89].Avg != 129 {
+		t.Errorf("wrong raw points: %v, %+v", err, ps)
+	}
+
+	if err := db.Prune(start.Add(80 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if ps, _ := db.Query("k10temp-pci-00c3/Tctl", start, start.Add(time.Hour), 0); len(ps) != 10 || ps[0].Avg != 120 {
+		t.Errorf("wrong points after pruning: %+v", ps)
+	}
+	if _, err := db.Query("nope/nope", start, start.Add(time.Hour), 0); err == nil {
+		t.Error("no error for an unknown sensor")
+	}
+}
+
+func TestAdd(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	start := time.Unix(1700000000, 0)
+	snapshot := func(at time.Time) *lmsensors.Snapshot {
+		s := &lmsensors.TempSensor{}
+		s.Name, s.Value, s.Time = "Tctl", 50, at
+		gone := &lmsensors.VoltageSensor{}
+		gone.Name, gone.Value = "in0", lmsensors.NoValue
+		return lmsensors.NewSnapshot(&lmsensors.System{Chips: map[string]*lmsensors.Chip{
+			"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]lmsensors.Sensor{"Tctl": s, "in0": gone}},
+		}}, at)
+	}
+	for i := range 3 {
+		if err := db.Add(snapshot(start.Add(time.Duration(i) * time.Second))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Append("nct6798-isa-0290/fan1", record.Sample{Time: start, Value: 900}); err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := db.Sensors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 3 || infos[0].Key() != "k10temp-pci-00c3/Tctl" || infos[0].Type != lmsensors.Temperature || infos[0].Unit != "°C" || !math.IsNaN(infos[0].Crit) {
+		t.Errorf("wrong sensors: %+v", infos)
+	}
+	if fan := infos[2]; fan.Key() != "nct6798-isa-0290/fan1" || fan.Type != lmsensors.Unhandled || fan.Unit != "" {
+		t.Errorf("wrong appended sensor: %+v", fan)
+	}
+	if ps, err := db.Query("k10temp-pci-00c3/Tctl", start, start.Add(time.Hour), time.Hour); err != nil || len(ps) != 1 || ps[0].Count != 3 {
+		t.Errorf("wrong points: %v, %+v", err, ps)
+	}
+	// Sensors with no valid readings are known, but have no points.
+	if ps, err := db.Query("k10temp-pci-00c3/in0", start, start.Add(time.Hour), 0); err != nil || len(ps) != 0 {
+		t.Errorf("wrong points for a sensor without readings: %v, %+v", err, ps)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Append("k10temp-pci-00c3/Tctl", record.Sample{Time: time.Unix(1700000000, 0), Value: 50}); err != nil {
+		t.Fatal(err)
+	}
+	var version int
+	if err := db.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil || version != len(migrations) {
+		t.Errorf("wrong schema version: %d, %v", version, err)
+	}
+	// The queries use the indexes.
+	for _, q := range []string{
+		`SELECT time FROM samples WHERE sensor = 1 AND time >= 0 AND time < 1`,
+		`DELETE FROM samples WHERE time < 0`,
+		`SELECT id FROM alarm_events WHERE started >= 0`,
+	} {
+		var plan string
+		if err := db.db.QueryRow(`EXPLAIN QUERY PLAN `+q).Scan(new(int), new(int), new(int), &plan); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(plan, "SEARCH") {
+			t.Errorf("%s isn't indexed: %s", q, plan)
+		}
+	}
+	db.Close()
+
+	// Opening it again doesn't migrate it again, or lose anything.
+	if db, err = Open(path); err != nil {
+		t.Fatal(err)
+	}
+	if ps, err := db.Query("k10temp-pci-00c3/Tctl", time.Unix(0, 0), time.Unix(1800000000, 0), 0); err != nil || len(ps) != 1 {
+		t.Errorf("wrong points after reopening: %v, %+v", err, ps)
+	}
+	// One from a newer version of the package is refused.
+	if _, err := db.db.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, len(migrations)+1)); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	if _, err := Open(path); err == nil {
+		t.Error("no error for a newer schema")
+	}
+}
+
+// alarmBackend checks that an [record.AlarmBackend] keeps and filters the events it's given.
+func alarmBackend(t *testing.T, b record.AlarmBackend) {
+	t.Helper()
+	start := time.Unix(1700000000, 0)
+	for _, err := range []error{
+		b.RaiseAlarm(record.AlarmEvent{Chip: "nct6798-isa-0290", Sensor: "VRM", Kind: "vrm-hot", Value: 95, Started: start}),
+		b.ClearAlarm("nct6798-isa-0290", "VRM", "vrm-hot", start.Add(2*time.Minute)),
+		b.RaiseAlarm(record.AlarmEvent{Chip: "nct6798-isa-0290", Sensor: "fan2", Value: lmsensors.NoValue, Started: start.Add(2 * time.Minute)}),
+		b.RaiseAlarm(record.AlarmEvent{Chip: "nct6798-isa-0290", Sensor: "VRM", Kind: "vrm-hot", Value: 96, Started: start.Add(10 * time.Minute)}),
+		b.ClearAlarm("nct6798-isa-0290", "VRM", "vrm-hot", start.Add(11*time.Minute)),
+		b.ClearAlarm("nct6798-isa-0290", "VRM", "vrm-hot", start.Add(12*time.Minute)), // Not raised
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, err := b.ListAlarmEvents(record.AlarmFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].Duration() != 2*time.Minute || all[0].Value != 95 || all[1].Sensor != "fan2" || !all[1].Cleared.IsZero() || !math.IsNaN(all[1].Value) || all[2].Duration() != time.Minute {
+		t.Fatalf("wrong events: %+v", all)
+	}
+	for _, c := range []struct {
+		f    record.AlarmFilter
+		want int
+	}{
+		{record.AlarmFilter{Sensor: "nct6798-*/VRM*"}, 2},
+		{record.AlarmFilter{Kinds: []string{""}}, 1},
+		{record.AlarmFilter{From: start.Add(5 * time.Minute)}, 1},
+		{record.AlarmFilter{To: start.Add(5 * time.Minute)}, 2},
+		{record.AlarmFilter{Sensor: "VRM", MinDuration: 90 * time.Second}, 1},
+	} {
+		if es, _ := b.ListAlarmEvents(c.f); len(es) != c.want {
+			t.Errorf("%+v: got %+v, want %d", c.f, es, c.want)
+		}
+	}
+}
+
+func TestAlarmEvents(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	alarmBackend(t, db)
+}
+
+// TestSQL checks the generic [record.SQL] backend, which needs a driver to test with.
+func TestSQL(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	s, err := record.NewSQL(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1700000000, 0)
+	for i := range 90 {
+		if err := s.Append("k10temp-pci-00c3/Tctl", record.Sample{Time: start.Add(time.Duration(i) * time.Second), Value: 40 + float64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ps, err := s.Query("k10temp-pci-00c3/Tctl", start.Add(35*time.Second), start.Add(55*time.Second), 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 2 || !ps[1].Time.Equal(start.Add(45*time.Second)) || ps[1].Min != 85 || ps[1].Avg != 89.5 || ps[1].Count != 10 {
+		t.Errorf("wrong points: %+v", ps)
+	}
+	if err := s.Prune(start.Add(80 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if ps, _ := s.Query("k10temp-pci-00c3/Tctl", start, start.Add(time.Hour), 0); len(ps) != 10 {
+		t.Errorf("wrong points after pruning: %+v", ps)
+	}
+	if _, err := s.Query("nope/nope", start, start.Add(time.Hour), 0); err == nil {
+		t.Error("no error for an unknown sensor")
+	}
+	alarmBackend(t, s)
+}
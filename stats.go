@@ -0,0 +1,44 @@
+package lmsensors
+
+import (
+	"sort"
+	"time"
+)
+
+// CollectionStats records how long reading took, since a single dead SMBus device can slow a sweep from milliseconds to seconds.
+type CollectionStats struct {
+	Duration time.Duration // Wall time for the whole system, or chip
+
+	// Per subfeature read latency, keyed by sysfs attribute name (eg temp1_input). Only recorded for chips.
+	SubFeatures map[string]time.Duration `json:",omitempty"`
+}
+
+func (s *CollectionStats) record(attr string, start time.Time) {
+	s.SubFeatures[attr] += time.Since(start)
+}
+
+// Slowest returns the names of the n slowest subfeatures, slowest first.
+func (s *CollectionStats) Slowest(n int) []string {
+	attrs := make([]string, 0, len(s.SubFeatures))
+	for a := range s.SubFeatures {
+		attrs = append(attrs, a)
+	}
+	sort.Slice(attrs, func(i, j int) bool { return s.SubFeatures[attrs[i]] > s.SubFeatures[attrs[j]] })
+	if n < len(attrs) {
+		attrs = attrs[:n]
+	}
+	return attrs
+}
+
+// SlowestChips returns the IDs of the n chips that took longest to read, slowest first.
+func (sys *System) SlowestChips(n int) []string {
+	ids := make([]string, 0, len(sys.Chips))
+	for id := range sys.Chips {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return sys.Chips[ids[i]].Stats.Duration > sys.Chips[ids[j]].Stats.Duration })
+	if n < len(ids) {
+		ids = ids[:n]
+	}
+	return ids
+}
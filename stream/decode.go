@@ -0,0 +1,229 @@
+package stream
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Frame is what one snapshot changed.
+type Frame struct {
+	Time    time.Time
+	Changed []lmsensors.Reading // In chip and sensor order
+	Removed []lmsensors.Reading // As they last were
+}
+
+// Decoder reads a stream written by an [Encoder], keeping the latest reading of every sensor.
+type Decoder struct {
+	r       *bufio.Reader
+	started bool
+	defs    map[uint64]*sensorDef
+	state   map[uint64]*lmsensors.Reading
+	values  map[uint64]uint64
+	nextID  uint64
+	last    time.Time
+}
+
+// NewDecoder makes a [Decoder] reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r), defs: map[uint64]*sensorDef{}, state: map[uint64]*lmsensors.Reading{}, values: map[uint64]uint64{}}
+}
+
+// ErrFormat is returned for streams that weren't written by an [Encoder], or are corrupt.
+var ErrFormat = errors.New("not a sensor stream")
+
+// Decode reads the next snapshot's changes, returning [io.EOF] at the end of the stream.
+func (d *Decoder) Decode() (Frame, error) {
+	if !d.started {
+		hdr := make([]byte, len(magic)+1)
+		if _, err := io.ReadFull(d.r, hdr); err != nil {
+			return Frame{}, err
+		}
+		if string(hdr[:len(magic)]) != magic {
+			return Frame{}, ErrFormat
+		}
+		if hdr[len(magic)] != version {
+			return Frame{}, fmt.Errorf("unsupported sensor stream version %d", hdr[len(magic)])
+		}
+		d.started = true
+	}
+	var f Frame
+	for partial := false; ; partial = true {
+		typ, err := d.r.ReadByte()
+		if err != nil {
+			if partial {
+				err = unexpected(err)
+			}
+			return Frame{}, err
+		}
+		switch typ {
+		case recRemoved:
+			n, err := d.uvarint()
+			if err != nil {
+				return Frame{}, err
+			}
+			for range n {
+				id, err := d.uvarint()
+				if err != nil {
+					return Frame{}, err
+				}
+				r, ok := d.state[id]
+				if !ok {
+					return Frame{}, fmt.Errorf("%w: removal of unknown sensor %d", ErrFormat, id)
+				}
+				f.Removed = append(f.Removed, *r)
+				delete(d.state, id)
+				delete(d.defs, id)
+				delete(d.values, id)
+			}
+		case recSensors:
+			n, err := d.uvarint()
+			if err != nil {
+				return Frame{}, err
+			}
+			for range n {
+				def := &sensorDef{id: d.nextID}
+				var typ uint64
+				if def.chip, err = d.string(); err == nil {
+					if def.sensor, err = d.string(); err == nil {
+						if typ, err = d.uvarint(); err == nil {
+							def.unit, err = d.string()
+						}
+					}
+				}
+				if err != nil {
+					return Frame{}, err
+				}
+				def.typ = lmsensors.LmSensorType(typ)
+				d.defs[def.id] = def
+				d.nextID++
+			}
+		case recFrame:
+			if err := d.frame(&f); err != nil {
+				return Frame{}, err
+			}
+			return f, nil
+		default:
+			return Frame{}, fmt.Errorf("%w: unknown record %q", ErrFormat, typ)
+		}
+	}
+}
+
+func (d *Decoder) frame(f *Frame) error {
+	dt, err := binary.ReadVarint(d.r)
+	if err != nil {
+		return unexpected(err)
+	}
+	if d.last.IsZero() {
+		f.Time = time.Unix(0, dt)
+	} else {
+		f.Time = d.last.Add(time.Duration(dt))
+	}
+	d.last = f.Time
+	n, err := d.uvarint()
+	if err != nil {
+		return err
+	}
+	next := uint64(0)
+	for range n {
+		delta, err := d.uvarint()
+		if err != nil {
+			return err
+		}
+		id := next + delta
+		next = id + 1
+		def, ok := d.defs[id]
+		if !ok {
+			return fmt.Errorf("%w: reading of unknown sensor %d", ErrFormat, id)
+		}
+		flags, err := d.r.ReadByte()
+		if err != nil {
+			return unexpected(err)
+		}
+		offset, err := binary.ReadVarint(d.r)
+		if err != nil {
+			return unexpected(err)
+		}
+		r := lmsensors.Reading{
+			Chip: def.chip, Sensor: def.sensor, Type: def.typ, FeatureType: def.typ, Unit: def.unit,
+			Value: lmsensors.NoValue, Valid: flags&flagValid != 0, Alarm: flags&flagAlarm != 0,
+			Time: f.Time.Add(time.Duration(offset) * time.Microsecond),
+			Min:  lmsensors.NoValue, Max: lmsensors.NoValue, Crit: lmsensors.NoValue,
+		}
+		if r.Valid {
+			tz, err := d.r.ReadByte()
+			if err != nil {
+				return unexpected(err)
+			}
+			var x uint64
+			switch {
+			case tz < 64:
+				if x, err = d.uvarint(); err != nil {
+					return err
+				}
+				x <<= tz
+			case tz > 64:
+				return fmt.Errorf("%w: bad value", ErrFormat)
+			}
+			d.values[id] ^= x
+			r.Value = math.Float64frombits(d.values[id])
+		}
+		d.state[id] = &r
+		f.Changed = append(f.Changed, r)
+	}
+	sort.Slice(f.Changed, func(i, j int) bool { return less(f.Changed[i], f.Changed[j]) })
+	return nil
+}
+
+// Readings are the latest reading of every sensor, in chip and sensor order, as of the last frame decoded.
+// Readings that haven't changed are kept from the frame they last changed in, time and all.
+func (d *Decoder) Readings() []lmsensors.Reading {
+	rs := make([]lmsensors.Reading, 0, len(d.state))
+	for _, r := range d.state {
+		rs = append(rs, *r)
+	}
+	sort.Slice(rs, func(i, j int) bool { return less(rs[i], rs[j]) })
+	return rs
+}
+
+func less(a, b lmsensors.Reading) bool {
+	if a.Chip != b.Chip {
+		return a.Chip < b.Chip
+	}
+	return a.Sensor < b.Sensor
+}
+
+func (d *Decoder) uvarint() (uint64, error) {
+	v, err := binary.ReadUvarint(d.r)
+	return v, unexpected(err)
+}
+
+func (d *Decoder) string() (string, error) {
+	n, err := d.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if n > 1<<16 {
+		return "", fmt.Errorf("%w: string of %d bytes", ErrFormat, n)
+	}
+	p := make([]byte, n)
+	if _, err := io.ReadFull(d.r, p); err != nil {
+		return "", unexpected(err)
+	}
+	return string(p), nil
+}
+
+// unexpected is an error reading the middle of a record, where the stream can't end.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
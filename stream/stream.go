@@ -0,0 +1,182 @@
+// Package stream records readings compactly, for high-frequency recording: each sensor is described once, and after that only the readings that changed are written, as (sensor, time, value) deltas.
+// Compared with writing whole snapshots, eg as JSON, a stream is typically an order of magnitude smaller, since most sensors don't change from one poll to the next, and the ones that do take a few bytes each.
+//
+// The format is a header, "LMSD" and a version byte, then records, each a type byte followed by:
+//
+//	'S' sensors  count, then for each: chip, sensor, type, unit; they're numbered in the order they're described, from 0
+//	'X' removed  count, then for each: sensor number
+//	'F' frame    time, count, then for each changed reading: sensor number, flags, time, value
+//
+// Strings are a uvarint length and the bytes. A frame's time is the nanoseconds since the previous frame's, zig-zag encoded, and the first's since the Unix epoch.
+// Sensor numbers in frames are deltas from the previous one's, plus one, as the readings are in number order.
+// The flags are bit 0 for valid and bit 1 for alarm. A reading's time is the microseconds from the frame's, zig-zag encoded.
+// A valid reading's value is the XOR of its float64 bits with the sensor's previous value's, as its number of trailing zero bits and then the rest as a uvarint, which for readings that changed a little is a few bytes.
+package stream
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/bits"
+	"sort"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+const (
+	magic   = "LMSD"
+	version = 1
+
+	recSensors = 'S'
+	recRemoved = 'X'
+	recFrame   = 'F'
+
+	flagValid = 1 << 0
+	flagAlarm = 1 << 1
+)
+
+// sensorDef is what's described of a sensor once.
+type sensorDef struct {
+	id           uint64
+	chip, sensor string
+	typ          lmsensors.LmSensorType
+	unit         string
+}
+
+// Encoder writes a stream of snapshots.
+// Readings' limits and renderings aren't recorded.
+type Encoder struct {
+	w       *bufio.Writer
+	started bool
+	defs    map[string]*sensorDef // By chip/sensor
+	values  map[uint64]uint64     // Each sensor's last value, as bits
+	nextID  uint64
+	prev    *lmsensors.Snapshot
+	last    time.Time // The last frame's time
+}
+
+// NewEncoder makes an [Encoder] writing to w; it's buffered, so call [Encoder.Flush] when done.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w), defs: map[string]*sensorDef{}, values: map[uint64]uint64{}}
+}
+
+// Encode writes the changes since the last snapshot it was given, or all of the first.
+func (e *Encoder) Encode(snap *lmsensors.Snapshot) error {
+	if !e.started {
+		e.w.WriteString(magic)
+		e.w.WriteByte(version)
+		e.started = true
+	}
+	changed, removed := snap.Changes(e.prev)
+	e.prev = snap
+
+	var gone []uint64
+	for _, r := range removed {
+		key := r.Chip + "/" + r.Sensor
+		gone = append(gone, e.defs[key].id)
+		delete(e.values, e.defs[key].id)
+		delete(e.defs, key)
+	}
+	// Sensors that are new, or whose type or unit changed, and so need describing again.
+	var fresh []*sensorDef
+	for _, r := range changed {
+		key := r.Chip + "/" + r.Sensor
+		if d, ok := e.defs[key]; ok {
+			if d.typ == r.Type && d.unit == r.Unit {
+				continue
+			}
+			gone = append(gone, d.id)
+			delete(e.values, d.id)
+		}
+		d := &sensorDef{e.nextID, r.Chip, r.Sensor, r.Type, r.Unit}
+		e.nextID++
+		e.defs[key] = d
+		fresh = append(fresh, d)
+	}
+
+	var buf []byte
+	if len(gone) != 0 {
+		buf = append(buf, recRemoved)
+		buf = binary.AppendUvarint(buf, uint64(len(gone)))
+		for _, id := range gone {
+			buf = binary.AppendUvarint(buf, id)
+		}
+	}
+	if len(fresh) != 0 {
+		buf = append(buf, recSensors)
+		buf = binary.AppendUvarint(buf, uint64(len(fresh)))
+		for _, d := range fresh {
+			buf = appendString(buf, d.chip)
+			buf = appendString(buf, d.sensor)
+			buf = binary.AppendUvarint(buf, uint64(d.typ))
+			buf = appendString(buf, d.unit)
+		}
+	}
+
+	at := snap.Time()
+	buf = append(buf, recFrame)
+	if e.last.IsZero() {
+		buf = binary.AppendVarint(buf, at.UnixNano())
+	} else {
+		buf = binary.AppendVarint(buf, int64(at.Sub(e.last)))
+	}
+	e.last = at
+	buf = binary.AppendUvarint(buf, uint64(len(changed)))
+	// Changes are in chip and sensor order, which isn't necessarily number order once sensors have come and gone.
+	type numbered struct {
+		id uint64
+		r  lmsensors.Reading
+	}
+	frame := make([]numbered, len(changed))
+	for i, r := range changed {
+		frame[i] = numbered{e.defs[r.Chip+"/"+r.Sensor].id, r}
+	}
+	sort.Slice(frame, func(i, j int) bool { return frame[i].id < frame[j].id })
+	next := uint64(0)
+	for _, n := range frame {
+		r, id := n.r, n.id
+		buf = binary.AppendUvarint(buf, id-next)
+		next = id + 1
+		var flags byte
+		if r.Valid {
+			flags |= flagValid
+		}
+		if r.Alarm {
+			flags |= flagAlarm
+		}
+		buf = append(buf, flags)
+		t := r.Time
+		if t.IsZero() {
+			t = at
+		}
+		buf = binary.AppendVarint(buf, t.Sub(at).Microseconds())
+		if r.Valid {
+			v := math.Float64bits(r.Value)
+			buf = appendXOR(buf, v^e.values[id])
+			e.values[id] = v
+		}
+	}
+	_, err := e.w.Write(buf)
+	return err
+}
+
+// Flush writes out what's buffered.
+func (e *Encoder) Flush() error {
+	return e.w.Flush()
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func appendXOR(buf []byte, x uint64) []byte {
+	tz := bits.TrailingZeros64(x) // 64 for no change
+	buf = append(buf, byte(tz))
+	if tz == 64 {
+		return buf
+	}
+	return binary.AppendUvarint(buf, x>>tz)
+}
@@ -0,0 +1,133 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// alarming is a fan in alarm, which can't be made otherwise outside lmsensors.
+type alarming struct{ *lmsensors.FanSensor }
+
+func (alarming) Alarm() bool { return true }
+
+// system is a board with a CPU temperature of temp, and a fan that's in alarm if it's stopped.
+func system(at time.Time, temp, rpm float64, extra bool) *lmsensors.System {
+	tctl := &lmsensors.TempSensor{}
+	tctl.Name, tctl.Value, tctl.Time = "Tctl", temp, at
+	fan := &lmsensors.FanSensor{}
+	fan.Name, fan.Value, fan.Time = "fan2", rpm, at.Add(3*time.Millisecond)
+	var f lmsensors.Sensor = fan
+	if rpm == 0 {
+		f = alarming{fan}
+	}
+	in0 := &lmsensors.VoltageSensor{}
+	in0.Name, in0.Value, in0.Time = "in0", lmsensors.NoValue, at
+	sys := &lmsensors.System{Time: at, Chips: map[string]*lmsensors.Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]lmsensors.Sensor{"Tctl": tctl}},
+		"nct6798-isa-0290": {ID: "nct6798-isa-0290", Sensors: map[string]lmsensors.Sensor{"fan2": f, "in0": in0}},
+	}}
+	if extra {
+		edge := &lmsensors.TempSensor{}
+		edge.Name, edge.Value, edge.Time = "edge", 50, at
+		sys.Chips["amdgpu-pci-0b00"] = &lmsensors.Chip{ID: "amdgpu-pci-0b00", Sensors: map[string]lmsensors.Sensor{"edge": edge}}
+	}
+	return sys
+}
+
+func TestRoundTrip(t *testing.T) {
+	start := time.Unix(1700000000, 123456000)
+	var snaps []*lmsensors.Snapshot
+	for i := range 50 {
+		at := start.Add(time.Duration(i) * 100 * time.Millisecond)
+		temp := 42.125 + float64(i%5)*0.125
+		rpm := 1200.0
+		if i >= 20 && i < 25 {
+			rpm = 0
+		}
+		snaps = append(snaps, lmsensors.NewSnapshot(system(at, temp, rpm, i >= 10 && i < 30), at))
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	for _, s := range snaps {
+		if err := enc.Encode(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	dec := NewDecoder(bytes.NewReader(buf.Bytes()))
+	for i, s := range snaps {
+		f, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		if !f.Time.Equal(s.Time()) {
+			t.Errorf("frame %d: wrong time %v", i, f.Time)
+		}
+		var want []lmsensors.Reading
+		for r := range s.Readings {
+			want = append(want, r)
+		}
+		got := dec.Readings()
+		if len(got) != len(want) {
+			t.Fatalf("frame %d: got %d readings, want %d", i, len(got), len(want))
+		}
+		for j := range want {
+			w, g := want[j], got[j]
+			if g.Chip != w.Chip || g.Sensor != w.Sensor || g.Type != w.Type || g.Unit != w.Unit || g.Valid != w.Valid || g.Alarm != w.Alarm || (w.Valid && g.Value != w.Value) || (!w.Valid && !math.IsNaN(g.Value)) {
+				t.Errorf("frame %d: got %+v, want %+v", i, g, w)
+			}
+		}
+		switch i {
+		case 10:
+			if len(f.Changed) != 2 || f.Changed[0].Sensor != "edge" {
+				t.Errorf("frame 10: wrong changes %+v", f.Changed)
+			}
+		case 30:
+			if len(f.Removed) != 1 || f.Removed[0].Sensor != "edge" {
+				t.Errorf("frame 30: wrong removals %+v", f.Removed)
+			}
+		}
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("wanted EOF, got %v", err)
+	}
+
+	// The point of it all.
+	var js int
+	for _, s := range snaps {
+		p, _ := json.Marshal(s)
+		js += len(p)
+	}
+	if buf.Len()*10 > js {
+		t.Errorf("stream is %d bytes, JSON %d", buf.Len(), js)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	at := time.Unix(1700000000, 0)
+	_ = enc.Encode(lmsensors.NewSnapshot(system(at, 40, 1000, false), at))
+	_ = enc.Flush()
+	stream := buf.Bytes()
+
+	if _, err := NewDecoder(bytes.NewReader([]byte("nope!"))).Decode(); !errors.Is(err, ErrFormat) {
+		t.Errorf("wrong error for garbage: %v", err)
+	}
+	for n := len(magic) + 2; n < len(stream); n++ {
+		if _, err := NewDecoder(bytes.NewReader(stream[:n])).Decode(); err != io.ErrUnexpectedEOF {
+			t.Errorf("truncated to %d bytes: %v", n, err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package lmsensors
+
+import "time"
+
+// StuckEvent reports a sensor that has started, or stopped, reading exactly the same value every poll.
+type StuckEvent struct {
+	Chip   string
+	Sensor string
+	Value  float64
+	Polls  int  // Number of consecutive polls with this value
+	Stuck  bool // false when a previously stuck sensor changes again
+}
+
+// DefaultStuckPolls are reasonable thresholds for sensor types that are expected to vary; voltages, for instance, legitimately don't.
+var DefaultStuckPolls = map[LmSensorType]int{
+	Temperature: 60,
+	Fan:         60,
+	Power:       60,
+}
+
+type stuckState struct {
+	value   float64
+	time    time.Time
+	count   int
+	flagged bool
+}
+
+type stuckDetector struct {
+	polls map[LmSensorType]int
+	fn    func(StuckEvent)
+	state map[string]*stuckState
+}
+
+// WithStuckDetection flags sensors whose value hasn't changed at all for the given number of polls, per sensor type.
+// Types not in polls aren't checked; pass [DefaultStuckPolls] if in doubt.
+func WithStuckDetection(polls map[LmSensorType]int, fn func(StuckEvent)) WatcherOption {
+	return func(w *Watcher) {
+		w.stuck = &stuckDetector{polls: polls, fn: fn, state: map[string]*stuckState{}}
+	}
+}
+
+func (d *stuckDetector) observe(sys *System) {
+	seen := make(map[string]*stuckState, len(d.state))
+	for _, chip := range sys.Chips {
+		for name, sensor := range chip.Sensors {
+			limit, ok := d.polls[sensor.Type()]
+			if !ok {
+				continue
+			}
+			val, ok := valueOf(sensor)
+			if !ok {
+				continue
+			}
+			key := sensorKey(chip.ID, name)
+			st, ok := d.state[key]
+			at := TimeOf(sensor)
+			switch {
+			case ok && !at.IsZero() && at.Equal(st.time):
+				// Carried over from the last poll, see [WithChipInterval], so not a new reading.
+			case !ok:
+				st = &stuckState{value: val, count: 1}
+			case st.value == val:
+				st.count++
+			default:
+				if st.flagged {
+					d.fn(StuckEvent{chip.ID, name, val, st.count, false})
+				}
+				st = &stuckState{value: val, count: 1}
+			}
+			st.time = at
+			if !st.flagged && st.count >= limit {
+				st.flagged = true
+				d.fn(StuckEvent{chip.ID, name, val, st.count, true})
+			}
+			seen[key] = st
+		}
+	}
+	d.state = seen
+}
@@ -0,0 +1,53 @@
+package lmsensors
+
+import (
+	"testing"
+	"time"
+)
+
+func tempSystem(val float64) *System {
+	return &System{Chips: map[string]*Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]Sensor{
+			"Tctl":  &TempSensor{baseSensor: baseSensor{Name: "Tctl", Value: val}, TempType: Unknown, TempTypeRaw: -1},
+			"Vcore": &VoltageSensor{baseSensor{Name: "Vcore", Value: 1.0}},
+		}},
+	}}
+}
+
+func TestStuckDetection(t *testing.T) {
+	var events []StuckEvent
+	w := NewWatcher(0, WithStuckDetection(map[LmSensorType]int{Temperature: 3}, func(e StuckEvent) {
+		events = append(events, e)
+	}))
+
+	for _, v := range []float64{40, 41, 41, 41, 41, 42} {
+		w.observe(tempSystem(v))
+	}
+	if len(events) != 2 {
+		t.Fatalf("wrong number of events: %v", events)
+	}
+	if !events[0].Stuck || events[0].Value != 41 || events[0].Polls != 3 || events[0].Sensor != "Tctl" {
+		t.Errorf("wrong stuck event: %v", events[0])
+	}
+	if events[1].Stuck || events[1].Value != 42 || events[1].Polls != 4 {
+		t.Errorf("wrong unstuck event: %v", events[1])
+	}
+}
+
+func TestStuckDetectionCarried(t *testing.T) {
+	var events []StuckEvent
+	w := NewWatcher(0, WithStuckDetection(map[LmSensorType]int{Temperature: 3}, func(e StuckEvent) {
+		events = append(events, e)
+	}))
+
+	// The same reading carried over between polls of a slow chip isn't stuck.
+	at := time.Now()
+	for range 5 {
+		sys := tempSystem(41)
+		sys.Chips["k10temp-pci-00c3"].Sensors["Tctl"].(*TempSensor).Time = at
+		w.observe(sys)
+	}
+	if len(events) != 0 {
+		t.Errorf("carried reading flagged: %v", events)
+	}
+}
@@ -16,6 +16,25 @@ func (s SubFeature) Error() string {
 	return "failed when getting subfeature: " + s.String()
 }
 
+// Scaling is the fixed factor between the kernel's sysfs representation of a subfeature and its value in natural units, eg 1000 for millivolts.
+// https://github.com/lm-sensors/lm-sensors/blob/42f240d2a457834bcbdf4dc8b57237f97b5f5854/lib/sysfs.c#L175
+func (s SubFeature) Scaling() float64 {
+	switch s & 0xff80 {
+	case IN_INPUT, TEMP_INPUT, CURR_INPUT, HUMIDITY_INPUT:
+		return 1000
+	case FAN_INPUT:
+		return 1
+	case POWER_AVERAGE, ENERGY_INPUT:
+		return 1000000
+	}
+	switch s {
+	case VID, TEMP_OFFSET:
+		return 1000
+	default:
+		return 1
+	}
+}
+
 const (
 	IN_INPUT       SubFeature = C.SENSORS_SUBFEATURE_IN_INPUT
 	IN_MIN         SubFeature = C.SENSORS_SUBFEATURE_IN_MIN
@@ -0,0 +1,56 @@
+package lmsensors
+
+import "sort"
+
+// Summary is the handful of headline values a status bar wants.
+type Summary struct {
+	CPUTemp    float64 // Hottest CPU temperature, if HasCPUTemp
+	HasCPUTemp bool
+	GPUTemp    float64 // Hottest GPU temperature, if HasGPUTemp
+	HasGPUTemp bool
+	Fans       int      // Number of fan sensors
+	Alarms     []string // chip/sensor of every sensor in alarm, sorted
+
+	Throttled      bool   // Whether the CPUs are being thermally throttled, with [WithThrottleDetection]
+	ThrottleSensor string // chip/sensor of the temperature limiting them, if Throttled
+}
+
+// Summary works out the headline values, using [Chip.Kind] to find the CPUs and GPUs.
+func (sys *System) Summary() Summary {
+	var sum Summary
+	for _, chip := range sys.Chips {
+		kind := chip.Kind()
+		for name, s := range chip.Sensors {
+			if s == nil {
+				continue
+			}
+			if s.Alarm() {
+				sum.Alarms = append(sum.Alarms, sensorKey(chip.ID, name))
+			}
+			switch s.Type() {
+			case Fan:
+				sum.Fans++
+			case Temperature:
+				if !Valid(s) {
+					continue
+				}
+				v := s.Reading()
+				switch {
+				case kind == KindCPU:
+					if !sum.HasCPUTemp || v > sum.CPUTemp {
+						sum.CPUTemp, sum.HasCPUTemp = v, true
+					}
+				case kind == KindGPU:
+					if !sum.HasGPUTemp || v > sum.GPUTemp {
+						sum.GPUTemp, sum.HasGPUTemp = v, true
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(sum.Alarms)
+	if t := sys.Throttling; t != nil {
+		sum.Throttled, sum.ThrottleSensor = true, t.Sensor
+	}
+	return sum
+}
@@ -0,0 +1,63 @@
+package lmsensors
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	sys := &System{Chips: map[string]*Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Type: "k10temp", Sensors: map[string]Sensor{
+			"Tctl":  &TempSensor{baseSensor: baseSensor{Name: "Tctl", Value: 61.5}, TempType: Unknown},
+			"Tccd1": &TempSensor{baseSensor: baseSensor{Name: "Tccd1", Value: 55}, TempType: Unknown},
+		}},
+		"amdgpu-pci-0b00": {ID: "amdgpu-pci-0b00", Type: "amdgpu", Sensors: map[string]Sensor{
+			"edge": &TempSensor{baseSensor: baseSensor{Name: "edge", Value: 48}, TempType: Unknown},
+			"fan1": &FanSensor{baseSensor: baseSensor{Name: "fan1", Value: 0, alarm: true}},
+		}},
+		"nct6798-isa-0290": {ID: "nct6798-isa-0290", Type: "nct6798", Sensors: map[string]Sensor{
+			"SYSTIN":    &TempSensor{baseSensor: baseSensor{Name: "SYSTIN", Value: 90}, TempType: Unknown},
+			"fan2":      &FanSensor{baseSensor: baseSensor{Name: "fan2", Value: 800}},
+			"intrusion": &IntrusionSensor{Name: "intrusion", alarm: true},
+		}},
+	}}
+
+	sum := sys.Summary()
+	if !sum.HasCPUTemp || sum.CPUTemp != 61.5 {
+		t.Errorf("wrong CPU temp: %+v", sum)
+	}
+	if !sum.HasGPUTemp || sum.GPUTemp != 48 {
+		t.Errorf("wrong GPU temp: %+v", sum)
+	}
+	if sum.Fans != 2 {
+		t.Errorf("wrong fan count: %+v", sum)
+	}
+	slices.Sort(sum.Alarms)
+	if !slices.Equal(sum.Alarms, []string{"amdgpu-pci-0b00/fan1", "nct6798-isa-0290/intrusion"}) {
+		t.Errorf("wrong alarms: %+v", sum)
+	}
+}
+
+func TestChipKind(t *testing.T) {
+	cases := []struct {
+		chip Chip
+		kind ChipKind
+	}{
+		{Chip{Type: "k10temp", Bus: "pci"}, KindCPU},
+		{Chip{Type: "amdgpu", Bus: "pci"}, KindGPU},
+		{Chip{Type: "nct6798", Bus: "isa"}, KindMotherboard},
+		{Chip{Type: "it8792", Bus: "isa"}, KindMotherboard},
+		{Chip{Type: "somethingnew", Bus: "isa"}, KindMotherboard},
+		{Chip{Type: "nvme", Bus: "pci"}, KindDrive},
+		{Chip{Type: "drivetemp", Bus: "scsi-0"}, KindDrive},
+		{Chip{Type: "corsairpsu", Bus: "hid-3"}, KindPSU},
+		{Chip{Type: "jc42", Bus: "i2c-0"}, KindMemory},
+		{Chip{Type: "BAT0", Bus: "acpi"}, KindBattery},
+		{Chip{Type: "mystery", Bus: "virtual"}, KindUnknown},
+	}
+	for _, c := range cases {
+		if k := c.chip.Kind(); k != c.kind {
+			t.Errorf("%s on %s: got %s, want %s", c.chip.Type, c.chip.Bus, k, c.kind)
+		}
+	}
+}
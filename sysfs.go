@@ -0,0 +1,168 @@
+package lmsensors
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SysfsRoot is where sysfs is read from by everything in this package that reads sysfs directly, rather than through libsensors.
+// Agents running in a container can bind-mount the host's /sys somewhere (eg -v /sys:/host/sys:ro) and point this at it.
+// Note that libsensors itself always scans /sys, so [Init], [Chips] and [Get] only see the host's chips if it's mounted there.
+// It can also be pointed at a fixture tree for testing.
+var SysfsRoot = "/sys"
+
+func hwmonDir() string {
+	return filepath.Join(SysfsRoot, "class", "hwmon")
+}
+
+// DetectSysfsRoot sets [SysfsRoot] to a conventional host sysfs mount point when running in a container and one exists.
+// It returns the root in use.
+func DetectSysfsRoot() string {
+	if !InContainer() {
+		return SysfsRoot
+	}
+	for _, cand := range []string{"/host/sys", "/hostfs/sys", "/rootfs/sys"} {
+		if _, err := os.Stat(filepath.Join(cand, "class", "hwmon")); err == nil {
+			SysfsRoot = cand
+			break
+		}
+	}
+	return SysfsRoot
+}
+
+// HwmonDevice is a hwmon class device found by walking sysfs directly, without libsensors.
+type HwmonDevice struct {
+	Name string // Contents of the name attribute, eg "k10temp"
+	Path string // eg /sys/class/hwmon/hwmon2
+
+	attrDir string
+}
+
+// HwmonDevices lists all the hwmon class devices under [SysfsRoot].
+func HwmonDevices() ([]HwmonDevice, error) {
+	dir := hwmonDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	devs := make([]HwmonDevice, 0, len(entries))
+	for _, e := range entries {
+		dev := HwmonDevice{Path: filepath.Join(dir, e.Name())}
+		// Like libsensors, fall back to the attributes on the parent device for old drivers.
+		for _, d := range []string{dev.Path, filepath.Join(dev.Path, "device")} {
+			p, err := os.ReadFile(filepath.Join(d, "name"))
+			if err != nil {
+				continue
+			}
+			dev.Name = strings.TrimSpace(string(p))
+			dev.attrDir = d
+			break
+		}
+		if dev.attrDir == "" {
+			continue
+		}
+		devs = append(devs, dev)
+	}
+	return devs, nil
+}
+
+// Attributes lists the names of the sensor attributes of the device, eg "temp1_input" or "pwm1".
+func (d HwmonDevice) Attributes() ([]string, error) {
+	return attributes(d.attrDir)
+}
+
+// attrRe matches hwmon channel attributes, as opposed to things like name and uevent.
+var attrRe = regexp.MustCompile(`^[a-z]+[0-9]+(_[a-z0-9_]+)?$`)
+
+func attributes(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var attrs []string
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !attrRe.MatchString(e.Name()) {
+			continue
+		}
+		attrs = append(attrs, e.Name())
+	}
+	sort.Strings(attrs)
+	return attrs, nil
+}
+
+// ReadAttribute returns the raw, unscaled contents of an attribute.
+func (d HwmonDevice) ReadAttribute(name string) (string, error) {
+	p, err := os.ReadFile(filepath.Join(d.attrDir, name))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(p)), nil
+}
+
+func readSysfsString(path string) string {
+	p, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(p))
+}
+
+// machineInfo is what's read about the machine, rather than its chips, which is the same for every chip.
+type machineInfo struct {
+	root                   string // The SysfsRoot it was read from
+	boardVendor, boardName string
+	adapters               map[int]*AdapterDevice // By i2c bus number, nil for those that couldn't be resolved; filled in as chips need them
+}
+
+var (
+	machineMu sync.Mutex
+	machine   *machineInfo // Read on first use after every Init, rather than for every chip on every Get
+)
+
+// machineLocked is the machine info, reading it if it hasn't been since the last Init, or SysfsRoot has moved. machineMu must be held.
+func machineLocked() *machineInfo {
+	if machine == nil || machine.root != SysfsRoot {
+		dir := filepath.Join(SysfsRoot, "class", "dmi", "id")
+		machine = &machineInfo{
+			root:        SysfsRoot,
+			boardVendor: readSysfsString(filepath.Join(dir, "board_vendor")),
+			boardName:   readSysfsString(filepath.Join(dir, "board_name")),
+			adapters:    map[int]*AdapterDevice{},
+		}
+	}
+	return machine
+}
+
+// forgetMachine makes the machine info be read again, next time it's needed.
+func forgetMachine() {
+	machineMu.Lock()
+	defer machineMu.Unlock()
+	machine = nil
+}
+
+func dmiBoard() (vendor, name string) {
+	machineMu.Lock()
+	defer machineMu.Unlock()
+	m := machineLocked()
+	return m.boardVendor, m.boardName
+}
+
+// acpiPath finds the ACPI namespace path of a hwmon device's parent.
+// ACPI devices (eg a thermal zone's LNXTHERM) have it directly, others through their firmware_node.
+func acpiPath(hwmonPath string) string {
+	dev := filepath.Join(hwmonPath, "device")
+	for _, p := range []string{
+		filepath.Join(dev, "path"),
+		filepath.Join(dev, "firmware_node", "path"),
+		filepath.Join(dev, "device", "path"),
+	} {
+		if s := readSysfsString(p); s != "" {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,309 @@
+package lmsensors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors/bus"
+	"github.com/mt-inside/go-lmsensors/chipname"
+	sf "github.com/mt-inside/go-lmsensors/subfeature"
+)
+
+// GetSysfs reads all the sensors straight from sysfs under [SysfsRoot], without libsensors.
+// It follows libsensors' naming of chips and features, and the kernel's fixed scaling, but there's no config file, so no labels, computes or ignores beyond the drivers' own.
+// It doesn't need [Init], and works on fixture trees, which is what the integration tests use it for.
+// To poll it often, see [SetSysfsFDCache].
+func GetSysfs() (*System, error) {
+	start := time.Now()
+	devs, err := HwmonDevices()
+	if err != nil {
+		return nil, err
+	}
+	if c := fdCache.Load(); c != nil {
+		c.prune(devs)
+	}
+	sys := &System{Chips: map[string]*Chip{}, Time: start}
+	for _, dev := range devs {
+		chip, err := sysfsChip(dev)
+		if err != nil {
+			logger.Debug("skipping hwmon device", "path", dev.Path, "error", err)
+			continue
+		}
+		sys.Chips[chip.ID] = chip
+	}
+	sys.Stats.Duration = time.Since(start)
+	return sys, nil
+}
+
+// sysfsBus works out a chip's bus and address from its parent device, like libsensors' sensors_read_one_sysfs_chip().
+func sysfsBus(hwmonPath string) (bus.Type, int, int, error) {
+	dev, err := filepath.EvalSymlinks(filepath.Join(hwmonPath, "device"))
+	if err != nil {
+		return bus.VIRTUAL, 0, 0, nil
+	}
+	subsys, err := os.Readlink(filepath.Join(dev, "subsystem"))
+	if err != nil {
+		return bus.VIRTUAL, 0, 0, nil
+	}
+	name := filepath.Base(dev)
+	var nr, addr, domain, pbus, slot, fn int
+	switch filepath.Base(subsys) {
+	case "i2c":
+		if _, err := fmt.Sscanf(name, "%d-%x", &nr, &addr); err != nil {
+			return 0, 0, 0, fmt.Errorf("bad i2c device %s", name)
+		}
+		return bus.I2C, nr, addr, nil
+	case "platform", "of_platform":
+		// eg it87.656; the number is the ISA address, in decimal
+		if _, err := fmt.Sscanf(filepath.Ext(name), ".%d", &addr); err != nil {
+			addr = 0
+		}
+		return bus.ISA, 0, addr, nil
+	case "pci":
+		if _, err := fmt.Sscanf(name, "%x:%x:%x.%x", &domain, &pbus, &slot, &fn); err != nil {
+			return 0, 0, 0, fmt.Errorf("bad pci device %s", name)
+		}
+		return bus.PCI, 0, domain<<16 | pbus<<8 | slot<<3 | fn, nil
+	case "spi":
+		if _, err := fmt.Sscanf(name, "spi%d.%d", &nr, &addr); err != nil {
+			return 0, 0, 0, fmt.Errorf("bad spi device %s", name)
+		}
+		return bus.SPI, nr, addr, nil
+	case "hid":
+		var vendor, product int
+		if _, err := fmt.Sscanf(name, "%x:%x:%x.%x", &nr, &vendor, &product, &addr); err != nil {
+			return 0, 0, 0, fmt.Errorf("bad hid device %s", name)
+		}
+		return bus.HID, nr, addr, nil
+	case "scsi":
+		var channel, id int
+		if _, err := fmt.Sscanf(name, "%d:%d:%d:%x", &nr, &channel, &id, &addr); err != nil {
+			return 0, 0, 0, fmt.Errorf("bad scsi device %s", name)
+		}
+		return bus.SCSI, nr, addr, nil
+	case "acpi":
+		return bus.ACPI, 0, 0, nil // Assumed unique, as libsensors does
+	case "mdio_bus":
+		return bus.MDIO, 0, 0, nil
+	}
+	return bus.VIRTUAL, 0, 0, nil
+}
+
+// adapterNames are libsensors' names for the buses that aren't i2c.
+var adapterNames = map[bus.Type]string{
+	bus.ISA:     "ISA adapter",
+	bus.PCI:     "PCI adapter",
+	bus.SPI:     "SPI adapter",
+	bus.VIRTUAL: "Virtual device",
+	bus.ACPI:    "ACPI interface",
+	bus.HID:     "HID adapter",
+	bus.MDIO:    "MDIO adapter",
+	bus.SCSI:    "SCSI adapter",
+}
+
+func sysfsAdapter(t bus.Type, nr int) string {
+	if t == bus.I2C {
+		return readSysfsString(filepath.Join(SysfsRoot, "class", "i2c-adapter", "i2c-"+strconv.Itoa(nr), "name"))
+	}
+	return adapterNames[t]
+}
+
+// sysfsChipName is a chip's ID, as libsensors would name it, and its bus' name, eg "k10temp-pci-00c3" and "pci".
+func sysfsChipName(prefix string, t bus.Type, nr, addr int) (string, string) {
+	name := chipname.Name{Prefix: prefix, Bus: t, BusNr: chipname.Any, Addr: addr}
+	busName := strings.ToLower(t.String())
+	if t == bus.I2C || t == bus.SPI || t == bus.HID || t == bus.SCSI {
+		name.BusNr = nr
+		busName += "-" + strconv.Itoa(nr)
+	}
+	return name.String(), busName
+}
+
+func sysfsChip(dev HwmonDevice) (*Chip, error) {
+	start := time.Now()
+	t, nr, addr, err := sysfsBus(dev.Path)
+	if err != nil {
+		return nil, err
+	}
+	id, busName := sysfsChipName(dev.Name, t, nr, addr)
+	ch := &Chip{
+		ID:      id,
+		Type:    dev.Name,
+		Bus:     busName,
+		Address: id[len(dev.Name)+len(busName)+2:],
+		Adapter: sysfsAdapter(t, nr),
+		Path:    dev.attrDir,
+		Sensors: map[string]Sensor{},
+	}
+	ch.BoardVendor, ch.BoardName = dmiBoard()
+	ch.ACPIPath = acpiPath(dev.Path)
+	if t == bus.I2C {
+		ch.AdapterDevice = adapterDevice(nr)
+	}
+
+	chans, err := sysfsChannels(dev.attrDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range chans {
+		if s := c.sensor(dev.Name); s != nil {
+			switch s := s.(type) {
+			case *FanSensor:
+				s.setDuty(readFanDuty(dev.attrDir, c.name))
+			case *IntrusionSensor:
+				s.feature = featureRef{ch.ID, ch.Type, dev.attrDir, c.name}
+			}
+			if b, ok := s.(interface{ base() *baseSensor }); ok {
+				b.base().feature = featureRef{ch.ID, ch.Type, dev.attrDir, c.name}
+			}
+			ch.Sensors[s.GetName()] = s
+		}
+	}
+	applyQuirk(ch)
+	ch.Stats.Duration = time.Since(start)
+	return ch, nil
+}
+
+// channelRe splits an attribute into the channel's type, its number, and the subfeature, eg "temp", "1", "input".
+var channelRe = regexp.MustCompile(`^(in|fan|temp|curr|power|energy|humidity|intrusion)([0-9]+)_([a-z0-9_]+)$`)
+
+// sysfsChannel is the attributes of one channel, eg temp1, read and with the kernel's fixed scaling applied.
+type sysfsChannel struct {
+	kind  string // eg temp
+	name  string // eg temp1
+	label string
+	attrs map[string]float64 // eg input, crit
+	read  time.Time          // When the last attribute was read
+}
+
+func sysfsChannels(dir string) ([]*sysfsChannel, error) {
+	attrs, err := attributes(dir)
+	if err != nil {
+		return nil, err
+	}
+	cache := fdCache.Load()
+	byName := map[string]*sysfsChannel{}
+	for _, attr := range attrs {
+		m := channelRe.FindStringSubmatch(attr)
+		if m == nil {
+			continue
+		}
+		name := m[1] + m[2]
+		c, ok := byName[name]
+		if !ok {
+			c = &sysfsChannel{kind: m[1], name: name, attrs: map[string]float64{}}
+			byName[name] = c
+		}
+		raw := readAttr(cache, filepath.Join(dir, attr))
+		if m[3] == "label" {
+			c.label = raw
+			continue
+		}
+		c.read = time.Now()
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			continue
+		}
+		c.attrs[m[3]] = v / sysfsScale(m[1], m[3])
+	}
+	chans := make([]*sysfsChannel, 0, len(byName))
+	for _, c := range byName {
+		chans = append(chans, c)
+	}
+	sort.Slice(chans, func(i, j int) bool { return chans[i].name < chans[j].name })
+	return chans, nil
+}
+
+// sysfsScale is the kernel's fixed scaling of an attribute, from Documentation/hwmon/sysfs-interface.rst.
+func sysfsScale(kind, sub string) float64 {
+	switch sub {
+	case "type", "alarm", "beep", "fault", "enable", "pulses", "div":
+		return 1
+	}
+	switch kind {
+	case "in", "temp", "curr", "humidity":
+		return 1e3
+	case "power", "energy":
+		return 1e6
+	}
+	return 1
+}
+
+var sysfsFeatureTypes = map[string]LmSensorType{
+	"in":        Voltage,
+	"fan":       Fan,
+	"temp":      Temperature,
+	"curr":      Current,
+	"power":     Power,
+	"energy":    Energy,
+	"humidity":  Humidity,
+	"intrusion": Intrusion,
+}
+
+// sysfsInputs are the attributes holding each type of channel's reading, in order of preference, like [inputSubFeatures].
+var sysfsInputs = map[string][]string{
+	"power":     {"input", "average"},
+	"intrusion": {"alarm"},
+}
+
+// sensor makes the [Sensor] for a channel, or nil if it has no reading.
+func (c *sysfsChannel) sensor(prefix string) Sensor {
+	inputs, ok := sysfsInputs[c.kind]
+	if !ok {
+		inputs = []string{"input"}
+	}
+	var val float64
+	var input string
+	for _, in := range inputs {
+		if v, ok := c.attrs[in]; ok {
+			val, input = v, in
+			break
+		}
+	}
+	if input == "" {
+		return nil
+	}
+	name := c.label
+	if name == "" {
+		name = c.name
+	}
+	typ := sysfsFeatureTypes[c.kind]
+	base := baseSensor{Name: name, Value: sanitize(typ, val), Time: c.read}
+	get := func(sub sf.SubFeature) (float64, error) {
+		attr, ok := sysfsSubFeatures[sub]
+		if !ok {
+			return 0, sub
+		}
+		v, ok := c.attrs[attr]
+		if !ok {
+			return 0, sub
+		}
+		return v, nil
+	}
+	sub := inputSubFeatures[typ][0]
+	if input == "average" {
+		sub = sf.POWER_AVERAGE
+	}
+	return newSensor(prefix, typ, sub, base, get)
+}
+
+// sysfsSubFeatures are the extra subfeatures [newSensor] reads, by attribute suffix.
+var sysfsSubFeatures = map[sf.SubFeature]string{
+	sf.TEMP_TYPE:      "type",
+	sf.TEMP_CRIT:      "crit",
+	sf.TEMP_EMERGENCY: "emergency",
+	sf.INTRUSION_BEEP: "beep",
+	sf.IN_BEEP:        "beep",
+	sf.FAN_BEEP:       "beep",
+	sf.TEMP_BEEP:      "beep",
+	sf.CURR_BEEP:      "beep",
+	sf.FAN_MIN:        "min",
+	sf.FAN_ALARM:      "alarm",
+	sf.FAN_FAULT:      "fault",
+}
@@ -0,0 +1,297 @@
+// Package testbench runs stress tests: it records the sensors while idle, while a load command runs, and while they cool down after, and reports how each responded, as reviewers and system builders otherwise do by hand with a stopwatch and watch sensors.
+package testbench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Bench is a stress test.
+type Bench struct {
+	Command  []string      // The load, eg {"stress-ng", "--cpu", "0"}, which is run until it exits, or for Duration
+	Duration time.Duration // If it's set, how long the command runs before it's killed
+	Before   time.Duration // How long to record for while idle beforehand; defaults to a minute
+	After    time.Duration // How long to record for while it cools down; defaults to five minutes
+	Interval time.Duration // How often to read the sensors; defaults to a second
+
+	Sensors lmsensors.Selector // Which sensors to report; defaults to the temperatures and fans
+
+	Stdout, Stderr io.Writer // The command's; default to discarding it
+
+	// Get reads the sensors; it defaults to [lmsensors.GetContext], for which [lmsensors.Init] must have been called.
+	Get func(context.Context) (*lmsensors.System, error)
+	// OnPoll, if set, is called with every reading, eg to show progress.
+	OnPoll func(Phase, *lmsensors.System)
+}
+
+// Phase is a part of a stress test.
+type Phase int
+
+const (
+	Idle Phase = iota
+	Loaded
+	Cooling
+)
+
+func (p Phase) String() string {
+	return [...]string{"idle", "loaded", "cooling"}[p]
+}
+
+type sample struct {
+	time  time.Time
+	phase Phase
+	value float64
+}
+
+// Run runs the test, returning its report even if the command fails, in which case the report has why.
+// Cancelling ctx stops the command, and the test, where it is, and the report is of what was recorded, along with ctx's error.
+func (b *Bench) Run(ctx context.Context) (*Report, error) {
+	if len(b.Command) == 0 {
+		return nil, errors.New("no load command")
+	}
+	get := b.Get
+	if get == nil {
+		get = func(ctx context.Context) (*lmsensors.System, error) { return lmsensors.GetContext(ctx) }
+	}
+	interval := orDefault(b.Interval, time.Second)
+
+	rep := &Report{Command: b.Command, Started: time.Now()}
+	rep.Host, _ = os.Hostname()
+	samples := map[string][]sample{}
+	units := map[string]string{}
+	var throttling *lmsensors.Throttling
+	throttles := lmsensors.NewWatcher(interval, lmsensors.WithThrottleDetection(func(e lmsensors.ThrottleEvent) {
+		if !e.Throttled {
+			rep.Throttles = append(rep.Throttles, e)
+		}
+	}))
+	poll := func(phase Phase) {
+		sys, err := get(ctx)
+		if err != nil {
+			rep.Errors = append(rep.Errors, err.Error())
+		}
+		if sys == nil {
+			return
+		}
+		at := time.Now()
+		if sys.Time.IsZero() {
+			sys.Time = at
+		}
+		throttles.Observe(sys)
+		throttling = sys.Throttling
+		for _, chip := range sys.Chips {
+			for name, s := range chip.Sensors {
+				if !b.selects(chip.ID, name, s) {
+					continue
+				}
+				v := s.Reading()
+				if math.IsNaN(v) {
+					continue
+				}
+				key := chip.ID + "/" + name
+				samples[key] = append(samples[key], sample{at, phase, v})
+				units[key] = s.Unit()
+			}
+		}
+		if b.OnPoll != nil {
+			b.OnPoll(phase, sys)
+		}
+	}
+	record := func(phase Phase, until <-chan struct{}) {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		poll(phase)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-until:
+				return
+			case <-t.C:
+				poll(phase)
+			}
+		}
+	}
+	after := func(d time.Duration) <-chan struct{} {
+		ch := make(chan struct{})
+		time.AfterFunc(d, func() { close(ch) })
+		return ch
+	}
+
+	finish := func() (*Report, error) {
+		rep.Ended = time.Now()
+		if throttling != nil {
+			rep.Throttles = append(rep.Throttles, lmsensors.ThrottleEvent{Throttling: *throttling, Duration: rep.Ended.Sub(throttling.Started), Throttled: true})
+		}
+		for key, ss := range samples {
+			if r, ok := analyse(ss, rep.LoadStarted, rep.LoadEnded); ok {
+				r.Sensor, r.Unit = key, units[key]
+				rep.Sensors = append(rep.Sensors, r)
+			}
+		}
+		sort.Slice(rep.Sensors, func(i, j int) bool { return rep.Sensors[i].Sensor < rep.Sensors[j].Sensor })
+		return rep, ctx.Err()
+	}
+
+	record(Idle, after(orDefault(b.Before, time.Minute)))
+	if ctx.Err() != nil {
+		return finish()
+	}
+
+	cmdCtx, cancel := ctx, context.CancelFunc(func() {})
+	if b.Duration > 0 {
+		cmdCtx, cancel = context.WithTimeout(ctx, b.Duration)
+	}
+	defer cancel()
+	cmd := exec.CommandContext(cmdCtx, b.Command[0], b.Command[1:]...)
+	cmd.Stdout, cmd.Stderr = b.Stdout, b.Stderr
+	rep.LoadStarted = time.Now()
+	if err := cmd.Start(); err != nil {
+		rep.CommandError = err.Error()
+		rep.LoadEnded = rep.LoadStarted
+		return finish()
+	}
+	done := make(chan struct{})
+	var cmdErr error
+	go func() {
+		cmdErr = cmd.Wait()
+		close(done)
+	}()
+	record(Loaded, done)
+	<-done
+	rep.LoadEnded = time.Now()
+	// Being killed at the end of Duration is how it's meant to end.
+	if cmdErr != nil && !(b.Duration > 0 && cmdCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil) {
+		rep.CommandError = cmdErr.Error()
+	}
+	if ctx.Err() != nil {
+		return finish()
+	}
+
+	record(Cooling, after(orDefault(b.After, 5*time.Minute)))
+	return finish()
+}
+
+func (b *Bench) selects(chip, name string, s lmsensors.Sensor) bool {
+	if b.Sensors != "" {
+		return b.Sensors.Match(chip, name)
+	}
+	return s.Type() == lmsensors.Temperature || s.Type() == lmsensors.Fan
+}
+
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+// Report is how a stress test went. It marshals to JSON, for comparing runs.
+type Report struct {
+	Host                            string
+	Command                         []string
+	Started, LoadStarted, LoadEnded time.Time
+	Ended                           time.Time
+	CommandError                    string   `json:",omitempty"` // If the command failed
+	Errors                          []string `json:",omitempty"` // From reading the sensors
+	Sensors                         []SensorReport
+	Throttles                       []lmsensors.ThrottleEvent `json:",omitempty"` // The spells of thermal throttling, as ended; one still underway at the end is Throttled
+}
+
+// SensorReport is how a sensor responded to the load.
+type SensorReport struct {
+	Sensor string // chip/sensor
+	Unit   string
+
+	Idle   float64       // The average before the load
+	Peak   float64       // The highest while loaded, or cooling down, which for a slow sensor might be after
+	PeakAt time.Duration // After the load started
+	Loaded float64       // The average over the last fifth of the load, when it's settled, if it's going to
+	Final  float64       // The average over the last fifth of cooling down
+	Delta  float64       // Peak less Idle
+
+	// The times taken to go 63% of the way from Idle to Peak, and back from the end of the load to Final, which for a sensor that heats and cools exponentially are its time constants; zero if it didn't rise.
+	HeatUp, CoolDown time.Duration
+}
+
+// Write writes a summary of the report, a line per sensor.
+func (r *Report) Write(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %s, loaded for %s\n", r.Host, strings.Join(r.Command, " "), r.LoadEnded.Sub(r.LoadStarted).Round(time.Second))
+	if r.CommandError != "" {
+		fmt.Fprintf(&sb, "command failed: %s\n", r.CommandError)
+	}
+	for _, s := range r.Sensors {
+		fmt.Fprintf(&sb, "%s: %.1f → %.1f%s (%+.1f) after %s, settling at %.1f; heats in %s, cools in %s to %.1f\n",
+			s.Sensor, s.Idle, s.Peak, s.Unit, s.Delta, s.PeakAt.Round(time.Second), s.Loaded, s.HeatUp.Round(time.Second), s.CoolDown.Round(time.Second), s.Final)
+	}
+	for _, t := range r.Throttles {
+		fmt.Fprintf(&sb, "throttled: %s, by %s, %s in, for %s", strings.Join(t.CPUs, " "), t.Cause, t.Started.Sub(r.Started).Round(time.Second), t.Duration.Round(time.Second))
+		if t.Sensor != "" {
+			fmt.Fprintf(&sb, ", at %s %.1f°C", t.Sensor, t.Temp)
+		}
+		sb.WriteString("\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// analyse sums up a sensor's samples; it needs some from every phase.
+func analyse(ss []sample, loadStarted, loadEnded time.Time) (SensorReport, bool) {
+	var phases [3][]sample
+	for _, s := range ss {
+		phases[s.phase] = append(phases[s.phase], s)
+	}
+	if len(phases[Idle]) == 0 || len(phases[Loaded]) == 0 || len(phases[Cooling]) == 0 {
+		return SensorReport{}, false
+	}
+	r := SensorReport{Idle: mean(phases[Idle]), Loaded: mean(lastFifth(phases[Loaded])), Final: mean(lastFifth(phases[Cooling]))}
+	r.Peak = phases[Loaded][0].value
+	for _, s := range append(phases[Loaded], phases[Cooling]...) {
+		if s.value > r.Peak {
+			r.Peak, r.PeakAt = s.value, s.time.Sub(loadStarted)
+		}
+	}
+	r.Delta = r.Peak - r.Idle
+	if r.Delta <= 0 {
+		return r, true
+	}
+	for _, s := range append(phases[Loaded], phases[Cooling]...) {
+		if s.value >= r.Idle+0.632*r.Delta {
+			r.HeatUp = s.time.Sub(loadStarted)
+			break
+		}
+	}
+	end := phases[Loaded][len(phases[Loaded])-1].value
+	if drop := end - r.Final; drop > 0 {
+		for _, s := range phases[Cooling] {
+			if s.value <= end-0.632*drop {
+				r.CoolDown = s.time.Sub(loadEnded)
+				break
+			}
+		}
+	}
+	return r, true
+}
+
+func mean(ss []sample) float64 {
+	var sum float64
+	for _, s := range ss {
+		sum += s.value
+	}
+	return sum / float64(len(ss))
+}
+
+func lastFifth(ss []sample) []sample {
+	return ss[len(ss)-max(1, len(ss)/5):]
+}
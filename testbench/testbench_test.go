@@ -0,0 +1,129 @@
+package testbench
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// heater simulates a CPU that heats towards 80 °C while loaded and cools towards 40 °C otherwise, a little each poll, with a fan that's constant.
+type heater struct {
+	temp   float64
+	phase  Phase
+	polls  int
+	onPoll func(int)
+}
+
+func (h *heater) get(context.Context) (*lmsensors.System, error) {
+	target := 40.0
+	if h.phase == Loaded {
+		target = 80
+	}
+	h.temp += (target - h.temp) * 0.2
+	h.polls++
+	if h.onPoll != nil {
+		h.onPoll(h.polls)
+	}
+	tctl := &lmsensors.TempSensor{}
+	tctl.Name, tctl.Value = "Tctl", h.temp
+	fan := &lmsensors.FanSensor{}
+	fan.Name, fan.Value = "fan1", 1000
+	in0 := &lmsensors.VoltageSensor{}
+	in0.Name, in0.Value = "in0", 1.2
+	return &lmsensors.System{Chips: map[string]*lmsensors.Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]lmsensors.Sensor{"Tctl": tctl}},
+		"nct6798-isa-0290": {ID: "nct6798-isa-0290", Sensors: map[string]lmsensors.Sensor{"fan1": fan, "in0": in0}},
+	}}, nil
+}
+
+func TestBench(t *testing.T) {
+	// A CPU that throttles once.
+	lmsensors.SysfsRoot = t.TempDir()
+	defer func() { lmsensors.SysfsRoot = "/sys" }()
+	dir := filepath.Join(lmsensors.SysfsRoot, "devices", "system", "cpu", "cpu0", "thermal_throttle")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	count := filepath.Join(dir, "package_throttle_count")
+	os.WriteFile(count, []byte("5\n"), 0o644)
+
+	h := &heater{temp: 40}
+	h.onPoll = func(n int) {
+		if h.phase == Loaded {
+			os.WriteFile(count, []byte("7\n"), 0o644)
+		}
+	}
+	b := &Bench{
+		Command:  []string{"sleep", "0.5"},
+		Before:   100 * time.Millisecond,
+		After:    500 * time.Millisecond,
+		Interval: 10 * time.Millisecond,
+		Get:      h.get,
+		OnPoll:   func(p Phase, _ *lmsensors.System) { h.phase = p },
+	}
+	rep, err := b.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.CommandError != "" || len(rep.Sensors) != 2 || len(rep.Errors) != 0 {
+		t.Fatalf("wrong report: %+v", rep)
+	}
+	tctl := rep.Sensors[0]
+	if tctl.Sensor != "k10temp-pci-00c3/Tctl" || tctl.Unit != "°C" || tctl.Idle != 40 || tctl.Peak < 79 || tctl.Delta < 39 || tctl.Loaded < 79 || tctl.Final > 41 {
+		t.Errorf("wrong levels: %+v", tctl)
+	}
+	if tctl.HeatUp <= 0 || tctl.HeatUp > 200*time.Millisecond || tctl.CoolDown <= 0 || tctl.CoolDown > 200*time.Millisecond {
+		t.Errorf("wrong time constants: %+v", tctl)
+	}
+	if fan := rep.Sensors[1]; fan.Delta != 0 || fan.HeatUp != 0 {
+		t.Errorf("wrong fan: %+v", fan)
+	}
+	if len(rep.Throttles) != 1 || rep.Throttles[0].Throttled || rep.Throttles[0].Cause != "thermal_throttle" || len(rep.Throttles[0].CPUs) != 1 || rep.Throttles[0].Started.Before(rep.LoadStarted) {
+		t.Errorf("wrong throttles: %+v", rep.Throttles)
+	}
+
+	var sb strings.Builder
+	if err := rep.Write(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "k10temp-pci-00c3/Tctl: 40.0 → ") || !strings.Contains(sb.String(), "throttled: cpu0, by thermal_throttle") {
+		t.Errorf("wrong summary:\n%s", sb.String())
+	}
+	if _, err := json.Marshal(rep); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBenchCommand(t *testing.T) {
+	h := &heater{temp: 40}
+	short := func(cmd ...string) *Bench {
+		return &Bench{Command: cmd, Before: 20 * time.Millisecond, After: 20 * time.Millisecond, Interval: 5 * time.Millisecond, Get: h.get}
+	}
+	// Being stopped after Duration isn't a failure, but failing is.
+	b := short("sleep", "10")
+	b.Duration = 50 * time.Millisecond
+	if rep, err := b.Run(context.Background()); err != nil || rep.CommandError != "" || rep.LoadEnded.Sub(rep.LoadStarted) > 5*time.Second {
+		t.Errorf("wrong report for a timed command: %v, %+v", err, rep)
+	}
+	if rep, err := short("false").Run(context.Background()); err != nil || rep.CommandError == "" {
+		t.Errorf("no error for a failed command: %v, %+v", err, rep)
+	}
+	if rep, err := short("/nonexistent").Run(context.Background()); err != nil || rep == nil || rep.CommandError == "" {
+		t.Errorf("no report of a missing command: %v, %+v", err, rep)
+	}
+
+	// Cancelling stops it where it is.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	b = short("sleep", "10")
+	b.After = 10 * time.Second
+	if rep, err := b.Run(ctx); err == nil || rep == nil || rep.LoadEnded.Sub(rep.LoadStarted) > 5*time.Second || rep.Ended.Sub(rep.LoadEnded) > 5*time.Second {
+		t.Errorf("wrong cancelled report: %v, %+v", err, rep)
+	}
+}
@@ -0,0 +1,152 @@
+package lmsensors
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Threshold is an alarm limit set here rather than in the hardware, eg to warn at 75 °C on a chip whose max is 100 °C, as hardware limits rarely match operational policy.
+type Threshold struct {
+	Name   string // Identifies it in [AlarmEvent]s and [AlarmState]s; defaults to the selector
+	Sensor Selector
+	Above  *float64
+	Below  *float64
+	For    time.Duration // How long the sensor must be out of range before the alarm's raised
+}
+
+func (th Threshold) outOfRange(v float64) bool {
+	return (th.Above != nil && v > *th.Above) || (th.Below != nil && v < *th.Below)
+}
+
+// name is the threshold's name, or failing that its sensor selector, which is at least recognisable.
+func (th Threshold) name() string {
+	if th.Name != "" {
+		return th.Name
+	}
+	return string(th.Sensor)
+}
+
+// thresholdState tracks one threshold against one sensor.
+type thresholdState struct {
+	since  time.Time // When it first went out of range
+	raised bool
+}
+
+type thresholdDetector struct {
+	thresholds []Threshold
+	fns        []func(AlarmEvent) // Each threshold's
+	state      map[string]*thresholdState
+}
+
+// WithThresholds raises alarms when sensors go outside the thresholds, calling fn, if not nil, when one is raised or cleared.
+// The raised ones are also in [Watcher.AlarmStates]. Sensors without a valid reading leave any alarm as it is.
+// It can be given more than once, eg for rules from different places, each with its own fn.
+func WithThresholds(fn func(AlarmEvent), thresholds ...Threshold) WatcherOption {
+	return func(w *Watcher) {
+		if w.thresholds == nil {
+			w.thresholds = &thresholdDetector{state: map[string]*thresholdState{}}
+		}
+		d := w.thresholds
+		for _, th := range thresholds {
+			d.thresholds = append(d.thresholds, th)
+			d.fns = append(d.fns, fn)
+		}
+	}
+}
+
+func (d *thresholdDetector) observe(sys *System) {
+	for _, chip := range sys.Chips {
+		for name, sensor := range chip.Sensors {
+			for i, th := range d.thresholds {
+				if !th.Sensor.Match(chip.ID, name) {
+					continue
+				}
+				fn := d.fns[i]
+				v, ok := valueOf(sensor)
+				if !ok {
+					continue
+				}
+				key := sensorKey(chip.ID, name) + "/" + strconv.Itoa(i)
+				st, ok := d.state[key]
+				if !th.outOfRange(v) {
+					if ok && st.raised && fn != nil {
+						fn(AlarmEvent{Chip: chip.ID, Sensor: name, Rule: th.name(), Value: v, Raised: false})
+					}
+					delete(d.state, key)
+					continue
+				}
+				if !ok {
+					st = &thresholdState{since: sys.Time}
+					d.state[key] = st
+				}
+				if !st.raised && sys.Time.Sub(st.since) >= th.For {
+					st.raised = true
+					if fn != nil {
+						fn(AlarmEvent{Chip: chip.ID, Sensor: name, Rule: th.name(), Value: v, Raised: true})
+					}
+				}
+			}
+		}
+	}
+}
+
+// raised lists the thresholds raised for a sensor.
+func (d *thresholdDetector) raised(chip, sensor string) []string {
+	var names []string
+	for i, th := range d.thresholds {
+		if st, ok := d.state[sensorKey(chip, sensor)+"/"+strconv.Itoa(i)]; ok && st.raised {
+			names = append(names, th.name())
+		}
+	}
+	return names
+}
+
+// AlarmState is why a sensor is in alarm.
+type AlarmState struct {
+	Chip       string
+	Sensor     string
+	Hardware   bool     // The chip's own alarm flag, see [Sensor.Alarm]
+	Thresholds []string // The names of the raised [Threshold]s
+}
+
+type alarmStates struct {
+	mu     sync.Mutex
+	states []AlarmState
+}
+
+func (a *alarmStates) observe(sys *System, thresholds *thresholdDetector) {
+	var states []AlarmState
+	for _, chip := range sys.Chips {
+		for name, sensor := range chip.Sensors {
+			if sensor == nil {
+				continue
+			}
+			st := AlarmState{Chip: chip.ID, Sensor: name, Hardware: sensor.Alarm()}
+			if thresholds != nil {
+				st.Thresholds = thresholds.raised(chip.ID, name)
+			}
+			if st.Hardware || len(st.Thresholds) != 0 {
+				states = append(states, st)
+			}
+		}
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Chip != states[j].Chip {
+			return states[i].Chip < states[j].Chip
+		}
+		return states[i].Sensor < states[j].Sensor
+	})
+	a.mu.Lock()
+	a.states = states
+	a.mu.Unlock()
+}
+
+// AlarmStates lists the sensors in alarm as of the last poll, from their hardware flags or [WithThresholds], ordered by chip then sensor.
+// It's safe to call from any goroutine.
+func (w *Watcher) AlarmStates() []AlarmState {
+	w.alarmStates.mu.Lock()
+	defer w.alarmStates.mu.Unlock()
+	return w.alarmStates.states
+}
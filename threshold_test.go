@@ -0,0 +1,58 @@
+package lmsensors
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestThresholds(t *testing.T) {
+	above := 90.0
+	var events []AlarmEvent
+	w := NewWatcher(time.Second, WithThresholds(func(ev AlarmEvent) { events = append(events, ev) },
+		Threshold{Name: "cpu hot", Sensor: "k10temp-*/Tctl", Above: &above, For: 10 * time.Second}))
+
+	start := time.Now()
+	sys := func(i int, v float64) *System {
+		return &System{Time: start.Add(time.Duration(i) * 5 * time.Second), Chips: map[string]*Chip{
+			"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]Sensor{"Tctl": &TempSensor{baseSensor: baseSensor{Name: "Tctl", Value: v}}}},
+		}}
+	}
+	var states [][]AlarmState
+	for i, v := range []float64{80, 95, 95, 85, 95, 95, 95, NoValue, 80} {
+		w.observe(sys(i, v))
+		states = append(states, w.AlarmStates())
+	}
+	// The first excursion is too short; the second lasts 10s, and isn't cleared by a failed reading.
+	if len(events) != 2 {
+		t.Fatalf("wrong number of events: %v", events)
+	}
+	if !events[0].Raised || events[0].Rule != "cpu hot" || events[0].Value != 95 {
+		t.Errorf("wrong raise: %+v", events[0])
+	}
+	if events[1].Raised || events[1].Value != 80 {
+		t.Errorf("wrong clear: %+v", events[1])
+	}
+	raised := []AlarmState{{Chip: "k10temp-pci-00c3", Sensor: "Tctl", Thresholds: []string{"cpu hot"}}}
+	for i, want := range [][]AlarmState{nil, nil, nil, nil, nil, nil, raised, raised, nil} {
+		if !reflect.DeepEqual(states[i], want) {
+			t.Errorf("poll %d: got states %+v, want %+v", i, states[i], want)
+		}
+	}
+}
+
+func TestThresholdsAdd(t *testing.T) {
+	hot, cold := 90.0, 10.0
+	var hots, colds []AlarmEvent
+	w := NewWatcher(time.Second,
+		WithThresholds(func(ev AlarmEvent) { hots = append(hots, ev) }, Threshold{Name: "hot", Sensor: "*/Tctl", Above: &hot}),
+		WithThresholds(func(ev AlarmEvent) { colds = append(colds, ev) }, Threshold{Name: "cold", Sensor: "*/Tctl", Below: &cold}))
+	for _, v := range []float64{95, 5} {
+		w.observe(&System{Time: time.Now(), Chips: map[string]*Chip{
+			"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]Sensor{"Tctl": &TempSensor{baseSensor: baseSensor{Name: "Tctl", Value: v}}, "gone": nil}},
+		}})
+	}
+	if len(hots) != 2 || hots[0].Rule != "hot" || len(colds) != 1 || colds[0].Rule != "cold" || !colds[0].Raised {
+		t.Errorf("hot %+v, cold %+v", hots, colds)
+	}
+}
@@ -0,0 +1,196 @@
+package lmsensors
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Throttling is a spell of the CPUs being thermally throttled, see [WithThrottleDetection].
+type Throttling struct {
+	Started time.Time
+	Sensor  string   // chip/sensor of the hottest CPU temperature, which is taken to be the one limiting them
+	Temp    float64  // Its hottest, so far; Sensor is empty and this 0 if there are no CPU temperatures
+	CPUs    []string // The ones throttled, so far, eg "cpu3"
+	Cause   string   // "thermal_throttle" if the kernel counted any of it, or "cpufreq" if it was only inferred from the frequencies
+}
+
+// ThrottleEvent reports the CPUs starting, or stopping, being thermally throttled.
+type ThrottleEvent struct {
+	Throttling
+	Duration  time.Duration // Of the whole spell, when it ends
+	Throttled bool          // false when it ends
+}
+
+// throttleTemps are the temperatures CPUs throttle at, for drivers that don't report them as limits; see [Quirk.ThrottleTemp].
+var throttleTemps = map[string]float64{
+	"k10temp":  95, // Tctl's limit on desktop parts; mobile ones are lower, but report it
+	"zenpower": 95,
+	"coretemp": 100, // The usual TjMax, which coretemp reports as crit anyway
+}
+
+const (
+	throttleMargin    = 5   // °C below the throttling temperature that frequency drops start counting
+	throttleFreqRatio = 0.8 // Of the CPU's maximum frequency, below which it's taken to be throttled, if it's hot
+)
+
+type throttleDetector struct {
+	fn     func(ThrottleEvent)
+	counts map[string]uint64 // By CPU, of core and package throttles
+	cur    *Throttling
+}
+
+// WithThrottleDetection detects the CPUs being thermally throttled, from the kernel's thermal_throttle counters, which Intel CPUs have, and from cpufreq, taking CPUs running well below their maximum frequency while the hottest CPU temperature is within a few degrees of the throttling temperature to be throttled.
+// The throttling temperature is the sensor's crit limit, or its max, if it has them, or else the driver's, see [Quirk.ThrottleTemp].
+// fn, if not nil, is called when a spell starts and ends; the one underway is in [System.Throttling], and [System.Summary].
+func WithThrottleDetection(fn func(ThrottleEvent)) WatcherOption {
+	return func(w *Watcher) {
+		w.throttles = &throttleDetector{fn: fn, counts: map[string]uint64{}}
+	}
+}
+
+func cpuDir() string {
+	return filepath.Join(SysfsRoot, "devices", "system", "cpu")
+}
+
+func (d *throttleDetector) observe(sys *System) {
+	now := sys.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	sensor, temp, limit := hottestCPU(sys)
+	throttled, counted := d.throttledCPUs(temp >= limit-throttleMargin)
+
+	if len(throttled) == 0 {
+		if d.cur != nil && d.fn != nil {
+			d.fn(ThrottleEvent{Throttling: *d.cur, Duration: now.Sub(d.cur.Started)})
+		}
+		d.cur = nil
+		return
+	}
+	started := d.cur == nil
+	if started {
+		d.cur = &Throttling{Started: now, Sensor: sensor, Temp: temp, Cause: "cpufreq"}
+	}
+	if temp > d.cur.Temp {
+		d.cur.Sensor, d.cur.Temp = sensor, temp
+	}
+	if counted {
+		d.cur.Cause = "thermal_throttle"
+	}
+	for _, cpu := range throttled {
+		if !contains(d.cur.CPUs, cpu) {
+			d.cur.CPUs = append(d.cur.CPUs, cpu)
+		}
+	}
+	sort.Slice(d.cur.CPUs, func(i, j int) bool { return cpuLess(d.cur.CPUs[i], d.cur.CPUs[j]) })
+	t := *d.cur
+	t.CPUs = append([]string(nil), t.CPUs...)
+	sys.Throttling = &t
+	if started && d.fn != nil {
+		d.fn(ThrottleEvent{Throttling: t, Throttled: true})
+	}
+}
+
+// hottestCPU finds the hottest CPU temperature, and the temperature it'd throttle at; with none, the limit's infinite.
+func hottestCPU(sys *System) (sensor string, temp, limit float64) {
+	limit = math.Inf(1)
+	for _, chip := range sys.Chips {
+		if chip.Kind() != KindCPU {
+			continue
+		}
+		for name, s := range chip.Sensors {
+			if s == nil || s.Type() != Temperature || !Valid(s) {
+				continue
+			}
+			v := s.Reading()
+			if sensor != "" && v <= temp {
+				continue
+			}
+			sensor, temp, limit = sensorKey(chip.ID, name), v, throttleTemp(chip.Type, s)
+		}
+	}
+	return sensor, temp, limit
+}
+
+func throttleTemp(prefix string, s Sensor) float64 {
+	if l := LimitsOf(s); l != nil {
+		if l.Crit != nil {
+			return *l.Crit
+		}
+		if l.Max != nil {
+			return *l.Max
+		}
+	}
+	if ts, ok := s.(*TempSensor); ok && ts.Crit != 0 {
+		return ts.Crit
+	}
+	if t := quirkOf(prefix).ThrottleTemp; t != 0 {
+		return t
+	}
+	if t, ok := throttleTemps[prefix]; ok {
+		return t
+	}
+	return math.Inf(1)
+}
+
+// throttledCPUs lists the CPUs whose throttle counters have gone up since the last poll, and, if hot, those running well below their maximum frequency.
+func (d *throttleDetector) throttledCPUs(hot bool) (cpus []string, counted bool) {
+	dirs, _ := filepath.Glob(filepath.Join(cpuDir(), "cpu[0-9]*"))
+	for _, dir := range dirs {
+		cpu := filepath.Base(dir)
+		var count uint64
+		var hasCount bool
+		for _, kind := range []string{"core", "package"} {
+			if n, ok := readUint(filepath.Join(dir, "thermal_throttle", kind+"_throttle_count")); ok {
+				count, hasCount = count+n, true
+			}
+		}
+		if hasCount {
+			last, seen := d.counts[cpu]
+			d.counts[cpu] = count
+			if seen && count > last {
+				cpus, counted = append(cpus, cpu), true
+				continue
+			}
+		}
+		if !hot {
+			continue
+		}
+		cur, ok1 := readUint(filepath.Join(dir, "cpufreq", "scaling_cur_freq"))
+		max, ok2 := readUint(filepath.Join(dir, "cpufreq", "cpuinfo_max_freq"))
+		if ok1 && ok2 && max != 0 && float64(cur) < throttleFreqRatio*float64(max) {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, counted
+}
+
+func readUint(path string) (uint64, bool) {
+	p, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(strings.TrimSpace(string(p)), 10, 64)
+	return n, err == nil
+}
+
+// cpuLess orders eg cpu2 before cpu10.
+func cpuLess(a, b string) bool {
+	x, _ := strconv.Atoi(strings.TrimPrefix(a, "cpu"))
+	y, _ := strconv.Atoi(strings.TrimPrefix(b, "cpu"))
+	return x < y
+}
+
+func contains(ss []string, s string) bool {
+	for _, t := range ss {
+		if t == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package lmsensors
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestThrottleDetection(t *testing.T) {
+	root := t.TempDir()
+	defer func() { SysfsRoot = "/sys" }()
+	SysfsRoot = root
+	write := func(cpu, attr string, v int) {
+		path := filepath.Join(root, "devices", "system", "cpu", cpu, attr)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(strconv.Itoa(v)+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, cpu := range []string{"cpu0", "cpu1", "cpu10"} {
+		write(cpu, "thermal_throttle/core_throttle_count", 0)
+		write(cpu, "cpufreq/scaling_cur_freq", 4000000)
+		write(cpu, "cpufreq/cpuinfo_max_freq", 4500000)
+	}
+
+	var events []ThrottleEvent
+	w := NewWatcher(time.Second, WithThrottleDetection(func(e ThrottleEvent) { events = append(events, e) }))
+	start := time.Now()
+	poll := func(i int, temp float64) *System {
+		s := &TempSensor{}
+		s.Name, s.Value, s.Time = "Tctl", temp, start.Add(time.Duration(i)*time.Second)
+		sys := &System{Time: s.Time, Chips: map[string]*Chip{
+			"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Type: "k10temp", Sensors: map[string]Sensor{"Tctl": s}},
+		}}
+		w.observe(sys)
+		return sys
+	}
+
+	if sys := poll(0, 70); sys.Throttling != nil || len(events) != 0 {
+		t.Fatalf("throttled at idle: %+v %v", sys.Throttling, events)
+	}
+	// Slow but cool is just idling.
+	write("cpu1", "cpufreq/scaling_cur_freq", 1000000)
+	if sys := poll(1, 70); sys.Throttling != nil {
+		t.Fatalf("throttled when cool: %+v", sys.Throttling)
+	}
+	// Slow and near k10temp's 95°C is throttling.
+	sys := poll(2, 92)
+	if sys.Throttling == nil || sys.Throttling.Cause != "cpufreq" || sys.Throttling.Sensor != "k10temp-pci-00c3/Tctl" || len(events) != 1 || !events[0].Throttled || len(events[0].CPUs) != 1 {
+		t.Fatalf("not throttled: %+v %v", sys.Throttling, events)
+	}
+	if sum := sys.Summary(); !sum.Throttled || sum.ThrottleSensor != "k10temp-pci-00c3/Tctl" {
+		t.Errorf("summary: %+v", sum)
+	}
+	// The counters going up is throttling whatever the temperature.
+	write("cpu1", "cpufreq/scaling_cur_freq", 4000000)
+	write("cpu10", "thermal_throttle/core_throttle_count", 3)
+	sys = poll(3, 94)
+	if th := sys.Throttling; th == nil || th.Cause != "thermal_throttle" || th.Temp != 94 || len(th.CPUs) != 2 || th.CPUs[0] != "cpu1" || th.CPUs[1] != "cpu10" || len(events) != 1 {
+		t.Fatalf("counting: %+v %v", th, events)
+	}
+	if c := sys.Clone(); c.Throttling == sys.Throttling || c.Throttling.CPUs[1] != "cpu10" {
+		t.Errorf("clone: %+v", c.Throttling)
+	}
+
+	if sys := poll(5, 80); sys.Throttling != nil || len(events) != 2 {
+		t.Fatalf("still throttled: %+v %v", sys.Throttling, events)
+	}
+	if e := events[1]; e.Throttled || e.Duration != 3*time.Second || e.Temp != 94 || !e.Started.Equal(start.Add(2*time.Second)) {
+		t.Errorf("end: %+v", e)
+	}
+}
@@ -0,0 +1,72 @@
+package lmsensors
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PhysicalDevice is one piece of hardware, like a CPU package, GPU card or drive, which may expose several hwmon chips.
+type PhysicalDevice struct {
+	ID    string   // Derived from the sysfs topology, eg "pci-0000:0b:00" or "scsi-0:0:0:0"
+	Path  string   // sysfs path of the device; empty for virtual chips
+	Chips []string // IDs of the chips belonging to the device
+}
+
+var (
+	pciAddrRe  = regexp.MustCompile(`^[0-9a-f]{4}:[0-9a-f]{2}:[0-9a-f]{2}\.[0-7]$`)
+	scsiAddrRe = regexp.MustCompile(`^\d+:\d+:\d+:\d+$`)
+)
+
+// physicalID finds the physical device in a resolved sysfs device path.
+// Drives are identified by their SCSI address, as they'd otherwise all group under their controller.
+// PCI devices are identified by the deepest PCI address with the function dropped, so that eg all the functions of an AMD CPU's data fabric (k10temp is on function 3) and a GPU's audio function group together.
+func physicalID(devPath string) (id, path string) {
+	parts := strings.Split(filepath.Clean(devPath), string(filepath.Separator))
+	for i := len(parts) - 1; i >= 0; i-- {
+		if scsiAddrRe.MatchString(parts[i]) {
+			return "scsi-" + parts[i], strings.Join(parts[:i+1], string(filepath.Separator))
+		}
+	}
+	for i := len(parts) - 1; i >= 0; i-- {
+		if pciAddrRe.MatchString(parts[i]) {
+			return "pci-" + parts[i][:len(parts[i])-2], strings.Join(parts[:i+1], string(filepath.Separator))
+		}
+	}
+	for i, p := range parts {
+		if p == "platform" && i+1 < len(parts) {
+			return "platform-" + parts[i+1], strings.Join(parts[:i+2], string(filepath.Separator))
+		}
+	}
+	return "", devPath
+}
+
+// Devices groups the chips by the physical device they're on, using the sysfs topology.
+// Chips without a parent device (eg virtual ones like acpitz) get a device of their own.
+func (sys *System) Devices() []PhysicalDevice {
+	byID := map[string]*PhysicalDevice{}
+	for _, chip := range sys.Chips {
+		id, path := "", ""
+		if devPath, err := filepath.EvalSymlinks(filepath.Join(chip.Path, "device")); err == nil {
+			id, path = physicalID(devPath)
+		}
+		if id == "" {
+			id, path = "chip-"+chip.ID, ""
+		}
+		dev, ok := byID[id]
+		if !ok {
+			dev = &PhysicalDevice{ID: id, Path: path}
+			byID[id] = dev
+		}
+		dev.Chips = append(dev.Chips, chip.ID)
+	}
+
+	devs := make([]PhysicalDevice, 0, len(byID))
+	for _, dev := range byID {
+		sort.Strings(dev.Chips)
+		devs = append(devs, *dev)
+	}
+	sort.Slice(devs, func(i, j int) bool { return devs[i].ID < devs[j].ID })
+	return devs
+}
@@ -0,0 +1,20 @@
+package lmsensors
+
+import "testing"
+
+func TestPhysicalID(t *testing.T) {
+	cases := []struct{ path, id, dev string }{
+		{"/sys/devices/pci0000:00/0000:00:18.3", "pci-0000:00:18", "/sys/devices/pci0000:00/0000:00:18.3"},
+		{"/sys/devices/pci0000:00/0000:00:03.1/0000:09:00.0/0000:0a:00.0/0000:0b:00.0", "pci-0000:0b:00", "/sys/devices/pci0000:00/0000:00:03.1/0000:09:00.0/0000:0a:00.0/0000:0b:00.0"},
+		{"/sys/devices/pci0000:00/0000:00:01.2/0000:01:00.0/nvme/nvme0", "pci-0000:01:00", "/sys/devices/pci0000:00/0000:00:01.2/0000:01:00.0"},
+		{"/sys/devices/pci0000:00/0000:00:11.0/ata1/host0/target0:0:0/0:0:0:0", "scsi-0:0:0:0", "/sys/devices/pci0000:00/0000:00:11.0/ata1/host0/target0:0:0/0:0:0:0"},
+		{"/sys/devices/platform/coretemp.0", "platform-coretemp.0", "/sys/devices/platform/coretemp.0"},
+		{"/sys/devices/virtual/thermal/thermal_zone0", "", "/sys/devices/virtual/thermal/thermal_zone0"},
+	}
+	for _, c := range cases {
+		id, dev := physicalID(c.path)
+		if id != c.id || dev != c.dev {
+			t.Errorf("physicalID(%s) = %s, %s; want %s, %s", c.path, id, dev, c.id, c.dev)
+		}
+	}
+}
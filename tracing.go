@@ -0,0 +1,65 @@
+package lmsensors
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Tracer starts spans around collection, so that slow chips can be found in production traces.
+// This package doesn't depend on OpenTelemetry, but Tracer is shaped so that an adapter over an OpenTelemetry trace.Tracer is a few lines, see the README.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a traced operation started by a [Tracer].
+type Span interface {
+	SetAttribute(key string, value any)
+	End()
+}
+
+type nopTracer struct{}
+
+func (nopTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttribute(string, any) {}
+func (nopSpan) End()                     {}
+
+// packageTracer forwards to whichever [Tracer] was last set.
+type packageTracer struct {
+	t atomic.Pointer[Tracer]
+}
+
+func (p *packageTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	if t := p.t.Load(); t != nil {
+		return (*t).Start(ctx, name)
+	}
+	return nopTracer{}.Start(ctx, name)
+}
+
+var tracer = &packageTracer{}
+
+// SetTracer sets the [Tracer] used by [GetContext]; nil turns tracing off, which is the default.
+// Collections under way when it's changed carry on with the spans they've started.
+func SetTracer(t Tracer) {
+	if t == nil {
+		tracer.t.Store(nil)
+		return
+	}
+	tracer.t.Store(&t)
+}
+
+// errCount is the number of individual errors aggregated by [collectError].
+func errCount(err error) int {
+	switch e := err.(type) {
+	case nil:
+		return 0
+	case wrapErrors:
+		return len(e)
+	default:
+		return 1
+	}
+}
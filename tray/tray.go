@@ -0,0 +1,159 @@
+// Package tray is the state behind a system tray applet: the hottest temperature and whether anything's in alarm, boiled down to an icon and a tooltip.
+// It's headless, so it works under Wayland and X11 alike; hook its callbacks up to whichever tray library the applet uses, see examples/tray.
+package tray
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+// Level is how worrying the state is, which picks the icon.
+type Level int
+
+const (
+	LevelUnknown Level = iota // No temperatures have been read
+	LevelNormal
+	LevelWarm
+	LevelHot
+	LevelAlarm // A sensor is in alarm
+)
+
+// Icons are the icon theme names for each level; change them to suit the applet's theme.
+var Icons = map[Level]string{
+	LevelUnknown: "dialog-question",
+	LevelNormal:  "temperature-normal",
+	LevelWarm:    "temperature-warm",
+	LevelHot:     "dialog-warning",
+	LevelAlarm:   "dialog-error",
+}
+
+// State is what the applet shows.
+type State struct {
+	MaxTemp float64 // The hottest temperature of any sensor, in °C, if HasTemp
+	HasTemp bool
+	Hottest string // chip/sensor of MaxTemp
+	Summary lmsensors.Summary
+	Level   Level
+}
+
+// Icon is the icon name for the state's level, from [Icons].
+func (s State) Icon() string {
+	return Icons[s.Level]
+}
+
+// Tooltip is a few lines describing the state, eg for hovering over the icon.
+func (s State) Tooltip() string {
+	var lines []string
+	if s.HasTemp {
+		lines = append(lines, fmt.Sprintf("Hottest: %.1f°C (%s)", s.MaxTemp, s.Hottest))
+	} else {
+		lines = append(lines, "No temperatures")
+	}
+	if s.Summary.HasCPUTemp {
+		lines = append(lines, fmt.Sprintf("CPU: %.1f°C", s.Summary.CPUTemp))
+	}
+	if s.Summary.HasGPUTemp {
+		lines = append(lines, fmt.Sprintf("GPU: %.1f°C", s.Summary.GPUTemp))
+	}
+	if s.Summary.Throttled {
+		lines = append(lines, "Throttled by "+s.Summary.ThrottleSensor)
+	}
+	if n := len(s.Summary.Alarms); n != 0 {
+		lines = append(lines, "Alarms: "+strings.Join(s.Summary.Alarms, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Provider works out the [State] from every poll of a [lmsensors.Watcher], telling its callbacks when it changes.
+// The callbacks are called from the Watcher's goroutine, so they shouldn't block, and most tray libraries want them handed over to their own.
+type Provider struct {
+	Warm, Hot float64 // Temperatures, in °C, from which the state is LevelWarm and LevelHot; default to 70 and 85
+
+	OnState func(State)  // Called with each state that's different from the last
+	OnIcon  func(string) // Called when the icon changes, which is less often
+
+	mu    sync.Mutex
+	state State
+	seen  bool
+}
+
+// Watch returns an option that updates the state with every poll of a [lmsensors.Watcher].
+func (p *Provider) Watch() lmsensors.WatcherOption {
+	return lmsensors.OnPoll(func(sys *lmsensors.System, _ error) {
+		p.Update(sys)
+	})
+}
+
+// Update works out the state from a reading, and calls the callbacks if it's changed.
+func (p *Provider) Update(sys *lmsensors.System) {
+	st := p.stateOf(sys)
+	p.mu.Lock()
+	prev, seen := p.state, p.seen
+	p.state, p.seen = st, true
+	p.mu.Unlock()
+
+	if p.OnState != nil && (!seen || !equal(prev, st)) {
+		p.OnState(st)
+	}
+	if p.OnIcon != nil && (!seen || prev.Icon() != st.Icon()) {
+		p.OnIcon(st.Icon())
+	}
+}
+
+// State is the latest state, with LevelUnknown before the first poll.
+func (p *Provider) State() State {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state
+}
+
+func (p *Provider) stateOf(sys *lmsensors.System) State {
+	st := State{Summary: sys.Summary()}
+	for _, chip := range sys.Chips {
+		for name, s := range chip.Sensors {
+			if s == nil || s.Type() != lmsensors.Temperature || !lmsensors.Valid(s) {
+				continue
+			}
+			key := chip.ID + "/" + name
+			// Break ties by name, so the same reading always gives the same state.
+			if v := s.Reading(); !st.HasTemp || v > st.MaxTemp || (v == st.MaxTemp && key < st.Hottest) {
+				st.MaxTemp, st.Hottest, st.HasTemp = v, key, true
+			}
+		}
+	}
+
+	warm, hot := p.Warm, p.Hot
+	if warm == 0 {
+		warm = 70
+	}
+	if hot == 0 {
+		hot = 85
+	}
+	switch {
+	case len(st.Summary.Alarms) != 0:
+		st.Level = LevelAlarm
+	case !st.HasTemp:
+		st.Level = LevelUnknown
+	case st.MaxTemp >= hot:
+		st.Level = LevelHot
+	case st.MaxTemp >= warm:
+		st.Level = LevelWarm
+	default:
+		st.Level = LevelNormal
+	}
+	return st
+}
+
+func equal(a, b State) bool {
+	if a.MaxTemp != b.MaxTemp || a.HasTemp != b.HasTemp || a.Hottest != b.Hottest || a.Level != b.Level ||
+		a.Summary.CPUTemp != b.Summary.CPUTemp || a.Summary.HasCPUTemp != b.Summary.HasCPUTemp ||
+		a.Summary.GPUTemp != b.Summary.GPUTemp || a.Summary.HasGPUTemp != b.Summary.HasGPUTemp ||
+		a.Summary.Fans != b.Summary.Fans || a.Summary.Throttled != b.Summary.Throttled || a.Summary.ThrottleSensor != b.Summary.ThrottleSensor {
+		return false
+	}
+	return slices.Equal(a.Summary.Alarms, b.Summary.Alarms)
+}
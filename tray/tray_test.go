@@ -0,0 +1,60 @@
+package tray
+
+import (
+	"testing"
+
+	"github.com/mt-inside/go-lmsensors"
+)
+
+func system(cpu float64) *lmsensors.System {
+	tctl := &lmsensors.TempSensor{}
+	tctl.Name, tctl.Value = "Tctl", cpu
+	edge := &lmsensors.TempSensor{}
+	edge.Name, edge.Value = "edge", 50
+	return &lmsensors.System{Chips: map[string]*lmsensors.Chip{
+		"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Type: "k10temp", Bus: "pci", Sensors: map[string]lmsensors.Sensor{"Tctl": tctl}},
+		"amdgpu-pci-0b00":  {ID: "amdgpu-pci-0b00", Type: "amdgpu", Bus: "pci", Sensors: map[string]lmsensors.Sensor{"edge": edge}},
+	}}
+}
+
+func TestProvider(t *testing.T) {
+	var states []State
+	var icons []string
+	p := &Provider{
+		OnState: func(st State) { states = append(states, st) },
+		OnIcon:  func(icon string) { icons = append(icons, icon) },
+	}
+	if p.State().Level != LevelUnknown {
+		t.Errorf("state before the first poll: %+v", p.State())
+	}
+
+	p.Update(system(45))
+	p.Update(system(45))
+	p.Update(system(46))
+	p.Update(system(75))
+	p.Update(system(90))
+
+	if len(states) != 4 {
+		t.Fatalf("wanted a state per change, got %+v", states)
+	}
+	if st := states[0]; !st.HasTemp || st.MaxTemp != 50 || st.Hottest != "amdgpu-pci-0b00/edge" || st.Level != LevelNormal || st.Summary.CPUTemp != 45 {
+		t.Errorf("wrong first state: %+v", st)
+	}
+	if st := states[3]; st.MaxTemp != 90 || st.Hottest != "k10temp-pci-00c3/Tctl" || st.Level != LevelHot {
+		t.Errorf("wrong last state: %+v", st)
+	}
+	if want := []string{"temperature-normal", "temperature-warm", "dialog-warning"}; len(icons) != len(want) || icons[0] != want[0] || icons[1] != want[1] || icons[2] != want[2] {
+		t.Errorf("wrong icons: %v", icons)
+	}
+	if tip := p.State().Tooltip(); tip != "Hottest: 90.0°C (k10temp-pci-00c3/Tctl)\nCPU: 90.0°C\nGPU: 50.0°C" {
+		t.Errorf("wrong tooltip: %q", tip)
+	}
+}
+
+func TestProviderEmpty(t *testing.T) {
+	p := &Provider{}
+	p.Update(&lmsensors.System{Chips: map[string]*lmsensors.Chip{}})
+	if st := p.State(); st.HasTemp || st.Level != LevelUnknown || st.Icon() != "dialog-question" || st.Tooltip() != "No temperatures" {
+		t.Errorf("wrong state: %+v", st)
+	}
+}
@@ -0,0 +1,119 @@
+package lmsensors
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Trend is a straight line fitted through a sensor's recent readings, see [WithTrends].
+type Trend struct {
+	Slope   float64   // Change per second
+	Value   float64   // The fitted value at At, which smooths out noise in the latest reading
+	At      time.Time // The time of the latest reading
+	Samples int       // How many readings it's fitted to
+}
+
+// TimeTo is how long until the trend reaches limit, eg until a CPU hits 95 °C at its current rate of heating.
+// It's false if the trend is flat, or moving away from limit, which includes having already passed it.
+func (t Trend) TimeTo(limit float64) (time.Duration, bool) {
+	if t.Slope == 0 {
+		return 0, false
+	}
+	secs := (limit - t.Value) / t.Slope
+	if secs < 0 || math.IsInf(secs, 0) {
+		return 0, false
+	}
+	return time.Duration(secs * float64(time.Second)), true
+}
+
+type trendSample struct {
+	time  time.Time
+	value float64
+}
+
+type trendDetector struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	samples map[string][]trendSample
+}
+
+// WithTrends keeps every sensor's readings over the last window, so that trends can be fitted through them, see [Watcher.Trend].
+func WithTrends(window time.Duration) WatcherOption {
+	return func(w *Watcher) {
+		w.trends = &trendDetector{window: window, samples: map[string][]trendSample{}}
+	}
+}
+
+func (d *trendDetector) observe(sys *System) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	seen := make(map[string][]trendSample, len(d.samples))
+	for _, chip := range sys.Chips {
+		for name, sensor := range chip.Sensors {
+			key := sensorKey(chip.ID, name)
+			ss := d.samples[key]
+			if val, ok := valueOf(sensor); ok {
+				at := TimeOf(sensor)
+				if at.IsZero() {
+					at = sys.Time
+				}
+				// Readings carried over from the last poll, see [WithChipInterval], aren't new ones.
+				if len(ss) == 0 || at.After(ss[len(ss)-1].time) {
+					ss = append(ss, trendSample{at, val})
+				}
+			}
+			for len(ss) != 0 && ss[len(ss)-1].time.Sub(ss[0].time) > d.window {
+				ss = ss[1:]
+			}
+			seen[key] = ss
+		}
+	}
+	d.samples = seen
+}
+
+// fitTrend fits a least-squares line through the samples; it needs at least two, at different times.
+func fitTrend(ss []trendSample) (Trend, bool) {
+	if len(ss) < 2 {
+		return Trend{}, false
+	}
+	// Relative to the latest sample, so the times are small and the intercept is the fitted current value.
+	last := ss[len(ss)-1].time
+	var sx, sy, sxx, sxy float64
+	for _, s := range ss {
+		x := s.time.Sub(last).Seconds()
+		sx += x
+		sy += s.value
+		sxx += x * x
+		sxy += x * s.value
+	}
+	n := float64(len(ss))
+	den := n*sxx - sx*sx
+	if den == 0 {
+		return Trend{}, false
+	}
+	slope := (n*sxy - sx*sy) / den
+	return Trend{Slope: slope, Value: (sy - slope*sx) / n, At: last, Samples: len(ss)}, true
+}
+
+// Trend fits a line through a sensor's readings over the window, if it's been made [WithTrends] and the sensor has been read at least twice.
+// It's safe to call from any goroutine.
+func (w *Watcher) Trend(chip, sensor string) (Trend, bool) {
+	if w.trends == nil {
+		return Trend{}, false
+	}
+	d := w.trends
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return fitTrend(d.samples[sensorKey(chip, sensor)])
+}
+
+// TimeToThreshold is how long until a sensor reaches limit at its current trend, eg because a fan's died; see [Watcher.Trend] and [Trend.TimeTo].
+func (w *Watcher) TimeToThreshold(chip, sensor string, limit float64) (time.Duration, bool) {
+	t, ok := w.Trend(chip, sensor)
+	if !ok {
+		return 0, false
+	}
+	return t.TimeTo(limit)
+}
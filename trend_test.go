@@ -0,0 +1,39 @@
+package lmsensors
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrend(t *testing.T) {
+	w := NewWatcher(time.Second, WithTrends(time.Minute))
+	start := time.Now()
+	// Heating at 0.5 °C/s, with some noise
+	for i, noise := range []float64{0, 0.2, -0.2, 0.1, -0.1, 0} {
+		at := start.Add(time.Duration(i) * 10 * time.Second)
+		ts := &TempSensor{baseSensor: baseSensor{Name: "Tctl", Value: 60 + 5*float64(i) + noise, Time: at}}
+		w.observe(&System{Time: at, Chips: map[string]*Chip{"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]Sensor{"Tctl": ts}}}})
+	}
+	tr, ok := w.Trend("k10temp-pci-00c3", "Tctl")
+	if !ok || tr.Samples != 6 || tr.Slope < 0.49 || tr.Slope > 0.51 || tr.Value < 84.5 || tr.Value > 85.5 {
+		t.Fatalf("wrong trend: %+v", tr)
+	}
+	d, ok := w.TimeToThreshold("k10temp-pci-00c3", "Tctl", 95)
+	if !ok || d < 19*time.Second || d > 21*time.Second {
+		t.Errorf("expected ~20s to 95 °C, got %v", d)
+	}
+	if _, ok := w.TimeToThreshold("k10temp-pci-00c3", "Tctl", 50); ok {
+		t.Error("a rising temperature shouldn't reach a lower limit")
+	}
+	if _, ok := w.Trend("k10temp-pci-00c3", "missing"); ok {
+		t.Error("expected no trend for a missing sensor")
+	}
+
+	// Only the window is kept
+	at := start.Add(2 * time.Minute)
+	ts := &TempSensor{baseSensor: baseSensor{Name: "Tctl", Value: 70, Time: at}}
+	w.observe(&System{Time: at, Chips: map[string]*Chip{"k10temp-pci-00c3": {ID: "k10temp-pci-00c3", Sensors: map[string]Sensor{"Tctl": ts}}}})
+	if tr, ok := w.Trend("k10temp-pci-00c3", "Tctl"); ok {
+		t.Errorf("expected the old readings to have aged out, got %+v", tr)
+	}
+}
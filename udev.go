@@ -0,0 +1,62 @@
+package lmsensors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Grant is a set of attributes of one type of chip that an application wants to write without being root.
+type Grant struct {
+	Prefix string   // The chip's prefix, eg nct6798
+	Attrs  []string // eg pwm1, pwm1_enable
+}
+
+// GrantsForPaths works out the [Grant]s needed to write the given sysfs files, eg /sys/class/hwmon/hwmon2/pwm1, by reading their chips' names.
+func GrantsForPaths(paths ...string) ([]Grant, error) {
+	byPrefix := map[string][]string{}
+	for _, p := range paths {
+		name, err := os.ReadFile(filepath.Join(filepath.Dir(p), "name"))
+		if err != nil {
+			return nil, fmt.Errorf("can't find the chip of %s: %w", p, err)
+		}
+		prefix := strings.TrimSpace(string(name))
+		byPrefix[prefix] = append(byPrefix[prefix], filepath.Base(p))
+	}
+	grants := make([]Grant, 0, len(byPrefix))
+	for prefix, attrs := range byPrefix {
+		sort.Strings(attrs)
+		grants = append(grants, Grant{prefix, attrs})
+	}
+	sort.Slice(grants, func(i, j int) bool { return grants[i].Prefix < grants[j].Prefix })
+	return grants, nil
+}
+
+// UdevRules generates udev rules letting group write the granted attributes, eg for /etc/udev/rules.d/90-lmsensors.rules.
+// Rules match on the chip's prefix, as hwmon device numbers aren't stable across boots.
+func UdevRules(group string, grants ...Grant) string {
+	var ret strings.Builder
+	fmt.Fprintf(&ret, "# Lets the %s group write these hwmon attributes\n", group)
+	for _, g := range grants {
+		ret.WriteString(udevRule(group, g.Prefix, g.Attrs...) + "\n")
+	}
+	return ret.String()
+}
+
+func udevRule(group, prefix string, attrs ...string) string {
+	files := strings.Join(attrs, " ")
+	return fmt.Sprintf(`ACTION=="add", SUBSYSTEM=="hwmon", ATTR{name}=="%s", RUN+="/bin/sh -c 'cd /sys%%p && chgrp %s %s && chmod g+w %s'"`, prefix, group, files, files)
+}
+
+// TmpfilesRules generates a systemd-tmpfiles snippet letting group write the given sysfs files, eg for /etc/tmpfiles.d/lmsensors.conf.
+// Unlike [UdevRules], this uses the paths as they are, so beware hwmon device numbers changing across boots; globs work.
+func TmpfilesRules(group string, paths ...string) string {
+	var ret strings.Builder
+	fmt.Fprintf(&ret, "# Lets the %s group write these hwmon attributes\n", group)
+	for _, p := range paths {
+		fmt.Fprintf(&ret, "z %s 0664 root %s -\n", p, group)
+	}
+	return ret.String()
+}
@@ -0,0 +1,31 @@
+package lmsensors
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUdevRules(t *testing.T) {
+	dir := "testdata/sysfs/class/hwmon/"
+	grants, err := GrantsForPaths(dir+"hwmon0/temp1_input", dir+"hwmon1/device/pwm2", dir+"hwmon1/device/pwm1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Grant{{"it87", []string{"pwm1", "pwm2"}}, {"k10temp", []string{"temp1_input"}}}
+	if !reflect.DeepEqual(grants, want) {
+		t.Fatalf("wrong grants: %+v", grants)
+	}
+
+	rules := UdevRules("fans", grants[0])
+	wantRules := `# Lets the fans group write these hwmon attributes
+ACTION=="add", SUBSYSTEM=="hwmon", ATTR{name}=="it87", RUN+="/bin/sh -c 'cd /sys%p && chgrp fans pwm1 pwm2 && chmod g+w pwm1 pwm2'"
+`
+	if rules != wantRules {
+		t.Errorf("wrong udev rules:\n%s", rules)
+	}
+
+	tmpfiles := TmpfilesRules("fans", "/sys/class/hwmon/hwmon1/pwm1")
+	if tmpfiles != "# Lets the fans group write these hwmon attributes\nz /sys/class/hwmon/hwmon1/pwm1 0664 root fans -\n" {
+		t.Errorf("wrong tmpfiles rules:\n%s", tmpfiles)
+	}
+}
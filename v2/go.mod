@@ -0,0 +1,8 @@
+module github.com/mt-inside/go-lmsensors/v2
+
+go 1.24.0
+
+require github.com/mt-inside/go-lmsensors v0.0.0
+
+// v2 is a layer over v1, which stays maintained; they're developed together.
+replace github.com/mt-inside/go-lmsensors => ../
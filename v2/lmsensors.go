@@ -0,0 +1,210 @@
+// Package lmsensors is v2 of the libsensors binding. It's a layer over v1, which carries on being maintained, with a more coherent surface:
+//
+//   - a [Handle] owns the library, rather than package-level Init and Cleanup
+//   - [Chip], [Feature] and [Subfeature] are plain values with methods, rather than a mix of C-backed pointers and copies
+//   - everything that reads the hardware takes a context
+//   - configuration is by options structs
+//
+// This is a proposal: the API may change until v2.0.0 is tagged.
+package lmsensors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	v1 "github.com/mt-inside/go-lmsensors"
+	"github.com/mt-inside/go-lmsensors/chipname"
+	sf "github.com/mt-inside/go-lmsensors/subfeature"
+)
+
+// Re-exported from v1, so that users of v2 don't need to import both.
+type (
+	SensorType     = v1.LmSensorType
+	SubfeatureType = sf.SubFeature
+	Logger         = v1.Logger
+)
+
+const (
+	Voltage     = v1.Voltage
+	Fan         = v1.Fan
+	Temperature = v1.Temperature
+	Power       = v1.Power
+	Energy      = v1.Energy
+	Current     = v1.Current
+	Humidity    = v1.Humidity
+	VID         = v1.VID
+	Intrusion   = v1.Intrusion
+	BeepEnable  = v1.BeepEnable
+)
+
+// ErrOpen is returned by [Open] when there's already a [Handle] open; libsensors' state is global, so there can only be one.
+var ErrOpen = errors.New("libsensors is already open")
+
+// ErrClosed is returned by methods on a closed [Handle].
+var ErrClosed = errors.New("handle is closed")
+
+// ErrReadOnlyMode is returned by writes to a [Handle] opened with ReadOnly, and by all of them when v1's read-only mode is on.
+var ErrReadOnlyMode = v1.ErrReadOnlyMode
+
+// Options configure [Open].
+type Options struct {
+	ConfigFile string // libsensors config; empty for the default /etc/sensors3.conf and /etc/sensors.d
+	Logger     Logger // Defaults to logging nothing
+	ReadOnly   bool   // Refuse every write through the Handle, with [ErrReadOnlyMode], so that monitoring can't change the hardware
+}
+
+// Handle is the open library. Its methods are safe to call concurrently; they're serialised, as libsensors isn't thread-safe, with each other and with v1's, through a [v1.Library] of the Handle's own.
+type Handle struct {
+	mu     sync.Mutex
+	opts   Options
+	lib    *v1.Library
+	closed bool
+}
+
+var (
+	openMu sync.Mutex
+	isOpen bool
+)
+
+// Open initialises libsensors. Only one [Handle] can be open at once; [Handle.Close] it to open another, eg with a different config.
+func Open(opts Options) (*Handle, error) {
+	openMu.Lock()
+	defer openMu.Unlock()
+	if isOpen {
+		return nil, ErrOpen
+	}
+	if opts.Logger != nil {
+		v1.SetLogger(opts.Logger)
+	}
+	lib := v1.NewLibrary(opts.ConfigFile)
+	if err := lib.Init(); err != nil {
+		return nil, err
+	}
+	isOpen = true
+	return &Handle{opts: opts, lib: lib}, nil
+}
+
+// Close releases libsensors. Values already read stay valid.
+func (h *Handle) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return ErrClosed
+	}
+	h.lib.Cleanup()
+	h.closed = true
+	openMu.Lock()
+	isOpen = false
+	openMu.Unlock()
+	return nil
+}
+
+// Reload re-reads the libsensors config, eg after it's been edited.
+func (h *Handle) Reload(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return ErrClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.lib.Init()
+}
+
+// Chips reads every chip, and all their features.
+// Unreadable subfeatures are returned with their [Subfeature.Err] set rather than failing the whole read; the error is only for the handle being closed or ctx being done.
+func (h *Handle) Chips(ctx context.Context) ([]Chip, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, ErrClosed
+	}
+	var chips []Chip
+	err := h.lib.Do(func() error {
+		for _, cp := range v1.Chips {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			chips = append(chips, readChip(cp))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chips, nil
+}
+
+// Chip reads one chip, by its name, eg "k10temp-pci-00c3".
+func (h *Handle) Chip(ctx context.Context, name string) (Chip, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return Chip{}, ErrClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return Chip{}, err
+	}
+	var c Chip
+	err := h.lib.Do(func() error {
+		cp, err := v1.GetChip(name)
+		if err != nil {
+			return fmt.Errorf("chip %s: %w", name, err)
+		}
+		c = readChip(cp)
+		return nil
+	})
+	return c, err
+}
+
+// Set writes a subfeature, eg a limit, going through v1's dry-run and audit machinery.
+func (h *Handle) Set(ctx context.Context, chip, feature string, sub SubfeatureType, value float64) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return ErrClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if h.opts.ReadOnly {
+		// Only this Handle is read-only; v1's mode is the whole process', and is left to whoever set it.
+		return fmt.Errorf("chip %s: %w", chip, ErrReadOnlyMode)
+	}
+	return h.lib.Do(func() error {
+		cp, err := v1.GetChip(chip)
+		if err != nil {
+			return fmt.Errorf("chip %s: %w", chip, err)
+		}
+		for _, feat := range cp.Features {
+			if feat.Name() == feature {
+				return feat.SetValue(sub, value)
+			}
+		}
+		return fmt.Errorf("chip %s has no feature %s", chip, feature)
+	})
+}
+
+// readChip reads a chip; it's only called inside [v1.Library.Do], as the chip's only valid while the Library's config is loaded.
+func readChip(cp v1.ChipPtr) Chip {
+	name, _ := chipname.Parse(cp.Name()) // libsensors' own names always parse
+	c := Chip{
+		ID:      cp.Name(),
+		Name:    name,
+		Adapter: cp.Adapter(),
+		Path:    cp.Path(),
+	}
+	for _, feat := range cp.Features {
+		f := Feature{Name: feat.Name(), Label: feat.Label(), Type: feat.Type()}
+		for sub := range feat.SubFeatures {
+			s := Subfeature{Type: sub, Attr: feat.Attr(sub)}
+			s.Value, s.Err = feat.GetValue(sub)
+			f.Subfeatures = append(f.Subfeatures, s)
+		}
+		c.Features = append(c.Features, f)
+	}
+	return c
+}
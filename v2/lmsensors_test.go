@@ -0,0 +1,94 @@
+package lmsensors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v1 "github.com/mt-inside/go-lmsensors"
+	sf "github.com/mt-inside/go-lmsensors/subfeature"
+)
+
+func TestFeature(t *testing.T) {
+	c := Chip{ID: "k10temp-pci-00c3", Features: []Feature{{
+		Name:  "temp1",
+		Label: "Tctl",
+		Type:  Temperature,
+		Subfeatures: []Subfeature{
+			{Type: sf.TEMP_CRIT, Attr: "temp1_crit", Value: 100},
+			{Type: sf.TEMP_INPUT, Attr: "temp1_input", Value: 42.5},
+		},
+	}}}
+	f, ok := c.Feature("Tctl")
+	if !ok {
+		t.Fatal("no feature")
+	}
+	if v, ok := f.Value(); !ok || v != 42.5 {
+		t.Errorf("wrong value: %v %t", v, ok)
+	}
+	if s, ok := f.Subfeature(sf.TEMP_CRIT); !ok || s.Value != 100 {
+		t.Errorf("wrong crit: %+v", s)
+	}
+	f.Subfeatures[1].Err = errors.New("nope")
+	if _, ok := f.Value(); ok {
+		t.Error("value despite read error")
+	}
+}
+
+func TestHandle(t *testing.T) {
+	h, err := Open(Options{})
+	if err != nil {
+		t.Skip(err)
+	}
+	if _, err := Open(Options{}); !errors.Is(err, ErrOpen) {
+		t.Errorf("second Open: %v", err)
+	}
+	chips, err := h.Chips(context.Background())
+	if err != nil {
+		t.Error(err)
+	}
+	for _, c := range chips {
+		if c.Name.String() != c.ID {
+			t.Errorf("chip name %s doesn't round-trip: %s", c.ID, c.Name)
+		}
+	}
+	// v1's package-level functions use a Library of their own, which doesn't pull the Handle's config out from under it.
+	if err := v1.Init(); err != nil {
+		t.Fatal(err)
+	}
+	v1.Cleanup()
+	if again, err := h.Chips(context.Background()); err != nil || len(again) != len(chips) {
+		t.Errorf("Chips after v1's Init and Cleanup: %d chips, %v", len(again), err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := h.Reload(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Reload with a done context: %v", err)
+	}
+	if err := h.Close(); err != nil {
+		t.Error(err)
+	}
+	if _, err := h.Chips(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("Chips after Close: %v", err)
+	}
+	h, err = Open(Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if err := h.Set(context.Background(), "nct6798-isa-0290", "fan1", 0, 0); !errors.Is(err, ErrReadOnlyMode) {
+		t.Errorf("Set on a read-only Handle: %v", err)
+	}
+	h.Close()
+
+	// Opening a Handle doesn't turn off read-only mode that something else in the process turned on.
+	v1.SetReadOnly(true)
+	defer v1.SetReadOnly(false)
+	h, err = Open(Options{})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer h.Close()
+	if err := v1.WriteAudited(v1.WriteEvent{Chip: "nct6798-isa-0290", Attr: "pwm1"}, func() error { return nil }); !errors.Is(err, ErrReadOnlyMode) {
+		t.Errorf("v1's read-only mode was turned off: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package lmsensors
+
+import (
+	"github.com/mt-inside/go-lmsensors/chipname"
+	sf "github.com/mt-inside/go-lmsensors/subfeature"
+)
+
+// Chip is a hardware monitoring chip, as read by [Handle.Chips].
+type Chip struct {
+	ID       string        // eg "k10temp-pci-00c3"
+	Name     chipname.Name // ID, parsed
+	Adapter  string
+	Path     string // sysfs
+	Features []Feature
+}
+
+// Feature is one channel of a chip, eg a temperature.
+type Feature struct {
+	Name        string // eg temp1
+	Label       string // From the driver or config, eg "Tctl"; defaults to Name
+	Type        SensorType
+	Subfeatures []Subfeature
+}
+
+// Subfeature is one value of a feature, eg its input or its crit limit.
+type Subfeature struct {
+	Type  SubfeatureType
+	Attr  string // sysfs attribute, eg temp1_input
+	Value float64
+	Err   error // If it couldn't be read
+}
+
+// inputs are the subfeatures holding each type of feature's reading, in order of preference.
+var inputs = map[SensorType][]SubfeatureType{
+	Voltage:     {sf.IN_INPUT},
+	Fan:         {sf.FAN_INPUT},
+	Temperature: {sf.TEMP_INPUT},
+	Power:       {sf.POWER_INPUT, sf.POWER_AVERAGE},
+	Energy:      {sf.ENERGY_INPUT},
+	Current:     {sf.CURR_INPUT},
+	Humidity:    {sf.HUMIDITY_INPUT},
+	VID:         {sf.VID},
+	Intrusion:   {sf.INTRUSION_ALARM},
+	BeepEnable:  {sf.BEEP_ENABLE},
+}
+
+// Feature finds a feature by its label.
+func (c Chip) Feature(label string) (Feature, bool) {
+	for _, f := range c.Features {
+		if f.Label == label {
+			return f, true
+		}
+	}
+	return Feature{}, false
+}
+
+// Subfeature finds a subfeature by type.
+func (f Feature) Subfeature(t SubfeatureType) (Subfeature, bool) {
+	for _, s := range f.Subfeatures {
+		if s.Type == t {
+			return s, true
+		}
+	}
+	return Subfeature{}, false
+}
+
+// Input is the subfeature holding the feature's reading, eg temp1_input for a temperature.
+func (f Feature) Input() (Subfeature, bool) {
+	for _, t := range inputs[f.Type] {
+		if s, ok := f.Subfeature(t); ok {
+			return s, true
+		}
+	}
+	return Subfeature{}, false
+}
+
+// Value is the feature's reading, or 0 and false if it doesn't have one or it couldn't be read.
+func (f Feature) Value() (float64, bool) {
+	s, ok := f.Input()
+	if !ok || s.Err != nil {
+		return 0, false
+	}
+	return s.Value, true
+}
+
+func (c Chip) String() string {
+	return c.ID
+}
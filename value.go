@@ -0,0 +1,173 @@
+package lmsensors
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NoValue is the Value of a sensor without a usable reading, eg because the driver returned garbage. It's NaN, so test for it with [math.IsNaN].
+// In JSON it's null.
+var NoValue = math.NaN()
+
+// TimeOf is when a sensor was read, or the zero time for sensors that weren't read by this package.
+func TimeOf(s Sensor) time.Time {
+	switch s := s.(type) {
+	case interface{ base() *baseSensor }:
+		return s.base().Time
+	case *IntrusionSensor:
+		return s.Time
+	}
+	return time.Time{}
+}
+
+// Valid is whether a sensor has a reading, so that eg exporters can skip the ones that don't, rather than graphing 0s.
+// A sensor is invalid when reading it failed, or gave an implausible value; its Value is then [NoValue].
+func Valid(s Sensor) bool {
+	if s == nil {
+		return false
+	}
+	if v, ok := s.(interface{ Valid() bool }); ok {
+		return v.Valid()
+	}
+	return !math.IsNaN(s.Reading())
+}
+
+// plausibleRange is the widest range of readings that could be real, for each type of sensor.
+// Anything outside is a disconnected input or a driver bug, eg an all-ones register scaled into a huge number.
+var plausibleRange = map[LmSensorType][2]float64{
+	Temperature: {-273.15, 1000},
+	Voltage:     {-1e4, 1e4},
+	Fan:         {0, 1e6},
+	Current:     {-1e5, 1e5},
+	Power:       {-1e7, 1e7},
+	Humidity:    {0, 100},
+}
+
+// sanitize replaces readings that can't be real with [NoValue].
+func sanitize(typ LmSensorType, v float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return NoValue
+	}
+	if r, ok := plausibleRange[typ]; ok && (v < r[0] || v > r[1]) {
+		return NoValue
+	}
+	return v
+}
+
+// marshalSensor encodes a sensor struct like encoding/json would, but with non-finite floats, ie [NoValue], as null rather than failing.
+func marshalSensor(s any) ([]byte, error) {
+	return encodeSensor(s, true)
+}
+
+// encodeSensor is [marshalSensor], with or without the fields version 2 of the format adds, see [JSONVersion].
+func encodeSensor(s any, v2 bool) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	// Fields promoted from the unexported baseSensor are read-only to reflect, so it's read through its accessor instead.
+	var base reflect.Value
+	if b, ok := s.(interface{ base() *baseSensor }); ok {
+		base = reflect.ValueOf(b.base()).Elem()
+	}
+	if err := marshalFields(&buf, reflect.ValueOf(s).Elem(), base, &first); err != nil {
+		return nil, err
+	}
+	if sensor, ok := s.(Sensor); ok && v2 {
+		sensorFields(&buf, sensor, first)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+var baseSensorType = reflect.TypeFor[baseSensor]()
+
+func marshalFields(buf *bytes.Buffer, v, base reflect.Value, first *bool) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if f.Anonymous && f.Type == baseSensorType {
+			if err := marshalFields(buf, base, base, first); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fv := v.Field(i)
+		if (opts == "omitempty" || opts == "omitzero") && fv.IsZero() {
+			continue
+		}
+		if !*first {
+			buf.WriteByte(',')
+		}
+		*first = false
+		buf.WriteString(strconv.Quote(name) + ":")
+		if err := marshalValue(buf, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func marshalValue(buf *bytes.Buffer, v reflect.Value) error {
+	switch {
+	case v.Kind() == reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			buf.WriteString("null")
+			return nil
+		}
+	case v.Kind() == reflect.Map && v.Type().Elem().Kind() == reflect.Float64 && !v.IsNil():
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(strconv.Quote(k.String()) + ":")
+			if err := marshalValue(buf, v.MapIndex(k)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	}
+	p, err := json.Marshal(v.Interface())
+	buf.Write(p)
+	return err
+}
+
+func (s *TempSensor) MarshalJSON() ([]byte, error) {
+	return marshalSensor(s)
+}
+
+func (s *VoltageSensor) MarshalJSON() ([]byte, error) {
+	return marshalSensor(s)
+}
+
+func (s *FanSensor) MarshalJSON() ([]byte, error) {
+	return marshalSensor(s)
+}
+
+func (s *CurrentSensor) MarshalJSON() ([]byte, error) {
+	return marshalSensor(s)
+}
+
+func (s *GenericSensor) MarshalJSON() ([]byte, error) {
+	return marshalSensor(s)
+}
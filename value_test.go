@@ -0,0 +1,81 @@
+package lmsensors
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	sf "github.com/mt-inside/go-lmsensors/subfeature"
+)
+
+func TestSanitize(t *testing.T) {
+	cases := []struct {
+		typ  LmSensorType
+		v    float64
+		want bool // Whether it's kept
+	}{
+		{Temperature, 45, true},
+		{Temperature, -128, true},
+		{Temperature, 4294967.295, false},
+		{Temperature, math.Inf(1), false},
+		{Fan, -1, false},
+		{Energy, 1e15, true},
+		{Unhandled, math.NaN(), false},
+	}
+	for _, c := range cases {
+		got := sanitize(c.typ, c.v)
+		if kept := !math.IsNaN(got); kept != c.want {
+			t.Errorf("%s %v: kept=%t", c.typ, c.v, kept)
+		}
+	}
+}
+
+func TestNoValueJSON(t *testing.T) {
+	ts := &TempSensor{baseSensor: baseSensor{Name: "temp2", Value: NoValue, Subfeatures: map[string]float64{"temp2_max": 80, "temp2_input": math.Inf(1)}}, TempType: Thermistor, TempTypeRaw: 4}
+	p, err := json.Marshal(map[string]Sensor{"temp2": ts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"temp2":{"Name":"temp2","Value":null,"Subfeatures":{"temp2_input":null,"temp2_max":80},"TempType":4,"TempTypeRaw":4,"Crit":0,"Emergency":0,"Type":"Temperature","Unit":"°C","Alarm":false}}`
+	if string(p) != want {
+		t.Errorf("wrong JSON:\n got %s\nwant %s", p, want)
+	}
+	if ts.String() != "temp2: N/A°C (Thermistor)" {
+		t.Errorf("wrong rendering: %s", ts)
+	}
+
+	// Finite values encode exactly as encoding/json would.
+	vs := &VoltageSensor{baseSensor{Name: "in0", Value: 1.032}}
+	p, _ = json.Marshal(vs)
+	if string(p) != `{"Name":"in0","Value":1.032,"Type":"Voltage","Unit":"V","Alarm":false}` {
+		t.Errorf("wrong JSON: %s", p)
+	}
+}
+
+func TestValid(t *testing.T) {
+	if Valid(nil) {
+		t.Error("nil sensor is valid")
+	}
+	failed := newSensor("", Fan, 0, baseSensor{Name: "fan1", Value: NoValue}, func(sub sf.SubFeature) (float64, error) { return 0, sub })
+	if Valid(failed) || failed.Rendered() != "N/A" {
+		t.Errorf("failed read looks valid: %v", failed)
+	}
+	intrusion := newSensor("", Intrusion, 0, baseSensor{Name: "intrusion0", Value: NoValue}, func(sub sf.SubFeature) (float64, error) { return 0, sub })
+	if Valid(intrusion) || intrusion.Alarm() || intrusion.Rendered() != "N/A" {
+		t.Errorf("failed intrusion read looks valid, or in alarm: %v", intrusion)
+	}
+	if !Valid(&FanSensor{baseSensor: baseSensor{Name: "fan1"}}) || !Valid(&IntrusionSensor{}) {
+		t.Error("0 rpm isn't valid")
+	}
+
+	// Invalid sensors don't trip the detectors.
+	sys := tempSystem(NoValue)
+	sys.Chips["k10temp-pci-00c3"].Type = "k10temp"
+	if sum := sys.Summary(); sum.HasCPUTemp {
+		t.Errorf("invalid temperature in summary: %+v", sum)
+	}
+	if r, _ := NewSnapshot(sys, time.Time{}).Reading("k10temp-pci-00c3", "Tctl"); r.Valid {
+		t.Errorf("invalid reading in snapshot is valid: %+v", r)
+	}
+}
@@ -0,0 +1,186 @@
+package lmsensors
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// Watcher polls all the sensors periodically, feeding each reading through any detectors it's been configured with.
+// A Watcher must only be [Watcher.Run] once. Its polls are serialised with other uses of its [Library], so [Get] and the like can be called alongside it, but [Chips] and [Feature]s only inside [Library.Do].
+// Other goroutines can get the latest reading with [Watcher.Snapshot].
+type Watcher struct {
+	lib        *Library
+	getOpts    []Option
+	interval   time.Duration
+	filters    []Filter
+	handlers   []func(*System, error)
+	stuck      *stuckDetector
+	alarms     *alarmDetector
+	energy     *energyDetector
+	intrusions *intrusionDetector
+	thresholds *thresholdDetector
+	trends     *trendDetector
+	anomalies  *anomalyDetector
+	throttles  *throttleDetector
+
+	alarmStates alarmStates
+
+	align  bool
+	jitter time.Duration
+	base   time.Time // The last poll's unjittered time
+
+	chipIntervals []chipInterval
+	next          map[string]time.Time // When each chip is next due, with chipIntervals
+	last          map[string]*Chip     // The last reading of each chip, with chipIntervals
+
+	latest atomic.Pointer[Snapshot]
+	seq    uint64
+}
+
+// WatcherOption configures a [Watcher]
+type WatcherOption func(*Watcher)
+
+// NewWatcher makes a [Watcher] that polls every interval, which must be positive. [Init] must have been called before it's run.
+func NewWatcher(interval time.Duration, opts ...WatcherOption) *Watcher {
+	w := &Watcher{lib: defaultLibrary, interval: interval}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// WithLibrary reads the sensors with l, rather than the package's default [Library].
+func WithLibrary(l *Library) WatcherOption {
+	return func(w *Watcher) {
+		w.lib = l
+	}
+}
+
+// WithGetOptions reads the sensors with opts every poll, eg [WithLimitCheck].
+func WithGetOptions(opts ...Option) WatcherOption {
+	return func(w *Watcher) {
+		w.getOpts = append(w.getOpts, opts...)
+	}
+}
+
+// Filter changes every reading before the detectors see it, eg dropping sensors or relabelling them, as a config file might.
+type Filter interface {
+	Apply(*System)
+}
+
+// WithFilter applies f to every reading, before the detectors and handlers see it, so that they all see the same sensors under the same names.
+// Filters are applied in the order they're added; adding the same one again does nothing, so f must be comparable, eg a pointer.
+func WithFilter(f Filter) WatcherOption {
+	return func(w *Watcher) {
+		if !slices.Contains(w.filters, f) {
+			w.filters = append(w.filters, f)
+		}
+	}
+}
+
+// OnPoll registers a function to be called with the result of every poll, after the detectors have seen it.
+func OnPoll(fn func(*System, error)) WatcherOption {
+	return func(w *Watcher) {
+		w.handlers = append(w.handlers, fn)
+	}
+}
+
+// Run polls immediately, and then every interval, until ctx is done.
+// See [WithAlignment] and [WithJitter] for when exactly.
+func (w *Watcher) Run(ctx context.Context) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+		now := time.Now()
+		w.Poll(ctx)
+		timer.Reset(time.Until(w.Next(now)))
+	}
+}
+
+// Poll reads all the sensors once, as [Run] does every interval.
+func (w *Watcher) Poll(ctx context.Context) (*System, error) {
+	sys, err := w.get(ctx)
+	w.process(sys, err)
+	return sys, err
+}
+
+// Observe runs a reading got some other way, eg from [GetSysfs] or a recording, through the filters, detectors and handlers, as if it had been polled.
+// sys.Time should be set, as the detectors go by it.
+func (w *Watcher) Observe(sys *System) {
+	w.process(sys, nil)
+}
+
+func (w *Watcher) process(sys *System, err error) {
+	for _, f := range w.filters {
+		f.Apply(sys)
+	}
+	w.observe(sys)
+	for _, fn := range w.handlers {
+		fn(sys, err)
+	}
+	w.record(sys)
+}
+
+// record publishes a reading as the latest snapshot, numbering it.
+func (w *Watcher) record(sys *System) {
+	w.seq++
+	snap := NewSnapshot(sys, sys.Time)
+	snap.seq = w.seq
+	w.latest.Store(snap)
+}
+
+// Snapshot returns the latest reading, after the handlers have seen it, or nil before the first poll.
+// Unlike the [System]s the handlers get, it's safe to call from any goroutine.
+func (w *Watcher) Snapshot() *Snapshot {
+	return w.latest.Load()
+}
+
+// observe runs the detectors over a reading; it's separate from Poll so that they can be tested without hardware.
+func (w *Watcher) observe(sys *System) {
+	if w.stuck != nil {
+		w.stuck.observe(sys)
+	}
+	if w.alarms != nil {
+		w.alarms.observe(sys)
+	}
+	if w.energy != nil {
+		w.energy.observe(sys)
+	}
+	if w.intrusions != nil {
+		w.intrusions.observe(sys)
+	}
+	if w.thresholds != nil {
+		w.thresholds.observe(sys)
+	}
+	w.alarmStates.observe(sys, w.thresholds)
+	if w.trends != nil {
+		w.trends.observe(sys)
+	}
+	if w.anomalies != nil {
+		w.anomalies.observe(sys)
+	}
+	if w.throttles != nil {
+		w.throttles.observe(sys)
+	}
+}
+
+// sensorKey identifies a sensor across polls.
+func sensorKey(chip string, sensor string) string {
+	return chip + "/" + sensor
+}
+
+// valueOf returns the numeric reading of a sensor, if it has a valid one.
+func valueOf(s Sensor) (float64, bool) {
+	b, ok := s.(interface{ base() *baseSensor })
+	if !ok || !b.base().Valid() {
+		return 0, false
+	}
+	return b.base().Value, true
+}